@@ -0,0 +1,88 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+// Command daxsoak drives a mixed workload against a DAX cluster and reports latency
+// percentiles and error breakdowns.
+//
+// Usage:
+//
+//	daxsoak -endpoint dax://mycluster.frfx8h.clustercfg.dax.usw2.amazonaws.com -region us-west-2 \
+//		-table soak -pk id -mix get=80,put=15,query=5 -concurrency 32 -duration 10m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax"
+	"github.com/aws/aws-dax-go-v2/dax/soak"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", "", "cluster endpoint, e.g. dax://mycluster.frfx8h.clustercfg.dax.usw2.amazonaws.com")
+	region := flag.String("region", "", "AWS region of the cluster")
+	table := flag.String("table", "", "table to run the workload against")
+	pk := flag.String("pk", "id", "name of the string partition key of the table")
+	keys := flag.Int("keys", 100000, "number of distinct keys")
+	dist := flag.String("dist", string(soak.Uniform), "key distribution: uniform or zipf")
+	mix := flag.String("mix", "get=80,put=15,query=5", "relative operation weights")
+	itemSize := flag.Int("item-size", 512, "payload size in bytes of written items")
+	concurrency := flag.Int("concurrency", 16, "number of concurrent workers")
+	duration := flag.Duration("duration", time.Minute, "length of the run")
+	requests := flag.Int64("requests", 0, "stop after this many requests, 0 for no limit")
+	flag.Parse()
+
+	m, err := soak.ParseMix(*mix)
+	if err != nil {
+		fail(err)
+	}
+
+	cfg := dax.DefaultConfig()
+	cfg.HostPorts = []string{*endpoint}
+	cfg.Region = *region
+	client, err := dax.New(cfg)
+	if err != nil {
+		fail(err)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := soak.Run(ctx, client, soak.Config{
+		Table:        *table,
+		PartitionKey: *pk,
+		Keys:         *keys,
+		Distribution: soak.Distribution(*dist),
+		Mix:          m,
+		ItemSize:     *itemSize,
+		Concurrency:  *concurrency,
+		Duration:     *duration,
+		Requests:     *requests,
+	})
+	if err != nil {
+		fail(err)
+	}
+	report.WriteTo(os.Stdout)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "daxsoak:", err)
+	os.Exit(1)
+}
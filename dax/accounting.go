@@ -0,0 +1,52 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"io"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// OperationStats summarizes the requests, retries, latency, bytes transferred and
+// consumed capacity for a single table and operation.
+type OperationStats = client.OperationStats
+
+type operationReporter interface {
+	OperationReport() []client.OperationStats
+	ResetOperationReport()
+}
+
+// OperationReport returns the statistics accumulated by this client since it was
+// created or since the last call to ResetOperationReport, ordered by table and operation.
+func (d *Dax) OperationReport() []OperationStats {
+	if r, ok := d.client.(operationReporter); ok {
+		return r.OperationReport()
+	}
+	return nil
+}
+
+// WriteOperationReport writes the current operation report to w as a text table.
+func (d *Dax) WriteOperationReport(w io.Writer) error {
+	return client.WriteOperationReport(w, d.OperationReport())
+}
+
+// ResetOperationReport discards the accumulated operation statistics.
+func (d *Dax) ResetOperationReport() {
+	if r, ok := d.client.(operationReporter); ok {
+		r.ResetOperationReport()
+	}
+}
@@ -18,14 +18,20 @@ package dax
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
 )
 
-// DynamoDBAPI is compatible to aws-sdk-go-v2/service/dynamodb.Client
-type DynamoDBAPI interface {
+// ItemAPI is the item operations of DynamoDB, with the signatures of
+// aws-sdk-go-v2/service/dynamodb.Client. Both *dynamodb.Client and *Dax implement it, so code
+// depending on ItemAPI can be given either, for instance to read through DAX in production
+// and from DynamoDB Local in tests.
+type ItemAPI interface {
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
@@ -36,6 +42,12 @@ type DynamoDBAPI interface {
 	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
 	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 	TransactGetItems(ctx context.Context, params *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error)
+}
+
+// DynamoDBAPI is compatible to aws-sdk-go-v2/service/dynamodb.Client. The operations beyond
+// ItemAPI are not served by DAX, see Config.DynamoDB.
+type DynamoDBAPI interface {
+	ItemAPI
 
 	BatchExecuteStatement(ctx context.Context, params *dynamodb.BatchExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error)
 	CreateBackup(ctx context.Context, params *dynamodb.CreateBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateBackupOutput, error)
@@ -86,302 +98,660 @@ type DynamoDBAPI interface {
 	UpdateKinesisStreamingDestination(ctx context.Context, params *dynamodb.UpdateKinesisStreamingDestinationInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error)
 }
 
+var (
+	_ ItemAPI     = (*Dax)(nil)
+	_ ItemAPI     = (*dynamodb.Client)(nil)
+	_ DynamoDBAPI = (*Dax)(nil)
+	_ DynamoDBAPI = (*dynamodb.Client)(nil)
+)
+
 func (d *Dax) PutItem(ctx context.Context, input *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(false, ctx, optFns...)
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	o, cfn, err := d.config.requestOptions(OpPutItem, false, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.PutItemWithOptions(ctx, input, &dynamodb.PutItemOutput{}, o)
+	stamped := d.config.Timestamps.stampPutItem(d.config.Clock, input)
+	versioned, expected, err := d.config.versionPutItem(stamped)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := d.config.Encryption.encryptPutItem(versioned)
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.client.PutItemWithOptions(ctx, encrypted, &dynamodb.PutItemOutput{}, o)
+	if err != nil {
+		if versioned != stamped {
+			err = versionConflict(err, input.TableName, input.ConditionExpression, expected)
+		}
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItem(input.TableName, out.Attributes); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(false, ctx, optFns...)
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	o, cfn, err := d.config.requestOptions(OpDeleteItem, false, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.DeleteItemWithOptions(ctx, input, &dynamodb.DeleteItemOutput{}, o)
+	versioned, conditioned, expected, err := d.config.versionDeleteItem(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.client.DeleteItemWithOptions(ctx, versioned, &dynamodb.DeleteItemOutput{}, o)
+	if err != nil {
+		if conditioned {
+			err = versionConflict(err, input.TableName, input.ConditionExpression, expected)
+		}
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItem(input.TableName, out.Attributes); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(false, ctx, optFns...)
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	o, cfn, err := d.config.requestOptions(OpUpdateItem, false, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.UpdateItemWithOptions(ctx, input, &dynamodb.UpdateItemOutput{}, o)
+	if err = d.config.Encryption.checkUpdateItem(input); err != nil {
+		return nil, err
+	}
+	stamped, err := d.config.Timestamps.stampUpdateItem(d.config.Clock, input)
+	if err != nil {
+		return nil, err
+	}
+	versioned, conditioned, expected, err := d.config.versionUpdateItem(ctx, stamped)
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.client.UpdateItemWithOptions(ctx, versioned, &dynamodb.UpdateItemOutput{}, o)
+	if err != nil {
+		if conditioned {
+			err = versionConflict(err, input.TableName, input.ConditionExpression, expected)
+		}
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItem(input.TableName, out.Attributes); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) GetItem(ctx context.Context, input *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(true, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpGetItem, true, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.GetItemWithOptions(ctx, input, &dynamodb.GetItemOutput{}, o)
+	out, err := d.client.GetItemWithOptions(ctx, input, &dynamodb.GetItemOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItem(input.TableName, out.Item); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) Scan(ctx context.Context, input *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
-	o, cfn, err := d.config.requestOptions(true, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpScan, true, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.ScanWithOptions(ctx, input, &dynamodb.ScanOutput{}, o)
+	out, err := d.client.ScanWithOptions(ctx, input, &dynamodb.ScanOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItems(input.TableName, out.Items); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) Query(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
-	o, cfn, err := d.config.requestOptions(true, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpQuery, true, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.QueryWithOptions(ctx, input, &dynamodb.QueryOutput{}, o)
+	out, err := d.client.QueryWithOptions(ctx, input, &dynamodb.QueryOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItems(input.TableName, out.Items); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
 func (d *Dax) BatchWriteItem(ctx context.Context, input *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(false, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpBatchWriteItem, false, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.BatchWriteItemWithOptions(ctx, input, &dynamodb.BatchWriteItemOutput{}, o)
+	if err = d.config.checkBatchWriteItem(input); err != nil {
+		return nil, err
+	}
+	encrypted, err := d.config.Encryption.encryptBatchWriteItem(input)
+	if err != nil {
+		return nil, err
+	}
+	return d.client.BatchWriteItemWithOptions(ctx, encrypted, &dynamodb.BatchWriteItemOutput{}, o)
 }
 
 func (d *Dax) BatchGetItem(ctx context.Context, input *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
-	o, cfn, err := d.config.requestOptions(true, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpBatchGetItem, true, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.BatchGetItemWithOptions(ctx, input, &dynamodb.BatchGetItemOutput{}, o)
+	out, err := d.client.BatchGetItemWithOptions(ctx, input, &dynamodb.BatchGetItemOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	for table, items := range out.Responses {
+		if err = d.config.Encryption.decryptItems(aws.String(table), items); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
 }
 
 func (d *Dax) TransactWriteItems(ctx context.Context, input *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
-	o, cfn, err := d.config.requestOptions(false, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpTransactWriteItems, false, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.TransactWriteItemsWithOptions(ctx, input, &dynamodb.TransactWriteItemsOutput{}, o)
+	versioned, checks, err := d.config.versionTransactWriteItems(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := d.config.Encryption.encryptTransactWriteItems(versioned)
+	if err != nil {
+		return nil, err
+	}
+	out, err := d.client.TransactWriteItemsWithOptions(ctx, encrypted, &dynamodb.TransactWriteItemsOutput{}, o)
+	if err != nil {
+		return out, transactionVersionConflict(err, checks)
+	}
+	return out, nil
 }
 
 func (d *Dax) TransactGetItems(ctx context.Context, input *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error) {
-	o, cfn, err := d.config.requestOptions(true, ctx, optFns...)
+	o, cfn, err := d.config.requestOptions(OpTransactGetItems, true, ctx, optFns...)
 	if err != nil {
 		return nil, err
 	}
 	if cfn != nil {
 		defer cfn()
 	}
-	return d.client.TransactGetItemsWithOptions(ctx, input, &dynamodb.TransactGetItemsOutput{}, o)
+	out, err := d.client.TransactGetItemsWithOptions(ctx, input, &dynamodb.TransactGetItemsOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	if err = d.config.Encryption.decryptTransactGetItems(input, out); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (d *Dax) BatchExecuteStatement(context.Context, *dynamodb.BatchExecuteStatementInput, ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
-	return nil, d.unImpl()
+// BatchExecuteStatement runs a batch of PartiQL statements like ExecuteStatement. Reads
+// must select single items by their primary key. Batches containing writes are not
+// retried, since the statements of the batch that succeeded would run again.
+func (d *Dax) BatchExecuteStatement(ctx context.Context, input *dynamodb.BatchExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchExecuteStatementOutput, error) {
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	read := true
+	for _, s := range input.Statements {
+		table, r := client.StatementTarget(aws.ToString(s.Statement))
+		if err := d.config.checkStatement(table, r); err != nil {
+			return nil, err
+		}
+		read = read && r
+	}
+	o, cfn, err := d.config.requestOptions(client.OpBatchExecuteStatement, read, ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	out, err := d.client.BatchExecuteStatementWithOptions(ctx, input, &dynamodb.BatchExecuteStatementOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	for _, r := range out.Responses {
+		if err = d.config.Encryption.decryptItem(r.TableName, r.Item); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
 }
 
-func (d *Dax) CreateBackup(context.Context, *dynamodb.CreateBackupInput, ...func(*dynamodb.Options)) (*dynamodb.CreateBackupOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) CreateBackup(ctx context.Context, input *dynamodb.CreateBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateBackupOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.CreateBackup(ctx, input, optFns...)
 }
 
-func (d *Dax) CreateGlobalTable(context.Context, *dynamodb.CreateGlobalTableInput, ...func(*dynamodb.Options)) (*dynamodb.CreateGlobalTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) CreateGlobalTable(ctx context.Context, input *dynamodb.CreateGlobalTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateGlobalTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.CreateGlobalTable(ctx, input, optFns...)
 }
 
-func (d *Dax) CreateTable(context.Context, *dynamodb.CreateTableInput, ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) CreateTable(ctx context.Context, input *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.CreateTable(ctx, input, optFns...)
 }
 
-func (d *Dax) DeleteBackup(context.Context, *dynamodb.DeleteBackupInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteBackupOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DeleteBackup(ctx context.Context, input *dynamodb.DeleteBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteBackupOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DeleteBackup(ctx, input, optFns...)
 }
 
-func (d *Dax) DeleteTable(context.Context, *dynamodb.DeleteTableInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DeleteTable(ctx context.Context, input *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DeleteTable(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeBackup(context.Context, *dynamodb.DescribeBackupInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeBackupOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeBackup(ctx context.Context, input *dynamodb.DescribeBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeBackupOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeBackup(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeContinuousBackups(context.Context, *dynamodb.DescribeContinuousBackupsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeContinuousBackupsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeContinuousBackups(ctx context.Context, input *dynamodb.DescribeContinuousBackupsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeContinuousBackups(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeContributorInsights(context.Context, *dynamodb.DescribeContributorInsightsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeContributorInsightsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeContributorInsights(ctx context.Context, input *dynamodb.DescribeContributorInsightsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeContributorInsights(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeEndpoints(context.Context, *dynamodb.DescribeEndpointsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeEndpointsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeEndpoints(ctx context.Context, input *dynamodb.DescribeEndpointsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeEndpointsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeEndpoints(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeGlobalTable(context.Context, *dynamodb.DescribeGlobalTableInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeGlobalTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeGlobalTable(ctx context.Context, input *dynamodb.DescribeGlobalTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeGlobalTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeGlobalTable(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeGlobalTableSettings(context.Context, *dynamodb.DescribeGlobalTableSettingsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeGlobalTableSettings(ctx context.Context, input *dynamodb.DescribeGlobalTableSettingsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeGlobalTableSettings(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeImport(context.Context, *dynamodb.DescribeImportInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeImportOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeImport(ctx context.Context, input *dynamodb.DescribeImportInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeImportOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeImport(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeLimits(context.Context, *dynamodb.DescribeLimitsInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeLimitsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeLimits(ctx context.Context, input *dynamodb.DescribeLimitsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeLimitsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeLimits(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeTable(context.Context, *dynamodb.DescribeTableInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeTable(ctx context.Context, input *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeTable(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeTableReplicaAutoScaling(context.Context, *dynamodb.DescribeTableReplicaAutoScalingInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeTableReplicaAutoScaling(ctx context.Context, input *dynamodb.DescribeTableReplicaAutoScalingInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeTableReplicaAutoScaling(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeTimeToLive(context.Context, *dynamodb.DescribeTimeToLiveInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeTimeToLive(ctx context.Context, input *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeTimeToLive(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeExport(context.Context, *dynamodb.DescribeExportInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeExportOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeExport(ctx context.Context, input *dynamodb.DescribeExportInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeExportOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeExport(ctx, input, optFns...)
 }
 
-func (d *Dax) DescribeKinesisStreamingDestination(context.Context, *dynamodb.DescribeKinesisStreamingDestinationInput, ...func(*dynamodb.Options)) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DescribeKinesisStreamingDestination(ctx context.Context, input *dynamodb.DescribeKinesisStreamingDestinationInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DescribeKinesisStreamingDestination(ctx, input, optFns...)
 }
 
-func (d *Dax) DisableKinesisStreamingDestination(context.Context, *dynamodb.DisableKinesisStreamingDestinationInput, ...func(*dynamodb.Options)) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DisableKinesisStreamingDestination(ctx context.Context, input *dynamodb.DisableKinesisStreamingDestinationInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DisableKinesisStreamingDestination(ctx, input, optFns...)
 }
 
-func (d *Dax) EnableKinesisStreamingDestination(context.Context, *dynamodb.EnableKinesisStreamingDestinationInput, ...func(*dynamodb.Options)) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) EnableKinesisStreamingDestination(ctx context.Context, input *dynamodb.EnableKinesisStreamingDestinationInput, optFns ...func(*dynamodb.Options)) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.EnableKinesisStreamingDestination(ctx, input, optFns...)
 }
 
-func (d *Dax) ExecuteStatement(context.Context, *dynamodb.ExecuteStatementInput, ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
-	return nil, d.unImpl()
+// ExecuteStatement runs a PartiQL statement, which is translated on the client into the
+// equivalent GetItem, Query, Scan, PutItem, UpdateItem or DeleteItem request. Statements
+// cannot modify tables with optimistic locking or encrypted attributes.
+func (d *Dax) ExecuteStatement(ctx context.Context, input *dynamodb.ExecuteStatementInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteStatementOutput, error) {
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	table, read := client.StatementTarget(aws.ToString(input.Statement))
+	if err := d.config.checkStatement(table, read); err != nil {
+		return nil, err
+	}
+	o, cfn, err := d.config.requestOptions(client.OpExecuteStatement, read, ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	out, err := d.client.ExecuteStatementWithOptions(ctx, input, &dynamodb.ExecuteStatementOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	if err = d.config.Encryption.decryptItems(&table, out.Items); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (d *Dax) ExecuteTransaction(context.Context, *dynamodb.ExecuteTransactionInput, ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
-	return nil, d.unImpl()
+// checkStatement rejects statements writing to tables whose items are versioned or
+// encrypted by the client, which statements would bypass.
+func (c *Config) checkStatement(table string, read bool) error {
+	if read || (c.versionAttribute(&table) == "" && len(c.Encryption.attributes(&table)) == 0) {
+		return nil
+	}
+	invalidParams := smithy.InvalidParamsError{Context: "Statement"}
+	invalidParams.Add(client.NewCustomInvalidParamError("Statement", fmt.Sprintf("writes to table %s require PutItem, UpdateItem or DeleteItem", table)))
+	return invalidParams
+}
+
+// ExecuteTransaction runs the PartiQL statements of a transaction, which are translated
+// on the client into the items of a TransactGetItems request if they are all reads, or
+// of a TransactWriteItems request. Reads must select single items by their primary key.
+// The cancellation reasons of a canceled transaction are in the order of its statements.
+func (d *Dax) ExecuteTransaction(ctx context.Context, input *dynamodb.ExecuteTransactionInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExecuteTransactionOutput, error) {
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	read := true
+	tables := make([]string, len(input.TransactStatements))
+	for i, s := range input.TransactStatements {
+		table, r := client.StatementTarget(aws.ToString(s.Statement))
+		if err := d.config.checkStatement(table, r); err != nil {
+			return nil, err
+		}
+		tables[i] = table
+		read = read && r
+	}
+	o, cfn, err := d.config.requestOptions(client.OpExecuteTransaction, read, ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	out, err := d.client.ExecuteTransactionWithOptions(ctx, input, &dynamodb.ExecuteTransactionOutput{}, o)
+	if err != nil {
+		return out, err
+	}
+	for i, r := range out.Responses {
+		if i < len(tables) {
+			if err = d.config.Encryption.decryptItem(&tables[i], r.Item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
 }
 
-func (d *Dax) ExportTableToPointInTime(context.Context, *dynamodb.ExportTableToPointInTimeInput, ...func(*dynamodb.Options)) (*dynamodb.ExportTableToPointInTimeOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ExportTableToPointInTime(ctx context.Context, input *dynamodb.ExportTableToPointInTimeInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ExportTableToPointInTimeOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ExportTableToPointInTime(ctx, input, optFns...)
 }
 
-func (d *Dax) ListBackups(context.Context, *dynamodb.ListBackupsInput, ...func(*dynamodb.Options)) (*dynamodb.ListBackupsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListBackups(ctx context.Context, input *dynamodb.ListBackupsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListBackupsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListBackups(ctx, input, optFns...)
 }
 
-func (d *Dax) ImportTable(context.Context, *dynamodb.ImportTableInput, ...func(*dynamodb.Options)) (*dynamodb.ImportTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ImportTable(ctx context.Context, input *dynamodb.ImportTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ImportTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ImportTable(ctx, input, optFns...)
 }
 
-func (d *Dax) ListContributorInsights(context.Context, *dynamodb.ListContributorInsightsInput, ...func(*dynamodb.Options)) (*dynamodb.ListContributorInsightsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListContributorInsights(ctx context.Context, input *dynamodb.ListContributorInsightsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListContributorInsightsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListContributorInsights(ctx, input, optFns...)
 }
 
-func (d *Dax) ListExports(context.Context, *dynamodb.ListExportsInput, ...func(*dynamodb.Options)) (*dynamodb.ListExportsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListExports(ctx context.Context, input *dynamodb.ListExportsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListExportsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListExports(ctx, input, optFns...)
 }
 
-func (d *Dax) ListGlobalTables(context.Context, *dynamodb.ListGlobalTablesInput, ...func(*dynamodb.Options)) (*dynamodb.ListGlobalTablesOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListGlobalTables(ctx context.Context, input *dynamodb.ListGlobalTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListGlobalTablesOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListGlobalTables(ctx, input, optFns...)
 }
 
-func (d *Dax) ListImports(context.Context, *dynamodb.ListImportsInput, ...func(*dynamodb.Options)) (*dynamodb.ListImportsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListImports(ctx context.Context, input *dynamodb.ListImportsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListImportsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListImports(ctx, input, optFns...)
 }
 
-func (d *Dax) ListTables(context.Context, *dynamodb.ListTablesInput, ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListTables(ctx context.Context, input *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListTables(ctx, input, optFns...)
 }
 
-func (d *Dax) ListTagsOfResource(context.Context, *dynamodb.ListTagsOfResourceInput, ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) ListTagsOfResource(ctx context.Context, input *dynamodb.ListTagsOfResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.ListTagsOfResource(ctx, input, optFns...)
 }
 
-func (d *Dax) RestoreTableFromBackup(context.Context, *dynamodb.RestoreTableFromBackupInput, ...func(*dynamodb.Options)) (*dynamodb.RestoreTableFromBackupOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) RestoreTableFromBackup(ctx context.Context, input *dynamodb.RestoreTableFromBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.RestoreTableFromBackupOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.RestoreTableFromBackup(ctx, input, optFns...)
 }
 
-func (d *Dax) RestoreTableToPointInTime(context.Context, *dynamodb.RestoreTableToPointInTimeInput, ...func(*dynamodb.Options)) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) RestoreTableToPointInTime(ctx context.Context, input *dynamodb.RestoreTableToPointInTimeInput, optFns ...func(*dynamodb.Options)) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.RestoreTableToPointInTime(ctx, input, optFns...)
 }
 
-func (d *Dax) TagResource(context.Context, *dynamodb.TagResourceInput, ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) TagResource(ctx context.Context, input *dynamodb.TagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.TagResource(ctx, input, optFns...)
 }
 
-func (d *Dax) UntagResource(context.Context, *dynamodb.UntagResourceInput, ...func(*dynamodb.Options)) (*dynamodb.UntagResourceOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UntagResource(ctx context.Context, input *dynamodb.UntagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UntagResourceOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UntagResource(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateContinuousBackups(context.Context, *dynamodb.UpdateContinuousBackupsInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateContinuousBackupsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateContinuousBackups(ctx context.Context, input *dynamodb.UpdateContinuousBackupsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateContinuousBackups(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateContributorInsights(context.Context, *dynamodb.UpdateContributorInsightsInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateContributorInsightsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateContributorInsights(ctx context.Context, input *dynamodb.UpdateContributorInsightsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateContributorInsights(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateGlobalTable(context.Context, *dynamodb.UpdateGlobalTableInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateGlobalTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateGlobalTable(ctx context.Context, input *dynamodb.UpdateGlobalTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateGlobalTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateGlobalTable(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateGlobalTableSettings(context.Context, *dynamodb.UpdateGlobalTableSettingsInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateGlobalTableSettings(ctx context.Context, input *dynamodb.UpdateGlobalTableSettingsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateGlobalTableSettings(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateTable(context.Context, *dynamodb.UpdateTableInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateTable(ctx context.Context, input *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateTable(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateTableReplicaAutoScaling(context.Context, *dynamodb.UpdateTableReplicaAutoScalingInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateTableReplicaAutoScaling(ctx context.Context, input *dynamodb.UpdateTableReplicaAutoScalingInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateTableReplicaAutoScaling(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateTimeToLive(context.Context, *dynamodb.UpdateTimeToLiveInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateTimeToLive(ctx context.Context, input *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateTimeToLive(ctx, input, optFns...)
 }
 
-func (d *Dax) DeleteResourcePolicy(context.Context, *dynamodb.DeleteResourcePolicyInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteResourcePolicyOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) DeleteResourcePolicy(ctx context.Context, input *dynamodb.DeleteResourcePolicyInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteResourcePolicyOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.DeleteResourcePolicy(ctx, input, optFns...)
 }
 
-func (d *Dax) GetResourcePolicy(context.Context, *dynamodb.GetResourcePolicyInput, ...func(*dynamodb.Options)) (*dynamodb.GetResourcePolicyOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) GetResourcePolicy(ctx context.Context, input *dynamodb.GetResourcePolicyInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetResourcePolicyOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.GetResourcePolicy(ctx, input, optFns...)
 }
 
-func (d *Dax) PutResourcePolicy(context.Context, *dynamodb.PutResourcePolicyInput, ...func(*dynamodb.Options)) (*dynamodb.PutResourcePolicyOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) PutResourcePolicy(ctx context.Context, input *dynamodb.PutResourcePolicyInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutResourcePolicyOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.PutResourcePolicy(ctx, input, optFns...)
 }
 
-func (d *Dax) UpdateKinesisStreamingDestination(context.Context, *dynamodb.UpdateKinesisStreamingDestinationInput, ...func(*dynamodb.Options)) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
-	return nil, d.unImpl()
+func (d *Dax) UpdateKinesisStreamingDestination(ctx context.Context, input *dynamodb.UpdateKinesisStreamingDestinationInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
+	if d.config.DynamoDB == nil {
+		return nil, d.unImpl()
+	}
+	return d.config.DynamoDB.UpdateKinesisStreamingDestination(ctx, input, optFns...)
 }
 
 func (d *Dax) unImpl() error {
@@ -394,3 +764,16 @@ func (d *Dax) Close() error {
 	}
 	return nil
 }
+
+// CloseWithContext closes the client like Close, then waits for its background tasks to
+// exit and for requests in flight to finish until ctx is done, which bounds the time a
+// service shutdown hook spends on the client. The error returned lists the parts of the
+// client which didn't stop in time and wraps ctx.Err().
+func (d *Dax) CloseWithContext(ctx context.Context) error {
+	if c, ok := d.client.(interface {
+		CloseWithContext(context.Context) error
+	}); ok {
+		return c.CloseWithContext(ctx)
+	}
+	return d.Close()
+}
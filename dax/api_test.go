@@ -17,9 +17,18 @@ package dax
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUnimplementedBehavior(t *testing.T) {
@@ -36,6 +45,47 @@ func TestUnimplementedBehavior(t *testing.T) {
 	}
 }
 
+type dynamoDBHandler func(*http.Request) (*http.Response, error)
+
+func (h dynamoDBHandler) Do(r *http.Request) (*http.Response, error) {
+	return h(r)
+}
+
+func TestPassthroughToDynamoDB(t *testing.T) {
+	var target string
+	ddb := dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String("http://localhost"),
+		HTTPClient: dynamoDBHandler(func(r *http.Request) (*http.Response, error) {
+			target = r.Header.Get("X-Amz-Target")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+				Body:       io.NopCloser(strings.NewReader(`{"Table":{"TableName":"t"}}`)),
+			}, nil
+		}),
+	})
+	d := &Dax{client: &statementClient{}, config: Config{DynamoDB: ddb}}
+
+	out, err := d.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String("t")})
+	require.NoError(t, err)
+	assert.Equal(t, "t", aws.ToString(out.Table.TableName))
+	assert.Equal(t, "DynamoDB_20120810.DescribeTable", target)
+}
+
+func TestPassthroughToDynamoDB_fromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	d, err := NewWithOptions(context.Background(), cfg, WithDynamoDBConfig(aws.Config{Region: "eu-west-1"}))
+	require.NoError(t, err)
+	defer d.Close()
+
+	require.NotNil(t, d.config.DynamoDB)
+	assert.Equal(t, "eu-west-1", d.config.DynamoDB.Options().Region)
+}
+
 func createClient(t *testing.T) *Dax {
 	cfg := DefaultConfig()
 	cfg.HostPorts = []string{"127.0.0.1:8111"}
@@ -46,3 +96,97 @@ func createClient(t *testing.T) *Dax {
 	}
 	return dax
 }
+
+type statementClient struct {
+	client.DaxAPI
+	retries int
+}
+
+func (c *statementClient) BatchExecuteStatementWithOptions(_ context.Context, _ *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt client.RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	c.retries = opt.RetryMaxAttempts
+	return output, nil
+}
+
+func (c *statementClient) ExecuteStatementWithOptions(_ context.Context, _ *dynamodb.ExecuteStatementInput, output *dynamodb.ExecuteStatementOutput, opt client.RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	c.retries = opt.RetryMaxAttempts
+	return output, nil
+}
+
+func (c *statementClient) ExecuteTransactionWithOptions(_ context.Context, _ *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt client.RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	c.retries = opt.RetryMaxAttempts
+	return output, nil
+}
+
+func TestExecuteStatement(t *testing.T) {
+	c := &statementClient{}
+	d := &Dax{client: c, config: Config{ReadRetries: 3, WriteRetries: 1, VersionAttributes: map[string]string{"versioned": "v"}}}
+
+	_, err := d.ExecuteStatement(context.Background(), &dynamodb.ExecuteStatementInput{Statement: aws.String(`SELECT * FROM "versioned" WHERE pk = 'a'`)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, c.retries)
+	_, err = d.ExecuteStatement(context.Background(), &dynamodb.ExecuteStatementInput{Statement: aws.String(`DELETE FROM "plain" WHERE pk = 'a'`)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.retries)
+
+	var invalid smithy.InvalidParamsError
+	_, err = d.ExecuteStatement(context.Background(), &dynamodb.ExecuteStatementInput{Statement: aws.String(`DELETE FROM "versioned" WHERE pk = 'a'`)})
+	assert.ErrorAs(t, err, &invalid)
+
+	var required *smithy.ParamRequiredError
+	_, err = d.ExecuteStatement(context.Background(), nil)
+	assert.ErrorAs(t, err, &required)
+}
+
+func TestBatchExecuteStatement(t *testing.T) {
+	c := &statementClient{}
+	d := &Dax{client: c, config: Config{ReadRetries: 3, WriteRetries: 1, VersionAttributes: map[string]string{"versioned": "v"}}}
+	batch := func(statements ...string) *dynamodb.BatchExecuteStatementInput {
+		in := &dynamodb.BatchExecuteStatementInput{}
+		for _, s := range statements {
+			in.Statements = append(in.Statements, types.BatchStatementRequest{Statement: aws.String(s)})
+		}
+		return in
+	}
+
+	_, err := d.BatchExecuteStatement(context.Background(), batch(`SELECT * FROM "versioned" WHERE pk = 'a'`, `SELECT * FROM "plain" WHERE pk = 'b'`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, c.retries)
+	_, err = d.BatchExecuteStatement(context.Background(), batch(`DELETE FROM "plain" WHERE pk = 'a'`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.retries)
+
+	var invalid smithy.InvalidParamsError
+	_, err = d.BatchExecuteStatement(context.Background(), batch(`DELETE FROM "plain" WHERE pk = 'a'`, `DELETE FROM "versioned" WHERE pk = 'a'`))
+	assert.ErrorAs(t, err, &invalid)
+
+	var required *smithy.ParamRequiredError
+	_, err = d.BatchExecuteStatement(context.Background(), nil)
+	assert.ErrorAs(t, err, &required)
+}
+
+func TestExecuteTransaction(t *testing.T) {
+	c := &statementClient{}
+	d := &Dax{client: c, config: Config{ReadRetries: 3, WriteRetries: 1, VersionAttributes: map[string]string{"versioned": "v"}}}
+	transaction := func(statements ...string) *dynamodb.ExecuteTransactionInput {
+		in := &dynamodb.ExecuteTransactionInput{}
+		for _, s := range statements {
+			in.TransactStatements = append(in.TransactStatements, types.ParameterizedStatement{Statement: aws.String(s)})
+		}
+		return in
+	}
+
+	_, err := d.ExecuteTransaction(context.Background(), transaction(`SELECT * FROM "versioned" WHERE pk = 'a'`, `SELECT * FROM "plain" WHERE pk = 'b'`))
+	require.NoError(t, err)
+	assert.Equal(t, 3, c.retries)
+	_, err = d.ExecuteTransaction(context.Background(), transaction(`DELETE FROM "plain" WHERE pk = 'a'`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.retries)
+
+	var invalid smithy.InvalidParamsError
+	_, err = d.ExecuteTransaction(context.Background(), transaction(`DELETE FROM "plain" WHERE pk = 'a'`, `DELETE FROM "versioned" WHERE pk = 'a'`))
+	assert.ErrorAs(t, err, &invalid)
+
+	var required *smithy.ParamRequiredError
+	_, err = d.ExecuteTransaction(context.Background(), nil)
+	assert.ErrorAs(t, err, &required)
+}
@@ -0,0 +1,45 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// RetryLayer identifies the layer of the client which made an attempt of a request.
+// Requests are retried by the cluster layer only, within the ReadRetries or WriteRetries
+// budget; the node layer retries its own health checks.
+type RetryLayer = client.RetryLayer
+
+// Attempt describes a single attempt of a request, see GetAttempts.
+type Attempt = client.Attempt
+
+const (
+	RetryLayerCluster = client.RetryLayerCluster
+	RetryLayerNode    = client.RetryLayerNode
+)
+
+// GetAttempts returns the attempts made for a request from the ResultMetadata of its
+// output, in the order they were made.
+//
+//	out, err := svc.GetItem(ctx, input)
+//	if err == nil {
+//		attempts, _ := dax.GetAttempts(out.ResultMetadata)
+//	}
+func GetAttempts(metadata middleware.Metadata) ([]Attempt, bool) {
+	return client.GetAttempts(metadata)
+}
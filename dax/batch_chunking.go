@@ -0,0 +1,281 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// MaxBatchWriteItems is the maximum number of put and delete requests in a BatchWriteItem call.
+	MaxBatchWriteItems = 25
+	// MaxBatchGetItemKeys is the maximum number of keys in a BatchGetItem call.
+	MaxBatchGetItemKeys = 100
+)
+
+// BatchWriteConflicts selects how BatchWriteItem requests holding several puts or deletes
+// for the same key of a table are handled, see Config.BatchWriteConflicts.
+type BatchWriteConflicts = client.BatchWriteConflicts
+
+// BatchWriteConflictError reports the conflicting writes of a rejected BatchWriteItem
+// request: their table, key and positions in the requests of the table.
+type BatchWriteConflictError = client.BatchWriteConflictError
+
+const (
+	BatchWriteConflictsReject    = client.BatchWriteConflictsReject
+	BatchWriteConflictsSerialize = client.BatchWriteConflictsSerialize
+)
+
+// BatchChunk is one of the requests a logical batch was split into.
+type BatchChunk struct {
+	// Index of the chunk in the order the chunks were sent.
+	Index int
+	// Exactly one of the inputs is set, depending on the operation.
+	GetInput   *dynamodb.BatchGetItemInput
+	WriteInput *dynamodb.BatchWriteItemInput
+	// Err is the error the chunk failed with, nil for successful chunks.
+	Err error
+}
+
+// PartialBatchError is returned by BatchGetItemChunked and BatchWriteItemChunked when
+// some, but not necessarily all, chunks of the batch failed. The output returned with
+// it contains the results of the successful chunks, so only the failed chunks need to
+// be retried.
+type PartialBatchError struct {
+	Operation string
+	Succeeded []BatchChunk
+	Failed    []BatchChunk
+}
+
+func (e *PartialBatchError) Error() string {
+	return fmt.Sprintf("%s: %d of %d chunks failed, first error: %v",
+		e.Operation, len(e.Failed), len(e.Failed)+len(e.Succeeded), e.Failed[0].Err)
+}
+
+// Unwrap returns the errors of the failed chunks.
+func (e *PartialBatchError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, c := range e.Failed {
+		errs[i] = c.Err
+	}
+	return errs
+}
+
+// FailedBatchGetItemInput merges the inputs of the failed BatchGetItem chunks.
+func (e *PartialBatchError) FailedBatchGetItemInput() *dynamodb.BatchGetItemInput {
+	out := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{}}
+	for _, c := range e.Failed {
+		if c.GetInput == nil {
+			continue
+		}
+		out.ReturnConsumedCapacity = c.GetInput.ReturnConsumedCapacity
+		for table, kaa := range c.GetInput.RequestItems {
+			merged, ok := out.RequestItems[table]
+			if !ok {
+				merged = kaa
+				merged.Keys = nil
+			}
+			merged.Keys = append(merged.Keys, kaa.Keys...)
+			out.RequestItems[table] = merged
+		}
+	}
+	return out
+}
+
+// FailedBatchWriteItemInput merges the inputs of the failed BatchWriteItem chunks.
+func (e *PartialBatchError) FailedBatchWriteItemInput() *dynamodb.BatchWriteItemInput {
+	out := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{}}
+	for _, c := range e.Failed {
+		if c.WriteInput == nil {
+			continue
+		}
+		out.ReturnConsumedCapacity = c.WriteInput.ReturnConsumedCapacity
+		out.ReturnItemCollectionMetrics = c.WriteInput.ReturnItemCollectionMetrics
+		for table, wrs := range c.WriteInput.RequestItems {
+			out.RequestItems[table] = append(out.RequestItems[table], wrs...)
+		}
+	}
+	return out
+}
+
+// BatchGetItemChunked splits input into BatchGetItem calls of at most MaxBatchGetItemKeys
+// keys and merges their outputs. Unprocessed keys are returned as usual; chunks that
+// fail are reported through a *PartialBatchError.
+func (d *Dax) BatchGetItemChunked(ctx context.Context, input *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	chunks := splitBatchGetItemInput(input, MaxBatchGetItemKeys)
+	if len(chunks) <= 1 {
+		return d.BatchGetItem(ctx, input, optFns...)
+	}
+
+	out := &dynamodb.BatchGetItemOutput{}
+	pbe := &PartialBatchError{Operation: "BatchGetItem"}
+	for i, in := range chunks {
+		res, err := d.BatchGetItem(ctx, in, optFns...)
+		chunk := BatchChunk{Index: i, GetInput: in, Err: err}
+		if err != nil {
+			pbe.Failed = append(pbe.Failed, chunk)
+			continue
+		}
+		pbe.Succeeded = append(pbe.Succeeded, chunk)
+		mergeBatchGetItemOutput(out, res)
+	}
+	if len(pbe.Failed) > 0 {
+		return out, pbe
+	}
+	return out, nil
+}
+
+// BatchWriteItemChunked splits input into BatchWriteItem calls of at most MaxBatchWriteItems
+// requests and merges their outputs. Unprocessed items are returned as usual; chunks that
+// fail are reported through a *PartialBatchError.
+func (d *Dax) BatchWriteItemChunked(ctx context.Context, input *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	chunks := splitBatchWriteItemInput(input, MaxBatchWriteItems)
+	if len(chunks) <= 1 {
+		return d.BatchWriteItem(ctx, input, optFns...)
+	}
+
+	out := &dynamodb.BatchWriteItemOutput{}
+	pbe := &PartialBatchError{Operation: "BatchWriteItem"}
+	for i, in := range chunks {
+		res, err := d.BatchWriteItem(ctx, in, optFns...)
+		chunk := BatchChunk{Index: i, WriteInput: in, Err: err}
+		if err != nil {
+			pbe.Failed = append(pbe.Failed, chunk)
+			continue
+		}
+		pbe.Succeeded = append(pbe.Succeeded, chunk)
+		mergeBatchWriteItemOutput(out, res)
+	}
+	if len(pbe.Failed) > 0 {
+		return out, pbe
+	}
+	return out, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitBatchGetItemInput(input *dynamodb.BatchGetItemInput, size int) []*dynamodb.BatchGetItemInput {
+	if input == nil {
+		return nil
+	}
+	var chunks []*dynamodb.BatchGetItemInput
+	var cur *dynamodb.BatchGetItemInput
+	n := 0
+	for _, table := range sortedKeys(input.RequestItems) {
+		kaa := input.RequestItems[table]
+		for _, key := range kaa.Keys {
+			if cur == nil || n == size {
+				cur = &dynamodb.BatchGetItemInput{
+					RequestItems:           map[string]types.KeysAndAttributes{},
+					ReturnConsumedCapacity: input.ReturnConsumedCapacity,
+				}
+				chunks = append(chunks, cur)
+				n = 0
+			}
+			tkaa, ok := cur.RequestItems[table]
+			if !ok {
+				tkaa = kaa
+				tkaa.Keys = nil
+			}
+			tkaa.Keys = append(tkaa.Keys, key)
+			cur.RequestItems[table] = tkaa
+			n++
+		}
+	}
+	return chunks
+}
+
+func splitBatchWriteItemInput(input *dynamodb.BatchWriteItemInput, size int) []*dynamodb.BatchWriteItemInput {
+	if input == nil {
+		return nil
+	}
+	var chunks []*dynamodb.BatchWriteItemInput
+	var cur *dynamodb.BatchWriteItemInput
+	n := 0
+	for _, table := range sortedKeys(input.RequestItems) {
+		for _, wr := range input.RequestItems[table] {
+			if cur == nil || n == size {
+				cur = &dynamodb.BatchWriteItemInput{
+					RequestItems:                map[string][]types.WriteRequest{},
+					ReturnConsumedCapacity:      input.ReturnConsumedCapacity,
+					ReturnItemCollectionMetrics: input.ReturnItemCollectionMetrics,
+				}
+				chunks = append(chunks, cur)
+				n = 0
+			}
+			cur.RequestItems[table] = append(cur.RequestItems[table], wr)
+			n++
+		}
+	}
+	return chunks
+}
+
+func mergeBatchGetItemOutput(dst, src *dynamodb.BatchGetItemOutput) {
+	if src == nil {
+		return
+	}
+	for table, items := range src.Responses {
+		if dst.Responses == nil {
+			dst.Responses = map[string][]map[string]types.AttributeValue{}
+		}
+		dst.Responses[table] = append(dst.Responses[table], items...)
+	}
+	for table, kaa := range src.UnprocessedKeys {
+		if dst.UnprocessedKeys == nil {
+			dst.UnprocessedKeys = map[string]types.KeysAndAttributes{}
+		}
+		merged, ok := dst.UnprocessedKeys[table]
+		if !ok {
+			merged = kaa
+			merged.Keys = nil
+		}
+		merged.Keys = append(merged.Keys, kaa.Keys...)
+		dst.UnprocessedKeys[table] = merged
+	}
+	dst.ConsumedCapacity = append(dst.ConsumedCapacity, src.ConsumedCapacity...)
+}
+
+func mergeBatchWriteItemOutput(dst, src *dynamodb.BatchWriteItemOutput) {
+	if src == nil {
+		return
+	}
+	for table, wrs := range src.UnprocessedItems {
+		if dst.UnprocessedItems == nil {
+			dst.UnprocessedItems = map[string][]types.WriteRequest{}
+		}
+		dst.UnprocessedItems[table] = append(dst.UnprocessedItems[table], wrs...)
+	}
+	for table, metrics := range src.ItemCollectionMetrics {
+		if dst.ItemCollectionMetrics == nil {
+			dst.ItemCollectionMetrics = map[string][]types.ItemCollectionMetrics{}
+		}
+		dst.ItemCollectionMetrics[table] = append(dst.ItemCollectionMetrics[table], metrics...)
+	}
+	dst.ConsumedCapacity = append(dst.ConsumedCapacity, src.ConsumedCapacity...)
+}
@@ -0,0 +1,149 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chunkingClient struct {
+	client.DaxAPI
+	calls  int
+	failAt map[int]error
+}
+
+func (c *chunkingClient) BatchWriteItemWithOptions(_ context.Context, input *dynamodb.BatchWriteItemInput, output *dynamodb.BatchWriteItemOutput, _ client.RequestOptions) (*dynamodb.BatchWriteItemOutput, error) {
+	call := c.calls
+	c.calls++
+	if err := c.failAt[call]; err != nil {
+		return nil, err
+	}
+	output.ConsumedCapacity = []types.ConsumedCapacity{{}}
+	return output, nil
+}
+
+func (c *chunkingClient) BatchGetItemWithOptions(_ context.Context, input *dynamodb.BatchGetItemInput, output *dynamodb.BatchGetItemOutput, _ client.RequestOptions) (*dynamodb.BatchGetItemOutput, error) {
+	call := c.calls
+	c.calls++
+	if err := c.failAt[call]; err != nil {
+		return nil, err
+	}
+	output.Responses = map[string][]map[string]types.AttributeValue{}
+	for table, kaa := range input.RequestItems {
+		output.Responses[table] = kaa.Keys
+	}
+	return output, nil
+}
+
+func testKeys(n int) []map[string]types.AttributeValue {
+	keys := make([]map[string]types.AttributeValue, n)
+	for i := range keys {
+		keys[i] = map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: fmt.Sprint(i)}}
+	}
+	return keys
+}
+
+func TestBatchWriteItemChunked_partialFailure(t *testing.T) {
+	boom := errors.New("boom")
+	c := &chunkingClient{failAt: map[int]error{1: boom}}
+	d := &Dax{client: c}
+
+	var writes []types.WriteRequest
+	for _, key := range testKeys(60) {
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: key}})
+	}
+	input := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{"table": writes}}
+
+	out, err := d.BatchWriteItemChunked(context.Background(), input)
+	assert.Equal(t, 3, c.calls)
+	assert.Len(t, out.ConsumedCapacity, 2)
+
+	var pbe *PartialBatchError
+	require.ErrorAs(t, err, &pbe)
+	assert.ErrorIs(t, err, boom)
+	assert.Len(t, pbe.Succeeded, 2)
+	require.Len(t, pbe.Failed, 1)
+	assert.Equal(t, 1, pbe.Failed[0].Index)
+
+	retry := pbe.FailedBatchWriteItemInput()
+	assert.Equal(t, writes[MaxBatchWriteItems:2*MaxBatchWriteItems], retry.RequestItems["table"])
+}
+
+func TestBatchGetItemChunked(t *testing.T) {
+	c := &chunkingClient{}
+	d := &Dax{client: c}
+	input := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"a": {Keys: testKeys(70), ProjectionExpression: new(string)},
+		"b": {Keys: testKeys(70)},
+	}}
+
+	out, err := d.BatchGetItemChunked(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.calls)
+	assert.Len(t, out.Responses["a"], 70)
+	assert.Len(t, out.Responses["b"], 70)
+
+	chunks := splitBatchGetItemInput(input, MaxBatchGetItemKeys)
+	require.Len(t, chunks, 2)
+	assert.Len(t, chunks[0].RequestItems["b"].Keys, 30)
+	assert.NotNil(t, chunks[1].RequestItems["b"].Keys)
+	assert.NotNil(t, chunks[0].RequestItems["a"].ProjectionExpression)
+}
+
+func TestBatchGetItemOrdered(t *testing.T) {
+	key := func(id string, n string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: id},
+			"n":  &types.AttributeValueMemberN{Value: n},
+		}
+	}
+	input := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"t": {Keys: []map[string]types.AttributeValue{key("a", "1"), key("b", "2.0"), key("c", "3")}},
+	}}
+	item := func(id, n, v string) map[string]types.AttributeValue {
+		it := key(id, n)
+		it["v"] = &types.AttributeValueMemberS{Value: v}
+		return it
+	}
+	responses := map[string][]map[string]types.AttributeValue{
+		"t": {item("c", "3", "third"), item("b", "2", "second")},
+	}
+
+	ordered := OrderBatchGetItemResponses(input, responses)
+	require.Len(t, ordered["t"], 3)
+	assert.Nil(t, ordered["t"][0])
+	assert.Equal(t, responses["t"][1], ordered["t"][1])
+	assert.Equal(t, responses["t"][0], ordered["t"][2])
+
+	d := &Dax{client: &chunkingClient{}}
+	out, err := d.BatchGetItemOrdered(context.Background(), &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"t": {Keys: testKeys(150)},
+	}})
+	require.NoError(t, err)
+	require.Len(t, out.Items["t"], 150)
+	for i, it := range out.Items["t"] {
+		assert.Equal(t, testKeys(150)[i], it)
+	}
+}
@@ -33,6 +33,7 @@ type BatchGetItemPaginator struct {
 	firstPage    bool
 	requestItems map[string]types.KeysAndAttributes
 	isTruncated  bool
+	stats        BatchGetStats
 }
 
 // NewBatchGetItemPaginator returns a new BatchGetItemPaginator
@@ -74,7 +75,12 @@ func (p *BatchGetItemPaginator) NextPage(ctx context.Context, optFns ...func(*dy
 	if err != nil {
 		return nil, err
 	}
+	if p.firstPage {
+		p.stats.Keys = countKeys(params.RequestItems)
+	}
 	p.firstPage = false
+	p.stats.Rounds++
+	p.stats.Unprocessed = append(p.stats.Unprocessed, countKeys(result.UnprocessedKeys))
 
 	prevToken := p.requestItems
 	p.isTruncated = len(result.UnprocessedKeys) != 0
@@ -89,10 +95,21 @@ func (p *BatchGetItemPaginator) NextPage(ctx context.Context, optFns ...func(*dy
 		DeepEqual(prevToken, p.requestItems) {
 		p.isTruncated = false
 	}
+	if !p.isTruncated {
+		recordBatchGetRounds(ctx, p.client, p.stats.Rounds)
+	}
 
 	return result, nil
 }
 
+// Stats returns the rounds of BatchGetItem calls made so far and the keys each of them
+// left unprocessed.
+func (p *BatchGetItemPaginator) Stats() BatchGetStats {
+	stats := p.stats
+	stats.Unprocessed = append([]int(nil), p.stats.Unprocessed...)
+	return stats
+}
+
 // Source: https://github.com/aws/aws-sdk-go-v2/blob/78fa10aa9eaaa0851b0006145382ec0a0f4304c5/internal/awsutil/equal.go#L13
 // DeepEqual returns if the two values are deeply equal like reflect.DeepEqual.
 // In addition to this, this method will also dereference the input values if
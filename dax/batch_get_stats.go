@@ -0,0 +1,57 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BatchGetStats describes the rounds of BatchGetItem calls made to fetch a set of keys,
+// each round retrying the keys the previous one left unprocessed. Unprocessed ratios
+// rising over time point at capacity problems before requests start failing.
+type BatchGetStats struct {
+	// Rounds is the number of calls made.
+	Rounds int
+	// Keys is the number of keys requested by the first round.
+	Keys int
+	// Unprocessed holds the number of keys left unprocessed by each round.
+	Unprocessed []int
+}
+
+type batchGetRoundsRecorder interface {
+	RecordBatchGetRounds(ctx context.Context, rounds int)
+}
+
+// recordBatchGetRounds records the number of rounds in the dax.workload.batch_get.rounds
+// histogram when c is a DAX client.
+func recordBatchGetRounds(ctx context.Context, c interface{}, rounds int) {
+	if d, ok := c.(*Dax); ok {
+		c = d.client
+	}
+	if r, ok := c.(batchGetRoundsRecorder); ok {
+		r.RecordBatchGetRounds(ctx, rounds)
+	}
+}
+
+func countKeys(items map[string]types.KeysAndAttributes) int {
+	n := 0
+	for _, kaa := range items {
+		n += len(kaa.Keys)
+	}
+	return n
+}
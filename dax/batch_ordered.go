@@ -0,0 +1,114 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"encoding/base64"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OrderedBatchGetItemOutput is the output of BatchGetItemOrdered.
+type OrderedBatchGetItemOutput struct {
+	// Items holds, per table, one entry for every key of the input in the same order.
+	// Entries of keys without an item, or which were not processed, are nil.
+	Items map[string][]map[string]types.AttributeValue
+	// UnprocessedKeys tells unprocessed keys apart from keys without an item.
+	UnprocessedKeys  map[string]types.KeysAndAttributes
+	ConsumedCapacity []types.ConsumedCapacity
+}
+
+// BatchGetItemOrdered is BatchGetItemChunked with the items of every table ordered to
+// match the order of the keys in input, saving callers from matching items to keys.
+// Items are matched by their key attributes, so a projection must include them.
+func (d *Dax) BatchGetItemOrdered(ctx context.Context, input *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*OrderedBatchGetItemOutput, error) {
+	out, err := d.BatchGetItemChunked(ctx, input, optFns...)
+	if out == nil {
+		return nil, err
+	}
+	return &OrderedBatchGetItemOutput{
+		Items:            OrderBatchGetItemResponses(input, out.Responses),
+		UnprocessedKeys:  out.UnprocessedKeys,
+		ConsumedCapacity: out.ConsumedCapacity,
+	}, err
+}
+
+// OrderBatchGetItemResponses orders the responses of one or more BatchGetItem calls,
+// e.g. the pages of a BatchGetItemPaginator, to match the key order of input. Every
+// table of input gets one entry per key, nil for keys without a response.
+func OrderBatchGetItemResponses(input *dynamodb.BatchGetItemInput, responses map[string][]map[string]types.AttributeValue) map[string][]map[string]types.AttributeValue {
+	if input == nil {
+		return nil
+	}
+	ordered := make(map[string][]map[string]types.AttributeValue, len(input.RequestItems))
+	for table, kaa := range input.RequestItems {
+		items := make([]map[string]types.AttributeValue, len(kaa.Keys))
+		ordered[table] = items
+		if len(kaa.Keys) == 0 {
+			continue
+		}
+		index := make(map[string]int, len(kaa.Keys))
+		for i, key := range kaa.Keys {
+			index[itemKey(key, key)] = i
+		}
+		// Keys of one table share their attribute names.
+		for _, item := range responses[table] {
+			if i, ok := index[itemKey(kaa.Keys[0], item)]; ok {
+				items[i] = item
+			}
+		}
+	}
+	return ordered
+}
+
+// itemKey returns a canonical representation of the attributes of item named in key.
+func itemKey(key, item map[string]types.AttributeValue) string {
+	names := sortedKeys(key)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte(0)
+		switch v := item[name].(type) {
+		case *types.AttributeValueMemberS:
+			sb.WriteString("S")
+			sb.WriteString(v.Value)
+		case *types.AttributeValueMemberN:
+			sb.WriteString("N")
+			sb.WriteString(canonicalNumber(v.Value))
+		case *types.AttributeValueMemberB:
+			sb.WriteString("B")
+			sb.WriteString(base64.StdEncoding.EncodeToString(v.Value))
+		default:
+			// Not a valid key, never matches.
+			return "\x00"
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+// canonicalNumber normalizes numbers such as "1.0" and "1" that DynamoDB considers equal.
+func canonicalNumber(n string) string {
+	f, ok := new(big.Float).SetPrec(256).SetString(n)
+	if !ok {
+		return n
+	}
+	return f.Text('g', -1)
+}
@@ -0,0 +1,253 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/metrics"
+)
+
+const (
+	// MetricPropertyClient is the metric property holding the name a client was added to a ClientSet with.
+	MetricPropertyClient = "dax.client"
+	// MetricPropertyRegion is the metric property holding the Region of a client in a ClientSet.
+	MetricPropertyRegion = "dax.region"
+)
+
+// ClientSet manages Dax clients for several Regions or clusters, for example to read
+// from Region-local DAX clusters in front of a global table.
+//
+// All clients in the set share a single credential cache, so credentials are only
+// refreshed once regardless of the number of clusters, and share a MeterProvider whose
+// measurements are labeled with the client name and Region.
+//
+// ClientSet methods are safe to use concurrently.
+type ClientSet struct {
+	credentials   aws.CredentialsProvider
+	meterProvider metrics.MeterProvider
+
+	mu      sync.RWMutex
+	clients map[string]*Dax
+	closed  bool
+}
+
+// NewClientSet creates an empty ClientSet. credentials is wrapped in an aws.CredentialsCache
+// unless it already is one. meterProvider may be nil, in which case each client keeps the
+// MeterProvider of its own Config.
+func NewClientSet(credentials aws.CredentialsProvider, meterProvider metrics.MeterProvider) *ClientSet {
+	if _, ok := credentials.(*aws.CredentialsCache); !ok && credentials != nil {
+		credentials = aws.NewCredentialsCache(credentials)
+	}
+	return &ClientSet{
+		credentials:   credentials,
+		meterProvider: meterProvider,
+		clients:       make(map[string]*Dax),
+	}
+}
+
+// Add creates a client from cfg and registers it under name. The shared credentials and
+// MeterProvider of the set take precedence over the ones in cfg.
+func (s *ClientSet) Add(name string, cfg Config) (*Dax, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, errors.New("dax: client set is closed")
+	}
+	if _, ok := s.clients[name]; ok {
+		return nil, fmt.Errorf("dax: client %q already exists in client set", name)
+	}
+
+	if s.credentials != nil {
+		cfg.Credentials = s.credentials
+	}
+	if s.meterProvider != nil {
+		cfg.MeterProvider = &labeledMeterProvider{
+			MeterProvider: s.meterProvider,
+			labels: map[string]string{
+				MetricPropertyClient: name,
+				MetricPropertyRegion: cfg.Region,
+			},
+		}
+	}
+
+	c, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[name] = c
+	return c, nil
+}
+
+// Get returns the client registered under name.
+func (s *ClientSet) Get(name string) (*Dax, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.clients[name]
+	return c, ok
+}
+
+// ForRegion returns a client configured for region. When several clients share the
+// Region, the one with the lowest name is returned.
+func (s *ClientSet) ForRegion(region string) (*Dax, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, name := range s.sortedNames() {
+		if c := s.clients[name]; c.config.Region == region {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of all registered clients in sorted order.
+func (s *ClientSet) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedNames()
+}
+
+func (s *ClientSet) sortedNames() []string {
+	names := make([]string, 0, len(s.clients))
+	for name := range s.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Remove closes the client registered under name and removes it from the set.
+func (s *ClientSet) Remove(name string) error {
+	s.mu.Lock()
+	c, ok := s.clients[name]
+	delete(s.clients, name)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return c.Close()
+}
+
+// Close closes every client in the set. The set cannot be used afterwards.
+func (s *ClientSet) Close() error {
+	return s.closeAll(func(c *Dax) error {
+		return c.Close()
+	})
+}
+
+// CloseWithContext closes every client in the set with Dax.CloseWithContext, bounded by
+// the same ctx. The set cannot be used afterwards.
+func (s *ClientSet) CloseWithContext(ctx context.Context) error {
+	return s.closeAll(func(c *Dax) error {
+		return c.CloseWithContext(ctx)
+	})
+}
+
+func (s *ClientSet) closeAll(closeFn func(c *Dax) error) error {
+	s.mu.Lock()
+	clients := s.clients
+	s.clients = make(map[string]*Dax)
+	s.closed = true
+	s.mu.Unlock()
+
+	var errs []error
+	for name, c := range clients {
+		if err := closeFn(c); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// labeledMeterProvider attaches a fixed set of properties to every measurement
+// recorded through the instruments used by the DAX client.
+type labeledMeterProvider struct {
+	metrics.MeterProvider
+	labels map[string]string
+}
+
+func (p *labeledMeterProvider) Meter(scope string, opts ...metrics.MeterOption) metrics.Meter {
+	return &labeledMeter{Meter: p.MeterProvider.Meter(scope, opts...), labels: p.labels}
+}
+
+type labeledMeter struct {
+	metrics.Meter
+	labels map[string]string
+}
+
+func (m *labeledMeter) Int64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Int64Counter, error) {
+	c, err := m.Meter.Int64Counter(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &labeledInt64Counter{Int64Counter: c, label: m.withLabels}, nil
+}
+
+func (m *labeledMeter) Int64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Int64Gauge, error) {
+	g, err := m.Meter.Int64Gauge(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &labeledInt64Gauge{Int64Gauge: g, label: m.withLabels}, nil
+}
+
+func (m *labeledMeter) Int64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	h, err := m.Meter.Int64Histogram(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &labeledInt64Histogram{Int64Histogram: h, label: m.withLabels}, nil
+}
+
+func (m *labeledMeter) withLabels(opts []metrics.RecordMetricOption) []metrics.RecordMetricOption {
+	return append(opts, func(o *metrics.RecordMetricOptions) {
+		for k, v := range m.labels {
+			o.Properties.Set(k, v)
+		}
+	})
+}
+
+type labeledInt64Counter struct {
+	metrics.Int64Counter
+	label func([]metrics.RecordMetricOption) []metrics.RecordMetricOption
+}
+
+func (c *labeledInt64Counter) Add(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	c.Int64Counter.Add(ctx, v, c.label(opts)...)
+}
+
+type labeledInt64Gauge struct {
+	metrics.Int64Gauge
+	label func([]metrics.RecordMetricOption) []metrics.RecordMetricOption
+}
+
+func (g *labeledInt64Gauge) Sample(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	g.Int64Gauge.Sample(ctx, v, g.label(opts)...)
+}
+
+type labeledInt64Histogram struct {
+	metrics.Int64Histogram
+	label func([]metrics.RecordMetricOption) []metrics.RecordMetricOption
+}
+
+func (h *labeledInt64Histogram) Record(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	h.Int64Histogram.Record(ctx, v, h.label(opts)...)
+}
@@ -0,0 +1,103 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSet_lookup(t *testing.T) {
+	s := NewClientSet(aws.AnonymousCredentials{}, nil)
+	_, ok := s.credentials.(*aws.CredentialsCache)
+	assert.True(t, ok, "credentials should be cached")
+
+	s.clients["west-b"] = &Dax{config: Config{Config: client.Config{Region: "us-west-2"}}}
+	s.clients["west-a"] = &Dax{config: Config{Config: client.Config{Region: "us-west-2"}}}
+	s.clients["east"] = &Dax{config: Config{Config: client.Config{Region: "us-east-1"}}}
+
+	assert.Equal(t, []string{"east", "west-a", "west-b"}, s.Names())
+
+	c, ok := s.ForRegion("us-west-2")
+	require.True(t, ok)
+	assert.Same(t, s.clients["west-a"], c)
+
+	_, ok = s.ForRegion("eu-west-1")
+	assert.False(t, ok)
+
+	assert.NoError(t, s.Remove("east"))
+	_, ok = s.Get("east")
+	assert.False(t, ok)
+
+	assert.NoError(t, s.Close())
+	assert.Empty(t, s.Names())
+	_, err := s.Add("again", DefaultConfig())
+	assert.Error(t, err)
+}
+
+type recordingCounter struct {
+	metrics.Int64Counter
+	props map[string]any
+}
+
+func (c *recordingCounter) Add(_ context.Context, _ int64, opts ...metrics.RecordMetricOption) {
+	var o metrics.RecordMetricOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	c.props = map[string]any{
+		MetricPropertyClient: o.Properties.Get(MetricPropertyClient),
+		MetricPropertyRegion: o.Properties.Get(MetricPropertyRegion),
+	}
+}
+
+type recordingMeter struct {
+	metrics.Meter
+	counter *recordingCounter
+}
+
+func (m *recordingMeter) Int64Counter(string, ...metrics.InstrumentOption) (metrics.Int64Counter, error) {
+	return m.counter, nil
+}
+
+type recordingMeterProvider struct {
+	meter *recordingMeter
+}
+
+func (p *recordingMeterProvider) Meter(string, ...metrics.MeterOption) metrics.Meter {
+	return p.meter
+}
+
+func TestLabeledMeterProvider(t *testing.T) {
+	rc := &recordingCounter{}
+	mp := &labeledMeterProvider{
+		MeterProvider: &recordingMeterProvider{meter: &recordingMeter{counter: rc}},
+		labels:        map[string]string{MetricPropertyClient: "west", MetricPropertyRegion: "us-west-2"},
+	}
+
+	c, err := mp.Meter("scope").Int64Counter("counter")
+	require.NoError(t, err)
+	c.Add(context.Background(), 1)
+
+	assert.Equal(t, "west", rc.props[MetricPropertyClient])
+	assert.Equal(t, "us-west-2", rc.props[MetricPropertyRegion])
+}
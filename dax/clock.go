@@ -0,0 +1,30 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// Clock is the source of time of the retry delays, background tasks and timers of the
+// client, see Config.Clock.
+type Clock = client.Clock
+
+// Timer is a timer created by a Clock.
+type Timer = client.Timer
+
+// SystemClock is the Clock of the time package, used when Config.Clock is nil.
+var SystemClock = client.SystemClock
@@ -0,0 +1,87 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// CounterOverflowError is returned by AddToAttribute when the updated counter does not
+// fit an int64. The update itself has been applied, Value holds the stored number.
+type CounterOverflowError struct {
+	Attribute string
+	Value     string
+}
+
+func (e *CounterOverflowError) Error() string {
+	return fmt.Sprintf("counter %s overflows int64: %s", e.Attribute, e.Value)
+}
+
+// AddToAttribute atomically adds delta to the number attribute attr of the item with the
+// given key and returns the new value. A missing item or attribute counts as zero, as
+// with the ADD action of UpdateItem.
+func (d *Dax) AddToAttribute(ctx context.Context, table string, key map[string]types.AttributeValue, attr string, delta int64, optFns ...func(*dynamodb.Options)) (int64, error) {
+	if err := validateAddToAttribute(table, key, attr); err != nil {
+		return 0, err
+	}
+	out, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		UpdateExpression:          aws.String("ADD #c :d"),
+		ExpressionAttributeNames:  map[string]string{"#c": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":d": &types.AttributeValueMemberN{Value: strconv.FormatInt(delta, 10)}},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	}, optFns...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := out.Attributes[attr].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, &smithy.DeserializationError{Err: fmt.Errorf("counter %s missing from UpdateItem response", attr)}
+	}
+	v, err := strconv.ParseInt(n.Value, 10, 64)
+	if err != nil {
+		return 0, &CounterOverflowError{Attribute: attr, Value: n.Value}
+	}
+	return v, nil
+}
+
+func validateAddToAttribute(table string, key map[string]types.AttributeValue, attr string) error {
+	invalidParams := smithy.InvalidParamsError{Context: "AddToAttribute"}
+	if table == "" {
+		invalidParams.Add(smithy.NewErrParamRequired("table"))
+	}
+	if len(key) == 0 {
+		invalidParams.Add(smithy.NewErrParamRequired("key"))
+	}
+	if attr == "" {
+		invalidParams.Add(smithy.NewErrParamRequired("attr"))
+	} else if _, ok := key[attr]; ok {
+		invalidParams.Add(client.NewCustomInvalidParamError("attr", "cannot be a key attribute"))
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counterClient struct {
+	client.DaxAPI
+	input  *dynamodb.UpdateItemInput
+	result string
+}
+
+func (c *counterClient) UpdateItemWithOptions(_ context.Context, input *dynamodb.UpdateItemInput, output *dynamodb.UpdateItemOutput, _ client.RequestOptions) (*dynamodb.UpdateItemOutput, error) {
+	c.input = input
+	output.Attributes = map[string]types.AttributeValue{"hits": &types.AttributeValueMemberN{Value: c.result}}
+	return output, nil
+}
+
+func TestAddToAttribute(t *testing.T) {
+	c := &counterClient{result: "42"}
+	d := &Dax{client: c}
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "page"}}
+
+	v, err := d.AddToAttribute(context.Background(), "table", key, "hits", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+	assert.Equal(t, "ADD #c :d", aws.ToString(c.input.UpdateExpression))
+	assert.Equal(t, "hits", c.input.ExpressionAttributeNames["#c"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, c.input.ExpressionAttributeValues[":d"])
+
+	c.result = "9223372036854775808"
+	_, err = d.AddToAttribute(context.Background(), "table", key, "hits", 1)
+	var overflow *CounterOverflowError
+	require.ErrorAs(t, err, &overflow)
+	assert.Equal(t, c.result, overflow.Value)
+
+	var invalid smithy.InvalidParamsError
+	_, err = d.AddToAttribute(context.Background(), "", nil, "pk", 1)
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, 2, invalid.Len())
+	_, err = d.AddToAttribute(context.Background(), "table", key, "pk", 1)
+	require.ErrorAs(t, err, &invalid)
+}
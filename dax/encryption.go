@@ -0,0 +1,319 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// encryptedFormatV1 prefixes every encrypted attribute value, followed by the nonce and
+// the sealed CBOR encoding of the original attribute value.
+const encryptedFormatV1 = 0x01
+
+// AttributeEncryption configures client side encryption of individual attributes. The
+// listed attributes are encrypted by PutItem, BatchWriteItem and the Put items of
+// TransactWriteItems and stored as binary values, and decrypted in the results of
+// GetItem, BatchGetItem, TransactGetItems, Query, Scan and the returned attributes of
+// writes. Encrypted attributes cannot be used in keys, indexes, condition or update
+// expressions; UpdateItem requests and Update items of transactions setting them are
+// rejected. Values stored before the attribute was encrypted are returned as they are,
+// unless they are binary values starting with the 0x01 format byte.
+type AttributeEncryption struct {
+	// Attributes lists the names of the encrypted attributes per table name.
+	Attributes map[string][]string
+	// AEAD seals the attribute values. The table and attribute name are bound to the
+	// ciphertext as additional data, so values cannot be moved between attributes.
+	AEAD cipher.AEAD
+}
+
+// AttributeDecryptionError is returned when an encrypted attribute cannot be decrypted,
+// e.g. because it was encrypted with a different key or was tampered with.
+type AttributeDecryptionError struct {
+	Table     string
+	Attribute string
+	Err       error
+}
+
+func (e *AttributeDecryptionError) Error() string {
+	return fmt.Sprintf("unable to decrypt attribute %s of table %s: %v", e.Attribute, e.Table, e.Err)
+}
+
+func (e *AttributeDecryptionError) Unwrap() error {
+	return e.Err
+}
+
+// NewAESGCM returns an AES-GCM AEAD for a 16, 24 or 32 byte key.
+func NewAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// DataKeyDecrypter decrypts an encrypted data key, typically by calling the KMS Decrypt
+// API with the key as CiphertextBlob and returning the Plaintext of the response.
+type DataKeyDecrypter interface {
+	DecryptDataKey(ctx context.Context, encryptedKey []byte) ([]byte, error)
+}
+
+// NewKMSDataKeyAEAD decrypts a data key generated with the KMS GenerateDataKey API and
+// returns an AES-GCM AEAD using it, for use as AttributeEncryption.AEAD. Only the
+// encrypted data key needs to be stored with the application configuration.
+func NewKMSDataKeyAEAD(ctx context.Context, kms DataKeyDecrypter, encryptedKey []byte) (cipher.AEAD, error) {
+	key, err := kms.DecryptDataKey(ctx, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESGCM(key)
+}
+
+func (e *AttributeEncryption) attributes(table *string) []string {
+	if e == nil || table == nil {
+		return nil
+	}
+	return e.Attributes[*table]
+}
+
+func additionalData(table, attr string) []byte {
+	return []byte(table + "\x00" + attr)
+}
+
+func (e *AttributeEncryption) seal(table, attr string, av types.AttributeValue) (types.AttributeValue, error) {
+	var buf bytes.Buffer
+	w := cbor.NewWriter(&buf)
+	if err := cbor.EncodeAttributeValue(av, w); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	w.Close()
+
+	nonce := make([]byte, e.AEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, 1+len(nonce)+buf.Len()+e.AEAD.Overhead())
+	out = append(out, encryptedFormatV1)
+	out = append(out, nonce...)
+	out = e.AEAD.Seal(out, nonce, buf.Bytes(), additionalData(table, attr))
+	return &types.AttributeValueMemberB{Value: out}, nil
+}
+
+func (e *AttributeEncryption) open(table, attr string, av types.AttributeValue) (types.AttributeValue, error) {
+	b, ok := av.(*types.AttributeValueMemberB)
+	if !ok || len(b.Value) == 0 || b.Value[0] != encryptedFormatV1 {
+		// Values written before the attribute was encrypted are returned as they are.
+		return av, nil
+	}
+	ns := e.AEAD.NonceSize()
+	if len(b.Value) < 1+ns {
+		return nil, errors.New("truncated encrypted value")
+	}
+	plain, err := e.AEAD.Open(nil, b.Value[1:1+ns], b.Value[1+ns:], additionalData(table, attr))
+	if err != nil {
+		return nil, err
+	}
+	r := cbor.NewReader(bytes.NewReader(plain))
+	defer r.Close()
+	return cbor.DecodeAttributeValue(r)
+}
+
+// encryptItem returns a copy of item with the encrypted attributes of table sealed.
+func (e *AttributeEncryption) encryptItem(table *string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	attrs := e.attributes(table)
+	if len(attrs) == 0 || len(item) == 0 {
+		return item, nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = v
+	}
+	for _, attr := range attrs {
+		av, ok := item[attr]
+		if !ok {
+			continue
+		}
+		sealed, err := e.seal(*table, attr, av)
+		if err != nil {
+			return nil, err
+		}
+		out[attr] = sealed
+	}
+	return out, nil
+}
+
+// decryptItem opens the encrypted attributes of item in place.
+func (e *AttributeEncryption) decryptItem(table *string, item map[string]types.AttributeValue) error {
+	for _, attr := range e.attributes(table) {
+		av, ok := item[attr]
+		if !ok {
+			continue
+		}
+		opened, err := e.open(*table, attr, av)
+		if err != nil {
+			return &AttributeDecryptionError{Table: *table, Attribute: attr, Err: err}
+		}
+		item[attr] = opened
+	}
+	return nil
+}
+
+func (e *AttributeEncryption) decryptItems(table *string, items []map[string]types.AttributeValue) error {
+	for _, item := range items {
+		if err := e.decryptItem(table, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *AttributeEncryption) encryptPutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemInput, error) {
+	if input == nil || len(e.attributes(input.TableName)) == 0 {
+		return input, nil
+	}
+	item, err := e.encryptItem(input.TableName, input.Item)
+	if err != nil {
+		return nil, err
+	}
+	in := *input
+	in.Item = item
+	return &in, nil
+}
+
+func (e *AttributeEncryption) encryptBatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemInput, error) {
+	if e == nil || input == nil {
+		return input, nil
+	}
+	in := *input
+	in.RequestItems = make(map[string][]types.WriteRequest, len(input.RequestItems))
+	for table, wrs := range input.RequestItems {
+		if len(e.Attributes[table]) == 0 {
+			in.RequestItems[table] = wrs
+			continue
+		}
+		out := make([]types.WriteRequest, len(wrs))
+		for i, wr := range wrs {
+			out[i] = wr
+			if wr.PutRequest == nil {
+				continue
+			}
+			item, err := e.encryptItem(aws.String(table), wr.PutRequest.Item)
+			if err != nil {
+				return nil, err
+			}
+			out[i].PutRequest = &types.PutRequest{Item: item}
+		}
+		in.RequestItems[table] = out
+	}
+	return &in, nil
+}
+
+// encryptTransactWriteItems returns a copy of input with the encrypted attributes of the
+// Put items sealed. Update items setting encrypted attributes are rejected.
+func (e *AttributeEncryption) encryptTransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsInput, error) {
+	if e == nil || input == nil {
+		return input, nil
+	}
+	in := *input
+	in.TransactItems = make([]types.TransactWriteItem, len(input.TransactItems))
+	for i, ti := range input.TransactItems {
+		in.TransactItems[i] = ti
+		if u := ti.Update; u != nil {
+			update := &dynamodb.UpdateItemInput{TableName: u.TableName, UpdateExpression: u.UpdateExpression, ExpressionAttributeNames: u.ExpressionAttributeNames}
+			if err := e.checkUpdateItem(update); err != nil {
+				return nil, err
+			}
+		}
+		if p := ti.Put; p != nil && len(e.attributes(p.TableName)) > 0 {
+			item, err := e.encryptItem(p.TableName, p.Item)
+			if err != nil {
+				return nil, err
+			}
+			put := *p
+			put.Item = item
+			in.TransactItems[i].Put = &put
+		}
+	}
+	return &in, nil
+}
+
+// decryptTransactGetItems opens the encrypted attributes of the items returned for the
+// Get items of input.
+func (e *AttributeEncryption) decryptTransactGetItems(input *dynamodb.TransactGetItemsInput, out *dynamodb.TransactGetItemsOutput) error {
+	if e == nil || out == nil {
+		return nil
+	}
+	for i, r := range out.Responses {
+		if i >= len(input.TransactItems) || input.TransactItems[i].Get == nil {
+			continue
+		}
+		if err := e.decryptItem(input.TransactItems[i].Get.TableName, r.Item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var updateIdentifier = regexp.MustCompile(`[:#]?[A-Za-z_][A-Za-z0-9_]*`)
+
+// checkUpdateItem rejects update expressions referencing encrypted attributes, as the
+// server cannot compute on encrypted values and plain values must not be stored.
+func (e *AttributeEncryption) checkUpdateItem(input *dynamodb.UpdateItemInput) error {
+	if input == nil {
+		return nil
+	}
+	attrs := e.attributes(input.TableName)
+	if len(attrs) == 0 {
+		return nil
+	}
+	encrypted := make(map[string]bool, len(attrs))
+	for _, a := range attrs {
+		encrypted[a] = true
+	}
+	for name := range input.AttributeUpdates {
+		if encrypted[name] {
+			return client.NewCustomInvalidParamError("UpdateItem", fmt.Sprintf("encrypted attribute %s cannot be updated, use PutItem", name))
+		}
+	}
+	for _, id := range updateIdentifier.FindAllString(aws.ToString(input.UpdateExpression), -1) {
+		name := id
+		switch id[0] {
+		case ':':
+			continue
+		case '#':
+			name = input.ExpressionAttributeNames[id]
+		}
+		if encrypted[name] {
+			return client.NewCustomInvalidParamError("UpdateItem", fmt.Sprintf("encrypted attribute %s cannot be updated, use PutItem", name))
+		}
+	}
+	return nil
+}
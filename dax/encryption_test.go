@@ -0,0 +1,211 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encryptionClient struct {
+	client.DaxAPI
+	stored map[string]types.AttributeValue
+}
+
+func (c *encryptionClient) PutItemWithOptions(_ context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, _ client.RequestOptions) (*dynamodb.PutItemOutput, error) {
+	c.stored = input.Item
+	return output, nil
+}
+
+func (c *encryptionClient) GetItemWithOptions(_ context.Context, _ *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, _ client.RequestOptions) (*dynamodb.GetItemOutput, error) {
+	output.Item = make(map[string]types.AttributeValue, len(c.stored))
+	for k, v := range c.stored {
+		output.Item[k] = v
+	}
+	return output, nil
+}
+
+func (c *encryptionClient) TransactWriteItemsWithOptions(_ context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, _ client.RequestOptions) (*dynamodb.TransactWriteItemsOutput, error) {
+	for _, ti := range input.TransactItems {
+		if ti.Put != nil {
+			c.stored = ti.Put.Item
+		}
+	}
+	return output, nil
+}
+
+func (c *encryptionClient) TransactGetItemsWithOptions(_ context.Context, input *dynamodb.TransactGetItemsInput, output *dynamodb.TransactGetItemsOutput, _ client.RequestOptions) (*dynamodb.TransactGetItemsOutput, error) {
+	for range input.TransactItems {
+		item := make(map[string]types.AttributeValue, len(c.stored))
+		for k, v := range c.stored {
+			item[k] = v
+		}
+		output.Responses = append(output.Responses, types.ItemResponse{Item: item})
+	}
+	return output, nil
+}
+
+func testEncryption(t *testing.T, seed byte) *AttributeEncryption {
+	key := make([]byte, 32)
+	key[0] = seed
+	aead, err := NewAESGCM(key)
+	require.NoError(t, err)
+	return &AttributeEncryption{Attributes: map[string][]string{"secrets": {"ssn", "card"}}, AEAD: aead}
+}
+
+func TestEncryption_roundTrip(t *testing.T) {
+	c := &encryptionClient{}
+	d := &Dax{client: c, config: Config{Encryption: testEncryption(t, 0)}}
+
+	card := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"number": &types.AttributeValueMemberS{Value: "4111111111111111"},
+		"cvc":    &types.AttributeValueMemberN{Value: "123"},
+	}}
+	item := map[string]types.AttributeValue{
+		"pk":   &types.AttributeValueMemberS{Value: "a"},
+		"ssn":  &types.AttributeValueMemberS{Value: "123-45-6789"},
+		"card": card,
+	}
+	_, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("secrets"), Item: item})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "123-45-6789"}, item["ssn"], "input must not be modified")
+	assert.Equal(t, item["pk"], c.stored["pk"])
+	assert.IsType(t, &types.AttributeValueMemberB{}, c.stored["ssn"])
+	assert.IsType(t, &types.AttributeValueMemberB{}, c.stored["card"])
+
+	out, err := d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	require.NoError(t, err)
+	assert.Equal(t, item, out.Item)
+
+	// Values are bound to their attribute.
+	c.stored["ssn"], c.stored["card"] = c.stored["card"], c.stored["ssn"]
+	_, err = d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	var decErr *AttributeDecryptionError
+	require.ErrorAs(t, err, &decErr)
+	assert.Equal(t, "secrets", decErr.Table)
+
+	// Plain values written before encryption was enabled are returned as they are.
+	c.stored["ssn"] = &types.AttributeValueMemberS{Value: "legacy"}
+	delete(c.stored, "card")
+	out, err = d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "legacy"}, out.Item["ssn"])
+}
+
+func TestEncryption_legacyBinary(t *testing.T) {
+	c := &encryptionClient{stored: map[string]types.AttributeValue{
+		"ssn":  &types.AttributeValueMemberB{Value: []byte("plain")},
+		"card": &types.AttributeValueMemberB{},
+	}}
+	d := &Dax{client: c, config: Config{Encryption: testEncryption(t, 0)}}
+	out, err := d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberB{Value: []byte("plain")}, out.Item["ssn"])
+	assert.Equal(t, &types.AttributeValueMemberB{}, out.Item["card"])
+
+	c.stored = map[string]types.AttributeValue{"ssn": &types.AttributeValueMemberB{Value: []byte{encryptedFormatV1, 'x'}}}
+	_, err = d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	var decErr *AttributeDecryptionError
+	assert.ErrorAs(t, err, &decErr, "values with the format byte are decrypted")
+}
+
+func TestEncryption_transactions(t *testing.T) {
+	c := &encryptionClient{}
+	d := &Dax{client: c, config: Config{Encryption: testEncryption(t, 0)}}
+	item := map[string]types.AttributeValue{
+		"pk":  &types.AttributeValueMemberS{Value: "a"},
+		"ssn": &types.AttributeValueMemberS{Value: "123-45-6789"},
+	}
+	_, err := d.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String("secrets"), Item: item}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "123-45-6789"}, item["ssn"], "input must not be modified")
+	assert.Equal(t, item["pk"], c.stored["pk"])
+	assert.IsType(t, &types.AttributeValueMemberB{}, c.stored["ssn"])
+
+	out, err := d.TransactGetItems(context.Background(), &dynamodb.TransactGetItemsInput{TransactItems: []types.TransactGetItem{
+		{Get: &types.Get{TableName: aws.String("secrets")}},
+		{Get: &types.Get{TableName: aws.String("plain")}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, item, out.Responses[0].Item)
+	assert.IsType(t, &types.AttributeValueMemberB{}, out.Responses[1].Item["ssn"], "other tables are not decrypted")
+
+	_, err = d.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Update: &types.Update{TableName: aws.String("secrets"), UpdateExpression: aws.String("SET ssn = :v")}},
+	}})
+	assert.Error(t, err, "updates of encrypted attributes are rejected")
+}
+
+func TestEncryption_wrongKey(t *testing.T) {
+	c := &encryptionClient{}
+	d := &Dax{client: c, config: Config{Encryption: testEncryption(t, 0)}}
+	_, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String("secrets"),
+		Item:      map[string]types.AttributeValue{"ssn": &types.AttributeValueMemberS{Value: "x"}},
+	})
+	require.NoError(t, err)
+
+	d.config.Encryption = testEncryption(t, 1)
+	_, err = d.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("secrets")})
+	var decErr *AttributeDecryptionError
+	require.ErrorAs(t, err, &decErr)
+	assert.Equal(t, "ssn", decErr.Attribute)
+}
+
+func TestEncryption_nilInput(t *testing.T) {
+	e := testEncryption(t, 0)
+	put, err := e.encryptPutItem(nil)
+	require.NoError(t, err)
+	assert.Nil(t, put)
+	batch, err := e.encryptBatchWriteItem(nil)
+	require.NoError(t, err)
+	assert.Nil(t, batch)
+	transact, err := e.encryptTransactWriteItems(nil)
+	require.NoError(t, err)
+	assert.Nil(t, transact)
+	assert.NoError(t, e.checkUpdateItem(nil))
+}
+
+func TestEncryption_checkUpdateItem(t *testing.T) {
+	e := testEncryption(t, 0)
+	cases := []struct {
+		input *dynamodb.UpdateItemInput
+		ok    bool
+	}{
+		{&dynamodb.UpdateItemInput{TableName: aws.String("secrets"), UpdateExpression: aws.String("SET other = :ssn")}, true},
+		{&dynamodb.UpdateItemInput{TableName: aws.String("secrets"), UpdateExpression: aws.String("SET ssn = :v")}, false},
+		{&dynamodb.UpdateItemInput{TableName: aws.String("secrets"), UpdateExpression: aws.String("REMOVE #a"), ExpressionAttributeNames: map[string]string{"#a": "card"}}, false},
+		{&dynamodb.UpdateItemInput{TableName: aws.String("secrets"), AttributeUpdates: map[string]types.AttributeValueUpdate{"ssn": {}}}, false},
+		{&dynamodb.UpdateItemInput{TableName: aws.String("plain"), UpdateExpression: aws.String("SET ssn = :v")}, true},
+	}
+	for _, c := range cases {
+		err := e.checkUpdateItem(c.input)
+		if c.ok {
+			assert.NoError(t, err, aws.ToString(c.input.UpdateExpression))
+		} else {
+			assert.Error(t, err, aws.ToString(c.input.UpdateExpression))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// AuthError is returned when a connection could not be authenticated for a request,
+// such as when the credentials provider fails or does not respond in time. Its Err
+// field holds the cause; use errors.As to tell it apart from failures of the operation.
+type AuthError = client.AuthError
+
+// ErrFirstByteTimeout is wrapped by the error of an attempt which received no response
+// within Config.FirstByteTimeout.
+var ErrFirstByteTimeout = client.ErrFirstByteTimeout
+
+// NotReadyError is returned for requests made before the client first discovered the
+// cluster nodes, as opposed to the error returned when none of the known nodes can be
+// used. Use errors.As to detect it and Dax.WaitUntilReady to wait for the discovery.
+type NotReadyError = client.NotReadyError
+
+// RetryBudgetExceededError is returned when a request gave up retrying because of
+// Config.MaxRetryElapsedTime. It wraps the error of the last attempt and tells how long
+// the request took and how many attempts it made.
+type RetryBudgetExceededError = client.RetryBudgetExceededError
+
+// RequestLogError is returned for failed requests made with RequestOptions.CaptureLog
+// set, for example through ContextWithOptions. Its Log field holds the debug log of the
+// request and Err its error.
+type RequestLogError = client.RequestLogError
@@ -0,0 +1,54 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// EventType identifies a change of state of a client, see Subscribe.
+type EventType = client.EventType
+
+// Event describes a change of state of a client.
+type Event = client.Event
+
+const (
+	EventTopologyChanged      = client.EventTopologyChanged
+	EventRouteRemoved         = client.EventRouteRemoved
+	EventRouteAdded           = client.EventRouteAdded
+	EventFailOpenEntered      = client.EventFailOpenEntered
+	EventFailOpenExited       = client.EventFailOpenExited
+	EventPoolExhausted        = client.EventPoolExhausted
+	EventCredentialsRefreshed = client.EventCredentialsRefreshed
+)
+
+type eventSubscriber interface {
+	Subscribe(buffer int) (<-chan client.Event, func())
+}
+
+// Subscribe returns a channel receiving the lifecycle events of this client, such as
+// nodes joining or leaving the cluster and routes being removed by the route manager,
+// and a function cancelling the subscription. Up to buffer events are queued; events
+// are dropped rather than slowing the client down when the subscriber falls behind.
+// The channel is closed when the subscription is cancelled or the client closed.
+func (d *Dax) Subscribe(buffer int) (<-chan Event, func()) {
+	if s, ok := d.client.(eventSubscriber); ok {
+		return s.Subscribe(buffer)
+	}
+	ch := make(chan Event)
+	close(ch)
+	return ch, func() {}
+}
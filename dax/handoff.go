@@ -0,0 +1,55 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// Roster is a snapshot of the cluster nodes, for use as Config.InitialRoster.
+type Roster = client.Roster
+
+// RosterNode is a single node of a Roster.
+type RosterNode = client.RosterNode
+
+// HandoffConn is an exported idle connection, for use in Config.HandoffConnections.
+type HandoffConn = client.HandoffConn
+
+type handoffExporter interface {
+	ExportRoster() client.Roster
+	ExportConnections() []client.HandoffConn
+}
+
+// ExportRoster returns the cluster nodes this client currently routes requests to.
+// During a binary upgrade, the new process can pass it as Config.InitialRoster to
+// start serving requests without waiting for discovery.
+func (d *Dax) ExportRoster() Roster {
+	if e, ok := d.client.(handoffExporter); ok {
+		return e.ExportRoster()
+	}
+	return Roster{}
+}
+
+// ExportConnections detaches the idle connections of this client so they can be passed
+// to a new process, which adopts them through Config.HandoffConnections. Only
+// unencrypted connections on platforms exposing socket file descriptors are exported.
+// The caller owns the returned files and should close them once they were passed on.
+func (d *Dax) ExportConnections() []HandoffConn {
+	if e, ok := d.client.(handoffExporter); ok {
+		return e.ExportConnections()
+	}
+	return nil
+}
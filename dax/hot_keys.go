@@ -0,0 +1,57 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// HotKey is a frequently accessed partition key with its estimated number of accesses.
+type HotKey = client.HotKey
+
+// QueryShape is a frequently issued query or scan pattern with its estimated number of
+// requests.
+type QueryShape = client.QueryShape
+
+type hotKeyReporter interface {
+	TopKeys(n int) []client.HotKey
+}
+
+type queryShapeReporter interface {
+	TopQueryShapes(n int) []client.QueryShape
+}
+
+// TopKeys returns up to n of the most frequently accessed partition keys, most frequent
+// first.
+// Keys are only counted when Config.HotKeySampleRate is set, which helps finding hot keys
+// behind an uneven load of the cluster nodes.
+func (d *Dax) TopKeys(n int) []HotKey {
+	if r, ok := d.client.(hotKeyReporter); ok {
+		return r.TopKeys(n)
+	}
+	return nil
+}
+
+// TopQueryShapes returns up to n of the most frequent query and scan shapes, most frequent
+// first. Shapes are only counted when Config.QueryShapeSampleRate is set; they show which
+// access patterns dominate the traffic and may benefit from a projection or a better
+// cached query.
+func (d *Dax) TopQueryShapes(n int) []QueryShape {
+	if r, ok := d.client.(queryShapeReporter); ok {
+		return r.TopQueryShapes(n)
+	}
+	return nil
+}
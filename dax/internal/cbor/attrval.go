@@ -172,13 +172,13 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 		if err != nil {
 			return nil, err
 		}
-		as := make([]types.AttributeValue, len)
+		as := make([]types.AttributeValue, 0, PreallocLength(len))
 		for i := 0; i < len; i++ {
 			a, err := DecodeAttributeValue(reader)
 			if err != nil {
 				return nil, err
 			}
-			as[i] = a
+			as = append(as, a)
 		}
 		return &types.AttributeValueMemberL{Value: as}, nil
 	case Map:
@@ -186,7 +186,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 		if err != nil {
 			return nil, err
 		}
-		m := make(map[string]types.AttributeValue, len)
+		m := make(map[string]types.AttributeValue, PreallocLength(len))
 		for i := 0; i < len; i++ {
 			k, err := reader.ReadString()
 			if err != nil {
@@ -206,6 +206,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 		}
 		return &types.AttributeValueMemberN{Value: s}, nil
 	case Simple:
+		start := reader.offset
 		if _, _, err := reader.readTypeHeader(); err != nil {
 			return nil, err
 		}
@@ -217,7 +218,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 		case Nil:
 			return &types.AttributeValueMemberNULL{Value: true}, nil
 		default:
-			return nil, &smithy.DeserializationError{Err: fmt.Errorf("unknown minor type %d for simple major type", minor)}
+			return nil, &smithy.DeserializationError{Err: fmt.Errorf("unknown minor type %d for simple major type at offset %d", minor, start)}
 		}
 	case Tag:
 		switch minor {
@@ -234,6 +235,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 			}
 			return &types.AttributeValueMemberN{Value: d.String()}, nil
 		default:
+			start := reader.offset
 			_, tag, err := reader.readTypeHeader()
 			if err != nil {
 				return nil, err
@@ -244,13 +246,13 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 				if err != nil {
 					return nil, err
 				}
-				ss := make([]string, len)
+				ss := make([]string, 0, PreallocLength(len))
 				for i := 0; i < len; i++ {
 					s, err := reader.ReadString()
 					if err != nil {
 						return nil, err
 					}
-					ss[i] = s
+					ss = append(ss, s)
 				}
 				return &types.AttributeValueMemberSS{Value: ss}, nil
 			case tagNumberSet:
@@ -258,7 +260,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 				if err != nil {
 					return nil, err
 				}
-				ss := make([]string, len)
+				ss := make([]string, 0, PreallocLength(len))
 				for i := 0; i < len; i++ {
 					av, err := DecodeAttributeValue(reader)
 					if err != nil {
@@ -268,7 +270,7 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 					if !ok {
 						return nil, &smithy.DeserializationError{Err: fmt.Errorf("attribute type is not number. type: %T", av)}
 					}
-					ss[i] = n.Value
+					ss = append(ss, n.Value)
 				}
 				return &types.AttributeValueMemberNS{Value: ss}, nil
 			case tagBinarySet:
@@ -276,20 +278,24 @@ func DecodeAttributeValue(reader *Reader) (types.AttributeValue, error) {
 				if err != nil {
 					return nil, err
 				}
-				bs := make([][]byte, len)
+				bs := make([][]byte, 0, PreallocLength(len))
 				for i := 0; i < len; i++ {
 					b, err := reader.ReadBytes()
 					if err != nil {
 						return nil, err
 					}
-					bs[i] = b
+					bs = append(bs, b)
 				}
 				return &types.AttributeValueMemberBS{Value: bs}, nil
 			default:
-				return nil, &smithy.DeserializationError{Err: fmt.Errorf("unknown minor type %d or tag %d", minor, tag)}
+				if reader.tolerant {
+					// Decode the tagged content as if it were untagged.
+					return DecodeAttributeValue(reader)
+				}
+				return nil, &smithy.DeserializationError{Err: &UnknownTagError{Tag: tag, Offset: start}}
 			}
 		}
 	default:
-		return nil, &smithy.DeserializationError{Err: fmt.Errorf("unknown major type %d", major)}
+		return nil, &smithy.DeserializationError{Err: fmt.Errorf("unknown major type %d at offset %d", major, reader.offset)}
 	}
 }
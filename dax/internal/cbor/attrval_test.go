@@ -17,6 +17,7 @@ package cbor
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -164,3 +165,37 @@ func TestDecodeAttributeValue_InvalidData(t *testing.T) {
 func containsError(err error, substr string) bool {
 	return err != nil && strings.Contains(err.Error(), substr)
 }
+
+func TestAttrValUnknownTag(t *testing.T) {
+	encode := func() *bytes.Buffer {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		w.WriteString("pad")
+		w.WriteTag(9999)
+		w.WriteString("abc")
+		w.Flush()
+		return &buf
+	}
+
+	r := NewReader(encode())
+	r.ReadString()
+	_, err := DecodeAttributeValue(r)
+	var tagErr *UnknownTagError
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("expected UnknownTagError, got %v", err)
+	}
+	if tagErr.Tag != 9999 || tagErr.Offset != 4 {
+		t.Errorf("unexpected tag error %+v", tagErr)
+	}
+
+	r = NewReader(encode())
+	r.SetTolerant(true)
+	r.ReadString()
+	av, err := DecodeAttributeValue(r)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if s, ok := av.(*types.AttributeValueMemberS); !ok || s.Value != "abc" {
+		t.Errorf("unexpected value %v", av)
+	}
+}
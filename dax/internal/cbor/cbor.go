@@ -31,6 +31,7 @@ import (
 const (
 	defaultBufSize = 8192
 	maxObjLenBytes = 1024 * 1024 * 1024
+	maxPreallocLen = 1024
 )
 
 var ErrNaN = &smithy.GenericAPIError{
@@ -250,6 +251,20 @@ type Reader struct {
 	buf     []byte
 	scratch [8]byte
 	recycle bool
+
+	offset   int64
+	tolerant bool
+}
+
+// UnknownTagError reports a tag which the decoder does not know how to interpret,
+// along with the offset of the tagged item within the stream.
+type UnknownTagError struct {
+	Tag    uint64
+	Offset int64
+}
+
+func (e *UnknownTagError) Error() string {
+	return fmt.Sprintf("cbor: unknown tag %d at offset %d", e.Tag, e.Offset)
 }
 
 func NewReader(r io.Reader) *Reader {
@@ -267,6 +282,92 @@ func NewReader(r io.Reader) *Reader {
 	return &rdr
 }
 
+// SetTolerant controls how unknown tags and fields are handled. A tolerant reader
+// skips them so that responses carrying newer server features can still be decoded,
+// while a strict reader (the default) fails with the tag and offset of the item.
+func (r *Reader) SetTolerant(tolerant bool) {
+	r.tolerant = tolerant
+}
+
+// Tolerant reports whether unknown tags and fields should be skipped.
+func (r *Reader) Tolerant() bool {
+	return r.tolerant
+}
+
+// Offset returns the number of bytes consumed by this Reader.
+func (r *Reader) Offset() int64 {
+	return r.offset
+}
+
+// Skip reads and discards the next data item, including any nested items and tags.
+func (r *Reader) Skip() error {
+	hdr, value, err := r.readTypeHeader()
+	if err != nil {
+		return err
+	}
+	stream := hdr&MinorTypeMask == SizeStream
+	switch hdr & MajorTypeMask {
+	case PosInt, NegInt:
+		return nil
+	case Bytes, Utf:
+		if stream {
+			return r.skipUntilBreak(1)
+		}
+		return r.discard(value)
+	case Array:
+		if stream {
+			return r.skipUntilBreak(1)
+		}
+		return r.skipItems(value)
+	case Map:
+		if stream {
+			return r.skipUntilBreak(2)
+		}
+		return r.skipItems(2 * value)
+	case Tag:
+		return r.Skip()
+	default:
+		if hdr == Break {
+			return &smithy.DeserializationError{Err: fmt.Errorf("cbor: unexpected break at offset %d", r.offset-1)}
+		}
+		// Simple values and floats are fully consumed with the header.
+		return nil
+	}
+}
+
+func (r *Reader) skipItems(n uint64) error {
+	for i := uint64(0); i < n; i++ {
+		if err := r.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reader) skipUntilBreak(itemsPerEntry uint64) error {
+	for {
+		hdr, err := r.PeekHeader()
+		if err != nil {
+			return err
+		}
+		if hdr == Break {
+			return r.ReadBreak()
+		}
+		if err := r.skipItems(itemsPerEntry); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Reader) discard(n uint64) error {
+	if n > maxObjLenBytes {
+		return ErrObjTooBig
+	}
+	d, err := r.br.Discard(int(n))
+	r.offset += int64(d)
+	return err
+}
+
 func (r *Reader) ReadString() (string, error) {
 	// TODO skip tags, indef length strings
 	hdr, value, err := r.readTypeHeader()
@@ -284,7 +385,8 @@ func (r *Reader) ReadString() (string, error) {
 		return "", nil
 	}
 	b := make([]byte, value)
-	_, err = io.ReadFull(r.br, b)
+	n, err := io.ReadFull(r.br, b)
+	r.offset += int64(n)
 	if err != nil {
 		return "", err
 	}
@@ -300,7 +402,9 @@ func (r *Reader) ReadRawBytes(o io.Writer) error {
 		return err
 	}
 	lr := io.LimitReader(r.br, int64(value))
-	if _, err = io.Copy(o, lr); err != nil {
+	n, err := io.Copy(o, lr)
+	r.offset += n
+	if err != nil {
 		return err
 	}
 	return nil
@@ -323,7 +427,8 @@ func (r *Reader) ReadBytes() ([]byte, error) {
 		return []byte{}, nil
 	}
 	b := make([]byte, value)
-	_, err = io.ReadFull(r.br, b)
+	n, err := io.ReadFull(r.br, b)
+	r.offset += int64(n)
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +446,11 @@ func (r *Reader) BytesReader() (*Reader, error) {
 	}
 	// TODO avoid double buffering
 	lr := io.LimitReader(r.br, int64(value))
-	return NewReader(lr), nil
+	// The nested reader consumes the byte string from this stream.
+	r.offset += int64(value)
+	nr := NewReader(lr)
+	nr.tolerant = r.tolerant
+	return nr, nil
 }
 
 func (r *Reader) ReadMapLength() (int, error) {
@@ -352,6 +461,9 @@ func (r *Reader) ReadMapLength() (int, error) {
 	if err = r.verifyMajorType(hdr, Map); err != nil {
 		return 0, err
 	}
+	if value > maxObjLenBytes {
+		return 0, ErrObjTooBig
+	}
 	return int(value), err
 }
 
@@ -363,6 +475,9 @@ func (r *Reader) ReadBytesLength() (int, error) {
 	if err = r.verifyMajorType(hdr, Bytes); err != nil {
 		return 0, err
 	}
+	if value > maxObjLenBytes {
+		return 0, ErrObjTooBig
+	}
 	return int(value), err
 }
 
@@ -374,9 +489,23 @@ func (r *Reader) ReadArrayLength() (int, error) {
 	if err = r.verifyMajorType(hdr, Array); err != nil {
 		return 0, err
 	}
+	if value > maxObjLenBytes {
+		return 0, ErrObjTooBig
+	}
 	return int(value), err
 }
 
+// PreallocLength returns the capacity to allocate up front for a container of n elements
+// whose length was read from the stream. The length is not trusted until the elements
+// have actually been read, so a corrupt header can't make the decoder allocate more than
+// a bounded amount before failing.
+func PreallocLength(n int) int {
+	if n > maxPreallocLen {
+		return maxPreallocLen
+	}
+	return n
+}
+
 func (r *Reader) ReadFloat64() (float64, error) {
 	hdr, value, err := r.readTypeHeader()
 	if err != nil {
@@ -432,6 +561,7 @@ func (r *Reader) readRawTypeHeader(o io.Writer) (hdr int, value uint64, err erro
 	if err != nil {
 		return 0, 0, err
 	}
+	r.offset++
 
 	// Use the buffer r.buf to store the header byte and write it to output writer o
 	if o != nil {
@@ -471,6 +601,11 @@ func (r *Reader) readRawTypeHeader(o io.Writer) (hdr int, value uint64, err erro
 		}
 	}
 
+	if err != nil {
+		return
+	}
+	r.offset += int64(c)
+
 	// Write remaining bytes stored in r.buf to output writer o
 	if o != nil {
 		if _, err = o.Write(r.buf[:c]); err != nil {
@@ -774,3 +774,38 @@ func benchmarkDecodeInt(value int, b *testing.B) {
 		br.Seek(0, 0)
 	}
 }
+
+func TestCborSkip(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.WriteMapHeader(2)
+	w.WriteString("a")
+	w.WriteArrayStreamHeader()
+	w.WriteInt(1)
+	w.WriteFloat64(2.5)
+	w.WriteBytes([]byte{1, 2, 3})
+	w.WriteStreamBreak()
+	w.WriteString("t")
+	w.WriteTag(TagDecimal)
+	w.WriteMapStreamHeader()
+	w.WriteString("b")
+	w.WriteNull()
+	w.WriteStreamBreak()
+	w.WriteString("next")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	total := int64(buf.Len())
+
+	r := NewReader(&buf)
+	if err := r.Skip(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	s, err := r.ReadString()
+	if err != nil || s != "next" {
+		t.Fatalf("expected next, got %q (%v)", s, err)
+	}
+	if r.Offset() != total {
+		t.Errorf("expected offset %d, got %d", total, r.Offset())
+	}
+}
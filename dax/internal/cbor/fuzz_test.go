@@ -0,0 +1,53 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package cbor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FuzzDecodeAttributeValue checks that no server response can make DecodeAttributeValue
+// panic or exhaust memory. Crashers found by the fuzzer are kept in
+// testdata/fuzz/FuzzDecodeAttributeValue and replayed by go test.
+func FuzzDecodeAttributeValue(f *testing.F) {
+	seeds := []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "abc"},
+		&types.AttributeValueMemberN{Value: "-123456789012345678901234567890"},
+		&types.AttributeValueMemberN{Value: "314E-2"},
+		&types.AttributeValueMemberB{Value: []byte{1, 2, 3}},
+		&types.AttributeValueMemberSS{Value: []string{"abc", "def"}},
+		&types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+		&types.AttributeValueMemberBS{Value: [][]byte{{1}, {2}}},
+		&types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "abc"}, &types.AttributeValueMemberNULL{Value: true}}},
+		&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"b": &types.AttributeValueMemberBOOL{Value: true}}},
+	}
+	for _, s := range seeds {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		if err := EncodeAttributeValue(s, w); err != nil {
+			f.Fatal(err)
+		}
+		w.Flush()
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		DecodeAttributeValue(NewReader(bytes.NewReader(data)))
+	})
+}
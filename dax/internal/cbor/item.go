@@ -18,7 +18,9 @@ package cbor
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
@@ -251,6 +253,9 @@ func DecodeItemKey(reader *Reader, keydef []types.AttributeDefinition) (map[stri
 			if err != nil {
 				return nil, err
 			}
+			if d == nil {
+				return nil, ErrMissingKey
+			}
 			s := d.String()
 			keys[*rk.AttributeName] = &types.AttributeValueMemberN{Value: s}
 		case types.ScalarAttributeTypeB:
@@ -301,23 +306,50 @@ func EncodeItemNonKeyAttributes(ctx context.Context, item map[string]types.Attri
 	return nil
 }
 
+// AttributeListMismatchError reports attribute values which do not line up with the
+// names of the attribute list id they were encoded with, as happens when the cached
+// names of the id are stale. Loading the names of the id again may resolve it.
+type AttributeListMismatchError struct {
+	ID int64
+}
+
+func (e *AttributeListMismatchError) Error() string {
+	return fmt.Sprintf("cbor: attribute values do not match attribute list %d", e.ID)
+}
+
+// DecodeItemNonKeyAttributes decodes the attributes encoded with an attribute list id.
+// reader must hold nothing but the attributes, so that values left over after the names
+// of the list are reported as an AttributeListMismatchError.
 func DecodeItemNonKeyAttributes(ctx context.Context, reader *Reader, attrListIdToNames *lru.Lru) (map[string]types.AttributeValue, error) {
 	id, err := reader.ReadInt64()
 	if err != nil {
 		return nil, err
 	}
+	if id < 0 {
+		return nil, &smithy.DeserializationError{Err: fmt.Errorf("cbor: invalid attribute list id %d", uint64(id))}
+	}
 	attrNames, err := attrListIdToNames.GetWithContext(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	names, ok := attrNames.([]string)
+	if !ok {
+		return nil, &AttributeListMismatchError{ID: id}
+	}
 
-	attrs := make(map[string]types.AttributeValue)
-	for _, n := range attrNames.([]string) {
+	attrs := make(map[string]types.AttributeValue, len(names))
+	for _, n := range names {
 		av, err := DecodeAttributeValue(reader)
 		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, &AttributeListMismatchError{ID: id}
+			}
 			return nil, err
 		}
 		attrs[n] = av
 	}
+	if _, err := reader.PeekHeader(); !errors.Is(err, io.EOF) {
+		return nil, &AttributeListMismatchError{ID: id}
+	}
 	return attrs, nil
 }
@@ -281,6 +281,10 @@ func decode(reader BytesReader, xormask int) (*Decimal, error) {
 		bits += 8
 		if bits >= 10 {
 			digit := (accum >> (bits - 10)) & 0x3ff
+			if lastDigit == nil && (digit <= 2 || digit >= 1021) {
+				// a terminator needs a digit before it
+				return nil, &smithy.SerializationError{Err: fmt.Errorf("invalid lexdecimal")}
+			}
 
 			switch digit {
 			case 0, 1023:
@@ -0,0 +1,362 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OperationStats summarizes the requests issued for a single table and operation.
+// Multi-table operations (batch and transaction APIs) are keyed by the comma separated
+// list of the tables involved.
+type OperationStats struct {
+	Table     string
+	Operation string
+
+	Requests      int64
+	Failures      int64
+	Retries       int64
+	TotalLatency  time.Duration
+	BytesSent     int64
+	BytesReceived int64
+
+	// ConsumedCapacity is only populated for requests which set ReturnConsumedCapacity.
+	ConsumedCapacity float64
+}
+
+// AverageLatency returns the mean latency of the requests, including retries and backoff.
+func (s OperationStats) AverageLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+type operationKey struct {
+	table string
+	op    string
+}
+
+// operationAccounting aggregates OperationStats for every table/operation pair seen by a client.
+type operationAccounting struct {
+	mu    sync.Mutex
+	stats map[operationKey]*OperationStats
+}
+
+func newOperationAccounting() *operationAccounting {
+	return &operationAccounting{stats: make(map[operationKey]*OperationStats)}
+}
+
+func (a *operationAccounting) record(table, op string, rs *requestStats, latency time.Duration, output interface{}, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	k := operationKey{table: table, op: op}
+	s, ok := a.stats[k]
+	if !ok {
+		s = &OperationStats{Table: table, Operation: op}
+		a.stats[k] = s
+	}
+	s.Requests++
+	if err != nil {
+		s.Failures++
+	}
+	s.TotalLatency += latency
+	if rs != nil {
+		if attempts := atomic.LoadInt64(&rs.attempts); attempts > 1 {
+			s.Retries += attempts - 1
+		}
+		s.BytesSent += atomic.LoadInt64(&rs.bytesSent)
+		s.BytesReceived += atomic.LoadInt64(&rs.bytesReceived)
+	}
+	for _, c := range responseCapacity(output) {
+		if c.CapacityUnits != nil {
+			s.ConsumedCapacity += *c.CapacityUnits
+		}
+	}
+}
+
+// snapshot returns a copy of the collected stats ordered by table and operation.
+func (a *operationAccounting) snapshot() []OperationStats {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	out := make([]OperationStats, 0, len(a.stats))
+	for _, s := range a.stats {
+		out = append(out, *s)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Table != out[j].Table {
+			return out[i].Table < out[j].Table
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+func (a *operationAccounting) reset() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stats = make(map[operationKey]*OperationStats)
+}
+
+// WriteOperationReport writes stats to w as an aligned, human readable table.
+func WriteOperationReport(w io.Writer, stats []OperationStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tOPERATION\tREQUESTS\tFAILURES\tRETRIES\tAVG LATENCY\tBYTES OUT\tBYTES IN\tCAPACITY")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t%d\t%d\t%.2f\n",
+			s.Table, s.Operation, s.Requests, s.Failures, s.Retries, s.AverageLatency(), s.BytesSent, s.BytesReceived, s.ConsumedCapacity)
+	}
+	return tw.Flush()
+}
+
+// requestStats tracks the attempts and bytes transferred for a single logical request,
+// across all retries. It travels with the request context.
+type requestStats struct {
+	attempts      int64
+	bytesSent     int64
+	bytesReceived int64
+
+	mu      sync.Mutex
+	history []Attempt
+}
+
+type requestStatsKey struct{}
+
+func withRequestStats(ctx context.Context, rs *requestStats) context.Context {
+	return context.WithValue(ctx, requestStatsKey{}, rs)
+}
+
+func requestStatsFromContext(ctx context.Context) *requestStats {
+	if ctx == nil {
+		return nil
+	}
+	rs, _ := ctx.Value(requestStatsKey{}).(*requestStats)
+	return rs
+}
+
+// addAttempt records a completed attempt made by layer, err being its outcome.
+func (rs *requestStats) addAttempt(layer RetryLayer, err error) {
+	if rs == nil {
+		return
+	}
+	atomic.AddInt64(&rs.attempts, 1)
+	rs.mu.Lock()
+	rs.history = append(rs.history, Attempt{Layer: layer, Err: err})
+	rs.mu.Unlock()
+}
+
+func (rs *requestStats) attemptHistory() []Attempt {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]Attempt(nil), rs.history...)
+}
+
+// tubeUsage measures the bytes transferred over a tube while it is held by a request.
+type tubeUsage struct {
+	stats    *requestStats
+	counter  byteCounter
+	sent     int64
+	received int64
+	done     bool
+}
+
+func beginTubeUsage(ctx context.Context, t tube) *tubeUsage {
+	rs := requestStatsFromContext(ctx)
+	if rs == nil {
+		return nil
+	}
+	bc, ok := t.(byteCounter)
+	if !ok {
+		return nil
+	}
+	return &tubeUsage{stats: rs, counter: bc, sent: bc.BytesSent(), received: bc.BytesReceived()}
+}
+
+// end records the bytes transferred since beginTubeUsage. It must be called before
+// the tube is returned to the pool, and is a no-op after the first call.
+func (u *tubeUsage) end() {
+	if u == nil || u.done {
+		return
+	}
+	u.done = true
+	atomic.AddInt64(&u.stats.bytesSent, u.counter.BytesSent()-u.sent)
+	atomic.AddInt64(&u.stats.bytesReceived, u.counter.BytesReceived()-u.received)
+}
+
+// requestTable returns the accounting key for the tables referenced by a request input.
+func requestTable(input interface{}) string {
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.PutItemInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.UpdateItemInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.DeleteItemInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.QueryInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.ScanInput:
+		if in != nil {
+			return aws.ToString(in.TableName)
+		}
+	case *dynamodb.BatchGetItemInput:
+		if in != nil {
+			names := make([]string, 0, len(in.RequestItems))
+			for t := range in.RequestItems {
+				names = append(names, t)
+			}
+			return tableNames(names...)
+		}
+	case *dynamodb.BatchWriteItemInput:
+		if in != nil {
+			names := make([]string, 0, len(in.RequestItems))
+			for t := range in.RequestItems {
+				names = append(names, t)
+			}
+			return tableNames(names...)
+		}
+	case *dynamodb.TransactGetItemsInput:
+		if in != nil {
+			names := make([]string, 0, len(in.TransactItems))
+			for _, ti := range in.TransactItems {
+				if ti.Get != nil {
+					names = append(names, aws.ToString(ti.Get.TableName))
+				}
+			}
+			return tableNames(names...)
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		if in != nil {
+			names := make([]string, 0, len(in.TransactItems))
+			for _, ti := range in.TransactItems {
+				switch {
+				case ti.Put != nil:
+					names = append(names, aws.ToString(ti.Put.TableName))
+				case ti.Update != nil:
+					names = append(names, aws.ToString(ti.Update.TableName))
+				case ti.Delete != nil:
+					names = append(names, aws.ToString(ti.Delete.TableName))
+				case ti.ConditionCheck != nil:
+					names = append(names, aws.ToString(ti.ConditionCheck.TableName))
+				}
+			}
+			return tableNames(names...)
+		}
+	}
+	return ""
+}
+
+// responseCapacity returns the consumed capacity reported in an operation output, if any.
+func responseCapacity(output interface{}) []types.ConsumedCapacity {
+	switch out := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.PutItemOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.UpdateItemOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.DeleteItemOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.QueryOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.ScanOutput:
+		if out != nil {
+			return consumedCapacity(out.ConsumedCapacity)
+		}
+	case *dynamodb.BatchGetItemOutput:
+		if out != nil {
+			return out.ConsumedCapacity
+		}
+	case *dynamodb.BatchWriteItemOutput:
+		if out != nil {
+			return out.ConsumedCapacity
+		}
+	case *dynamodb.TransactGetItemsOutput:
+		if out != nil {
+			return out.ConsumedCapacity
+		}
+	case *dynamodb.TransactWriteItemsOutput:
+		if out != nil {
+			return out.ConsumedCapacity
+		}
+	}
+	return nil
+}
+
+func tableNames(names ...string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	uniq := make(map[string]struct{}, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if _, ok := uniq[n]; ok || n == "" {
+			continue
+		}
+		uniq[n] = struct{}{}
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func consumedCapacity(c *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if c == nil {
+		return nil
+	}
+	return []types.ConsumedCapacity{*c}
+}
@@ -0,0 +1,109 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationAccounting_record(t *testing.T) {
+	a := newOperationAccounting()
+
+	rs := &requestStats{attempts: 3, bytesSent: 100, bytesReceived: 250}
+	out := &dynamodb.GetItemOutput{ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(0.5)}}
+	a.record("t1", OpGetItem, rs, 10*time.Millisecond, out, nil)
+	a.record("t1", OpGetItem, &requestStats{attempts: 1}, 30*time.Millisecond, nil, errors.New("boom"))
+	a.record("t0", OpPutItem, nil, time.Millisecond, nil, nil)
+
+	stats := a.snapshot()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "t0", stats[0].Table)
+	assert.Equal(t, OpPutItem, stats[0].Operation)
+
+	s := stats[1]
+	assert.Equal(t, int64(2), s.Requests)
+	assert.Equal(t, int64(1), s.Failures)
+	assert.Equal(t, int64(2), s.Retries)
+	assert.Equal(t, int64(100), s.BytesSent)
+	assert.Equal(t, int64(250), s.BytesReceived)
+	assert.Equal(t, 0.5, s.ConsumedCapacity)
+	assert.Equal(t, 20*time.Millisecond, s.AverageLatency())
+
+	a.reset()
+	assert.Empty(t, a.snapshot())
+}
+
+func TestRequestTable(t *testing.T) {
+	assert.Equal(t, "t1", requestTable(&dynamodb.QueryInput{TableName: aws.String("t1")}))
+	assert.Equal(t, "a,b", requestTable(&dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{"b": {}, "a": {}},
+	}))
+	assert.Equal(t, "a,b", requestTable(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{TableName: aws.String("b")}},
+			{Delete: &types.Delete{TableName: aws.String("a")}},
+			{ConditionCheck: &types.ConditionCheck{TableName: aws.String("b")}},
+		},
+	}))
+	assert.Equal(t, "", requestTable((*dynamodb.GetItemInput)(nil)))
+}
+
+func TestClusterDaxClient_accountsRetries(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster, accounting: newOperationAccounting()}
+
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		calls++
+		if calls < 3 {
+			return newDaxRequestFailure([]int{1}, "RetryableError", "", "", 500, smithy.FaultServer)
+		}
+		return nil
+	}
+	opt := RequestOptions{
+		Options: dynamodb.Options{RetryMaxAttempts: 3},
+		Retryer: DaxRetryer{BaseThrottleDelay: time.Millisecond, MaxBackoffDelay: time.Millisecond},
+	}
+
+	input := &dynamodb.GetItemInput{TableName: aws.String("t1")}
+	done := cc.track(context.Background(), OpGetItem, input, &opt)
+	err := cc.retry(context.Background(), OpGetItem, action, opt)
+	done(nil, err)
+	require.NoError(t, err)
+
+	stats := cc.OperationReport()
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(1), stats[0].Requests)
+	assert.Equal(t, int64(2), stats[0].Retries)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOperationReport(&buf, stats))
+	assert.True(t, strings.HasPrefix(buf.String(), "TABLE"))
+	assert.Contains(t, buf.String(), "t1")
+}
@@ -0,0 +1,130 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// RetryLayer identifies the layer of the client which made an attempt of a request.
+//
+// A request is retried by exactly one layer, the outermost one it passes through, and
+// RequestOptions.RetryMaxAttempts is the budget of that layer alone. Requests issued
+// through a ClusterDaxClient are retried by the cluster layer, which picks a node for
+// every attempt, while the node it picks makes a single attempt. The node layer only
+// retries requests it receives directly, such as its own health checks.
+type RetryLayer string
+
+const (
+	// RetryLayerCluster is the ClusterDaxClient, retrying on other nodes of the cluster.
+	RetryLayerCluster RetryLayer = "cluster"
+	// RetryLayerNode is the SingleDaxClient, retrying on the same node.
+	RetryLayerNode RetryLayer = "node"
+)
+
+// Attempt describes a single attempt of a request.
+type Attempt struct {
+	Layer RetryLayer
+	// Err is the error the attempt failed with, nil for the attempt which succeeded.
+	Err error
+}
+
+type attemptsMetadataKey struct{}
+
+// GetAttempts returns the attempts made for the request whose output carries metadata,
+// in the order they were made.
+func GetAttempts(metadata middleware.Metadata) ([]Attempt, bool) {
+	attempts, ok := metadata.Get(attemptsMetadataKey{}).([]Attempt)
+	return attempts, ok
+}
+
+// setAttemptsMetadata stores attempts in the ResultMetadata of output, if it has one.
+func setAttemptsMetadata(output interface{}, attempts []Attempt) {
+	var md *middleware.Metadata
+	switch out := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.PutItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.UpdateItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.DeleteItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.BatchGetItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.BatchWriteItemOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.QueryOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.ScanOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.TransactGetItemsOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.TransactWriteItemsOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.ExecuteStatementOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.BatchExecuteStatementOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	case *dynamodb.ExecuteTransactionOutput:
+		if out != nil {
+			md = &out.ResultMetadata
+		}
+	}
+	if md != nil {
+		md.Set(attemptsMetadataKey{}, attempts)
+	}
+}
+
+type retryOwnerKey struct{}
+
+// withRetryOwner marks layer as the owner of the retry budget of the request in ctx.
+func withRetryOwner(ctx context.Context, layer RetryLayer) context.Context {
+	return context.WithValue(ctx, retryOwnerKey{}, layer)
+}
+
+// retryOwner returns the layer owning the retry budget of the request in ctx, if any.
+func retryOwner(ctx context.Context) (RetryLayer, bool) {
+	layer, ok := ctx.Value(retryOwnerKey{}).(RetryLayer)
+	return layer, ok
+}
@@ -0,0 +1,121 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+const (
+	defaultIMDSEndpoint = "http://169.254.169.254"
+	// imdsTimeout bounds the detection of the availability zone, so that clients running
+	// outside of EC2, where the metadata service doesn't answer, aren't held up.
+	imdsTimeout = 2 * time.Second
+)
+
+// imdsEndpoint returns the endpoint of the instance metadata service, which the
+// AWS_EC2_METADATA_SERVICE_ENDPOINT environment variable overrides as in the AWS SDKs.
+func imdsEndpoint() string {
+	if e := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"); e != "" {
+		return strings.TrimSuffix(e, "/")
+	}
+	return defaultIMDSEndpoint
+}
+
+// detectAvailabilityZone asks the instance metadata service at endpoint, with IMDSv2, for
+// the availability zone of the instance the client runs on.
+func detectAvailabilityZone(ctx context.Context, endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := imdsGet(req)
+	if err != nil {
+		return "", fmt.Errorf("instance metadata token: %w", err)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/latest/meta-data/placement/availability-zone", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	az, err := imdsGet(req)
+	if err != nil {
+		return "", fmt.Errorf("instance availability zone: %w", err)
+	}
+	return az, nil
+}
+
+func imdsGet(req *http.Request) (string, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// resolveAvailabilityZone detects the availability zone of the client when the affinity
+// is enabled without one. Clients failing to detect it route to every zone alike.
+func (cfg *Config) resolveAvailabilityZone(ctx context.Context) {
+	if !cfg.AvailabilityZoneAffinity || cfg.AvailabilityZone != "" {
+		return
+	}
+	az, err := detectAvailabilityZone(ctx, imdsEndpoint())
+	if err != nil {
+		if cfg.logger != nil {
+			cfg.logger.Logf(logging.Warn, "Cannot detect the availability zone of the client, routing to all availability zones: %v", err)
+		}
+		return
+	}
+	cfg.AvailabilityZone = az
+}
+
+// localRoutes returns the routes to the nodes in the availability zone of the client
+// which are not in tried, or nil without availability zone affinity. Once the local nodes
+// were tried, or were removed from the routes as unhealthy, requests go to other zones.
+// c.lock must be held.
+func (c *cluster) localRoutes(routes, tried []DaxAPI) []DaxAPI {
+	if !c.config.AvailabilityZoneAffinity || c.config.AvailabilityZone == "" {
+		return nil
+	}
+	var local []DaxAPI
+	for _, cc := range c.active {
+		if cc.cfg.availabilityZone == c.config.AvailabilityZone && containsRoute(routes, cc.client) && !containsRoute(tried, cc.client) {
+			local = append(local, cc.client)
+		}
+	}
+	return local
+}
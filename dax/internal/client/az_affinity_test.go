@@ -0,0 +1,103 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAvailabilityZone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			assert.Equal(t, "60", r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+			w.Write([]byte("token"))
+		case r.Method == http.MethodGet && r.URL.Path == "/latest/meta-data/placement/availability-zone":
+			if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("us-west-2b"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	az, err := detectAvailabilityZone(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "us-west-2b", az)
+
+	t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", srv.URL+"/")
+	cfg := DefaultConfig()
+	cfg.AvailabilityZoneAffinity = true
+	cfg.resolveAvailabilityZone(context.Background())
+	assert.Equal(t, "us-west-2b", cfg.AvailabilityZone)
+
+	_, err = detectAvailabilityZone(context.Background(), srv.URL+"/missing")
+	assert.Error(t, err)
+}
+
+func TestCluster_availabilityZoneAffinity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.AvailabilityZoneAffinity = true
+	cfg.AvailabilityZone = "us-west-2a"
+	cluster, _ := newTestClusterWithConfig(cfg)
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111, availabilityZone: "us-west-2a"},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111, availabilityZone: "us-west-2b"},
+		{hostname: "node3", address: []byte{127, 0, 0, 3}, port: 8111, availabilityZone: "us-west-2c"},
+	}))
+	local := cluster.active[hostPort{"127.0.0.1", 8111}].client
+
+	for i := 0; i < 20; i++ {
+		route, err := cluster.client(nil, OpGetItem)
+		require.NoError(t, err)
+		assert.Same(t, local, route)
+	}
+
+	route, err := cluster.client(local, OpGetItem)
+	require.NoError(t, err)
+	assert.NotSame(t, local, route, "retries go to other zones once the local node was tried")
+
+	var others []DaxAPI
+	for hp, cc := range cluster.active {
+		if hp.host != "127.0.0.1" {
+			others = append(others, cc.client)
+		}
+	}
+	cluster.routeManager.setRoutes(others)
+	route, err = cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	assert.NotSame(t, local, route, "requests go to other zones while the local node is not routed to")
+
+	selector := &recordingSelector{pick: func(routes []RouteState) DaxAPI { return routes[0].Route }}
+	cluster.config.RouteSelector = selector
+	cluster.routeManager.setRoutes(append(others, local))
+	route, err = cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	assert.Same(t, local, route)
+	require.Len(t, selector.routes, 1, "the selector picks among the local nodes")
+	assert.Equal(t, "us-west-2a", selector.routes[0].AvailabilityZone)
+}
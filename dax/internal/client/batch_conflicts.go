@@ -0,0 +1,201 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// BatchWriteConflicts selects how a BatchWriteItem request holding several puts or deletes
+// for the same key of a table is handled. DynamoDB rejects such requests as a whole.
+type BatchWriteConflicts string
+
+const (
+	// BatchWriteConflictsReject fails the request with a *BatchWriteConflictError before it
+	// is sent. It is the default.
+	BatchWriteConflictsReject BatchWriteConflicts = "reject"
+	// BatchWriteConflictsSerialize sends the conflicting writes in successive requests, in
+	// the order of the input, so that the last write of a key takes effect.
+	BatchWriteConflictsSerialize BatchWriteConflicts = "serialize"
+)
+
+func (c BatchWriteConflicts) validate() error {
+	switch c {
+	case "", BatchWriteConflictsReject, BatchWriteConflictsSerialize:
+		return nil
+	}
+	return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("unknown BatchWriteConflicts %q", string(c)))
+}
+
+// BatchWriteConflictError is returned for a BatchWriteItem request holding several puts or
+// deletes for the same key of a table. Indexes are the positions of the conflicting writes
+// in the requests of Table.
+type BatchWriteConflictError struct {
+	Table   string
+	Key     map[string]types.AttributeValue
+	Indexes []int
+}
+
+func (e *BatchWriteConflictError) Error() string {
+	return fmt.Sprintf("api error %s: %s", e.ErrorCode(), e.ErrorMessage())
+}
+
+func (e *BatchWriteConflictError) ErrorCode() string { return ErrCodeInvalidParameter }
+
+func (e *BatchWriteConflictError) ErrorMessage() string {
+	return fmt.Sprintf("Provided list of item keys contains duplicates: table %s, key {%s}, writes %v",
+		e.Table, renderKey(e.Key), e.Indexes)
+}
+
+func (e *BatchWriteConflictError) ErrorFault() smithy.ErrorFault { return smithy.FaultClient }
+
+// writeConflicts returns the positions in wrs of the writes sharing their key with another
+// write, grouped by key in the order of their first write. Writes missing a key attribute
+// are left to the encoder to reject.
+func writeConflicts(wrs []types.WriteRequest, defs []types.AttributeDefinition) [][]int {
+	if len(wrs) <= 1 {
+		return nil
+	}
+	var order []string
+	positions := make(map[string][]int, len(wrs))
+	for i, wr := range wrs {
+		k, ok := writeKey(wr, defs)
+		if !ok {
+			continue
+		}
+		if _, seen := positions[k]; !seen {
+			order = append(order, k)
+		}
+		positions[k] = append(positions[k], i)
+	}
+	var conflicts [][]int
+	for _, k := range order {
+		if len(positions[k]) > 1 {
+			conflicts = append(conflicts, positions[k])
+		}
+	}
+	return conflicts
+}
+
+func writeKey(wr types.WriteRequest, defs []types.AttributeDefinition) (string, bool) {
+	var sb strings.Builder
+	for _, def := range defs {
+		v := writeItem(wr).key(def)
+		if v == "" {
+			return "", false
+		}
+		sb.WriteString(v)
+		sb.WriteByte(0)
+	}
+	return sb.String(), true
+}
+
+func writeRequestKey(wr types.WriteRequest, defs []types.AttributeDefinition) map[string]types.AttributeValue {
+	var attrs map[string]types.AttributeValue
+	if wr.PutRequest != nil {
+		attrs = wr.PutRequest.Item
+	} else if wr.DeleteRequest != nil {
+		attrs = wr.DeleteRequest.Key
+	}
+	key := make(map[string]types.AttributeValue, len(defs))
+	for _, def := range defs {
+		key[aws.ToString(def.AttributeName)] = attrs[aws.ToString(def.AttributeName)]
+	}
+	return key
+}
+
+// batchWriteRounds splits input into successive requests such that none of them holds two
+// writes for the same key: the n-th write of every key goes to the n-th request. It returns
+// nil when input has no conflicting writes.
+func (client *SingleDaxClient) batchWriteRounds(ctx context.Context, input *dynamodb.BatchWriteItemInput) ([]*dynamodb.BatchWriteItemInput, error) {
+	var rounds []*dynamodb.BatchWriteItemInput
+	for table, wrs := range input.RequestItems {
+		defs, err := getKeySchema(ctx, client.keySchema, table)
+		if err != nil {
+			return nil, err
+		}
+		conflicts := writeConflicts(wrs, defs)
+		if len(conflicts) == 0 {
+			continue
+		}
+		round := make([]int, len(wrs))
+		for _, positions := range conflicts {
+			for n, i := range positions {
+				round[i] = n
+			}
+		}
+		for i, wr := range wrs {
+			for len(rounds) <= round[i] {
+				rounds = append(rounds, &dynamodb.BatchWriteItemInput{
+					RequestItems:                map[string][]types.WriteRequest{},
+					ReturnConsumedCapacity:      input.ReturnConsumedCapacity,
+					ReturnItemCollectionMetrics: input.ReturnItemCollectionMetrics,
+				})
+			}
+			rounds[round[i]].RequestItems[table] = append(rounds[round[i]].RequestItems[table], wr)
+		}
+	}
+	if len(rounds) == 0 {
+		return nil, nil
+	}
+	for table, wrs := range input.RequestItems {
+		if _, ok := rounds[0].RequestItems[table]; !ok {
+			rounds[0].RequestItems[table] = wrs
+		}
+	}
+	return rounds, nil
+}
+
+// batchWriteSerialized sends rounds one after the other with send. Once a round leaves
+// unprocessed items, the writes of the following rounds are returned as unprocessed as
+// well, after them, so that retrying the unprocessed items preserves the order of the
+// writes.
+func batchWriteSerialized(rounds []*dynamodb.BatchWriteItemInput, output *dynamodb.BatchWriteItemOutput, send func(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)) (*dynamodb.BatchWriteItemOutput, error) {
+	if output == nil {
+		output = &dynamodb.BatchWriteItemOutput{}
+	}
+	for n, round := range rounds {
+		out, err := send(round)
+		if err != nil {
+			return output, err
+		}
+		for table, metrics := range out.ItemCollectionMetrics {
+			if output.ItemCollectionMetrics == nil {
+				output.ItemCollectionMetrics = map[string][]types.ItemCollectionMetrics{}
+			}
+			output.ItemCollectionMetrics[table] = append(output.ItemCollectionMetrics[table], metrics...)
+		}
+		output.ConsumedCapacity = append(output.ConsumedCapacity, out.ConsumedCapacity...)
+		if len(out.UnprocessedItems) == 0 {
+			continue
+		}
+		output.UnprocessedItems = out.UnprocessedItems
+		for _, rest := range rounds[n+1:] {
+			for table, wrs := range rest.RequestItems {
+				output.UnprocessedItems[table] = append(output.UnprocessedItems[table], wrs...)
+			}
+		}
+		break
+	}
+	return output, nil
+}
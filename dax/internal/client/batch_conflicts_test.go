@@ -0,0 +1,151 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conflictTestKeySchema() *lru.Lru {
+	return &lru.Lru{
+		LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+			return []types.AttributeDefinition{{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS}}, nil
+		},
+	}
+}
+
+func putPK(pk string) types.WriteRequest {
+	return types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: pk},
+		"v":  &types.AttributeValueMemberN{Value: "1"},
+	}}}
+}
+
+func deletePK(pk string) types.WriteRequest {
+	return types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: pk},
+	}}}
+}
+
+func TestEncodeBatchWriteItemInput_conflict(t *testing.T) {
+	input := &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"t": {putPK("a"), putPK("b"), deletePK("a")},
+	}}
+	var buf bytes.Buffer
+	err := encodeBatchWriteItemInput(context.Background(), input, conflictTestKeySchema(), nil, cbor.NewWriter(&buf))
+
+	var ce *BatchWriteConflictError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, "t", ce.Table)
+	assert.Equal(t, map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}, ce.Key)
+	assert.Equal(t, []int{0, 2}, ce.Indexes)
+	assert.Contains(t, err.Error(), "table t, key {pk=a}, writes [0 2]")
+
+	var ae smithy.APIError
+	require.ErrorAs(t, err, &ae)
+	assert.Equal(t, ErrCodeInvalidParameter, ae.ErrorCode())
+	assert.Equal(t, smithy.FaultClient, ae.ErrorFault())
+}
+
+func TestSingleDaxClient_batchWriteRounds(t *testing.T) {
+	client := &SingleDaxClient{keySchema: conflictTestKeySchema()}
+	ctx := context.Background()
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{
+			"t": {putPK("a"), putPK("b"), deletePK("a"), putPK("a")},
+			"u": {putPK("a")},
+		},
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+	rounds, err := client.batchWriteRounds(ctx, input)
+	require.NoError(t, err)
+	require.Len(t, rounds, 3)
+	assert.Equal(t, []types.WriteRequest{putPK("a"), putPK("b")}, rounds[0].RequestItems["t"])
+	assert.Equal(t, []types.WriteRequest{putPK("a")}, rounds[0].RequestItems["u"], "tables without conflicts go first")
+	assert.Equal(t, map[string][]types.WriteRequest{"t": {deletePK("a")}}, rounds[1].RequestItems)
+	assert.Equal(t, map[string][]types.WriteRequest{"t": {putPK("a")}}, rounds[2].RequestItems)
+	for _, r := range rounds {
+		assert.Equal(t, types.ReturnConsumedCapacityTotal, r.ReturnConsumedCapacity)
+	}
+
+	rounds, err = client.batchWriteRounds(ctx, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"t": {putPK("a"), putPK("b")},
+	}})
+	require.NoError(t, err)
+	assert.Nil(t, rounds)
+}
+
+func TestBatchWriteSerialized(t *testing.T) {
+	rounds := []*dynamodb.BatchWriteItemInput{
+		{RequestItems: map[string][]types.WriteRequest{"t": {putPK("a"), putPK("b")}}},
+		{RequestItems: map[string][]types.WriteRequest{"t": {deletePK("a")}}},
+		{RequestItems: map[string][]types.WriteRequest{"t": {putPK("a")}}},
+	}
+
+	var sent []*dynamodb.BatchWriteItemInput
+	out, err := batchWriteSerialized(rounds, nil, func(in *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		sent = append(sent, in)
+		return &dynamodb.BatchWriteItemOutput{ConsumedCapacity: []types.ConsumedCapacity{{TableName: aws.String("t")}}}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rounds, sent)
+	assert.Len(t, out.ConsumedCapacity, 3)
+	assert.Empty(t, out.UnprocessedItems)
+
+	sent = nil
+	out, err = batchWriteSerialized(rounds, nil, func(in *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		sent = append(sent, in)
+		if len(sent) == 2 {
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: in.RequestItems}, nil
+		}
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, sent, 2, "rounds after unprocessed items are not sent")
+	assert.Equal(t, []types.WriteRequest{deletePK("a"), putPK("a")}, out.UnprocessedItems["t"])
+
+	boom := errors.New("boom")
+	_, err = batchWriteSerialized(rounds, nil, func(in *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		return nil, boom
+	})
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestConfig_validateBatchWriteConflicts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"localhost:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Credentials = &testCredentialProvider{}
+	for _, c := range []BatchWriteConflicts{"", BatchWriteConflictsReject, BatchWriteConflictsSerialize} {
+		cfg.BatchWriteConflicts = c
+		assert.NoError(t, cfg.validate(), c)
+	}
+	cfg.BatchWriteConflicts = "merge"
+	assert.Error(t, cfg.validate())
+}
@@ -0,0 +1,69 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+)
+
+// Clock is the source of time of the timing dependent logic of the client: the delays
+// between retries, the intervals of the background tasks, the reconnect jitter and the
+// fail-open timer of the route manager. SystemClock uses the time package, whose timers
+// follow the fake clock of testing/synctest, so the client can be tested deterministically
+// inside a synctest bubble without replacing the clock.
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer sending the current time on its channel after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc returns a timer calling f in its own goroutine after d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires, nil for timers
+	// created by AfterFunc.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, reporting whether it stopped it.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// clockOrSystem returns c, or SystemClock when c is nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
@@ -0,0 +1,157 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a Clock whose time only moves with advance. The timers which are due fire
+// from advance, AfterFunc functions being called synchronously.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	when  time.Time
+	c     chan time.Time
+	f     func()
+	done  bool // fired or stopped, protected by clock.mu
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(d, make(chan time.Time, 1), nil)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(d, nil, f)
+}
+
+func (c *fakeClock) add(d time.Duration, ch chan time.Time, f func()) *fakeTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), c: ch, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// pending returns the number of timers which neither fired nor were stopped.
+func (c *fakeClock) pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, t := range c.timers {
+		if !t.done {
+			n++
+		}
+	}
+	return n
+}
+
+// advance moves the time forward by d and fires the timers which are due.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	var due []*fakeTimer
+	for _, t := range c.timers {
+		if !t.done && !t.when.After(now) {
+			t.done = true
+			due = append(due, t)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, t := range due {
+		if t.f != nil {
+			t.f()
+		} else {
+			t.c <- now
+		}
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	stopped := !t.done
+	t.done = true
+	return stopped
+}
+
+func TestSleepWithClock(t *testing.T) {
+	clk := newFakeClock()
+	done := make(chan error)
+	go func() {
+		done <- sleepWithClock(context.Background(), clk, OpGetItem, time.Minute)
+	}()
+	for clk.pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	clk.advance(time.Minute - time.Nanosecond)
+	select {
+	case <-done:
+		t.Fatal("slept less than a minute")
+	default:
+	}
+	clk.advance(time.Nanosecond)
+	assert.NoError(t, <-done)
+}
+
+func TestTaskExecutor_clock(t *testing.T) {
+	clk := newFakeClock()
+	e := newExecutor()
+	e.clock = clk
+	var runs int32
+	ran := make(chan struct{})
+	e.start(time.Second, func() error {
+		atomic.AddInt32(&runs, 1)
+		ran <- struct{}{}
+		return nil
+	})
+	defer e.stopAll()
+
+	for i := 1; i <= 3; i++ {
+		for clk.pending() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clk.advance(time.Second)
+		<-ran
+		assert.EqualValues(t, i, atomic.LoadInt32(&runs))
+	}
+}
@@ -16,12 +16,17 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net"
 	"net/url"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
@@ -36,6 +41,7 @@ import (
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/logging"
 	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/tracing"
 )
 
 type serviceEndpoint struct {
@@ -57,12 +63,27 @@ type hostPort struct {
 	port int
 }
 
+// String returns the host:port form of hp, with IPv6 addresses in brackets.
+func (hp hostPort) String() string {
+	return net.JoinHostPort(hp.host, strconv.Itoa(hp.port))
+}
+
 type Config struct {
 	MaxPendingConnectionsPerHost int
 	ClusterUpdateThreshold       time.Duration
 	ClusterUpdateInterval        time.Duration
-	IdleConnectionReapDelay      time.Duration
-	ClientHealthCheckInterval    time.Duration
+	// ClusterUpdateMaxBackoff caps the delay between the background refreshes of the
+	// cluster, which grows exponentially from ClusterUpdateInterval while the discovery
+	// keeps failing. It defaults to one minute.
+	ClusterUpdateMaxBackoff   time.Duration
+	IdleConnectionReapDelay   time.Duration
+	ClientHealthCheckInterval time.Duration
+	// HealthCheckTimeout bounds a health check probe of a node, its retries included. It
+	// defaults to one second and must be shorter than the health check interval.
+	HealthCheckTimeout time.Duration
+	// HealthCheckRetries is the number of retries of a failed probe within
+	// HealthCheckTimeout. It defaults to 3; a negative value disables the retries.
+	HealthCheckRetries int
 
 	Region      string
 	HostPorts   []string
@@ -70,19 +91,272 @@ type Config struct {
 	DialContext func(ctx context.Context, network string, address string) (net.Conn, error)
 	connConfig  connConfig
 
+	// ConnectTimeout, when positive, bounds the establishment of each connection to a node,
+	// TCP and TLS handshakes included. Connections are established in the background, so a
+	// slow node otherwise holds up the pending connection attempts until the operating
+	// system gives up, whatever the deadline of the requests waiting for them.
+	ConnectTimeout time.Duration
+
+	// AuthTimeout, when positive, bounds retrieving the credentials for authenticating a
+	// connection, so that a stalling credentials provider fails the request with an
+	// AuthError. It defaults to 5 seconds.
+	AuthTimeout time.Duration
+
 	SkipHostnameVerification bool
-	logger                   logging.Logger
-	logLevel                 utils.LogLevelType
+
+	// ExpectedHostnamePattern, when set, accepts node certificates valid for a hostname
+	// matching the pattern instead of the hostname of the endpoint, e.g.
+	// "*.abc123.dax-clusters.us-west-2.amazonaws.com" for a cluster reached through a CNAME.
+	// A * matches any characters within a label. The certificate chain is still verified.
+	ExpectedHostnamePattern string
+
+	// HostOverrides maps hostnames of the cluster roster to the IP addresses to connect to
+	// instead of the addresses the nodes advertise, for networks where those aren't
+	// reachable, e.g. behind PrivateLink or NAT. TLS connections are still verified against
+	// the hostname of the cluster endpoint.
+	HostOverrides map[string]string
+
+	// VerifyHostname, when set, replaces the check of the hostname the node certificates are
+	// valid for, once their chain was verified. It is called with the hostname of the
+	// endpoint and the certificate of the node, and takes precedence over
+	// ExpectedHostnamePattern.
+	VerifyHostname func(hostname string, cert *x509.Certificate) error
+
+	// TLSConfig, when set, is the base configuration of the connections to encrypted daxs://
+	// endpoints, for instance with RootCAs trusting the private CA of a TLS terminating
+	// proxy. ServerName defaults to the hostname of the endpoint, and
+	// SkipHostnameVerification still disables the verification.
+	TLSConfig *tls.Config
+
+	// ClientCertificates are presented to the nodes of encrypted daxs:// endpoints requiring
+	// mutual TLS, in addition to the certificates of TLSConfig. They are not used with
+	// unencrypted endpoints.
+	ClientCertificates []tls.Certificate
+
+	// Proxy, when set, returns the URL of the proxy to connect to a node through, or nil to
+	// connect directly. HTTP proxies, tunneling with the CONNECT method, and SOCKS5 proxies,
+	// with the socks5 scheme and optional username and password, are supported.
+	// ProxyFromEnvironment honors the HTTPS_PROXY and NO_PROXY environment variables and
+	// ProxyURL always uses the same proxy. It is not used with a custom DialContext.
+	Proxy func(address string) (*url.URL, error)
+
+	// Resolver, when set, replaces net.DefaultResolver to resolve the hostnames of the seed
+	// endpoints, for instance to look them up in a service registry or a split-horizon DNS.
+	Resolver Resolver
+
+	// AvailabilityZoneAffinity routes requests to the nodes in AvailabilityZone while one
+	// of them is healthy and wasn't tried by the request yet, which cuts the latency and
+	// the cost of the traffic across zones. AvailabilityZone, e.g. "us-west-2a", is detected
+	// with the EC2 instance metadata service when empty.
+	AvailabilityZoneAffinity bool
+	AvailabilityZone         string
+
+	// RoleAwareRouting sends writes to the leader node, saving the hop through the leader
+	// the other nodes make for them, and spreads reads across the replicas. Requests go to
+	// any node when no node of the role is healthy and untried. Combined with
+	// AvailabilityZoneAffinity, reads prefer the replicas in the zone of the client.
+	RoleAwareRouting bool
+
+	// KeyAffinityRouting sends the requests for an item, GetItem, PutItem, UpdateItem and
+	// DeleteItem, to a node picked by hashing its table and partition key, so that the
+	// requests for a hot item hit the item cache of the same node. The other nodes are
+	// used once that node was tried or while it is unhealthy. It applies after the
+	// narrowing of RoleAwareRouting and AvailabilityZoneAffinity, and takes precedence over
+	// RouteSelector.
+	KeyAffinityRouting bool
+
+	// HedgeDelay, when positive, sends a second GetItem or BatchGetItem request to another
+	// node when the first one didn't complete within the delay, and takes the first
+	// successful response, which cuts the tail latency when a node stalls, e.g. during a
+	// garbage collection pause. A delay around the p95 latency of the reads sends about one
+	// request in twenty twice.
+	HedgeDelay time.Duration
+
+	// NodeWeights scales the share of the requests sent to the nodes with the given roster
+	// hostnames, relative to the weight of 1 of the other nodes, e.g. 0.25 for a node known
+	// to be degraded. A node with a weight of 0 only receives the requests which already
+	// failed on the other nodes.
+	NodeWeights map[string]float64
+
+	// LatencyWeighting scales the weight of every node by the ratio of the latency of the
+	// fastest node to its own, averaged over its recent requests, so that a slow node
+	// receives a reduced share of the requests rather than being removed. The share of a
+	// node is reduced down to a tenth of its weight.
+	LatencyWeighting bool
+
+	// RouteSelector, when set, picks the node each request attempt is sent to instead of
+	// the default random selection, e.g. &RoundRobinRouteSelector{}.
+	RouteSelector RouteSelector
+
+	// ClusterName, when set and HostPorts is empty, looks up the discovery endpoint of the
+	// cluster with the DescribeClusters API of the DAX control plane when the client is
+	// created, so that the endpoint doesn't need to be configured. The Credentials need the
+	// dax:DescribeClusters permission; credentials of a role assumed in another account find
+	// the clusters of that account. The request connects like the connections to the nodes,
+	// with DialContext, Proxy and TLSConfig.
+	ClusterName string
+
+	// ControlPlaneEndpoint overrides the endpoint of the DAX control plane API used with
+	// ClusterName, https://dax.<Region>.amazonaws.com by default.
+	ControlPlaneEndpoint string
+
+	// SeedLookupTTL, when positive, is how long the addresses of a seed hostname are reused
+	// before it is resolved again, so that the periodic discovery doesn't query the resolver
+	// every time. SeedLookupNegativeTTL is how long a failure to resolve it is remembered,
+	// during which the addresses of the last successful lookup, if any, keep being used.
+	// Both are set by DefaultConfig; zero disables the caching.
+	SeedLookupTTL         time.Duration
+	SeedLookupNegativeTTL time.Duration
+
+	logger   logging.Logger
+	logLevel utils.LogLevelType
 
 	MeterProvider metrics.MeterProvider
 
+	// TracerProvider, when set, receives a span for every operation, with child spans for
+	// each attempt and its phases: connection acquisition, authentication, encoding, the
+	// network round trip and decoding. OpenTelemetry can be plugged in with the smithy-go
+	// tracing adapter, smithyoteltracing.Adapter.
+	TracerProvider tracing.TracerProvider
+
+	// LatencyHistogramBuckets overrides DefaultLatencyHistogramBuckets for meters
+	// implementing HistogramBucketsMeter. Other meters need to configure the buckets
+	// themselves, e.g. with an OpenTelemetry view.
+	LatencyHistogramBuckets []float64
+
+	// TolerantDecoding skips unknown CBOR tags and response fields instead of failing,
+	// which allows talking to servers newer than this client.
+	TolerantDecoding bool
+
+	// OperationReportInterval, when positive, periodically logs the per table and
+	// operation accounting report. The report is always available on demand.
+	OperationReportInterval time.Duration
+
+	// Schedule overrides the intervals of the background tasks and allows disabling them
+	// individually. Intervals left at zero fall back to the fields above.
+	Schedule Schedule
+
 	RouteManagerEnabled bool // this flag temporarily removes routes facing network errors.
+
+	// MaxConcurrentDials, when positive, limits the connection attempts in progress across
+	// all the nodes of the cluster, on top of MaxPendingConnectionsPerHost.
+	MaxConcurrentDials int
+
+	// ReconnectJitter, when positive, delays the connection attempts replacing connections
+	// lost to a node, closed because of an error, by a random duration up to
+	// ReconnectJitter; the connections opened as the load grows are not delayed. Together
+	// with MaxConcurrentDials it spreads out the reconnects of many clients after all the
+	// nodes of the cluster were restarted or replaced at once.
+	ReconnectJitter time.Duration
+
+	// MaxConcurrentRebuilds is the number of clients of nodes failing their health checks
+	// which are rebuilt at once, in the background. Failures of a node reported while its
+	// rebuild is pending are ignored. Zero rebuilds the clients on the goroutine of the
+	// failed health check. Set by DefaultConfig.
+	MaxConcurrentRebuilds int
+
+	// WarmConnections, when positive, is the number of connections opened and authenticated
+	// to a node joining the cluster before requests are routed to it, so that its first
+	// requests don't wait for the TCP and TLS handshakes and the authentication.
+	WarmConnections int
+
+	// DrainTimeout, when positive, is how long the client of a node leaving the cluster
+	// waits for the requests in flight on it to finish before it is closed. The node stops
+	// receiving new requests right away. Zero closes it at once.
+	DrainTimeout time.Duration
+
+	// MaxConnectionsPerHost, when positive, bounds the connections open to each node.
+	// Requests finding all of them in use wait for one to be returned, within their
+	// timeouts. Zero leaves the connections unbounded.
+	MaxConnectionsPerHost int
+
+	// MinIdleConnections is the number of idle connections to each node which the idle
+	// connection reaper keeps open, opening new ones when fewer are left.
+	MinIdleConnections int
+
+	// Recorder, when set, receives a sanitized record of every completed request which can
+	// be used to reproduce a workload against a test cluster.
+	Recorder RequestRecorder
+
+	// VerifyRosterConsistency cross checks the cluster roster against a second node on every
+	// refresh, logging a warning and emitting a metric when they disagree.
+	VerifyRosterConsistency bool
+
+	// ProfilerLabels attaches the operation and table as pprof labels to the goroutines
+	// executing a request, so that CPU and block profiles attribute the time spent in
+	// the client to individual operations.
+	ProfilerLabels bool
+
+	// InitialRoster seeds the routes with a roster exported by a previous process, so that
+	// requests can be served while the first discovery is still pending or failing.
+	InitialRoster *Roster
+
+	// HandoffConnections are idle connections exported by a previous process through
+	// ExportConnections. They are adopted by the clients of the matching nodes instead of
+	// dialing new connections.
+	HandoffConnections []HandoffConn
+
+	// Clock, when set, replaces SystemClock as the source of time of the retry delays, the
+	// background tasks and the route manager, e.g. with a fake clock in tests. Tests running
+	// the client in a testing/synctest bubble don't need to replace it.
+	Clock Clock
+
+	// AutoTune scales MaxPendingConnectionsPerHost and MaxConcurrentDials, when left at their
+	// defaults, to the CPUs available to the process: GOMAXPROCS, or the CPU quota of its
+	// container when lower. It is set by DefaultConfig; clear it to keep the fixed defaults.
+	AutoTune bool
+
+	// HotKeySampleRate, between 0 and 1, is the fraction of requests whose partition keys
+	// are counted to estimate the most frequently accessed keys reported by TopKeys. Zero
+	// disables the sampling.
+	HotKeySampleRate float64
+
+	// QueryShapeSampleRate, between 0 and 1, is the fraction of queries and scans whose
+	// shape, the table, index and structure of the expressions without their values, is
+	// counted to estimate the most frequent shapes reported by TopQueryShapes. Zero disables
+	// the sampling.
+	QueryShapeSampleRate float64
+
+	// BatchWriteConflicts selects how BatchWriteItem requests holding several writes for the
+	// same key are handled: rejected with a *BatchWriteConflictError, the default, or sent
+	// in successive requests with BatchWriteConflictsSerialize.
+	BatchWriteConflicts BatchWriteConflicts
 }
 
 type connConfig struct {
 	isEncrypted              bool
 	hostname                 string
 	skipHostnameVerification bool
+	tolerantDecoding         bool
+	baseTLS                  *tls.Config
+	clientCertificates       []tls.Certificate
+	proxy                    func(address string) (*url.URL, error)
+	hostnamePattern          string
+	verifyHostname           func(hostname string, cert *x509.Certificate) error
+}
+
+// tlsConfig returns the configuration of TLS connections to the nodes, based on
+// Config.TLSConfig and Config.ClientCertificates. The server name defaults to the hostname
+// of the cluster endpoint.
+func (cc connConfig) tlsConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if cc.baseTLS != nil {
+		cfg = cc.baseTLS.Clone()
+	}
+	if len(cc.clientCertificates) > 0 {
+		cfg.Certificates = append(cfg.Certificates[:len(cfg.Certificates):len(cfg.Certificates)], cc.clientCertificates...)
+	}
+	if cc.skipHostnameVerification {
+		cfg.InsecureSkipVerify = true
+		return cfg
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = cc.hostname
+	}
+	if cc.hostnamePattern != "" || cc.verifyHostname != nil {
+		cc.verifyHostnamePolicy(cfg)
+	}
+	return cfg
 }
 
 func (cfg *Config) validate() error {
@@ -102,6 +376,70 @@ func (cfg *Config) validate() error {
 		return NewCustomInvalidParamError("ConfigValidation", "MaxPendingConnectionsPerHost cannot be negative")
 	}
 
+	if cfg.MaxConcurrentRebuilds < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "MaxConcurrentRebuilds cannot be negative")
+	}
+
+	if cfg.WarmConnections < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "WarmConnections cannot be negative")
+	}
+
+	if cfg.DrainTimeout < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "DrainTimeout cannot be negative")
+	}
+
+	if cfg.MaxConnectionsPerHost < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "MaxConnectionsPerHost cannot be negative")
+	}
+
+	if cfg.MinIdleConnections < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "MinIdleConnections cannot be negative")
+	}
+
+	if cfg.MaxConnectionsPerHost > 0 && cfg.MinIdleConnections > cfg.MaxConnectionsPerHost {
+		return NewCustomInvalidParamError("ConfigValidation", "MinIdleConnections cannot exceed MaxConnectionsPerHost")
+	}
+
+	if err := cfg.validateDurations(); err != nil {
+		return err
+	}
+
+	if err := cfg.validateHealthCheck(); err != nil {
+		return err
+	}
+
+	if err := validateHistogramBuckets(cfg.LatencyHistogramBuckets); err != nil {
+		return err
+	}
+
+	if cfg.HotKeySampleRate < 0 || cfg.HotKeySampleRate > 1 {
+		return NewCustomInvalidParamError("ConfigValidation", "HotKeySampleRate must be between 0 and 1")
+	}
+
+	if cfg.QueryShapeSampleRate < 0 || cfg.QueryShapeSampleRate > 1 {
+		return NewCustomInvalidParamError("ConfigValidation", "QueryShapeSampleRate must be between 0 and 1")
+	}
+
+	if err := validateHostnamePattern(cfg.ExpectedHostnamePattern); err != nil {
+		return err
+	}
+
+	if err := validateHostOverrides(cfg.HostOverrides); err != nil {
+		return err
+	}
+
+	if err := validateNodeWeights(cfg.NodeWeights); err != nil {
+		return err
+	}
+
+	if err := cfg.BatchWriteConflicts.validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.schedule().validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -116,6 +454,10 @@ func (cfg *Config) SetLogger(logger logging.Logger, logLevel utils.LogLevelType)
 	cfg.logLevel = logLevel
 }
 
+// discoveryRetries is the retry budget for pulling endpoints from a seed node, owned
+// by the node layer as the node is not yet part of the cluster.
+const discoveryRetries = 2
+
 var defaultPorts = map[string]int{
 	"dax":  8111,
 	"daxs": 9111,
@@ -123,20 +465,25 @@ var defaultPorts = map[string]int{
 
 func DefaultConfig() Config {
 	cfg := Config{
-		MaxPendingConnectionsPerHost: 10,
-		ClusterUpdateInterval:        time.Second * 4,
+		MaxPendingConnectionsPerHost: defaultMaxPendingConnectionsPerHost,
+		ClusterUpdateInterval:        defaultClusterUpdateInterval,
 		ClusterUpdateThreshold:       time.Millisecond * 125,
-		ClientHealthCheckInterval:    time.Second * 5,
+		ClientHealthCheckInterval:    defaultClientHealthCheckInterval,
 
 		connConfig:               connConfig{},
 		SkipHostnameVerification: false,
 		logger:                   utils.NewDefaultLogger(),
 		logLevel:                 utils.LogOff,
-		IdleConnectionReapDelay:  30 * time.Second,
+		IdleConnectionReapDelay:  defaultIdleConnectionReapDelay,
 
 		MeterProvider: &metrics.NopMeterProvider{},
 
-		RouteManagerEnabled: false,
+		RouteManagerEnabled:   false,
+		AutoTune:              true,
+		MaxConcurrentRebuilds: defaultMaxConcurrentRebuilds,
+
+		SeedLookupTTL:         defaultSeedLookupTTL,
+		SeedLookupNegativeTTL: defaultSeedLookupNegativeTTL,
 	}
 
 	if cfg.Credentials == nil {
@@ -151,27 +498,167 @@ func DefaultConfig() Config {
 }
 
 type ClusterDaxClient struct {
-	config  Config
-	cluster *cluster
+	config     Config
+	cluster    *cluster
+	accounting *operationAccounting
+	tracer     tracing.Tracer
 }
 
 func New(config Config) (*ClusterDaxClient, error) {
+	return NewWithContext(context.Background(), config)
+}
+
+// NewWithContext creates a client like New, giving up on the initial discovery of the
+// cluster nodes when ctx is done. The client keeps discovering them in the background.
+func NewWithContext(ctx context.Context, config Config) (*ClusterDaxClient, error) {
+	if config.AutoTune {
+		config.autoTune(availableCPUs())
+	}
+	if len(config.HostPorts) == 0 && config.ClusterName != "" {
+		endpoint, err := clusterEndpoint(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		config.HostPorts = []string{endpoint}
+	}
+	config.resolveAvailabilityZone(ctx)
 	cluster, err := newCluster(config)
 	if err != nil {
 		return nil, err
 	}
-	err = cluster.start()
+	err = cluster.start(ctx)
 	if err != nil {
 		return nil, err
 	}
-	client := &ClusterDaxClient{config: config, cluster: cluster}
+	client := &ClusterDaxClient{config: config, cluster: cluster, accounting: newOperationAccounting(), tracer: newTracer(config.TracerProvider)}
+	cluster.executor.startTask(cluster.config.schedule().OperationReport, func() error {
+		client.logOperationReport()
+		return nil
+	})
 	return client, nil
 }
 
+// OperationReport returns the accumulated per table and operation statistics.
+func (cc *ClusterDaxClient) OperationReport() []OperationStats {
+	return cc.accounting.snapshot()
+}
+
+// ResetOperationReport discards the statistics accumulated so far.
+func (cc *ClusterDaxClient) ResetOperationReport() {
+	cc.accounting.reset()
+}
+
+// TopKeys returns up to n of the most frequently accessed partition keys, estimated from the
+// requests sampled according to Config.HotKeySampleRate.
+func (cc *ClusterDaxClient) TopKeys(n int) []HotKey {
+	return cc.cluster.hotKeys.top(n)
+}
+
+// TopQueryShapes returns up to n of the most frequent query and scan shapes, estimated
+// from the requests sampled according to Config.QueryShapeSampleRate.
+func (cc *ClusterDaxClient) TopQueryShapes(n int) []QueryShape {
+	return cc.cluster.queryShapes.topShapes(n)
+}
+
+func (cc *ClusterDaxClient) logOperationReport() {
+	logger := cc.cluster.config.logger
+	if logger == nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := WriteOperationReport(&buf, cc.OperationReport()); err != nil {
+		return
+	}
+	logger.Logf(logging.Classification("INFO"), "DAX operation report:\n%s", buf.String())
+}
+
+// track attaches per request accounting to opt and returns a function which records
+// the outcome of the operation once it completes.
+func (cc *ClusterDaxClient) track(ctx context.Context, op string, input interface{}, opt *RequestOptions) func(output interface{}, err error) {
+	restoreLabels := func() {}
+	if cc.config.ProfilerLabels {
+		restoreLabels = setProfilerLabels(ctx, op, requestTable(input), opt)
+	}
+	recorder := cc.config.Recorder
+	var sdkMetrics *daxSdkMetrics
+	if cc.cluster != nil {
+		sdkMetrics = cc.cluster.daxSdkMetrics
+	}
+	if cc.cluster != nil {
+		reqCtx := cc.newContext(ctx, *opt)
+		opt.Context = withAffinityKey(reqCtx, cc.cluster.affinityKey(reqCtx, input))
+	}
+	if cc.accounting == nil && recorder == nil {
+		return func(output interface{}, err error) {
+			restoreLabels()
+			recordWorkload(ctx, sdkMetrics, op, input, output)
+		}
+	}
+	rs := &requestStats{}
+	opt.Context = withRequestStats(cc.newContext(ctx, *opt), rs)
+	start := time.Now()
+	return func(output interface{}, err error) {
+		restoreLabels()
+		recordWorkload(ctx, sdkMetrics, op, input, output)
+		setAttemptsMetadata(output, rs.attemptHistory())
+		latency := time.Since(start)
+		if cc.accounting != nil {
+			cc.accounting.record(requestTable(input), op, rs, latency, output, err)
+		}
+		if recorder != nil {
+			rec := newRequestRecord(op, input, *opt)
+			rec.Time = start
+			rec.Duration = latency
+			rec.Attempts = atomic.LoadInt64(&rs.attempts)
+			if err != nil {
+				rec.Error = err.Error()
+			}
+			recorder.Record(rec)
+		}
+	}
+}
+
 func (cc *ClusterDaxClient) Close() error {
 	return cc.cluster.Close()
 }
 
+// CloseWithContext closes the client, waiting until ctx is done for its background
+// tasks to exit and for requests in flight to finish. The error returned lists the
+// parts of the client which didn't stop in time.
+func (cc *ClusterDaxClient) CloseWithContext(ctx context.Context) error {
+	return cc.cluster.CloseWithContext(ctx)
+}
+
+// RefreshTopology discovers the cluster nodes right away instead of waiting for the
+// next periodic refresh, and returns the nodes the client routes requests to once the
+// routes were updated. The discovery gives up when ctx is done.
+func (cc *ClusterDaxClient) RefreshTopology(ctx context.Context) ([]RosterNode, error) {
+	return cc.cluster.refreshTopology(ctx)
+}
+
+// WaitUntilReady blocks until the client discovered the cluster nodes and can route
+// requests, or until ctx is done, in which case the error is a *NotReadyError.
+func (cc *ClusterDaxClient) WaitUntilReady(ctx context.Context) error {
+	return cc.cluster.waitUntilReady(ctx)
+}
+
+// Subscribe returns a channel receiving the lifecycle events of the client, buffering
+// up to buffer of them, and a function cancelling the subscription. Events are dropped
+// while the buffer is full. The channel is closed when the subscription is cancelled or
+// the client closed.
+func (cc *ClusterDaxClient) Subscribe(buffer int) (<-chan Event, func()) {
+	return cc.cluster.events.subscribe(buffer)
+}
+
+// UpdateEndpoints replaces the endpoints the cluster nodes are discovered from, such as
+// the configuration endpoint of another cluster, and discovers the nodes right away.
+// Requests keep being routed to the current nodes until the discovery succeeds; the nodes
+// which are not part of the new roster are then closed. It returns the nodes requests
+// are routed to after the discovery.
+func (cc *ClusterDaxClient) UpdateEndpoints(ctx context.Context, hostPorts []string) ([]RosterNode, error) {
+	return cc.cluster.updateSeeds(ctx, hostPorts)
+}
+
 func (cc *ClusterDaxClient) endpoints(ctx context.Context, opt RequestOptions) ([]serviceEndpoint, error) {
 	var out []serviceEndpoint
 	var err error
@@ -187,170 +674,346 @@ func (cc *ClusterDaxClient) endpoints(ctx context.Context, opt RequestOptions) (
 
 func (cc *ClusterDaxClient) PutItemWithOptions(ctx context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, opt RequestOptions) (*dynamodb.PutItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpPutItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.PutItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpPutItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) DeleteItemWithOptions(ctx context.Context, input *dynamodb.DeleteItemInput, output *dynamodb.DeleteItemOutput, opt RequestOptions) (*dynamodb.DeleteItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpDeleteItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.DeleteItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpDeleteItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) UpdateItemWithOptions(ctx context.Context, input *dynamodb.UpdateItemInput, output *dynamodb.UpdateItemOutput, opt RequestOptions) (*dynamodb.UpdateItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpUpdateItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.UpdateItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpUpdateItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) BatchWriteItemWithOptions(ctx context.Context, input *dynamodb.BatchWriteItemInput, output *dynamodb.BatchWriteItemOutput, opt RequestOptions) (*dynamodb.BatchWriteItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpBatchWriteItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.BatchWriteItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpBatchWriteItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) TransactWriteItemsWithOptions(ctx context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, opt RequestOptions) (*dynamodb.TransactWriteItemsOutput, error) {
 	var err error
+	done := cc.track(ctx, OpTransactWriteItems, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.TransactWriteItemsWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpTransactWriteItems, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) TransactGetItemsWithOptions(ctx context.Context, input *dynamodb.TransactGetItemsInput, output *dynamodb.TransactGetItemsOutput, opt RequestOptions) (*dynamodb.TransactGetItemsOutput, error) {
 	var err error
+	done := cc.track(ctx, OpTransactGetItems, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.TransactGetItemsWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpTransactGetItems, action, opt); err != nil {
+		done(output, err)
+		return output, err
+	}
+	done(output, nil)
+	return output, nil
+}
+
+func (cc *ClusterDaxClient) ExecuteStatementWithOptions(ctx context.Context, input *dynamodb.ExecuteStatementInput, output *dynamodb.ExecuteStatementOutput, opt RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	var err error
+	done := cc.track(ctx, OpExecuteStatement, input, &opt)
+	action := func(client DaxAPI, o RequestOptions) error {
+		output, err = client.ExecuteStatementWithOptions(ctx, input, output, o)
+		return err
+	}
+	if err = cc.retry(ctx, OpExecuteStatement, action, opt); err != nil {
+		done(output, err)
+		return output, err
+	}
+	done(output, nil)
+	return output, nil
+}
+
+func (cc *ClusterDaxClient) BatchExecuteStatementWithOptions(ctx context.Context, input *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	var err error
+	done := cc.track(ctx, OpBatchExecuteStatement, input, &opt)
+	// A failed batch is retried whole, which would run its writes that succeeded again.
+	if input != nil {
+		for _, s := range input.Statements {
+			if _, read := StatementTarget(aws.ToString(s.Statement)); !read {
+				opt.RetryMaxAttempts = 0
+				break
+			}
+		}
+	}
+	action := func(client DaxAPI, o RequestOptions) error {
+		output, err = client.BatchExecuteStatementWithOptions(ctx, input, output, o)
+		return err
+	}
+	if err = cc.retry(ctx, OpBatchExecuteStatement, action, opt); err != nil {
+		done(output, err)
+		return output, err
+	}
+	done(output, nil)
+	return output, nil
+}
+
+func (cc *ClusterDaxClient) ExecuteTransactionWithOptions(ctx context.Context, input *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	var err error
+	done := cc.track(ctx, OpExecuteTransaction, input, &opt)
+	action := func(client DaxAPI, o RequestOptions) error {
+		output, err = client.ExecuteTransactionWithOptions(ctx, input, output, o)
+		return err
+	}
+	if err = cc.retry(ctx, OpExecuteTransaction, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) GetItemWithOptions(ctx context.Context, input *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, opt RequestOptions) (*dynamodb.GetItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpGetItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
+		if cc.config.HedgeDelay > 0 {
+			output, err = hedgedCall(cc, OpGetItem, client, o, func(client DaxAPI, o RequestOptions) (*dynamodb.GetItemOutput, error) {
+				return client.GetItemWithOptions(ctx, input, &dynamodb.GetItemOutput{}, o)
+			})
+			return err
+		}
 		output, err = client.GetItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpGetItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) QueryWithOptions(ctx context.Context, input *dynamodb.QueryInput, output *dynamodb.QueryOutput, opt RequestOptions) (*dynamodb.QueryOutput, error) {
 	var err error
+	done := cc.track(ctx, OpQuery, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.QueryWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpQuery, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) ScanWithOptions(ctx context.Context, input *dynamodb.ScanInput, output *dynamodb.ScanOutput, opt RequestOptions) (*dynamodb.ScanOutput, error) {
 	var err error
+	done := cc.track(ctx, OpScan, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
 		output, err = client.ScanWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpScan, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
 func (cc *ClusterDaxClient) BatchGetItemWithOptions(ctx context.Context, input *dynamodb.BatchGetItemInput, output *dynamodb.BatchGetItemOutput, opt RequestOptions) (*dynamodb.BatchGetItemOutput, error) {
 	var err error
+	done := cc.track(ctx, OpBatchGetItem, input, &opt)
 	action := func(client DaxAPI, o RequestOptions) error {
+		if cc.config.HedgeDelay > 0 {
+			output, err = hedgedCall(cc, OpBatchGetItem, client, o, func(client DaxAPI, o RequestOptions) (*dynamodb.BatchGetItemOutput, error) {
+				return client.BatchGetItemWithOptions(ctx, input, &dynamodb.BatchGetItemOutput{}, o)
+			})
+			return err
+		}
 		output, err = client.BatchGetItemWithOptions(ctx, input, output, o)
 		return err
 	}
 	if err = cc.retry(ctx, OpBatchGetItem, action, opt); err != nil {
+		done(output, err)
 		return output, err
 	}
+	done(output, nil)
 	return output, nil
 }
 
+// errRetryBudgetExceeded is the cause of the cancellation of the context of a request
+// once its RequestOptions.MaxRetryElapsedTime is reached.
+var errRetryBudgetExceeded = errors.New("retry time budget exceeded")
+
 func (cc *ClusterDaxClient) retry(ctx context.Context, op string, action func(client DaxAPI, o RequestOptions) error, opt RequestOptions) (err error) {
+	var capture *logCapture
+	if opt.CaptureLog {
+		capture = newLogCapture(opt.Logger, opt.LogLevel)
+		opt.Logger, opt.LogLevel = capture, utils.LogDebugWithRequestRetries
+		defer func() {
+			if err != nil {
+				err = &RequestLogError{Err: err, Log: capture.log()}
+			}
+		}()
+	}
+	// Set when the retries stop early, wraps the error once converted by the next deferred call.
+	var exceeded *RetryBudgetExceededError
+	defer func() {
+		if exceeded != nil {
+			exceeded.Err = err
+			err = exceeded
+		}
+	}()
 	defer func() {
 		if daxErr, ok := err.(daxError); ok {
-			err = convertDaxError(daxErr)
+			err = withRequestID(convertDaxError(daxErr), daxErr)
 		}
 	}()
 
-	ctx = cc.newContext(ctx, opt)
+	tracer := cc.tracer
+	if tracer == nil {
+		tracer = newTracer(nil)
+	}
+	ctx, span := startOperationSpan(cc.newContext(ctx, opt), tracer, op)
+	defer func() {
+		endSpan(span, err)
+	}()
 
+	// The cluster owns the retry budget, the node picked for an attempt makes a single one.
+	ctx = withRetryOwner(ctx, RetryLayerCluster)
+	opt.Context = ctx
 	attempts := opt.RetryMaxAttempts
-	opt.RetryMaxAttempts = 0 // disable retries on single node client
 
 	var client DaxAPI
+	var tried []DaxAPI
+	throttled := 0
+	clock := clockOrSystem(cc.config.Clock)
+	start := clock.Now()
+	if opt.MaxRetryElapsedTime > 0 {
+		// The attempt in progress when the budget runs out is abandoned too.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, opt.MaxRetryElapsedTime, errRetryBudgetExceeded)
+		defer cancel()
+		opt.Context = ctx
+	}
+	budgetExceeded := func(i int) bool {
+		if context.Cause(ctx) != errRetryBudgetExceeded {
+			return false
+		}
+		exceeded = &RetryBudgetExceededError{Elapsed: clock.Now().Sub(start), Attempts: i + 1}
+		return true
+	}
+	affinity := affinityKeyFrom(ctx)
 	// Start from 0 to accomodate for the initial request
 	for i := 0; i <= attempts; i++ {
 		if i > 0 && opt.Logger != nil && opt.LogLevel.Matches(utils.LogDebugWithRequestRetries) {
-			opt.Logger.Logf(logging.Debug, "Retrying Request %s/%s, attempt %d", service, op, i)
+			logAttrs(ctx, opt.Logger, logging.Debug, requestAttrs(op, i, err), "Retrying Request %s/%s, attempt %d", service, op, i)
 		}
-		client, err = cc.cluster.client(client, op)
+		client, err = cc.cluster.clientExcluding(tried, op, affinity)
 
 		if err == nil {
-			err = action(client, opt)
+			o := opt
+			var attemptSpan tracing.Span
+			o.Context, attemptSpan = startAttemptSpan(ctx, op, i)
+			attemptStart := time.Now()
+			err = action(client, o)
+			endSpan(attemptSpan, err)
+			capture.addf("%s attempt %d on %s took %s, error: %v", op, i, routeName(client), time.Since(attemptStart), err)
+			if err != nil && !containsRoute(tried, client) {
+				tried = append(tried, client)
+			}
+		} else {
+			capture.addf("%s attempt %d found no route: %v", op, i, err)
 		}
+		requestStatsFromContext(ctx).addAttempt(RetryLayerCluster, err)
 
 		if err == nil {
 			// success
 			return nil
 		}
+		if budgetExceeded(i) {
+			return err
+		}
 		if !isRetryable(opt, err) {
 			return err
 		}
+		if IsThrottleError(err) {
+			if !opt.Retryer.allowThrottleRetry(op, throttled) {
+				return err
+			}
+			throttled++
+		}
 
 		if i != attempts {
 			if opt.Logger != nil && opt.LogLevel.Matches(utils.LogDebugWithRequestRetries) {
-				opt.Logger.Logf(logging.Debug, "Error in executing request %s/%s. : %s", service, op, err)
+				logAttrs(ctx, opt.Logger, logging.Debug, requestAttrs(op, i, err), "Error in executing request %s/%s. : %s", service, op, err)
 			}
 
 			var delay time.Duration
-			delay = opt.Retryer.RetryDelay(i+1, err)
+			delay = opt.Retryer.retryDelay(op, i+1, err)
 			if delay == 0 {
 				delay = opt.RetryDelay
 			}
+			if opt.MaxRetryElapsedTime > 0 {
+				if elapsed := clock.Now().Sub(start); elapsed+delay >= opt.MaxRetryElapsedTime {
+					exceeded = &RetryBudgetExceededError{Elapsed: elapsed, Attempts: i + 1}
+					return err
+				}
+			}
 
 			if delay > 0 {
-				if err = SleepWithContext(ctx, op, delay); err != nil {
-					return err
+				if serr := sleepWithClock(ctx, cc.config.Clock, op, delay); serr != nil {
+					if budgetExceeded(i) {
+						return err
+					}
+					return serr
 				}
 			}
 		}
@@ -374,15 +1037,27 @@ type cluster struct {
 	routeManager   RouteManager                 // protected by lock
 	closed         bool                         // protected by lock
 	lastRefreshErr error                        // protected by lock
+	handoff        map[string][]net.Conn        // protected by lock
 
-	lastUpdateNs int64
-	executor     *taskExecutor
+	lastUpdateNs    int64
+	refreshFailures int32 // consecutive failed refreshes, see refreshDelay
+	executor        *taskExecutor
+	ready           chan struct{} // closed once routes were first set
+	readyOnce       sync.Once
 
 	seeds         []hostPort
+	seedLookups   seedLookups
+	probes        probes
 	config        Config
 	clientBuilder clientBuilder
 
 	daxSdkMetrics *daxSdkMetrics
+	hotKeys       *keySketch
+	queryShapes   *keySketch
+	dialLimiter   *dialLimiter
+	rebuilds      *rebuildPool
+	events        *eventBus
+	pinnedUntil   time.Time // zero unless the topology is pinned, see ClusterDaxClient.Pin
 }
 
 type clientAndConfig struct {
@@ -403,28 +1078,60 @@ func newCluster(cfg Config) (*cluster, error) {
 	cfg.connConfig.isEncrypted = isEncrypted
 	cfg.connConfig.skipHostnameVerification = cfg.SkipHostnameVerification
 	cfg.connConfig.hostname = hostname
-	sdkMetrics, err := buildDaxSdkMetrics(cfg.MeterProvider)
+	cfg.connConfig.tolerantDecoding = cfg.TolerantDecoding
+	cfg.connConfig.baseTLS = cfg.TLSConfig
+	cfg.connConfig.clientCertificates = cfg.ClientCertificates
+	cfg.connConfig.proxy = cfg.Proxy
+	cfg.connConfig.hostnamePattern = cfg.ExpectedHostnamePattern
+	cfg.connConfig.verifyHostname = cfg.VerifyHostname
+	buckets := cfg.LatencyHistogramBuckets
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyHistogramBuckets
+	}
+	sdkMetrics, err := buildDaxSdkMetricsWithBuckets(cfg.MeterProvider, buckets)
 	if err != nil {
 		return nil, err
 	}
 
 	cfg.validateConnConfig()
+	cfg.warnHostOverrides()
+	cfg.clampDurations()
 
+	// The routes removed after network errors are only added back by the health checks.
+	healthCheck := cfg.schedule().HealthCheck
 	routeManager := newRouteManager(
-		cfg.RouteManagerEnabled,
-		cfg.ClientHealthCheckInterval,
+		cfg.RouteManagerEnabled && !healthCheck.Disabled,
+		healthCheck.Interval,
 		cfg.logger,
 		cfg.logLevel,
 		sdkMetrics,
 	)
 
+	events := newEventBus()
+	routeManager.events = events
+	routeManager.clock = clockOrSystem(cfg.Clock)
+	routeManager.latencyWeighted = cfg.LatencyWeighting
+	executor := newExecutor()
+	executor.clock = cfg.Clock
+	dialLimiter := newDialLimiter(cfg.MaxConcurrentDials, cfg.ReconnectJitter)
+	if dialLimiter != nil {
+		dialLimiter.clock = cfg.Clock
+	}
+
 	return &cluster{
 		seeds:         seeds,
 		config:        cfg,
-		executor:      newExecutor(),
+		executor:      executor,
+		ready:         make(chan struct{}),
 		clientBuilder: &singleClientBuilder{},
 		routeManager:  routeManager,
 		daxSdkMetrics: sdkMetrics,
+		handoff:       adoptHandoffConns(&cfg),
+		hotKeys:       newKeySketch(cfg.HotKeySampleRate),
+		queryShapes:   newKeySketch(cfg.QueryShapeSampleRate),
+		dialLimiter:   dialLimiter,
+		rebuilds:      newRebuildPool(cfg.MaxConcurrentRebuilds, sdkMetrics),
+		events:        events,
 	}, nil
 }
 
@@ -491,6 +1198,13 @@ func parseHostPort(hostPort string) (host string, port int, scheme string, err e
 	host = u.Hostname()
 	scheme = u.Scheme
 	portStr := u.Port()
+	if strings.Contains(host, ":") && !strings.HasPrefix(u.Host, "[") {
+		return handle(&smithy.GenericAPIError{
+			Code:    ErrCodeInvalidParameter,
+			Message: "IPv6 addresses must be enclosed in brackets, e.g. dax://[2001:db8::1]:8111",
+			Fault:   smithy.FaultClient,
+		})
+	}
 	if host == "" {
 		return handle(&smithy.GenericAPIError{
 			Code:    ErrCodeInvalidParameter,
@@ -516,29 +1230,81 @@ func parseHostPort(hostPort string) (host string, port int, scheme string, err e
 	return host, port, scheme, nil
 }
 
-func (c *cluster) start() error {
-	c.executor.start(c.config.ClusterUpdateInterval, func() error {
+func (c *cluster) start(ctx context.Context) error {
+	if r := c.config.InitialRoster; r != nil && len(r.Nodes) > 0 {
+		eps, err := r.endpoints()
+		if err != nil {
+			return err
+		}
+		if err := c.update(eps); err != nil {
+			return err
+		}
+	}
+	schedule := c.config.schedule()
+	c.executor.startTask(schedule.IdleConnectionReap, c.reapIdleConnections)
+	if c.pinned() {
+		return nil
+	}
+	c.executor.startTaskWithDelay(schedule.ClusterRefresh, c.refreshDelay, func() error {
 		c.safeRefresh(false)
 		return nil
 	})
-	c.executor.start(c.config.IdleConnectionReapDelay, c.reapIdleConnections)
-	c.safeRefresh(false)
+	c.safeRefreshWithContext(ctx, false)
 	return nil
 }
 
 func (c *cluster) Close() error {
+	for _, config := range c.shutdown() {
+		c.closeClient(config.client)
+	}
+	return nil
+}
+
+// CloseWithContext closes the cluster like Close, then waits for the background tasks
+// of the cluster and of its nodes to exit, and for requests in flight to finish, until
+// ctx is done. The error returned lists the parts which didn't stop in time.
+func (c *cluster) CloseWithContext(ctx context.Context) error {
+	var errs []error
+	for hp, config := range c.shutdown() {
+		if cl, ok := config.client.(contextCloser); ok {
+			if err := cl.CloseWithContext(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", hp, err))
+			}
+		} else {
+			c.closeClient(config.client)
+		}
+	}
+	if err := c.executor.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("cluster: %w", err))
+	}
+	if err := c.rebuilds.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("cluster: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// shutdown stops the background tasks of the cluster, marks it closed and returns the
+// clients of the nodes, which the caller must close.
+func (c *cluster) shutdown() map[hostPort]clientAndConfig {
 	c.executor.stopAll()
+	c.rebuilds.close()
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	c.closed = true
-	for _, config := range c.active {
-		c.closeClient(config.client)
-	}
+	c.closeHandoff()
+	c.events.close()
+	active := c.active
 	c.active = nil
-	c.routeManager.close()
-	c.routeManager = nil
-	return nil
+	if c.routeManager != nil {
+		c.routeManager.close()
+		c.routeManager = nil
+	}
+	return active
+}
+
+type contextCloser interface {
+	CloseWithContext(ctx context.Context) error
 }
 
 func (c *cluster) reapIdleConnections() error {
@@ -552,21 +1318,74 @@ func (c *cluster) reapIdleConnections() error {
 }
 
 func (c *cluster) client(prev DaxAPI, op string) (DaxAPI, error) {
+	var tried []DaxAPI
+	if prev != nil {
+		tried = []DaxAPI{prev}
+	}
+	return c.clientExcluding(tried, op, nil)
+}
+
+// clientExcluding returns a route for op, preferring routes which are not in tried, the
+// routes that already failed during the current retry sequence, and the node assigned to
+// the affinity key, if any.
+func (c *cluster) clientExcluding(tried []DaxAPI, op string, affinity []byte) (DaxAPI, error) {
+	if c.pinned() && !clockOrSystem(c.config.Clock).Now().Before(c.pinnedUntil) {
+		return nil, &smithy.OperationError{
+			ServiceID:     service,
+			OperationName: op,
+			Err:           &PinnedClientExpiredError{ExpiredAt: c.pinnedUntil},
+		}
+	}
 	c.lock.RLock()
 	defer c.lock.RUnlock()
-	route := c.routeManager.getRoute(prev)
+	route := c.selectRoute(tried, op, affinity)
+	if route == nil {
+		route = c.routeManager.getRouteExcluding(tried)
+	}
 	if route == nil {
+		err := fmt.Errorf("no routes found. lastRefreshError: %v", c.lastRefreshErr)
+		if !c.isReady() {
+			err = &NotReadyError{Err: c.lastRefreshErr}
+		}
 		return nil, &smithy.OperationError{
 			ServiceID:     service,
 			OperationName: op,
-			Err:           fmt.Errorf("no routes found. lastRefreshError: %v", c.lastRefreshError()),
+			Err:           err,
 		}
 	}
 	return route, nil
 }
 
+func (c *cluster) markReady() {
+	c.readyOnce.Do(func() { close(c.ready) })
+}
+
+func (c *cluster) isReady() bool {
+	select {
+	case <-c.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitUntilReady blocks until routes were first set, or until ctx is done.
+func (c *cluster) waitUntilReady(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", &NotReadyError{Err: c.lastRefreshError()}, ctx.Err())
+	}
+}
+
 func (c *cluster) safeRefresh(force bool) {
-	err := c.refresh(force)
+	c.safeRefreshWithContext(context.Background(), force)
+}
+
+func (c *cluster) safeRefreshWithContext(ctx context.Context, force bool) {
+	err := c.refreshWithContext(ctx, force)
+	c.recordRefresh(err)
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	c.lastRefreshErr = err
@@ -579,18 +1398,29 @@ func (c *cluster) lastRefreshError() error {
 }
 
 func (c *cluster) refresh(force bool) error {
+	return c.refreshWithContext(context.Background(), force)
+}
+
+func (c *cluster) refreshWithContext(ctx context.Context, force bool) error {
 	last := atomic.LoadInt64(&c.lastUpdateNs)
 	now := time.Now().UnixNano()
 	if now-last > c.config.ClusterUpdateThreshold.Nanoseconds() || force {
 		if atomic.CompareAndSwapInt64(&c.lastUpdateNs, last, now) {
-			return c.refreshNow()
+			return c.refreshNowWithContext(ctx)
 		}
 	}
 	return nil
 }
 
 func (c *cluster) refreshNow() error {
-	cfg, err := c.pullEndpoints()
+	return c.refreshNowWithContext(context.Background())
+}
+
+func (c *cluster) refreshNowWithContext(ctx context.Context) error {
+	if c.pinned() {
+		return ErrTopologyPinned
+	}
+	cfg, err := c.pullEndpoints(ctx)
 	if err != nil {
 		c.debugLog("ERROR: Failed to refresh endpoint : %s", err)
 		return err
@@ -601,6 +1431,49 @@ func (c *cluster) refreshNow() error {
 	return c.update(cfg)
 }
 
+// updateSeeds replaces the endpoints the cluster nodes are discovered from, then
+// refreshes the topology. The nodes which are not part of the new roster are closed.
+func (c *cluster) updateSeeds(ctx context.Context, hostPorts []string) ([]RosterNode, error) {
+	if c.pinned() {
+		return nil, ErrTopologyPinned
+	}
+	if len(hostPorts) == 0 {
+		return nil, smithy.NewErrParamRequired("Endpoint")
+	}
+	seeds, hostname, isEncrypted, err := getHostPorts(hostPorts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.seeds = seeds
+	c.config.HostPorts = hostPorts
+	c.config.connConfig.isEncrypted = isEncrypted
+	c.config.connConfig.hostname = hostname
+	c.lock.Unlock()
+
+	return c.refreshTopology(ctx)
+}
+
+func (c *cluster) refreshTopology(ctx context.Context) ([]RosterNode, error) {
+	c.lock.RLock()
+	closed := c.closed
+	c.lock.RUnlock()
+	if closed {
+		return nil, os.ErrClosed
+	}
+
+	atomic.StoreInt64(&c.lastUpdateNs, time.Now().UnixNano())
+	err := c.refreshNowWithContext(ctx)
+	c.lock.Lock()
+	c.lastRefreshErr = err
+	c.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return c.exportRoster().Nodes, nil
+}
+
 // This method is responsible for updating the set of active routes tracked by
 // the clsuter-dax-client in response to updates in the roster.
 func (c *cluster) update(config []serviceEndpoint) error {
@@ -615,6 +1488,7 @@ func (c *cluster) update(config []serviceEndpoint) error {
 	var toClose []clientAndConfig
 	// Track the newly created client instances, so that we can clean them up in case of partial failures.
 	var newCliCfg []clientAndConfig
+	warmed := c.warmClients(config)
 
 	c.lock.Lock()
 
@@ -637,7 +1511,7 @@ func (c *cluster) update(config []serviceEndpoint) error {
 		for i, ep := range config {
 			cliAndCfg, alreadyExists := oldActive[ep.hostPort()]
 			if !alreadyExists {
-				cli, err := c.newSingleClient(ep)
+				cli, err := c.newWarmClient(ep, warmed)
 				if err != nil {
 					shouldUpdateRoutes = false
 					break
@@ -655,17 +1529,34 @@ func (c *cluster) update(config []serviceEndpoint) error {
 		}
 	}
 
+	var changed *Event
 	if shouldUpdateRoutes {
 		c.active = newActive
 		c.routeManager.setRoutes(newRoutes)
+		c.updateRouteWeights()
+		if len(newRoutes) > 0 {
+			c.markReady()
+		}
+		changed = topologyChange(oldActive, newActive)
 	} else {
 		// cleanup newly created clients if they are not going to be tracked further.
 		toClose = append(toClose, newCliCfg...)
 	}
+	for _, cliAndCfg := range warmed {
+		toClose = append(toClose, cliAndCfg)
+	}
 	c.lock.Unlock()
 
+	if changed != nil {
+		c.events.publish(*changed)
+	}
+
 	go func() {
 		for _, client := range toClose {
+			if c.config.DrainTimeout > 0 {
+				go c.drainClient(client)
+				continue
+			}
 			c.debugLog("Closing client for : %s", client.cfg.hostname)
 			c.closeClient(client.client)
 		}
@@ -673,40 +1564,62 @@ func (c *cluster) update(config []serviceEndpoint) error {
 	return nil
 }
 
-func (c *cluster) onHealthCheckFailed(host hostPort) {
-	c.lock.Lock()
-	c.debugLog("Refreshing cache for host: " + host.host)
-	shouldCloseOldClient := true
-	var oldClientConfig, ok = c.active[host]
-	if ok {
-		cli, err := c.newSingleClient(oldClientConfig.cfg)
-		if singleCli, ok := cli.(HealthCheckDaxAPI); ok {
-			singleCli.startHealthChecks(c, host)
-		}
-
-		if err == nil {
-			c.active[host] = clientAndConfig{client: cli, cfg: oldClientConfig.cfg}
+// onHealthCheckFailed schedules the rebuild of failed, the client of host whose health
+// check failed, on the rebuild pool.
+func (c *cluster) onHealthCheckFailed(host hostPort, failed DaxAPI) {
+	if !c.rebuilds.submit(host, func() { c.rebuildClient(host, failed) }) {
+		c.debugLog("A refresh is already pending, ignoring the health check failure for host: " + host.host)
+		countMetricInt64(context.Background(), c.daxSdkMetrics, daxRebuildSkipped, 1, endpointAttr(host.String()))
+	}
+}
 
-			newRoutes := make([]DaxAPI, len(c.active))
-			i := 0
-			for _, cliAndCfg := range c.active {
-				newRoutes[i] = cliAndCfg.client
-				i++
-			}
-			c.routeManager.setRoutes(newRoutes)
-		} else {
-			shouldCloseOldClient = false
-			c.debugLog("Failed to refresh cache for host: " + host.host)
-		}
-	} else {
+// rebuildClient replaces failed, the client of host, with a new one and closes it. The
+// new client is created without holding the lock and discarded when failed was replaced
+// meanwhile, for instance by a topology update.
+func (c *cluster) rebuildClient(host hostPort, failed DaxAPI) {
+	c.lock.RLock()
+	old, ok := c.active[host]
+	closed, cc := c.closed, c.config.connConfig
+	c.lock.RUnlock()
+	if closed {
+		return
+	}
+	if !ok || old.client != failed {
 		c.debugLog("The node is not part of active routes. Ignoring the health check failure for host: " + host.host)
+		return
+	}
+
+	c.debugLog("Refreshing cache for host: " + host.host)
+	cli, err := c.buildClient(old.cfg, cc)
+	if err != nil {
+		c.debugLog("Failed to refresh cache for host: " + host.host)
+		countMetricInt64(context.Background(), c.daxSdkMetrics, daxRebuildFailure, 1, endpointAttr(host.String()), errorTypeAttr(err))
+		return
+	}
+
+	c.lock.Lock()
+	if c.closed || c.active[host].client != failed {
+		c.lock.Unlock()
+		c.debugLog("The client of host %s was replaced while rebuilding it, discarding the new one", host.host)
+		c.closeClient(cli)
+		return
+	}
+	c.adoptConnections(cli)
+	c.active[host] = clientAndConfig{client: cli, cfg: old.cfg}
+	newRoutes := make([]DaxAPI, 0, len(c.active))
+	for _, cliAndCfg := range c.active {
+		newRoutes = append(newRoutes, cliAndCfg.client)
 	}
+	c.routeManager.setRoutes(newRoutes)
+	c.updateRouteWeights()
 	c.lock.Unlock()
 
-	if shouldCloseOldClient {
-		c.debugLog("Closing old instance of a replaced client for endpoint: %s", oldClientConfig.cfg.hostPort().host)
-		c.closeClient(oldClientConfig.client)
+	if singleCli, ok := cli.(HealthCheckDaxAPI); ok {
+		singleCli.startHealthChecks(c, host)
 	}
+	countMetricInt64(context.Background(), c.daxSdkMetrics, daxRebuildSuccess, 1, endpointAttr(host.String()))
+	c.debugLog("Closing old instance of a replaced client for endpoint: %s", old.cfg.hostPort().host)
+	c.closeClient(failed)
 }
 
 func (c *cluster) hasChanged(cfg []serviceEndpoint) bool {
@@ -721,10 +1634,17 @@ func (c *cluster) hasChanged(cfg []serviceEndpoint) bool {
 	return len(cfg) != len(c.active)
 }
 
-func (c *cluster) pullEndpoints() ([]serviceEndpoint, error) {
+func (c *cluster) pullEndpoints(ctx context.Context) ([]serviceEndpoint, error) {
+	c.lock.RLock()
+	seeds, cc := c.seeds, c.config.connConfig
+	c.lock.RUnlock()
+
 	var lastErr error // TODO chain errors?
-	for _, s := range c.seeds {
-		ips, err := net.LookupIP(s.host)
+	for _, s := range seeds {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ips, err := c.lookupSeed(ctx, s.host)
 		if err != nil {
 			lastErr = err
 			continue
@@ -739,13 +1659,17 @@ func (c *cluster) pullEndpoints() ([]serviceEndpoint, error) {
 		}
 
 		for _, ip := range ips {
-			endpoints, err := c.pullEndpointsFrom(ip, s.port)
+			endpoints, err := c.pullEndpointsFrom(ctx, cc, ip, s.port)
 			if err != nil {
 				lastErr = err
 				continue
 			}
 			c.debugLog("Pulled endpoints from %s : %v", ip, endpoints)
 			if len(endpoints) > 0 {
+				countMetricInt64(ctx, c.daxSdkMetrics, daxDiscoverySeedUsed, 1, seedAttr(s))
+				if c.config.VerifyRosterConsistency {
+					c.verifyRoster(ctx, cc, ip, endpoints)
+				}
 				return endpoints, nil
 			}
 		}
@@ -753,17 +1677,82 @@ func (c *cluster) pullEndpoints() ([]serviceEndpoint, error) {
 	return nil, lastErr
 }
 
-func (c *cluster) pullEndpointsFrom(ip net.IP, port int) ([]serviceEndpoint, error) {
-	client, err := c.clientBuilder.newClient(ip, port, c.config.connConfig, c.config.Region, c.config.Credentials,
+// verifyRoster pulls the roster from a second node and reports when it disagrees with
+// the one returned by the node at ip. A mismatch usually means a stale or partitioned
+// node, which otherwise only shows up as connection errors to nodes that have left. The
+// client of the second node is reused when it is already part of the cluster.
+func (c *cluster) verifyRoster(ctx context.Context, cc connConfig, ip net.IP, endpoints []serviceEndpoint) {
+	var other *serviceEndpoint
+	for i := range endpoints {
+		if !net.IP(endpoints[i].address).Equal(ip) {
+			other = &endpoints[i]
+			break
+		}
+	}
+	if other == nil {
+		return
+	}
+	c.lock.RLock()
+	active, ok := c.active[other.hostPort()]
+	c.lock.RUnlock()
+	var otherEndpoints []serviceEndpoint
+	var err error
+	if ok {
+		otherEndpoints, err = endpointsOf(ctx, active.client)
+	} else {
+		otherEndpoints, err = c.pullEndpointsFrom(ctx, cc, net.IP(other.address), other.port)
+	}
+	if err != nil {
+		c.debugLog("Failed to pull endpoints from %s for roster verification : %s", other.hostname, err)
+		return
+	}
+	if sameRoster(endpoints, otherEndpoints) {
+		return
+	}
+	countMetricInt64(context.Background(), c.daxSdkMetrics, daxClusterRosterMismatches, 1)
+	if c.config.logger != nil {
+		c.config.logger.Logf(logging.Warn, "DAX cluster roster from %s %v does not match roster from %s %v", ip, endpoints, net.IP(other.address), otherEndpoints)
+	}
+}
+
+func sameRoster(a, b []serviceEndpoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	nodes := make(map[hostPort]struct{}, len(a))
+	for _, ep := range a {
+		nodes[ep.hostPort()] = struct{}{}
+	}
+	for _, ep := range b {
+		if _, ok := nodes[ep.hostPort()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cluster) pullEndpointsFrom(ctx context.Context, cc connConfig, ip net.IP, port int) ([]serviceEndpoint, error) {
+	client, err := c.clientBuilder.newClient(ip, port, cc, c.config.Region, c.config.Credentials,
 		c.config.MaxPendingConnectionsPerHost, c.config.DialContext, nil, c.daxSdkMetrics)
 	if err != nil {
 		return nil, err
 	}
+	if single, ok := client.(*SingleDaxClient); ok {
+		single.pool.connectTimeout = c.config.ConnectTimeout
+		if c.config.AuthTimeout > 0 {
+			single.authTimeout = c.config.AuthTimeout
+		}
+	}
 	defer c.closeClient(client)
-	ctx, cfn := context.WithTimeout(context.Background(), 5*time.Second)
+	return endpointsOf(ctx, client)
+}
+
+// endpointsOf pulls the roster from the node of client.
+func endpointsOf(ctx context.Context, client DaxAPI) ([]serviceEndpoint, error) {
+	ctx, cfn := context.WithTimeout(ctx, 5*time.Second)
 	defer cfn()
 	opts := RequestOptions{}
-	opts.RetryMaxAttempts = 2
+	opts.RetryMaxAttempts = discoveryRetries
 	return client.endpoints(ctx, opts)
 }
 
@@ -782,7 +1771,7 @@ func (c *cluster) debugLog(logString string, args ...interface{}) {
 }
 
 func (c *cluster) isRouteManagerEnabled() bool {
-	return c.config.RouteManagerEnabled
+	return c.config.RouteManagerEnabled && !c.config.schedule().HealthCheck.Disabled
 }
 
 func (c *cluster) addRoute(endpoint string, route DaxAPI) {
@@ -804,7 +1793,35 @@ func (c *cluster) getAllRoutes() []DaxAPI {
 }
 
 func (c *cluster) newSingleClient(cfg serviceEndpoint) (DaxAPI, error) {
-	return c.clientBuilder.newClient(net.IP(cfg.address), cfg.port, c.config.connConfig, c.config.Region, c.config.Credentials, c.config.MaxPendingConnectionsPerHost, c.config.DialContext, c, c.daxSdkMetrics)
+	cli, err := c.buildClient(cfg, c.config.connConfig)
+	if err == nil {
+		c.adoptConnections(cli)
+	}
+	return cli, err
+}
+
+// buildClient creates the client of the node cfg, connecting with cc. Unlike
+// newSingleClient, it doesn't need c.lock as it doesn't adopt handed off connections.
+func (c *cluster) buildClient(cfg serviceEndpoint, cc connConfig) (DaxAPI, error) {
+	cli, err := c.clientBuilder.newClient(c.nodeAddress(cfg), cfg.port, cc, c.config.Region, c.config.Credentials, c.config.MaxPendingConnectionsPerHost, c.config.DialContext, c, c.daxSdkMetrics)
+	if err == nil {
+		if single, ok := cli.(*SingleDaxClient); ok {
+			single.pool.setLimits(c.config.MinIdleConnections, c.config.MaxConnectionsPerHost)
+			single.hotKeys = c.hotKeys
+			single.queryShapes = c.queryShapes
+			single.batchWriteConflicts = c.config.BatchWriteConflicts
+			single.events = c.events
+			single.pool.dialLimiter = c.dialLimiter
+			single.clock = c.config.Clock
+			single.executor.clock = c.config.Clock
+			single.pool.connectTimeout = c.config.ConnectTimeout
+			if c.config.AuthTimeout > 0 {
+				single.authTimeout = c.config.AuthTimeout
+			}
+			single.pool.events = c.events
+		}
+	}
+	return cli, err
 }
 
 type clientBuilder interface {
@@ -814,7 +1831,7 @@ type clientBuilder interface {
 type singleClientBuilder struct{}
 
 func (*singleClientBuilder) newClient(ip net.IP, port int, connConfigData connConfig, region string, credentials aws.CredentialsProvider, maxPendingConnects int, dialContextFn dialContext, routeListener RouteListener, sdkMetrics *daxSdkMetrics) (DaxAPI, error) {
-	endpoint := fmt.Sprintf("%s:%d", ip, port)
+	endpoint := hostPort{ip.String(), port}.String()
 
 	return newSingleClientWithOptions(
 		endpoint,
@@ -831,6 +1848,11 @@ func (*singleClientBuilder) newClient(ip net.IP, port int, connConfigData connCo
 type taskExecutor struct {
 	tasks int32
 	close chan struct{}
+	clock Clock // SystemClock when nil
+
+	mu      sync.Mutex
+	stopped bool // protected by mu
+	wg      sync.WaitGroup
 }
 
 func newExecutor() *taskExecutor {
@@ -840,15 +1862,29 @@ func newExecutor() *taskExecutor {
 }
 
 func (e *taskExecutor) start(d time.Duration, action func() error) {
-	ticker := time.NewTicker(d)
+	e.startWithDelay(func() time.Duration { return d }, action)
+}
+
+// startWithDelay runs action repeatedly, waiting for the duration returned by delay before
+// every run.
+func (e *taskExecutor) startWithDelay(delay func() time.Duration, action func() error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.stopped {
+		return
+	}
+	clk := clockOrSystem(e.clock)
 	atomic.AddInt32(&e.tasks, 1)
+	e.wg.Add(1)
 	go func() {
+		defer e.wg.Done()
 		for {
+			timer := clk.NewTimer(delay())
 			select {
-			case <-ticker.C:
+			case <-timer.C():
 				action() // TODO recover from panic()?
 			case <-e.close:
-				ticker.Stop()
+				timer.Stop()
 				atomic.AddInt32(&e.tasks, -1)
 				return
 			}
@@ -861,7 +1897,33 @@ func (e *taskExecutor) numTasks() int32 {
 }
 
 func (e *taskExecutor) stopAll() {
-	close(e.close)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.stopped {
+		e.stopped = true
+		close(e.close)
+	}
+}
+
+// wait waits for the tasks to exit after stopAll, which they do once the action they
+// may be running returns, or until ctx is done.
+func (e *taskExecutor) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	default:
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d tasks still running: %w", e.numTasks(), ctx.Err())
+	}
 }
 
 type RouteListener interface {
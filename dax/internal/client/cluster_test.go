@@ -17,20 +17,27 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"reflect"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-dax-go-v2/dax/leakcheck"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/logging"
 	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -157,6 +164,77 @@ func TestClusterDaxClient_retry(t *testing.T) {
 	}
 }
 
+func TestClusterDaxClient_retryRecordsAttempts(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+
+	failure := newDaxRequestFailure([]int{1}, "RetryableError", "", "", 500, smithy.FaultServer)
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		if layer, ok := retryOwner(o.Context); !ok || layer != RetryLayerCluster {
+			t.Errorf("expected the cluster to own the retry budget, got %q", layer)
+		}
+		calls++
+		if calls < 3 {
+			return failure
+		}
+		return nil
+	}
+	rs := &requestStats{}
+	opt := RequestOptions{Options: dynamodb.Options{RetryMaxAttempts: 3}}
+	if err := cc.retry(withRequestStats(context.Background(), rs), OpGetItem, action, opt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := &dynamodb.GetItemOutput{}
+	setAttemptsMetadata(output, rs.attemptHistory())
+	attempts, ok := GetAttempts(output.ResultMetadata)
+	if !ok {
+		t.Fatal("expected attempts in the output metadata")
+	}
+	expected := []Attempt{
+		{Layer: RetryLayerCluster, Err: failure},
+		{Layer: RetryLayerCluster, Err: failure},
+		{Layer: RetryLayerCluster},
+	}
+	if !reflect.DeepEqual(attempts, expected) {
+		t.Errorf("expected %v, got %v", expected, attempts)
+	}
+}
+
+func TestSetAttemptsMetadata(t *testing.T) {
+	attempts := []Attempt{{Layer: RetryLayerCluster}}
+	for _, output := range []interface{}{
+		&dynamodb.GetItemOutput{}, &dynamodb.PutItemOutput{}, &dynamodb.UpdateItemOutput{},
+		&dynamodb.DeleteItemOutput{}, &dynamodb.BatchGetItemOutput{}, &dynamodb.BatchWriteItemOutput{},
+		&dynamodb.QueryOutput{}, &dynamodb.ScanOutput{}, &dynamodb.TransactGetItemsOutput{},
+		&dynamodb.TransactWriteItemsOutput{}, &dynamodb.ExecuteStatementOutput{},
+		&dynamodb.BatchExecuteStatementOutput{}, &dynamodb.ExecuteTransactionOutput{},
+	} {
+		setAttemptsMetadata(output, attempts)
+		md := reflect.ValueOf(output).Elem().FieldByName("ResultMetadata").Interface().(middleware.Metadata)
+		got, ok := GetAttempts(md)
+		assert.True(t, ok, "%T", output)
+		assert.Equal(t, attempts, got, "%T", output)
+	}
+	setAttemptsMetadata((*dynamodb.GetItemOutput)(nil), attempts)
+	setAttemptsMetadata(nil, attempts)
+}
+
+func TestClusterDaxClient_trackWithoutStats(t *testing.T) {
+	cc := &ClusterDaxClient{config: DefaultConfig()}
+	opt := RequestOptions{}
+	done := cc.track(context.Background(), OpGetItem, &dynamodb.GetItemInput{}, &opt)
+	assert.Nil(t, requestStatsFromContext(cc.newContext(context.Background(), opt)), "no stats are kept without accounting or recorder")
+	done(&dynamodb.GetItemOutput{}, nil)
+
+	cc.accounting = newOperationAccounting()
+	opt = RequestOptions{}
+	cc.track(context.Background(), OpGetItem, &dynamodb.GetItemInput{}, &opt)
+	assert.NotNil(t, requestStatsFromContext(opt.Context))
+}
+
 func TestClusterDaxClient_retrySleepCycleCount(t *testing.T) {
 	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
 	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
@@ -408,6 +486,10 @@ func TestClusterDaxClient_retryReturnsCorrectErrorType(t *testing.T) {
 		opt := RequestOptions{}
 
 		err := cc.retry(context.Background(), "op", action, opt)
+		var re *awshttp.ResponseError
+		require.ErrorAsf(t, err, &re, "conversion of code sequence %v failed: expected the request ID to be attached", c.codes)
+		assert.Equal(t, requestID, re.ServiceRequestID())
+		err = re.Err
 		actualClass := reflect.TypeOf(err)
 		if actualClass != c.class {
 			t.Errorf("conversion of code sequence %v failed: expected %s, but got %s", c.codes, c.class.String(), actualClass.String())
@@ -472,6 +554,224 @@ func TestCluster_refreshEmpty(t *testing.T) {
 	assertDiscoveryClient(clientBuilder.clients[0], t)
 }
 
+func TestCluster_verifyRoster(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.VerifyRosterConsistency = true
+	cluster, clientBuilder := newTestClusterWithConfig(cfg)
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	cluster.daxSdkMetrics = om
+
+	roster := []serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8112},
+	}
+	clientBuilder.ep = roster
+	if _, err := cluster.pullEndpoints(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(clientBuilder.clients) != 2 {
+		t.Errorf("expected roster to be pulled from 2 nodes, got %d", len(clientBuilder.clients))
+	}
+	assert.Equal(t, hostPort{"127.0.0.2", 8112}, clientBuilder.clients[1].hp, "the second node is reached at its own port")
+	expectCounters(t, om, map[string]int{daxClusterRosterMismatches: 0})
+
+	clientBuilder.epByIP = map[string][]serviceEndpoint{"127.0.0.2": roster[1:]}
+	endpoints, err := cluster.pullEndpoints(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(endpoints) != 2 {
+		t.Errorf("expected roster of the first node to be used, got %v", endpoints)
+	}
+	expectCounters(t, om, map[string]int{daxClusterRosterMismatches: 1})
+
+	// once the second node is part of the cluster, its client is reused
+	clientBuilder.epByIP = nil
+	require.NoError(t, cluster.update(roster))
+	node2 := cluster.active[roster[1].hostPort()].client.(*testClient)
+	built := len(clientBuilder.clients)
+	_, err = cluster.pullEndpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, clientBuilder.clients, built+1, "only the seed client is built")
+	assert.Equal(t, 1, node2.endpointsCalls)
+	assert.Zero(t, node2.closeCalls)
+}
+
+type fakeResolver struct {
+	hosts   map[string][]net.IP
+	lookups []string
+}
+
+func (r *fakeResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	r.lookups = append(r.lookups, host)
+	if ips, ok := r.hosts[host]; ok {
+		return ips, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestCluster_pullEndpointsResolver(t *testing.T) {
+	resolver := &fakeResolver{hosts: map[string][]net.IP{"dax.consul": {net.IPv4(10, 0, 0, 7)}}}
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"unknown.consul:8111", "dax.consul:8111", "127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Resolver = resolver
+	cluster, clientBuilder := newTestClusterWithConfig(cfg)
+	clientBuilder.ep = []serviceEndpoint{{hostname: "node1", address: []byte{10, 0, 0, 7}, port: 8111}}
+
+	endpoints, err := cluster.pullEndpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+	assert.Equal(t, []string{"unknown.consul", "dax.consul"}, resolver.lookups)
+	require.Len(t, clientBuilder.clients, 1)
+	assert.Equal(t, "10.0.0.7", clientBuilder.clients[0].hp.host, "endpoints are pulled from the resolved address")
+
+	resolver.hosts = nil
+	resolver.lookups = nil
+	cluster.seeds = cluster.seeds[2:]
+	_, err = cluster.pullEndpoints(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, resolver.lookups, "IP literals are not resolved")
+}
+
+func TestCluster_lookupSeedCache(t *testing.T) {
+	addrs := []net.IP{net.IPv4(10, 0, 0, 7), net.IPv4(10, 0, 0, 8)}
+	resolver := &fakeResolver{hosts: map[string][]net.IP{"dax.internal": addrs}}
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"dax.internal:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Resolver = resolver
+	cfg.Clock = clock
+	cfg.SeedLookupTTL = 30 * time.Second
+	cfg.SeedLookupNegativeTTL = 5 * time.Second
+	cluster, _ := newTestClusterWithConfig(cfg)
+	ctx := context.Background()
+
+	ips, err := cluster.lookupSeed(ctx, "dax.internal")
+	require.NoError(t, err)
+	assert.Equal(t, addrs, ips)
+	ips[0], ips[1] = ips[1], ips[0]
+	ips, err = cluster.lookupSeed(ctx, "dax.internal")
+	require.NoError(t, err)
+	assert.Equal(t, addrs, ips, "the cached addresses are not reordered by callers")
+	assert.Len(t, resolver.lookups, 1)
+
+	// A failure after the TTL keeps the previous addresses for the negative TTL.
+	clock.advance(30 * time.Second)
+	resolver.hosts = nil
+	ips, err = cluster.lookupSeed(ctx, "dax.internal")
+	require.NoError(t, err)
+	assert.Equal(t, addrs, ips)
+	clock.advance(4 * time.Second)
+	_, err = cluster.lookupSeed(ctx, "dax.internal")
+	require.NoError(t, err)
+	assert.Len(t, resolver.lookups, 2)
+	clock.advance(time.Second)
+	resolver.hosts = map[string][]net.IP{"dax.internal": addrs[1:]}
+	ips, err = cluster.lookupSeed(ctx, "dax.internal")
+	require.NoError(t, err)
+	assert.Equal(t, addrs[1:], ips)
+	assert.Len(t, resolver.lookups, 3)
+
+	// Failures without previous addresses are cached for the negative TTL.
+	_, err = cluster.lookupSeed(ctx, "unknown.internal")
+	assert.Error(t, err)
+	_, err = cluster.lookupSeed(ctx, "unknown.internal")
+	assert.Error(t, err)
+	assert.Len(t, resolver.lookups, 4)
+	clock.advance(5 * time.Second)
+	_, err = cluster.lookupSeed(ctx, "unknown.internal")
+	assert.Error(t, err)
+	assert.Len(t, resolver.lookups, 5)
+
+	cluster.config.SeedLookupTTL = 0
+	cluster.config.SeedLookupNegativeTTL = 0
+	cluster.lookupSeed(ctx, "dax.internal")
+	cluster.lookupSeed(ctx, "dax.internal")
+	assert.Len(t, resolver.lookups, 7, "caching is disabled")
+}
+
+func TestCluster_refreshTopology(t *testing.T) {
+	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8111"})
+	clientBuilder.ep = []serviceEndpoint{
+		{nodeId: 2, hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111},
+		{nodeId: 1, hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111},
+	}
+
+	nodes, err := cluster.refreshTopology(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assert.Equal(t, []RosterNode{
+		{NodeID: 1, Hostname: "node1", Address: "127.0.0.1", Port: 8111},
+		{NodeID: 2, Hostname: "node2", Address: "127.0.0.2", Port: 8111},
+	}, nodes)
+	assertNumRoutes(cluster, 2, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cluster.refreshTopology(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, cluster.lastRefreshError(), context.Canceled)
+
+	cluster.Close()
+	_, err = cluster.refreshTopology(context.Background())
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestCluster_updateSeeds(t *testing.T) {
+	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8111"})
+	node1 := serviceEndpoint{nodeId: 1, hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111}
+	node2 := serviceEndpoint{nodeId: 2, hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111}
+	clientBuilder.epByIP = map[string][]serviceEndpoint{
+		"127.0.0.1": {node1},
+		"127.0.0.2": {node2},
+	}
+	require.NoError(t, cluster.refreshNow())
+	assertNumRoutes(cluster, 1, t)
+
+	nodes, err := cluster.updateSeeds(context.Background(), []string{"dax://127.0.0.2:8111"})
+	require.NoError(t, err)
+	assert.Equal(t, []RosterNode{{NodeID: 2, Hostname: "node2", Address: "127.0.0.2", Port: 8111}}, nodes)
+	assert.Equal(t, []hostPort{{"127.0.0.2", 8111}}, cluster.seeds)
+	assert.Equal(t, []string{"dax://127.0.0.2:8111"}, cluster.config.HostPorts)
+
+	_, err = cluster.updateSeeds(context.Background(), nil)
+	assert.Error(t, err)
+	_, err = cluster.updateSeeds(context.Background(), []string{"http://127.0.0.3:8111"})
+	assert.Error(t, err)
+	assert.Equal(t, []hostPort{{"127.0.0.2", 8111}}, cluster.seeds, "invalid endpoints are not applied")
+}
+
+func TestCluster_notReady(t *testing.T) {
+	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.lastRefreshErr = errors.New("discovery failed")
+
+	_, err := cluster.client(nil, OpGetItem)
+	var nre *NotReadyError
+	if assert.ErrorAs(t, err, &nre) {
+		assert.EqualError(t, nre.Err, "discovery failed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err = cluster.waitUntilReady(ctx)
+	assert.ErrorAs(t, err, &nre)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	clientBuilder.ep = []serviceEndpoint{{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111}}
+	require.NoError(t, cluster.refreshNow())
+	require.NoError(t, cluster.waitUntilReady(context.Background()))
+
+	cluster.routeManager.setRoutes(nil)
+	_, err = cluster.client(nil, OpGetItem)
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &nre), "expected no routes error once ready, got %v", err)
+}
+
 func TestCluster_refreshThreshold(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.ClusterUpdateThreshold = time.Millisecond * 100
@@ -626,6 +926,7 @@ func TestCluster_update(t *testing.T) {
 }
 
 func TestCluster_onHealthCheckFailed(t *testing.T) {
+	leakcheck.Check(t)
 	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8888"})
 	endpoint := serviceEndpoint{hostname: "localhost", port: 8123}
 	first := []serviceEndpoint{endpoint, {hostname: "localhost", port: 8124}, {hostname: "localhost", port: 8125}}
@@ -638,7 +939,9 @@ func TestCluster_onHealthCheckFailed(t *testing.T) {
 	assert.Equal(t, 3, len(clientBuilder.clients))
 	assertCloseCalls(cluster, 0, t)
 
-	cluster.onHealthCheckFailed(endpoint.hostPort())
+	failed := cluster.active[endpoint.hostPort()].client
+	cluster.onHealthCheckFailed(endpoint.hostPort(), failed)
+	require.NoError(t, cluster.rebuilds.wait(context.Background()))
 	assertNumRoutes(cluster, 3, t)
 	assertConnections(cluster, first, t)
 	assertHealthCheckCalls(cluster, t)
@@ -646,8 +949,15 @@ func TestCluster_onHealthCheckFailed(t *testing.T) {
 	assert.Equal(t, 4, len(clientBuilder.clients))
 	assertCloseCalls(cluster, 1, t)
 
+	// A late failure of the replaced client is ignored
+	cluster.onHealthCheckFailed(endpoint.hostPort(), failed)
+	require.NoError(t, cluster.rebuilds.wait(context.Background()))
+	assert.Equal(t, 4, len(clientBuilder.clients))
+	assertCloseCalls(cluster, 1, t)
+
 	// Another failure
-	cluster.onHealthCheckFailed(endpoint.hostPort())
+	cluster.onHealthCheckFailed(endpoint.hostPort(), cluster.active[endpoint.hostPort()].client)
+	require.NoError(t, cluster.rebuilds.wait(context.Background()))
 	assertNumRoutes(cluster, 3, t)
 	assertConnections(cluster, first, t)
 	assertHealthCheckCalls(cluster, t)
@@ -656,6 +966,83 @@ func TestCluster_onHealthCheckFailed(t *testing.T) {
 	assertCloseCalls(cluster, 2, t)
 }
 
+func TestCluster_rebuildClientReplacedMeanwhile(t *testing.T) {
+	leakcheck.Check(t)
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8888"})
+	endpoints := []serviceEndpoint{{hostname: "localhost", port: 8123}, {hostname: "localhost", port: 8124}}
+	require.NoError(t, cluster.update(endpoints))
+	host := endpoints[0].hostPort()
+	failed := cluster.active[host].client
+
+	// The node leaves and comes back while the rebuild creates its new client.
+	b := &blockingClientBuilder{release: make(chan struct{})}
+	cluster.clientBuilder = b
+	cluster.onHealthCheckFailed(host, failed)
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.calls == 1
+	}, time.Second, time.Millisecond)
+	require.NoError(t, cluster.update(endpoints[1:]), "the update doesn't wait for the rebuild")
+	close(b.release)
+	require.NoError(t, cluster.update(endpoints))
+	installed := cluster.active[host].client
+	require.NoError(t, cluster.rebuilds.wait(context.Background()))
+
+	assert.Same(t, installed, cluster.active[host].client, "the rebuild doesn't replace the client installed by the update")
+	require.Len(t, b.clients, 2)
+	assert.Equal(t, 1, b.clients[0].closeCalls+b.clients[1].closeCalls, "the client of the rebuild is discarded")
+	require.NoError(t, cluster.Close())
+}
+
+// blockingClientBuilder builds clients once release is closed.
+type blockingClientBuilder struct {
+	testClientBuilder
+	release chan struct{}
+	mu      sync.Mutex
+	calls   int
+}
+
+func (b *blockingClientBuilder) newClient(ip net.IP, port int, cc connConfig, region string, credentials aws.CredentialsProvider, maxPendingConnects int, dialContextFn dialContext, routeListener RouteListener, sdkMetrics *daxSdkMetrics) (DaxAPI, error) {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.testClientBuilder.newClient(ip, port, cc, region, credentials, maxPendingConnects, dialContextFn, routeListener, sdkMetrics)
+}
+
+func TestCluster_onHealthCheckFailedRebuildPool(t *testing.T) {
+	leakcheck.Check(t)
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8888"}
+	cfg.Region = "us-west-2"
+	cfg.MaxConcurrentRebuilds = 1
+	mp := &testMeterProvider{}
+	cfg.MeterProvider = mp
+	cluster, _ := newTestClusterWithConfig(cfg)
+	endpoints := []serviceEndpoint{{hostname: "localhost", port: 8123}, {hostname: "localhost", port: 8124}}
+	cluster.update(endpoints)
+	assertNumRoutes(cluster, 2, t)
+
+	b := &blockingClientBuilder{release: make(chan struct{})}
+	cluster.clientBuilder = b
+	first, second := endpoints[0].hostPort(), endpoints[1].hostPort()
+	cluster.onHealthCheckFailed(first, cluster.active[first].client)
+	cluster.onHealthCheckFailed(first, cluster.active[first].client)
+	cluster.onHealthCheckFailed(second, cluster.active[second].client)
+	assert.Equal(t, 2, cluster.rebuilds.numPending(), "the second failure of a node is ignored")
+
+	close(b.release)
+	require.NoError(t, cluster.rebuilds.wait(context.Background()))
+	assert.Equal(t, 2, b.calls)
+	assert.Equal(t, 2, len(b.clients))
+	expectCounters(t, cluster.daxSdkMetrics, map[string]int{daxRebuildSuccess: 2, daxRebuildSkipped: 1})
+	expectGauges(t, cluster.daxSdkMetrics, map[string]int{daxRebuildsPending: 0})
+	require.NoError(t, cluster.Close())
+}
+
 func TestCluster_client(t *testing.T) {
 	cluster, _ := newTestCluster([]string{"127.0.0.1:8888"})
 	endpoints := []serviceEndpoint{{hostname: "localhost", port: 8121}, {hostname: "localhost", port: 8122}, {hostname: "localhost", port: 8123}}
@@ -679,6 +1066,7 @@ func TestCluster_client(t *testing.T) {
 }
 
 func TestCluster_Close(t *testing.T) {
+	leakcheck.Check(t)
 	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8111"})
 	setExpectation(cluster, []serviceEndpoint{{hostname: "localhost", port: 8121}})
 
@@ -701,6 +1089,35 @@ func TestCluster_Close(t *testing.T) {
 	}
 }
 
+func TestCluster_CloseWithContext(t *testing.T) {
+	leakcheck.Check(t)
+	cluster, clientBuilder := newTestCluster([]string{"127.0.0.1:8111"})
+	setExpectation(cluster, []serviceEndpoint{{hostname: "localhost", port: 8121}})
+	require.NoError(t, cluster.refreshNow())
+
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	cluster.executor.start(time.Millisecond, func() error {
+		once.Do(func() { close(running) })
+		<-release
+		return nil
+	})
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := cluster.CloseWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "cluster: 1 tasks still running")
+	for _, c := range clientBuilder.clients {
+		assert.Equal(t, 1, c.closeCalls)
+	}
+
+	close(release)
+	assert.NoError(t, cluster.CloseWithContext(context.Background()))
+}
+
 func Test_CorrectHostPortUrlFormat(t *testing.T) {
 	hostPort := "dax://test.nds.clustercfg.dax.usw2integ.cache.amazonaws.com:1234"
 	host, port, scheme, _ := parseHostPort(hostPort)
@@ -747,6 +1164,55 @@ func Test_DaxsCorrectUrlFormat(t *testing.T) {
 	assertEqual(t, "daxs", scheme, "")
 }
 
+func Test_IPv6HostPort(t *testing.T) {
+	for hp, want := range map[string]hostPort{
+		"dax://[2600:1f18::1]:8111": {"2600:1f18::1", 8111},
+		"daxs://[2600:1f18::1]":     {"2600:1f18::1", 9111},
+		"[2600:1f18::1]:1234":       {"2600:1f18::1", 1234},
+		"[::1]":                     {"::1", 8111},
+	} {
+		host, port, _, err := parseHostPort(hp)
+		require.NoError(t, err, hp)
+		assert.Equal(t, want, hostPort{host, port}, hp)
+	}
+
+	_, _, _, err := parseHostPort("dax://2600:1f18::1")
+	assert.ErrorContains(t, err, "brackets")
+
+	assert.Equal(t, "[2600:1f18::1]:8111", hostPort{"2600:1f18::1", 8111}.String())
+	assert.Equal(t, "10.0.0.1:8111", hostPort{"10.0.0.1", 8111}.String())
+	ep := serviceEndpoint{address: net.ParseIP("2600:1f18::1"), port: 8111}
+	assert.Equal(t, "[2600:1f18::1]:8111", ep.hostPort().String())
+
+	cli, err := (&singleClientBuilder{}).newClient(net.ParseIP("::1"), 8111, connConfigData, "us-west-2", &testCredentialProvider{}, 1, nil, nil, nil)
+	require.NoError(t, err)
+	defer cli.(*SingleDaxClient).Close()
+	assert.Equal(t, "[::1]:8111", cli.(*SingleDaxClient).pool.address)
+}
+
+func TestCluster_authTimeout(t *testing.T) {
+	for _, c := range []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{0, defaultAuthTimeout},
+		{time.Second, time.Second},
+	} {
+		cfg := DefaultConfig()
+		cfg.HostPorts = []string{"127.0.0.1:8111"}
+		cfg.Region = "us-west-2"
+		cfg.Credentials = &testCredentialProvider{}
+		cfg.AuthTimeout = c.timeout
+		cluster, err := newCluster(cfg)
+		require.NoError(t, err)
+
+		cli, err := cluster.buildClient(serviceEndpoint{hostname: "127.0.0.1", port: 8111}, cfg.connConfig)
+		require.NoError(t, err)
+		assert.Equal(t, c.want, cli.(*SingleDaxClient).authTimeout)
+		cli.(*SingleDaxClient).Close()
+	}
+}
+
 var nonEncEp = "dax://cluster.random.alpha-dax-clusters.us-east-1.amazonaws.com"
 var nonEncNodeEp = "cluster-a.random.nodes.alpha-dax-clusters.us-east-1.amazonaws.com:8111"
 var encEp = "daxs://cluster2.random.alpha-dax-clusters.us-east-1.amazonaws.com"
@@ -793,6 +1259,7 @@ func TestCluster_RouteManagerDisabled(t *testing.T) {
 }
 
 func TestCluster_RouteManagerEnabled(t *testing.T) {
+	leakcheck.Check(t)
 	cluster, clientBuilder := newTestClusterWithRouteManagerEnabled([]string{"non-existent-host:8888", "127.0.0.1:8111"})
 	setExpectation(cluster, []serviceEndpoint{{hostname: "localhost", port: 8121}})
 	if !cluster.isRouteManagerEnabled() {
@@ -922,6 +1389,7 @@ func setExpectation(cluster *cluster, ep []serviceEndpoint) {
 }
 
 func TestCluster_customDialer(t *testing.T) {
+	leakcheck.Check(t)
 	ours, theirs := net.Pipe()
 	var wg sync.WaitGroup
 	var result []byte
@@ -954,6 +1422,7 @@ func TestCluster_customDialer(t *testing.T) {
 	}
 	cc, err := New(cfg)
 	require.NoError(t, err)
+	defer cc.Close()
 	cc.GetItemWithOptions(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("MyTable")}, &dynamodb.GetItemOutput{}, RequestOptions{})
 
 	wg.Wait()
@@ -971,13 +1440,18 @@ func getEndPointResolver(url string) aws.EndpointResolverWithOptions {
 
 type testClientBuilder struct {
 	ep      []serviceEndpoint
+	epByIP  map[string][]serviceEndpoint
 	clients []*testClient
 }
 
 var _ clientBuilder = (*testClientBuilder)(nil)
 
 func (b *testClientBuilder) newClient(ip net.IP, port int, _ connConfig, _ string, _ aws.CredentialsProvider, _ int, _ dialContext, _ RouteListener, _ *daxSdkMetrics) (DaxAPI, error) {
-	t := &testClient{ep: b.ep, hp: hostPort{ip.String(), port}}
+	ep := b.ep
+	if e, ok := b.epByIP[ip.String()]; ok {
+		ep = e
+	}
+	t := &testClient{ep: ep, hp: hostPort{ip.String(), port}}
 	b.clients = append(b.clients, []*testClient{t}...)
 	return t, nil
 }
@@ -1044,6 +1518,18 @@ func (c *testClient) TransactGetItemsWithOptions(_ context.Context, _ *dynamodb.
 	panic("not implemented")
 }
 
+func (c *testClient) BatchExecuteStatementWithOptions(_ context.Context, _ *dynamodb.BatchExecuteStatementInput, _ *dynamodb.BatchExecuteStatementOutput, _ RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	panic("not implemented")
+}
+
+func (c *testClient) ExecuteTransactionWithOptions(_ context.Context, _ *dynamodb.ExecuteTransactionInput, _ *dynamodb.ExecuteTransactionOutput, _ RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	panic("not implemented")
+}
+
+func (c *testClient) ExecuteStatementWithOptions(_ context.Context, _ *dynamodb.ExecuteStatementInput, _ *dynamodb.ExecuteStatementOutput, _ RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	panic("not implemented")
+}
+
 type testCredentialProvider struct {
 }
 
@@ -1054,3 +1540,122 @@ func (p *testCredentialProvider) Retrieve(_ context.Context) (aws.Credentials, e
 		SessionToken:    "token",
 	}, nil
 }
+
+func TestConnConfig_tlsConfig(t *testing.T) {
+	cc := connConfig{isEncrypted: true, hostname: "mycluster.dax.amazonaws.com"}
+	assert.Equal(t, "mycluster.dax.amazonaws.com", cc.tlsConfig().ServerName)
+
+	roots := x509.NewCertPool()
+	cc.baseTLS = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS13}
+	cfg := cc.tlsConfig()
+	assert.Same(t, roots, cfg.RootCAs)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assert.Equal(t, "mycluster.dax.amazonaws.com", cfg.ServerName)
+	assert.Empty(t, cc.baseTLS.ServerName, "the base configuration is not modified")
+
+	cc.baseTLS.ServerName = "proxy.internal"
+	assert.Equal(t, "proxy.internal", cc.tlsConfig().ServerName)
+
+	cc.skipHostnameVerification = true
+	assert.True(t, cc.tlsConfig().InsecureSkipVerify)
+}
+
+func TestConnConfig_tlsConfigClientCertificates(t *testing.T) {
+	base, extra := tls.Certificate{Certificate: [][]byte{{1}}}, tls.Certificate{Certificate: [][]byte{{2}}}
+	cc := connConfig{isEncrypted: true, hostname: "mycluster.dax.amazonaws.com", clientCertificates: []tls.Certificate{extra}}
+	assert.Equal(t, []tls.Certificate{extra}, cc.tlsConfig().Certificates)
+
+	cc.baseTLS = &tls.Config{Certificates: make([]tls.Certificate, 1, 2)}
+	cc.baseTLS.Certificates[0] = base
+	assert.Equal(t, []tls.Certificate{base, extra}, cc.tlsConfig().Certificates)
+	assert.Empty(t, cc.baseTLS.Certificates[:2][1], "the base configuration is not modified")
+}
+
+func TestClusterDaxClient_retryMaxElapsedTime(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	clk := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	cc := ClusterDaxClient{config: cfg, cluster: cluster}
+
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		calls++
+		clk.advance(400 * time.Millisecond)
+		return newDaxRequestFailure([]int{1}, "RetryableError", "", "req-1", 500, smithy.FaultServer)
+	}
+	opt := RequestOptions{MaxRetryElapsedTime: time.Second}
+	opt.RetryMaxAttempts = 10
+
+	err := cc.retry(context.Background(), OpGetItem, action, opt)
+	var exceeded *RetryBudgetExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, exceeded.Attempts)
+	assert.Equal(t, 1200*time.Millisecond, exceeded.Elapsed)
+	var re *awshttp.ResponseError
+	require.ErrorAs(t, exceeded.Err, &re, "the error of the last attempt is converted as usual")
+	assert.Equal(t, "req-1", re.RequestID)
+
+	calls = 0
+	opt.RetryDelay = 700 * time.Millisecond
+	err = cc.retry(context.Background(), OpGetItem, action, opt)
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 1, calls, "no retry is made when its backoff would end past the budget")
+	assert.Equal(t, 400*time.Millisecond, exceeded.Elapsed)
+
+	calls = 0
+	opt.RetryDelay = 0
+	opt.MaxRetryElapsedTime = 0
+	err = cc.retry(context.Background(), OpGetItem, action, opt)
+	assert.False(t, errors.As(err, &exceeded))
+	assert.Equal(t, 11, calls)
+
+	// an attempt still in progress when the budget runs out is abandoned
+	calls = 0
+	opt.MaxRetryElapsedTime = 20 * time.Millisecond
+	err = cc.retry(context.Background(), OpGetItem, func(client DaxAPI, o RequestOptions) error {
+		calls++
+		<-o.Context.Done()
+		return o.Context.Err()
+	}, opt)
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, exceeded.Attempts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type batchStatementClient struct {
+	*testClient
+	calls int
+}
+
+func (c *batchStatementClient) BatchExecuteStatementWithOptions(_ context.Context, _ *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, _ RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	c.calls++
+	return output, newDaxRequestFailure([]int{1}, "RetryableError", "", "", 500, smithy.FaultServer)
+}
+
+func TestClusterDaxClient_batchExecuteStatementRetries(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	c := &batchStatementClient{testClient: &testClient{}}
+	cluster.routeManager.setRoutes([]DaxAPI{c})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+	batch := func(statements ...string) *dynamodb.BatchExecuteStatementInput {
+		in := &dynamodb.BatchExecuteStatementInput{}
+		for _, s := range statements {
+			in.Statements = append(in.Statements, types.BatchStatementRequest{Statement: aws.String(s)})
+		}
+		return in
+	}
+	opt := RequestOptions{Options: dynamodb.Options{RetryMaxAttempts: 2}}
+
+	_, err := cc.BatchExecuteStatementWithOptions(context.Background(), batch(`SELECT * FROM "t" WHERE pk = 'a'`), nil, opt)
+	assert.Error(t, err)
+	assert.Equal(t, 3, c.calls)
+
+	c.calls = 0
+	_, err = cc.BatchExecuteStatementWithOptions(context.Background(), batch(`SELECT * FROM "t" WHERE pk = 'a'`, `UPDATE "t" SET x = x + 1 WHERE pk = 'b'`), nil, opt)
+	assert.Error(t, err)
+	assert.Equal(t, 1, c.calls, "batches with writes must not be retried")
+}
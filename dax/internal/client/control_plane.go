@@ -0,0 +1,196 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	controlPlaneService = "dax"
+	describeClusters    = "AmazonDAXV3.DescribeClusters"
+	// controlPlaneTimeout bounds the DescribeClusters request made when the client is
+	// created, so that an unreachable control plane fails New instead of blocking it.
+	controlPlaneTimeout = 10 * time.Second
+)
+
+// describeClustersOutput holds the parts of the DescribeClusters response used to find the
+// discovery endpoint of a cluster.
+type describeClustersOutput struct {
+	Clusters []struct {
+		ClusterName              string
+		Status                   string
+		ClusterDiscoveryEndpoint *struct {
+			Address string
+			Port    int
+		}
+		ClusterEndpointEncryptionType string
+	}
+}
+
+// clusterEndpoint looks up the discovery endpoint of the cluster named cfg.ClusterName with
+// the DescribeClusters API of the DAX control plane. The endpoint is returned in the form
+// of Config.HostPorts, with the daxs scheme for clusters encrypting their endpoints.
+func clusterEndpoint(ctx context.Context, cfg Config) (string, error) {
+	if len(cfg.Region) == 0 {
+		return "", smithy.NewErrParamRequired("config.Region")
+	}
+	if cfg.Credentials == nil {
+		return "", smithy.NewErrParamRequired("config.Credentials")
+	}
+	ctx, cancel := context.WithTimeout(ctx, controlPlaneTimeout)
+	defer cancel()
+
+	endpoint := cfg.ControlPlaneEndpoint
+	if endpoint == "" {
+		endpoint = "https://dax." + cfg.Region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(map[string][]string{"ClusterNames": {cfg.ClusterName}})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", describeClusters)
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", &AuthError{Err: err}
+	}
+	sum := sha256.Sum256(body)
+	if err = v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), controlPlaneService, cfg.Region, clockOrSystem(cfg.Clock).Now()); err != nil {
+		return "", err
+	}
+
+	httpClient := controlPlaneClient(cfg)
+	defer httpClient.CloseIdleConnections()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", controlPlaneError(resp, payload)
+	}
+
+	var out describeClustersOutput
+	if err = json.Unmarshal(payload, &out); err != nil {
+		return "", &smithy.DeserializationError{Err: err}
+	}
+	for _, c := range out.Clusters {
+		if !strings.EqualFold(c.ClusterName, cfg.ClusterName) {
+			continue
+		}
+		if c.ClusterDiscoveryEndpoint == nil || c.ClusterDiscoveryEndpoint.Address == "" {
+			return "", &smithy.GenericAPIError{
+				Code:    ErrCodeServiceUnavailable,
+				Message: fmt.Sprintf("cluster %s has no discovery endpoint, its status is %s", cfg.ClusterName, c.Status),
+				Fault:   smithy.FaultServer,
+			}
+		}
+		scheme := "dax"
+		if c.ClusterEndpointEncryptionType == "TLS" {
+			scheme = "daxs"
+		}
+		return scheme + "://" + net.JoinHostPort(c.ClusterDiscoveryEndpoint.Address, strconv.Itoa(c.ClusterDiscoveryEndpoint.Port)), nil
+	}
+	return "", &smithy.GenericAPIError{
+		Code:    "ClusterNotFoundFault",
+		Message: fmt.Sprintf("cluster %s not found", cfg.ClusterName),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// controlPlaneClient returns the HTTP client of the control plane requests, which connects
+// the way the client connects to the nodes: with cfg.DialContext, or else through cfg.Proxy,
+// and with cfg.TLSConfig as the base TLS configuration.
+func controlPlaneClient(cfg Config) *http.Client {
+	connectTimeout := max(cfg.ConnectTimeout, 0)
+	transport := &http.Transport{
+		TLSClientConfig:     cfg.TLSConfig.Clone(),
+		TLSHandshakeTimeout: connectTimeout,
+	}
+	if cfg.DialContext != nil {
+		transport.DialContext = cfg.DialContext
+	} else {
+		transport.DialContext = (&net.Dialer{Timeout: connectTimeout}).DialContext
+		if cfg.Proxy != nil {
+			transport.Proxy = func(req *http.Request) (*url.URL, error) {
+				port := req.URL.Port()
+				if port == "" {
+					port = "443"
+					if req.URL.Scheme == "http" {
+						port = "80"
+					}
+				}
+				return cfg.Proxy(net.JoinHostPort(req.URL.Hostname(), port))
+			}
+		}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// controlPlaneError returns the error of a failed control plane request, carrying its
+// request ID like the errors returned by the nodes.
+func controlPlaneError(resp *http.Response, payload []byte) error {
+	var body struct {
+		Type    string `json:"__type"`
+		Message string `json:"message"`
+	}
+	json.Unmarshal(payload, &body)
+	code := body.Type
+	if i := strings.LastIndexByte(code, '#'); i >= 0 {
+		code = code[i+1:]
+	}
+	if code == "" {
+		code = http.StatusText(resp.StatusCode)
+	}
+	fault := smithy.FaultServer
+	if resp.StatusCode < 500 {
+		fault = smithy.FaultClient
+	}
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: resp},
+			Err:      &smithy.GenericAPIError{Code: code, Message: body.Message, Fault: fault},
+		},
+		RequestID: resp.Header.Get("X-Amzn-Requestid"),
+	}
+}
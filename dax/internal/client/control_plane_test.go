@@ -0,0 +1,147 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newControlPlane(t *testing.T, status int, response string) (*httptest.Server, *[]map[string][]string) {
+	var requests []map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, describeClusters, r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-west-2/dax/aws4_request")
+		assert.Equal(t, "token", r.Header.Get("X-Amz-Security-Token"))
+		body, _ := io.ReadAll(r.Body)
+		var in map[string][]string
+		assert.NoError(t, json.Unmarshal(body, &in))
+		requests = append(requests, in)
+		w.Header().Set("X-Amzn-Requestid", "req-1")
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func controlPlaneConfig(endpoint string) Config {
+	cfg := DefaultConfig()
+	cfg.Region = "us-west-2"
+	cfg.Credentials = &testCredentialProvider{}
+	cfg.ClusterName = "orders"
+	cfg.ControlPlaneEndpoint = endpoint
+	return cfg
+}
+
+func TestClusterEndpoint(t *testing.T) {
+	srv, requests := newControlPlane(t, http.StatusOK, `{"Clusters":[{"ClusterName":"orders","Status":"available",
+		"ClusterDiscoveryEndpoint":{"Address":"orders.abc123.dax-clusters.us-west-2.amazonaws.com","Port":9111},
+		"ClusterEndpointEncryptionType":"TLS"}]}`)
+
+	endpoint, err := clusterEndpoint(context.Background(), controlPlaneConfig(srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "daxs://orders.abc123.dax-clusters.us-west-2.amazonaws.com:9111", endpoint)
+	assert.Equal(t, []map[string][]string{{"ClusterNames": {"orders"}}}, *requests)
+}
+
+func TestClusterEndpoint_unencrypted(t *testing.T) {
+	srv, _ := newControlPlane(t, http.StatusOK, `{"Clusters":[{"ClusterName":"orders","Status":"available",
+		"ClusterDiscoveryEndpoint":{"Address":"orders.abc123.dax-clusters.us-west-2.amazonaws.com","Port":8111},
+		"ClusterEndpointEncryptionType":"NONE"}]}`)
+
+	endpoint, err := clusterEndpoint(context.Background(), controlPlaneConfig(srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "dax://orders.abc123.dax-clusters.us-west-2.amazonaws.com:8111", endpoint)
+}
+
+func TestClusterEndpoint_errors(t *testing.T) {
+	srv, _ := newControlPlane(t, http.StatusBadRequest, `{"__type":"com.amazonaws.dax.v20170419#ClusterNotFoundFault","message":"Cluster orders not found"}`)
+	_, err := clusterEndpoint(context.Background(), controlPlaneConfig(srv.URL))
+	var re *awshttp.ResponseError
+	require.ErrorAs(t, err, &re)
+	assert.Equal(t, "req-1", re.ServiceRequestID())
+	var ae smithy.APIError
+	require.ErrorAs(t, err, &ae)
+	assert.Equal(t, "ClusterNotFoundFault", ae.ErrorCode())
+	assert.Equal(t, smithy.FaultClient, ae.ErrorFault())
+
+	srv, _ = newControlPlane(t, http.StatusOK, `{"Clusters":[{"ClusterName":"orders","Status":"creating"}]}`)
+	_, err = clusterEndpoint(context.Background(), controlPlaneConfig(srv.URL))
+	require.ErrorAs(t, err, &ae)
+	assert.Equal(t, ErrCodeServiceUnavailable, ae.ErrorCode())
+	assert.Contains(t, ae.ErrorMessage(), "creating")
+
+	srv, _ = newControlPlane(t, http.StatusOK, `{"Clusters":[]}`)
+	_, err = clusterEndpoint(context.Background(), controlPlaneConfig(srv.URL))
+	require.ErrorAs(t, err, &ae)
+	assert.Equal(t, "ClusterNotFoundFault", ae.ErrorCode())
+}
+
+func TestClusterEndpoint_connection(t *testing.T) {
+	srv, _ := newControlPlane(t, http.StatusOK, `{"Clusters":[{"ClusterName":"orders","Status":"available",
+		"ClusterDiscoveryEndpoint":{"Address":"127.0.0.1","Port":8111},"ClusterEndpointEncryptionType":"NONE"}]}`)
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	cfg := controlPlaneConfig("http://dax.invalid")
+	var proxied []string
+	cfg.Proxy = func(address string) (*url.URL, error) {
+		proxied = append(proxied, address)
+		return srvURL, nil
+	}
+	endpoint, err := clusterEndpoint(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "dax://127.0.0.1:8111", endpoint)
+	assert.Equal(t, []string{"dax.invalid:80"}, proxied)
+
+	cfg = controlPlaneConfig("http://dax.invalid")
+	var dialed []string
+	cfg.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		return (&net.Dialer{}).DialContext(ctx, network, srvURL.Host)
+	}
+	_, err = clusterEndpoint(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dax.invalid:80"}, dialed)
+}
+
+func TestNewWithContext_clusterName(t *testing.T) {
+	srv, _ := newControlPlane(t, http.StatusOK, `{"Clusters":[{"ClusterName":"orders","Status":"available",
+		"ClusterDiscoveryEndpoint":{"Address":"127.0.0.1","Port":8111},"ClusterEndpointEncryptionType":"NONE"}]}`)
+	cfg := controlPlaneConfig(srv.URL)
+	cfg.Schedule.ClusterRefresh.Disabled = true
+
+	cc, err := NewWithContext(context.Background(), cfg)
+	require.NoError(t, err)
+	defer cc.Close()
+	assert.Equal(t, []string{"dax://127.0.0.1:8111"}, cc.config.HostPorts)
+	assert.Equal(t, []hostPort{{"127.0.0.1", 8111}}, cc.cluster.seeds)
+}
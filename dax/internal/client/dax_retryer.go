@@ -16,14 +16,31 @@
 package client
 
 import (
+	"errors"
 	"math/rand"
 	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 // DaxRetryer implements retry strategy with equal jitter backoff for throttled requests
 type DaxRetryer struct {
 	BaseThrottleDelay time.Duration
 	MaxBackoffDelay   time.Duration
+
+	// Operations overrides the handling of throttled requests for individual operations,
+	// keyed by operation name such as OpScan. Retrying a throttled Scan adds to the
+	// pressure on the table, while point reads usually succeed on the next attempt.
+	Operations map[string]OperationRetryPolicy
+}
+
+// OperationRetryPolicy tunes the retries of throttled requests of an operation.
+type OperationRetryPolicy struct {
+	// ThrottleRetries, when set, caps the number of retries of throttled attempts, within
+	// the retries of the request. Other errors are retried as usual.
+	ThrottleRetries *int
+	// BackoffMultiplier, when positive, scales the backoff delay of throttled attempts.
+	BackoffMultiplier float64
 }
 
 const (
@@ -57,6 +74,27 @@ func (r DaxRetryer) RetryDelay(attempts int, err error) time.Duration {
 	return 0
 }
 
+// retryDelay returns the delay before retrying an attempt of op, scaling RetryDelay by
+// the backoff multiplier of op.
+func (r DaxRetryer) retryDelay(op string, attempts int, err error) time.Duration {
+	r.setRetryerDefaults()
+	delay := r.RetryDelay(attempts, err)
+	if p, ok := r.Operations[op]; ok && p.BackoffMultiplier > 0 {
+		delay = time.Duration(float64(delay) * p.BackoffMultiplier)
+		if delay > r.MaxBackoffDelay {
+			delay = r.MaxBackoffDelay
+		}
+	}
+	return delay
+}
+
+// allowThrottleRetry reports whether a throttled attempt of op may be retried after
+// throttled retries of throttled attempts.
+func (r DaxRetryer) allowThrottleRetry(op string, throttled int) bool {
+	p, ok := r.Operations[op]
+	return !ok || p.ThrottleRetries == nil || throttled < *p.ThrottleRetries
+}
+
 // MaxAttempts returns the maximum number of retry attempts
 func (r DaxRetryer) MaxAttempts() int {
 	return 0 // You can adjust this value based on your requirements
@@ -65,9 +103,22 @@ func (r DaxRetryer) MaxAttempts() int {
 // IsErrorRetryable returns if the error is daxError
 // if code sequences correct any condition return a value other than unknown.
 func (r DaxRetryer) IsErrorRetryable(err error) bool {
+	// Checked first so that no classification, including a customized ThrottleChecker,
+	// can turn a failed condition into a retry.
+	if isConditionalCheckFailed(err) {
+		return false
+	}
 	if IsThrottleError(err) {
 		return true
 	}
+	var fbt *firstByteTimeoutError
+	if errors.As(err, &fbt) {
+		return IsReadOperation(fbt.op)
+	}
+	var ae *AuthError
+	if errors.As(err, &ae) {
+		return ae.retryable()
+	}
 	de, ok := err.(daxError)
 	if !ok {
 		return false
@@ -88,6 +139,21 @@ func isAuthCRequiredException(codes []int) bool {
 	return len(codes) == 4 && codes[0] == 4 && codes[1] == 23 && codes[2] == 31 && codes[3] == 33
 }
 
+// isConditionalCheckFailed reports whether err is a failed condition expression, code
+// sequence [4.37.*.39.43]. These failures are deterministic for a given item state.
+func isConditionalCheckFailed(err error) bool {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return true
+	}
+	var de daxError
+	if !errors.As(err, &de) {
+		return false
+	}
+	codes := de.CodeSequence()
+	return len(codes) > 4 && codes[0] == 4 && codes[1] == 37 && codes[3] == 39 && codes[4] == 43
+}
+
 func isRetryable(o RequestOptions, err error) bool {
 	return o.Retryer.IsErrorRetryable(err)
 }
@@ -16,9 +16,14 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/smithy-go"
 )
 
@@ -58,6 +63,21 @@ func TestDaxRetryer_IsErrorRetryable(t *testing.T) {
 			err:      newDaxRequestFailure([]int{0}, "", "", "", 500, smithy.FaultServer),
 			expected: false,
 		},
+		{
+			name:     "first byte timeout of read",
+			err:      &firstByteTimeoutError{op: OpGetItem},
+			expected: true,
+		},
+		{
+			name:     "first byte timeout of write",
+			err:      &firstByteTimeoutError{op: OpPutItem},
+			expected: false,
+		},
+		{
+			name:     "translated first byte timeout of write",
+			err:      translateError(&firstByteTimeoutError{op: OpPutItem}),
+			expected: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,6 +111,67 @@ func TestDaxRetryer_RetryDelay(t *testing.T) {
 	}
 }
 
+func TestDaxRetryer_operations(t *testing.T) {
+	retryer := DaxRetryer{
+		BaseThrottleDelay: 10 * time.Millisecond,
+		MaxBackoffDelay:   time.Second,
+		Operations: map[string]OperationRetryPolicy{
+			OpScan:    {ThrottleRetries: aws.Int(1), BackoffMultiplier: 1000},
+			OpGetItem: {ThrottleRetries: aws.Int(0)},
+		},
+	}
+	throttleErr := newDaxRequestFailure([]int{}, "ThrottlingException", "", "", 400, smithy.FaultClient)
+
+	if delay := retryer.retryDelay(OpScan, 1, throttleErr); delay != time.Second {
+		t.Errorf("Expected the Scan delay to be capped at %v, got %v", time.Second, delay)
+	}
+	if delay := retryer.retryDelay(OpQuery, 1, throttleErr); delay > 20*time.Millisecond {
+		t.Errorf("Expected the Query delay not to be scaled, got %v", delay)
+	}
+
+	if !retryer.allowThrottleRetry(OpScan, 0) || retryer.allowThrottleRetry(OpScan, 1) {
+		t.Error("Expected a single throttled Scan attempt to be retried")
+	}
+	if retryer.allowThrottleRetry(OpGetItem, 0) {
+		t.Error("Expected throttled GetItem attempts not to be retried")
+	}
+	if !retryer.allowThrottleRetry(OpQuery, 5) {
+		t.Error("Expected throttled Query attempts to be retried")
+	}
+}
+
+func TestClusterDaxClient_retryThrottlePolicy(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		calls++
+		return newDaxRequestFailure([]int{}, "ThrottlingException", "", "", 400, smithy.FaultClient)
+	}
+	opt := RequestOptions{
+		Options: dynamodb.Options{RetryMaxAttempts: 5},
+		Retryer: DaxRetryer{
+			BaseThrottleDelay: time.Millisecond,
+			MaxBackoffDelay:   time.Millisecond,
+			Operations:        map[string]OperationRetryPolicy{OpScan: {ThrottleRetries: aws.Int(1)}},
+		},
+	}
+	if err := cc.retry(context.Background(), OpScan, action, opt); err == nil {
+		t.Fatal("Expected the throttling error")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 Scan attempts, got %d", calls)
+	}
+
+	calls = 0
+	cc.retry(context.Background(), OpGetItem, action, opt)
+	if calls != 6 {
+		t.Errorf("Expected 6 GetItem attempts, got %d", calls)
+	}
+}
+
 // Test MaxAttempts
 func TestDaxRetryer_MaxAttempts(t *testing.T) {
 	retryer := &DaxRetryer{}
@@ -98,3 +179,45 @@ func TestDaxRetryer_MaxAttempts(t *testing.T) {
 		t.Errorf("Expected MaxAttempts to return 0, got %d", retryer.MaxAttempts())
 	}
 }
+
+// TestRetryDecisionMatrix documents which errors the cluster retry loop retries.
+func TestRetryDecisionMatrix(t *testing.T) {
+	ccfCodes := []int{4, 37, 38, 39, 43}
+	tests := []struct {
+		name  string
+		err   error
+		retry bool
+	}{
+		{"io error", fmt.Errorf("io"), false},
+		{"recoverable code 1", newDaxRequestFailure([]int{1}, "", "", "", 500, smithy.FaultServer), true},
+		{"recoverable code 2", newDaxRequestFailure([]int{2}, "", "", "", 500, smithy.FaultServer), true},
+		{"authentication required", newDaxRequestFailure([]int{4, 23, 31, 33}, "", "", "", 500, smithy.FaultServer), true},
+		{"throttling", newDaxRequestFailure([]int{4, 37, 38, 39, 50}, ErrCodeThrottlingException, "", "", 400, smithy.FaultClient), true},
+		{"provisioned throughput exceeded", newDaxRequestFailure([]int{4, 37, 38, 39, 40}, "ProvisionedThroughputExceededException", "", "", 400, smithy.FaultClient), true},
+		{"validation", newDaxRequestFailure([]int{4, 37, 38, 39, 46}, ErrCodeValidationException, "", "", 400, smithy.FaultClient), false},
+		{"resource not found", newDaxRequestFailure([]int{4, 37, 38, 39, 41}, "ResourceNotFoundException", "", "", 400, smithy.FaultClient), false},
+		{"conditional check failed", newDaxRequestFailure(ccfCodes, "ConditionalCheckFailedException", "", "", 400, smithy.FaultClient), false},
+		{"converted conditional check failed", &types.ConditionalCheckFailedException{}, false},
+		{"wrapped conditional check failed", fmt.Errorf("wrapped: %w", newDaxRequestFailure(ccfCodes, "", "", "", 400, smithy.FaultClient)), false},
+	}
+
+	check := func(t *testing.T) {
+		for _, tt := range tests {
+			opt := RequestOptions{Retryer: DaxRetryer{}}
+			if got := isRetryable(opt, tt.err); got != tt.retry {
+				t.Errorf("%s: isRetryable() = %v, want %v", tt.name, got, tt.retry)
+			}
+		}
+	}
+	check(t)
+
+	// Classifying condition failures as throttles must not make them retryable.
+	saved := ThrottleChecker
+	defer func() { ThrottleChecker = saved }()
+	codes := map[string]struct{}{"ConditionalCheckFailedException": {}}
+	for k, v := range saved.Codes {
+		codes[k] = v
+	}
+	ThrottleChecker.Codes = codes
+	check(t)
+}
@@ -29,11 +29,16 @@ import (
 //
 // Expects Context to always return a non-nil error if the Done channel is closed.
 func SleepWithContext(ctx context.Context, op string, dur time.Duration) error {
-	t := time.NewTimer(dur)
+	return sleepWithClock(ctx, SystemClock, op, dur)
+}
+
+// sleepWithClock is SleepWithContext timed by clk, SystemClock when nil.
+func sleepWithClock(ctx context.Context, clk Clock, op string, dur time.Duration) error {
+	t := clockOrSystem(clk).NewTimer(dur)
 	defer t.Stop()
 
 	select {
-	case <-t.C:
+	case <-t.C():
 		break
 	case <-ctx.Done():
 		err := ctx.Err()
@@ -0,0 +1,76 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// dialLimiter smooths the connection attempts to the nodes of a cluster. When every node
+// is restarted or replaced at once, all the pools of all the clients reconnect at the same
+// time; the limiter bounds the number of attempts in progress across the nodes and spreads
+// the reconnects of a client over time.
+type dialLimiter struct {
+	slots  chan struct{} // nil when the number of attempts in progress is not limited
+	jitter time.Duration
+	clock  Clock // SystemClock when nil
+}
+
+// newDialLimiter returns a limiter allowing maxConcurrent attempts in progress and
+// delaying reconnects by up to jitter, or nil when neither is positive.
+func newDialLimiter(maxConcurrent int, jitter time.Duration) *dialLimiter {
+	if maxConcurrent <= 0 && jitter <= 0 {
+		return nil
+	}
+	l := &dialLimiter{jitter: jitter}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// dial connects to address with dialFn once the limiter allows it. Reconnects, the attempts
+// to replace the connections lost to a node, are delayed by a random duration up to the
+// jitter. A nil limiter dials right away.
+func (l *dialLimiter) dial(ctx context.Context, reconnect bool, dialFn dialContext, network, address string) (net.Conn, error) {
+	if l == nil {
+		return dialFn(ctx, network, address)
+	}
+
+	if l.jitter > 0 && reconnect {
+		timer := clockOrSystem(l.clock).NewTimer(time.Duration(rand.Int63n(int64(l.jitter))))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			defer func() { <-l.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return dialFn(ctx, network, address)
+}
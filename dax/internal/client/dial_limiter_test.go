@@ -0,0 +1,104 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialLimiter_nil(t *testing.T) {
+	assert.Nil(t, newDialLimiter(0, 0))
+
+	var l *dialLimiter
+	calls := 0
+	_, err := l.dial(context.Background(), false, func(ctx context.Context, network, address string) (net.Conn, error) {
+		calls++
+		return &mockConn{}, nil
+	}, network, "127.0.0.1:8111")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDialLimiter_maxConcurrent(t *testing.T) {
+	l := newDialLimiter(2, 0)
+	var inFlight, maxInFlight int32
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return &mockConn{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := l.dial(context.Background(), false, dial, network, "127.0.0.1:8111")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 2, atomic.LoadInt32(&maxInFlight))
+}
+
+func TestDialLimiter_maxConcurrentContextDone(t *testing.T) {
+	l := newDialLimiter(1, 0)
+	l.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := l.dial(ctx, false, func(ctx context.Context, network, address string) (net.Conn, error) {
+		t.Fatal("unexpected dial")
+		return nil, nil
+	}, network, "127.0.0.1:8111")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDialLimiter_reconnectJitter(t *testing.T) {
+	l := newDialLimiter(0, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// new connections are not delayed, however many were opened before
+	for i := 0; i < 3; i++ {
+		_, err := l.dial(ctx, false, func(ctx context.Context, network, address string) (net.Conn, error) {
+			return &mockConn{}, nil
+		}, network, "127.0.0.1:8111")
+		require.NoError(t, err)
+	}
+
+	// reconnects are
+	_, err := l.dial(ctx, true, func(ctx context.Context, network, address string) (net.Conn, error) {
+		t.Fatal("unexpected dial")
+		return nil, nil
+	}, network, "127.0.0.1:8111")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,57 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+)
+
+// drainer is implemented by the clients which can wait for their requests in flight.
+type drainer interface {
+	drain(ctx context.Context) error
+}
+
+// drain waits until no request is in flight on the client, or until ctx is done.
+func (client *SingleDaxClient) drain(ctx context.Context) error {
+	return client.pool.waitIdle(ctx)
+}
+
+// drainClient closes the client of a node which left the cluster once its requests in
+// flight finished, waiting up to Config.DrainTimeout for them. Closing the cluster cuts
+// the wait short.
+func (c *cluster) drainClient(cc clientAndConfig) {
+	if d, ok := cc.client.(drainer); ok {
+		c.debugLog("Draining client for : %s", cc.cfg.hostname)
+		if err := c.waitDrained(d); err != nil {
+			c.debugLog("Closing client for %s with requests still in flight: %v", cc.cfg.hostname, err)
+		}
+	}
+	c.debugLog("Closing client for : %s", cc.cfg.hostname)
+	c.closeClient(cc.client)
+}
+
+func (c *cluster) waitDrained(d drainer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.DrainTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.executor.close:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return d.drain(ctx)
+}
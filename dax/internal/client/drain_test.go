@@ -0,0 +1,128 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleDaxClient_drain(t *testing.T) {
+	client, err := newSingleClientWithOptions("127.0.0.1:9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{}, nil
+	}, nil, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.drain(context.Background()), "an idle client is drained")
+
+	require.NoError(t, client.pool.begin())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.drain(ctx), context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() { done <- client.drain(context.Background()) }()
+	client.pool.end()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return once the request ended")
+	}
+}
+
+// drainTestClient is a testClient which stays busy until released.
+type drainTestClient struct {
+	testClient
+	release chan struct{}
+	closed  chan struct{}
+}
+
+func (c *drainTestClient) drain(ctx context.Context) error {
+	select {
+	case <-c.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *drainTestClient) Close() error {
+	close(c.closed)
+	return nil
+}
+
+type drainTestClientBuilder struct {
+	mu      sync.Mutex
+	clients map[hostPort]*drainTestClient
+}
+
+func (b *drainTestClientBuilder) newClient(ip net.IP, port int, _ connConfig, _ string, _ aws.CredentialsProvider, _ int, _ dialContext, _ RouteListener, _ *daxSdkMetrics) (DaxAPI, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	hp := hostPort{ip.String(), port}
+	c := &drainTestClient{testClient: testClient{hp: hp}, release: make(chan struct{}), closed: make(chan struct{})}
+	b.clients[hp] = c
+	return c, nil
+}
+
+func TestCluster_updateDrainsRemovedNodes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8888"}
+	cfg.Region = "us-west-2"
+	cfg.DrainTimeout = time.Minute
+	cluster, _ := newTestClusterWithConfig(cfg)
+	b := &drainTestClientBuilder{clients: map[hostPort]*drainTestClient{}}
+	cluster.clientBuilder = b
+
+	first := serviceEndpoint{hostname: "localhost", port: 8121}
+	second := serviceEndpoint{hostname: "localhost", port: 8122}
+	require.NoError(t, cluster.update([]serviceEndpoint{first, second}))
+	assertNumRoutes(cluster, 2, t)
+	drained := b.clients[second.hostPort()]
+
+	require.NoError(t, cluster.update([]serviceEndpoint{first}))
+	assertNumRoutes(cluster, 1, t)
+	select {
+	case <-drained.closed:
+		t.Fatal("client closed while requests are in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(drained.release)
+	select {
+	case <-drained.closed:
+	case <-time.After(time.Second):
+		t.Fatal("client not closed once drained")
+	}
+
+	// Closing the cluster cuts the drain short.
+	require.NoError(t, cluster.update([]serviceEndpoint{second}))
+	remaining := b.clients[first.hostPort()]
+	require.NoError(t, cluster.Close())
+	select {
+	case <-remaining.closed:
+	case <-time.After(time.Second):
+		t.Fatal("client not closed when the cluster closed")
+	}
+}
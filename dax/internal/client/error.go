@@ -22,14 +22,18 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
 	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 const (
@@ -41,6 +45,7 @@ const (
 	ErrCodeInvalidParameter    = "InvalidParameter"
 	ErrCodeResponseTimeout     = "ResponseTimeout"
 	ErrCodeInternalServerError = "InternalServerError"
+	ErrCodeAuthFailure         = "AuthFailure"
 )
 
 type daxError interface {
@@ -100,6 +105,12 @@ func (f *daxRequestFailure) StatusCode() int {
 	return f.statusCode
 }
 
+// ServiceRequestID returns the ID the node assigned to the request, like the errors of the
+// AWS SDK do.
+func (f *daxRequestFailure) ServiceRequestID() string {
+	return f.requestID
+}
+
 func (f *daxRequestFailure) recoverable() bool {
 	return len(f.codes) > 0 && f.codes[0] == 2
 }
@@ -153,12 +164,13 @@ func decodeError(reader *cbor.Reader) (error, error) {
 		return nil, nil
 	}
 
-	codes := make([]int, length)
+	codes := make([]int, 0, cbor.PreallocLength(length))
 	for i := 0; i < length; i++ {
-		codes[i], err = reader.ReadInt()
+		code, err := reader.ReadInt()
 		if err != nil {
 			return nil, err
 		}
+		codes = append(codes, code)
 	}
 
 	msg, err := reader.ReadString()
@@ -225,28 +237,31 @@ func decodeError(reader *cbor.Reader) (error, error) {
 				return nil, &smithy.DeserializationError{Err: fmt.Errorf("error found when parsing CancellationReasons")}
 			}
 			cancellationReasonsLen := arrLen / 3
-			cancellationReasonCodes = make([]*string, cancellationReasonsLen)
-			cancellationReasonMsgs = make([]*string, cancellationReasonsLen)
+			cancellationReasonCodes = make([]*string, 0, cbor.PreallocLength(cancellationReasonsLen))
+			cancellationReasonMsgs = make([]*string, 0, cbor.PreallocLength(cancellationReasonsLen))
 			itemsBuf := bytes.Buffer{}
 			for i := 0; i < cancellationReasonsLen; i++ {
+				var reasonCode, reasonMsg *string
 				if consumed, err := consumeNil(reader); err != nil {
 					return nil, err
 				} else if !consumed {
 					s, err := reader.ReadString()
-					cancellationReasonCodes[i] = aws.String(s)
 					if err != nil {
 						return nil, err
 					}
+					reasonCode = aws.String(s)
 				}
 				if consumed, err := consumeNil(reader); err != nil {
 					return nil, err
 				} else if !consumed {
 					s, err := reader.ReadString()
-					cancellationReasonMsgs[i] = aws.String(s)
 					if err != nil {
 						return nil, err
 					}
+					reasonMsg = aws.String(s)
 				}
+				cancellationReasonCodes = append(cancellationReasonCodes, reasonCode)
+				cancellationReasonMsgs = append(cancellationReasonMsgs, reasonMsg)
 				if consumed, err := consumeNil(reader); err != nil {
 					return nil, err
 				} else if !consumed {
@@ -382,6 +397,22 @@ func convertDaxError(e daxError) error {
 	}
 }
 
+// withRequestID wraps err, converted from e, in an *awshttp.ResponseError carrying the request
+// ID and status code of e, as the AWS SDK wraps the errors returned by DynamoDB. Callers can
+// then find the request ID of DAX and DynamoDB errors alike with errors.As.
+func withRequestID(err error, e daxError) error {
+	if e.RequestID() == "" {
+		return err
+	}
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: e.StatusCode()}},
+			Err:      err,
+		},
+		RequestID: e.RequestID(),
+	}
+}
+
 func decodeTransactionCancellationReasons(ctx context.Context, failure *daxTransactionCanceledFailure,
 	keys []map[string]types.AttributeValue, attrListIdToNames *lru.Lru) ([]types.CancellationReason, error) {
 	inputL := len(keys)
@@ -488,3 +519,115 @@ var ThrottleChecker = retry.ThrottleErrorCode{
 func IsThrottleError(err error) bool {
 	return ThrottleChecker.IsErrorThrottle(err) == aws.TrueTernary
 }
+
+// ErrFirstByteTimeout is wrapped by the error of an attempt which received no response
+// within RequestOptions.FirstByteTimeout.
+var ErrFirstByteTimeout = errors.New("no response received within the first byte timeout")
+
+// firstByteTimeoutError is a net.Error, so that a stalling node is treated like one
+// with network problems. It is also an API error, which translateError keeps as is, so
+// that the retryer can tell reads from writes.
+type firstByteTimeoutError struct {
+	op string
+}
+
+func (e *firstByteTimeoutError) Error() string {
+	return fmt.Sprintf("%s: %v", e.op, ErrFirstByteTimeout)
+}
+
+func (e *firstByteTimeoutError) ErrorCode() string {
+	return ErrCodeResponseTimeout
+}
+
+func (e *firstByteTimeoutError) ErrorMessage() string {
+	return e.Error()
+}
+
+func (e *firstByteTimeoutError) ErrorFault() smithy.ErrorFault {
+	return smithy.FaultServer
+}
+
+func (e *firstByteTimeoutError) Unwrap() error {
+	return ErrFirstByteTimeout
+}
+
+func (e *firstByteTimeoutError) Timeout() bool {
+	return true
+}
+
+func (e *firstByteTimeoutError) Temporary() bool {
+	return true
+}
+
+// AuthError is returned when a connection could not be authenticated for a request,
+// either because the credentials could not be retrieved within the authentication
+// timeout or because the authentication could not be sent to the node. Err is the cause.
+// Rejections of the credentials by the node are reported by the request itself.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AuthError) ErrorCode() string {
+	return ErrCodeAuthFailure
+}
+
+func (e *AuthError) ErrorMessage() string {
+	return e.Error()
+}
+
+func (e *AuthError) ErrorFault() smithy.ErrorFault {
+	return smithy.FaultClient
+}
+
+// retryable reports whether authenticating on another connection may succeed, which is
+// the case for network errors and credential retrievals cut short by the timeout.
+func (e *AuthError) retryable() bool {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(e.Err, &ne)
+}
+
+// NotReadyError is returned for requests made before the client first discovered the
+// nodes of the cluster, telling the bootstrap window apart from a cluster which has no
+// usable nodes left. Err is the error of the last discovery attempt, if any.
+type NotReadyError struct {
+	Err error
+}
+
+func (e *NotReadyError) Error() string {
+	if e.Err == nil {
+		return "cluster nodes not discovered yet"
+	}
+	return fmt.Sprintf("cluster nodes not discovered yet: %v", e.Err)
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return e.Err
+}
+
+// RetryBudgetExceededError is returned when the retries of a request stop because the
+// time spent on it, backoffs included, reached RequestOptions.MaxRetryElapsedTime. Err is
+// the error of the last attempt.
+type RetryBudgetExceededError struct {
+	Err      error
+	Elapsed  time.Duration // time spent from the first attempt until giving up
+	Attempts int           // attempts made, the first one included
+}
+
+func (e *RetryBudgetExceededError) Error() string {
+	return fmt.Sprintf("retry time budget exceeded after %d attempts in %s: %v", e.Attempts, e.Elapsed, e.Err)
+}
+
+func (e *RetryBudgetExceededError) Unwrap() error {
+	return e.Err
+}
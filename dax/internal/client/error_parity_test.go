@@ -0,0 +1,158 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+// errorParityCase is one entry of testdata/error_translation_parity.json, recording the
+// error code the Java and Node.js DAX clients surface for a server code sequence. The
+// Java and Node.js columns are transcribed by hand from the error mappings of those
+// clients; they are not generated from them, so a change there must be copied here.
+// TestErrorTranslationParity_coversTranslatedSequences keeps the set of code sequences
+// in step with convertDaxError.
+type errorParityCase struct {
+	Codes []int  `json:"codes"`
+	Java  string `json:"java"`
+	Node  string `json:"node"`
+}
+
+type errorParityDivergence struct {
+	codes      []int
+	language   string
+	expected   string
+	translated string
+}
+
+func (d errorParityDivergence) String() string {
+	return fmt.Sprintf("%v: %s client returns %s, Go client returns %s", d.codes, d.language, d.expected, d.translated)
+}
+
+func loadErrorParityCases(t *testing.T) []errorParityCase {
+	b, err := os.ReadFile("testdata/error_translation_parity.json")
+	if err != nil {
+		t.Fatalf("unable to read golden data: %v", err)
+	}
+	var cases []errorParityCase
+	if err := json.Unmarshal(b, &cases); err != nil {
+		t.Fatalf("unable to parse golden data: %v", err)
+	}
+	return cases
+}
+
+// translatedErrorCode returns the API error code convertDaxError produces for codes.
+func translatedErrorCode(codes []int) string {
+	err := convertDaxError(newDaxRequestFailure(codes, "", "parity", "", 400, smithy.FaultClient))
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// checkErrorParity compares the Go translation of every golden code sequence with the
+// error codes of the other DAX clients.
+func checkErrorParity(cases []errorParityCase) []errorParityDivergence {
+	var out []errorParityDivergence
+	for _, c := range cases {
+		got := translatedErrorCode(c.Codes)
+		for lang, want := range map[string]string{"java": c.Java, "node": c.Node} {
+			if want != "" && want != got {
+				out = append(out, errorParityDivergence{codes: c.Codes, language: lang, expected: want, translated: got})
+			}
+		}
+	}
+	return out
+}
+
+// errorParityPrefixes are the code sequence prefixes convertDaxError dispatches on; the
+// last code of a sequence selects the error type.
+var errorParityPrefixes = [][]int{
+	{4, 23},
+	{4, 37, 38},
+	{4, 37, 38, 39},
+}
+
+// translatedErrorSequences returns every code sequence under errorParityPrefixes which
+// convertDaxError translates to something other than the unknown error.
+func translatedErrorSequences() [][]int {
+	var out [][]int
+	for _, prefix := range errorParityPrefixes {
+		for last := 0; last < 128; last++ {
+			codes := append(append([]int{}, prefix...), last)
+			if translatedErrorCode(codes) != ErrCodeUnknown {
+				out = append(out, codes)
+			}
+		}
+	}
+	return out
+}
+
+func TestErrorTranslationParity(t *testing.T) {
+	cases := loadErrorParityCases(t)
+	if len(cases) == 0 {
+		t.Fatal("golden data is empty")
+	}
+
+	if testing.Verbose() {
+		var sb strings.Builder
+		for _, c := range cases {
+			fmt.Fprintf(&sb, "%-20v go=%-42s java=%-42s node=%s\n", c.Codes, translatedErrorCode(c.Codes), c.Java, c.Node)
+		}
+		t.Logf("error translation table:\n%s", sb.String())
+	}
+
+	for _, d := range checkErrorParity(cases) {
+		t.Errorf("error translation diverges: %s", d)
+	}
+}
+
+func TestErrorTranslationParity_detectsDivergence(t *testing.T) {
+	divergences := checkErrorParity([]errorParityCase{
+		{Codes: []int{4, 23, 24}, Java: "ResourceNotFoundException", Node: "SomethingElse"},
+	})
+	if len(divergences) != 1 || divergences[0].language != "node" {
+		t.Errorf("expected a single node divergence, got %v", divergences)
+	}
+}
+
+func TestErrorTranslationParity_coversTranslatedSequences(t *testing.T) {
+	golden := map[string]bool{}
+	for _, c := range loadErrorParityCases(t) {
+		golden[fmt.Sprint(c.Codes)] = true
+	}
+	translated := map[string]bool{}
+	for _, codes := range translatedErrorSequences() {
+		key := fmt.Sprint(codes)
+		translated[key] = true
+		if !golden[key] {
+			t.Errorf("%v is translated to %s but has no golden entry", codes, translatedErrorCode(codes))
+		}
+	}
+	for key := range golden {
+		if !translated[key] {
+			t.Errorf("golden entry %s is not translated by convertDaxError", key)
+		}
+	}
+}
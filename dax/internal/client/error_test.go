@@ -29,9 +29,11 @@ import (
 	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecodeError(t *testing.T) {
@@ -444,6 +446,29 @@ func TestDaxRequestFailureImplementsDaxError(t *testing.T) {
 	})
 }
 
+func TestWithRequestID(t *testing.T) {
+	failure := newDaxRequestFailure([]int{4, 37, 38, 39, 43}, "ConditionalCheckFailedException", "failed", "request-1", 400, smithy.FaultServer)
+	err := withRequestID(convertDaxError(failure), failure)
+
+	var re *awshttp.ResponseError
+	require.ErrorAs(t, err, &re)
+	assert.Equal(t, "request-1", re.ServiceRequestID())
+	assert.Equal(t, 400, re.HTTPStatusCode())
+	var ccf *types.ConditionalCheckFailedException
+	assert.ErrorAs(t, err, &ccf)
+	var ae smithy.APIError
+	require.ErrorAs(t, err, &ae)
+	assert.Equal(t, "ConditionalCheckFailedException", ae.ErrorCode())
+
+	var rid interface{ ServiceRequestID() string }
+	require.ErrorAs(t, failure, &rid, "unconverted failures expose the request ID too")
+	assert.Equal(t, "request-1", rid.ServiceRequestID())
+
+	noID := newDaxRequestFailure([]int{4, 37, 38, 39, 43}, "ConditionalCheckFailedException", "failed", "", 400, smithy.FaultServer)
+	converted := convertDaxError(noID)
+	assert.Same(t, converted, withRequestID(converted, noID))
+}
+
 func TestIsThrottleError(t *testing.T) {
 	throttleChecker := retry.ThrottleErrorCode{
 		Codes: retry.DefaultThrottleErrorCodes,
@@ -0,0 +1,161 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// EventType identifies a change of state of a client.
+type EventType string
+
+const (
+	// EventTopologyChanged is emitted when discovery found nodes joining or leaving the
+	// cluster, listed in Event.Added and Event.Removed.
+	EventTopologyChanged EventType = "TopologyChanged"
+	// EventRouteRemoved and EventRouteAdded are emitted when the route manager stops, and
+	// resumes, routing requests to the node at Event.Endpoint.
+	EventRouteRemoved EventType = "RouteRemoved"
+	EventRouteAdded   EventType = "RouteAdded"
+	// EventFailOpenEntered is emitted when too many nodes would be excluded and the route
+	// manager routes to all of them instead. EventFailOpenExited is emitted when it excludes
+	// unhealthy nodes again, once it resumes after repeated fail-opens or removes a route.
+	EventFailOpenEntered EventType = "FailOpenEntered"
+	EventFailOpenExited  EventType = "FailOpenExited"
+	// EventPoolExhausted is emitted when a request waits for a connection to Event.Endpoint
+	// because none is idle and the limit of concurrent connection attempts, or of open
+	// connections, is reached. It is emitted again once a connection returned to the pool.
+	EventPoolExhausted EventType = "PoolExhausted"
+	// EventCredentialsRefreshed is emitted when the credentials provider returned new
+	// credentials.
+	EventCredentialsRefreshed EventType = "CredentialsRefreshed"
+)
+
+// Event describes a change of state of a client.
+type Event struct {
+	Type EventType
+	Time time.Time
+	// Endpoint is the host:port of the node the event relates to, if any.
+	Endpoint string
+	// Added and Removed list the host:port of the nodes of an EventTopologyChanged.
+	Added   []string
+	Removed []string
+}
+
+// eventBus delivers events to subscribers. Delivery never blocks the client: events
+// are dropped for subscribers whose buffer is full. A nil bus discards all events.
+type eventBus struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{} // protected by mu
+	closed bool                    // protected by mu
+	creds  string                  // identifies the last credentials seen, protected by mu
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[chan Event]struct{})}
+}
+
+// subscribe returns a channel receiving events, buffering up to buffer of them, and a
+// function cancelling the subscription. The channel is closed when the subscription is
+// cancelled or the client closed.
+func (b *eventBus) subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *eventBus) publish(e Event) {
+	if b == nil {
+		return
+	}
+	e.Time = time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// credentialsRetrieved emits EventCredentialsRefreshed when creds differ from the
+// credentials previously retrieved.
+func (b *eventBus) credentialsRetrieved(creds aws.Credentials) {
+	if b == nil {
+		return
+	}
+	id := creds.AccessKeyID + "/" + creds.Expires.String()
+	b.mu.Lock()
+	prev := b.creds
+	b.creds = id
+	b.mu.Unlock()
+	if prev != "" && prev != id {
+		b.publish(Event{Type: EventCredentialsRefreshed})
+	}
+}
+
+// topologyChange returns the EventTopologyChanged from the nodes in before to those in
+// after, or nil when they are the same.
+func topologyChange(before, after map[hostPort]clientAndConfig) *Event {
+	e := Event{Type: EventTopologyChanged}
+	for hp := range after {
+		if _, ok := before[hp]; !ok {
+			e.Added = append(e.Added, hp.String())
+		}
+	}
+	for hp := range before {
+		if _, ok := after[hp]; !ok {
+			e.Removed = append(e.Removed, hp.String())
+		}
+	}
+	if len(e.Added) == 0 && len(e.Removed) == 0 {
+		return nil
+	}
+	sort.Strings(e.Added)
+	sort.Strings(e.Removed)
+	return &e
+}
+
+func (b *eventBus) close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func drainEvents(ch <-chan Event) []EventType {
+	var types []EventType
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return types
+			}
+			types = append(types, e.Type)
+		default:
+			return types
+		}
+	}
+}
+
+func TestEventBus(t *testing.T) {
+	b := newEventBus()
+	ch, cancel := b.subscribe(1)
+	full, cancelFull := b.subscribe(0)
+	defer cancelFull()
+
+	b.publish(Event{Type: EventRouteAdded, Endpoint: "127.0.0.1:8111"})
+	b.publish(Event{Type: EventRouteRemoved})
+	e := <-ch
+	assert.Equal(t, EventRouteAdded, e.Type)
+	assert.Equal(t, "127.0.0.1:8111", e.Endpoint)
+	assert.False(t, e.Time.IsZero())
+	assert.Empty(t, drainEvents(ch), "events are dropped while the buffer is full")
+	assert.Empty(t, drainEvents(full))
+
+	cancel()
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok, "cancel closes the channel")
+
+	b.close()
+	_, ok = <-full
+	assert.False(t, ok, "close closes the channels")
+	closed, _ := b.subscribe(1)
+	_, ok = <-closed
+	assert.False(t, ok)
+
+	var nilBus *eventBus
+	nilBus.publish(Event{Type: EventRouteAdded})
+}
+
+func TestEventBus_credentialsRetrieved(t *testing.T) {
+	b := newEventBus()
+	ch, cancel := b.subscribe(4)
+	defer cancel()
+
+	creds := aws.Credentials{AccessKeyID: "AKID1", Expires: time.Unix(100, 0)}
+	b.credentialsRetrieved(creds)
+	b.credentialsRetrieved(creds)
+	creds.AccessKeyID = "AKID2"
+	b.credentialsRetrieved(creds)
+	assert.Equal(t, []EventType{EventCredentialsRefreshed}, drainEvents(ch))
+}
+
+func TestRouteManager_events(t *testing.T) {
+	daxAPI1, daxAPI2, daxAPI3 := mockDaxAPI{1}, mockDaxAPI{2}, mockDaxAPI{3}
+	clients := map[hostPort]clientAndConfig{
+		{"dummy.1", 9111}: {client: daxAPI1},
+		{"dummy.2", 9111}: {client: daxAPI2},
+		{"dummy.3", 9111}: {client: daxAPI3},
+	}
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	rm := newRouteManager(true, time.Second, nil, utils.LogOff, om)
+	defer rm.close()
+	rm.events = newEventBus()
+	ch, cancel := rm.events.subscribe(8)
+	defer cancel()
+
+	rm.setRoutes([]DaxAPI{daxAPI1, daxAPI2, daxAPI3})
+	rm.removeRoute("dummy.1:9111", daxAPI1, clients)
+	rm.removeRoute("dummy.2:9111", daxAPI2, clients)
+	rm.removeRoute("dummy.2:9111", daxAPI2, clients)
+	rm.addRoute("dummy.2:9111", daxAPI2)
+	assert.Equal(t, []EventType{
+		EventRouteRemoved, EventFailOpenEntered, EventRouteRemoved, EventFailOpenExited, EventRouteAdded,
+	}, drainEvents(ch))
+}
+
+func TestCluster_topologyEvents(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	ch, _ := cluster.events.subscribe(4)
+
+	node1 := serviceEndpoint{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111}
+	node2 := serviceEndpoint{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111}
+	assert.NoError(t, cluster.update([]serviceEndpoint{node1}))
+	assert.NoError(t, cluster.update([]serviceEndpoint{node1}))
+	assert.NoError(t, cluster.update([]serviceEndpoint{node2}))
+
+	first, second := <-ch, <-ch
+	assert.Equal(t, []string{"127.0.0.1:8111"}, first.Added)
+	assert.Empty(t, first.Removed)
+	assert.Equal(t, EventTopologyChanged, second.Type)
+	assert.Equal(t, []string{"127.0.0.2:8111"}, second.Added)
+	assert.Equal(t, []string{"127.0.0.1:8111"}, second.Removed)
+
+	cluster.Close()
+	assert.Empty(t, drainEvents(ch))
+	_, ok := <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,47 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import "time"
+
+// MaxForcedFailOpen bounds the duration of ClusterDaxClient.ForceFailOpen.
+const MaxForcedFailOpen = time.Hour
+
+// ForceFailOpen makes the route manager route requests to every node of the cluster for
+// d, whatever the health checks report, as it does on its own when too many nodes would
+// be excluded. It is meant for incidents where the health logic itself is suspected of
+// removing good nodes. d is capped by MaxForcedFailOpen and a zero d ends a forced
+// fail-open early. Without Config.RouteManagerEnabled every node is routed to anyway.
+func (cc *ClusterDaxClient) ForceFailOpen(d time.Duration) error {
+	if d < 0 {
+		return NewCustomInvalidParamError("ConfigValidation", "forced fail-open duration cannot be negative")
+	}
+	if d > MaxForcedFailOpen {
+		d = MaxForcedFailOpen
+	}
+	cc.cluster.forceFailOpen(d)
+	return nil
+}
+
+func (c *cluster) forceFailOpen(d time.Duration) {
+	var until time.Time
+	if d > 0 {
+		until = clockOrSystem(c.config.Clock).Now().Add(d)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.routeManager.forceFailOpen(until, c.active)
+}
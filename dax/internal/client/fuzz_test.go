@@ -0,0 +1,191 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The fuzz targets below check that no server response can make the client panic or
+// exhaust memory while decoding it. Crashers found by the fuzzer are kept in
+// testdata/fuzz and replayed by go test.
+
+func FuzzDecodeError(f *testing.F) {
+	seeds := []func(w *cbor.Writer){
+		func(w *cbor.Writer) {
+			w.WriteArrayHeader(0)
+		},
+		func(w *cbor.Writer) {
+			w.WriteArrayHeader(3)
+			w.WriteInt(4)
+			w.WriteInt(37)
+			w.WriteInt(54)
+			w.WriteString("conditional check failed")
+			w.WriteNull()
+		},
+		func(w *cbor.Writer) {
+			w.WriteArrayHeader(3)
+			w.WriteInt(4)
+			w.WriteInt(37)
+			w.WriteInt(58)
+			w.WriteString("transaction canceled")
+			w.WriteArrayHeader(4)
+			w.WriteString("request-id")
+			w.WriteString("TransactionCanceledException")
+			w.WriteInt(400)
+			w.WriteArrayHeader(6)
+			w.WriteString("ConditionalCheckFailed")
+			w.WriteString("failed")
+			w.WriteNull()
+			w.WriteNull()
+			w.WriteNull()
+			w.WriteNull()
+		},
+	}
+	for _, seed := range seeds {
+		var buf bytes.Buffer
+		w := cbor.NewWriter(&buf)
+		seed(w)
+		w.Flush()
+		f.Add(buf.Bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		daxErr, err := decodeError(cbor.NewReader(bytes.NewReader(data)))
+		if err != nil || daxErr == nil {
+			return
+		}
+		if failure, ok := daxErr.(*daxTransactionCanceledFailure); ok {
+			_, _, attrListIdToNames := goldenCaches()
+			keys := make([]map[string]types.AttributeValue, len(failure.cancellationReasonCodes))
+			decodeTransactionCancellationReasons(context.Background(), failure, keys, attrListIdToNames)
+		}
+	})
+}
+
+// fuzzOutputDecoders decodes the body of a response for each operation, in the order
+// the op argument of FuzzDecodeOutput selects them.
+var fuzzOutputDecoders = []struct {
+	name   string
+	decode func(ctx context.Context, r *cbor.Reader) error
+}{
+	{"endpoints", func(ctx context.Context, r *cbor.Reader) error {
+		_, err := decodeEndpointsOutput(r)
+		return err
+	}},
+	{"define_attribute_list_id", func(ctx context.Context, r *cbor.Reader) error {
+		_, err := decodeDefineAttributeListIdOutput(r)
+		return err
+	}},
+	{"define_attribute_list", func(ctx context.Context, r *cbor.Reader) error {
+		_, err := decodeDefineAttributeListOutput(r)
+		return err
+	}},
+	{"define_key_schema", func(ctx context.Context, r *cbor.Reader) error {
+		_, err := decodeDefineKeySchemaOutput(r)
+		return err
+	}},
+	{"get_item", func(ctx context.Context, r *cbor.Reader) error {
+		_, _, attrListIdToNames := goldenCaches()
+		_, err := decodeGetItemOutput(ctx, r, &dynamodb.GetItemInput{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}, attrListIdToNames, nil)
+		return err
+	}},
+	{"put_item", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodePutItemOutput(ctx, r, &dynamodb.PutItemInput{TableName: aws.String(goldenTable), Item: goldenItem("p", "1", "a", "x")}, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"delete_item", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodeDeleteItemOutput(ctx, r, &dynamodb.DeleteItemInput{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"update_item", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodeUpdateItemOutput(ctx, r, &dynamodb.UpdateItemInput{TableName: aws.String(goldenTable), Key: goldenKey("p", "1"), ReturnValues: types.ReturnValueUpdatedNew}, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"scan", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodeScanOutput(ctx, r, &dynamodb.ScanInput{TableName: aws.String(goldenTable), IndexName: aws.String("i")}, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"query", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodeQueryOutput(ctx, r, &dynamodb.QueryInput{TableName: aws.String(goldenTable)}, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"batch_get_item", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		input := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+			goldenTable: {Keys: []map[string]types.AttributeValue{goldenKey("p", "1")}},
+		}}
+		_, err := decodeBatchGetItemOutput(ctx, r, input, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"batch_write_item", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		_, err := decodeBatchWriteItemOutput(ctx, r, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"transact_write_items", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		input := &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}},
+		}}
+		_, err := decodeTransactWriteItemsOutput(ctx, r, input, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+	{"transact_get_items", func(ctx context.Context, r *cbor.Reader) error {
+		keySchema, _, attrListIdToNames := goldenCaches()
+		input := &dynamodb.TransactGetItemsInput{TransactItems: []types.TransactGetItem{
+			{Get: &types.Get{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}},
+			{Get: &types.Get{TableName: aws.String(goldenTable), Key: goldenKey("p", "2"), ProjectionExpression: aws.String("a")}},
+		}}
+		_, err := decodeTransactGetItemsOutput(ctx, r, input, keySchema, attrListIdToNames, nil)
+		return err
+	}},
+}
+
+// FuzzDecodeOutput decodes data as a response of the operation op selects, seeded with
+// the golden response frames.
+func FuzzDecodeOutput(f *testing.F) {
+	for i, d := range fuzzOutputDecoders {
+		frame, err := os.ReadFile(filepath.Join("testdata", "golden", d.name+"_response.cbor"))
+		if err != nil {
+			frame = []byte{byte(cbor.Array), byte(cbor.Nil)}
+		}
+		f.Add(uint8(i), frame)
+	}
+
+	f.Fuzz(func(t *testing.T, op uint8, data []byte) {
+		d := fuzzOutputDecoders[int(op)%len(fuzzOutputDecoders)]
+		r := cbor.NewReader(bytes.NewReader(data))
+		if daxErr, err := decodeError(r); err != nil || daxErr != nil {
+			return
+		}
+		d.decode(context.Background(), r)
+	})
+}
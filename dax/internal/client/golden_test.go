@@ -0,0 +1,585 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The golden frames under testdata/golden are the wire encoding of each operation's
+// request and response, checked in so that changes to the encoders and decoders can be
+// validated byte-for-byte. Run the tests with -update to rewrite them after an
+// intentional change of the protocol.
+var updateGolden = flag.Bool("update", false, "rewrite the golden frames in testdata/golden")
+
+const goldenTable = "t"
+
+var goldenKeySchema = []types.AttributeDefinition{
+	{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+	{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+}
+
+// goldenAttrLists are the attribute lists the frames refer to, by attribute list id.
+var goldenAttrLists = map[int64][]string{
+	1: {},
+	2: {"a"},
+	3: {"a", "b"},
+}
+
+// goldenCaches returns the key schema and attribute list caches of a client which has
+// learned goldenKeySchema and goldenAttrLists, without a server to define them.
+func goldenCaches() (keySchema, attrNamesListToId, attrListIdToNames *lru.Lru) {
+	keySchema = &lru.Lru{
+		MaxEntries: keySchemaLruCacheSize,
+		LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+			if key.(string) != goldenTable {
+				return nil, fmt.Errorf("unknown table %v", key)
+			}
+			return goldenKeySchema, nil
+		},
+	}
+	attrNamesListToId = &lru.Lru{
+		MaxEntries: attributeListLruCacheSize,
+		LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+			names := strings.Join(key.([]string), ",")
+			for id, l := range goldenAttrLists {
+				if strings.Join(l, ",") == names {
+					return id, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown attribute list %v", key)
+		},
+		KeyMarshaller: func(key lru.Key) lru.Key {
+			return strings.Join(key.([]string), ",")
+		},
+	}
+	attrListIdToNames = &lru.Lru{
+		MaxEntries: attributeListLruCacheSize,
+		LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+			l, ok := goldenAttrLists[key.(int64)]
+			if !ok {
+				return nil, fmt.Errorf("unknown attribute list id %v", key)
+			}
+			return l, nil
+		},
+	}
+	return
+}
+
+func goldenKey(pk, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: pk},
+		"sk": &types.AttributeValueMemberN{Value: sk},
+	}
+}
+
+func goldenItem(pk, sk string, attrs ...string) map[string]types.AttributeValue {
+	item := goldenKey(pk, sk)
+	for i := 0; i < len(attrs); i += 2 {
+		item[attrs[i]] = &types.AttributeValueMemberS{Value: attrs[i+1]}
+	}
+	return item
+}
+
+// encodeFrame returns the bytes encode writes.
+func encodeFrame(t *testing.T, encode func(*cbor.Writer) error) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := cbor.NewWriter(&buf)
+	require.NoError(t, encode(w))
+	require.NoError(t, w.Flush())
+	return buf.Bytes()
+}
+
+// checkGolden compares frame with the golden frame name, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, frame []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".cbor")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, frame, 0644))
+		return frame
+	}
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden frame, run the test with -update to create it")
+	if !bytes.Equal(golden, frame) {
+		t.Fatalf("frame differs from %s\ngolden:\n%sgot:\n%s", path, hex.Dump(golden), hex.Dump(frame))
+	}
+	return golden
+}
+
+func TestGoldenRequests(t *testing.T) {
+	ctx := context.Background()
+	keySchema, attrNamesListToId, _ := goldenCaches()
+
+	cases := []struct {
+		name   string
+		encode func(*cbor.Writer) error
+	}{
+		{"endpoints_request", func(w *cbor.Writer) error {
+			return encodeEndpointsInput(w)
+		}},
+		{"authorize_connection_request", func(w *cbor.Writer) error {
+			return encodeAuthInput("AKID", "token", "string-to-sign", "signature", "agent", w)
+		}},
+		{"define_attribute_list_id_request", func(w *cbor.Writer) error {
+			return encodeDefineAttributeListIdInput([]string{"a", "b"}, w)
+		}},
+		{"define_attribute_list_request", func(w *cbor.Writer) error {
+			return encodeDefineAttributeListInput(3, w)
+		}},
+		{"define_key_schema_request", func(w *cbor.Writer) error {
+			return encodeDefineKeySchemaInput(goldenTable, w)
+		}},
+		{"get_item_request", func(w *cbor.Writer) error {
+			return encodeGetItemInput(ctx, &dynamodb.GetItemInput{
+				TableName:                aws.String(goldenTable),
+				Key:                      goldenKey("p", "1"),
+				ConsistentRead:           aws.Bool(true),
+				ProjectionExpression:     aws.String("#a"),
+				ExpressionAttributeNames: map[string]string{"#a": "a"},
+				ReturnConsumedCapacity:   types.ReturnConsumedCapacityTotal,
+			}, keySchema, w)
+		}},
+		{"put_item_request", func(w *cbor.Writer) error {
+			return encodePutItemInput(ctx, &dynamodb.PutItemInput{
+				TableName:                   aws.String(goldenTable),
+				Item:                        goldenItem("p", "1", "b", "y", "a", "x"),
+				ConditionExpression:         aws.String("attribute_not_exists(pk)"),
+				ReturnValues:                types.ReturnValueAllOld,
+				ReturnItemCollectionMetrics: types.ReturnItemCollectionMetricsSize,
+			}, keySchema, attrNamesListToId, w)
+		}},
+		{"update_item_request", func(w *cbor.Writer) error {
+			return encodeUpdateItemInput(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(goldenTable),
+				Key:                       goldenKey("p", "1"),
+				UpdateExpression:          aws.String("SET a = :v"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}},
+				ReturnValues:              types.ReturnValueUpdatedNew,
+			}, keySchema, w)
+		}},
+		{"delete_item_request", func(w *cbor.Writer) error {
+			return encodeDeleteItemInput(ctx, &dynamodb.DeleteItemInput{
+				TableName:    aws.String(goldenTable),
+				Key:          goldenKey("p", "1"),
+				ReturnValues: types.ReturnValueAllOld,
+			}, keySchema, w)
+		}},
+		{"scan_request", func(w *cbor.Writer) error {
+			return encodeScanInput(ctx, &dynamodb.ScanInput{
+				TableName:                 aws.String(goldenTable),
+				FilterExpression:          aws.String("a = :v"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}},
+				Limit:                     aws.Int32(10),
+				Segment:                   aws.Int32(1),
+				TotalSegments:             aws.Int32(4),
+				ExclusiveStartKey:         goldenKey("p", "1"),
+			}, keySchema, w)
+		}},
+		{"query_request", func(w *cbor.Writer) error {
+			return encodeQueryInput(ctx, &dynamodb.QueryInput{
+				TableName:                 aws.String(goldenTable),
+				KeyConditionExpression:    aws.String("pk = :v"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "p"}},
+				ScanIndexForward:          aws.Bool(false),
+				Limit:                     aws.Int32(5),
+			}, keySchema, w)
+		}},
+		{"batch_get_item_request", func(w *cbor.Writer) error {
+			return encodeBatchGetItemInput(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{
+					goldenTable: {Keys: []map[string]types.AttributeValue{goldenKey("p", "1"), goldenKey("p", "2")}, ConsistentRead: aws.Bool(true)},
+				},
+			}, keySchema, w)
+		}},
+		{"batch_write_item_request", func(w *cbor.Writer) error {
+			return encodeBatchWriteItemInput(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{
+					goldenTable: {
+						{PutRequest: &types.PutRequest{Item: goldenItem("p", "1", "a", "x")}},
+						{DeleteRequest: &types.DeleteRequest{Key: goldenKey("p", "2")}},
+					},
+				},
+			}, keySchema, attrNamesListToId, w)
+		}},
+		{"transact_get_items_request", func(w *cbor.Writer) error {
+			input := &dynamodb.TransactGetItemsInput{
+				TransactItems: []types.TransactGetItem{
+					{Get: &types.Get{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}},
+					{Get: &types.Get{TableName: aws.String(goldenTable), Key: goldenKey("p", "2")}},
+				},
+			}
+			return encodeTransactGetItemsInput(ctx, input, keySchema, w, make([]map[string]types.AttributeValue, len(input.TransactItems)))
+		}},
+		{"transact_write_items_request", func(w *cbor.Writer) error {
+			input := &dynamodb.TransactWriteItemsInput{
+				TransactItems: []types.TransactWriteItem{
+					{Put: &types.Put{TableName: aws.String(goldenTable), Item: goldenItem("p", "1", "a", "x")}},
+					{Delete: &types.Delete{TableName: aws.String(goldenTable), Key: goldenKey("p", "2"), ConditionExpression: aws.String("attribute_exists(a)")}},
+				},
+				ClientRequestToken: aws.String("token"),
+			}
+			return encodeTransactWriteItemsInput(ctx, input, keySchema, attrNamesListToId, w, make([]map[string]types.AttributeValue, len(input.TransactItems)))
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checkGolden(t, c.name, encodeFrame(t, c.encode))
+		})
+	}
+}
+
+// writeGoldenNonKeyAttributes writes the attributes of item outside of the key schema,
+// the way the server returns them.
+func writeGoldenNonKeyAttributes(ctx context.Context, item map[string]types.AttributeValue, attrNamesListToId *lru.Lru, w *cbor.Writer) error {
+	return encodeNonKeyAttributes(ctx, item, goldenKeySchema, attrNamesListToId, w)
+}
+
+func writeGoldenConsumedCapacity(units float64, w *cbor.Writer) error {
+	var buf bytes.Buffer
+	cw := cbor.NewWriter(&buf)
+	defer cw.Close()
+	if err := cw.WriteString(goldenTable); err != nil {
+		return err
+	}
+	if err := cw.WriteFloat64(units); err != nil {
+		return err
+	}
+	// no table, global or local secondary index capacity
+	for i := 0; i < 3; i++ {
+		if err := cw.WriteNull(); err != nil {
+			return err
+		}
+	}
+	if err := cw.Flush(); err != nil {
+		return err
+	}
+	return w.WriteBytes(buf.Bytes())
+}
+
+func TestGoldenResponses(t *testing.T) {
+	ctx := context.Background()
+	keySchema, attrNamesListToId, attrListIdToNames := goldenCaches()
+	keyBytes := func(pk, sk string) []byte {
+		b, err := cbor.GetEncodedItemKey(goldenKey(pk, sk), goldenKeySchema)
+		require.NoError(t, err)
+		return b
+	}
+	consumed := &types.ConsumedCapacity{TableName: aws.String(goldenTable), CapacityUnits: aws.Float64(0.5)}
+
+	getInput := &dynamodb.GetItemInput{TableName: aws.String(goldenTable), Key: goldenKey("p", "1")}
+	putInput := &dynamodb.PutItemInput{TableName: aws.String(goldenTable), Item: goldenItem("p", "1", "a", "y"), ReturnValues: types.ReturnValueAllOld}
+	queryInput := &dynamodb.QueryInput{TableName: aws.String(goldenTable)}
+	countInput := &dynamodb.QueryInput{TableName: aws.String(goldenTable), Select: types.SelectCount}
+	scanInput := &dynamodb.ScanInput{TableName: aws.String(goldenTable)}
+	batchGetInput := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		goldenTable: {Keys: []map[string]types.AttributeValue{goldenKey("p", "1"), goldenKey("p", "2")}},
+	}}
+
+	cases := []struct {
+		name   string
+		encode func(*cbor.Writer) error
+		decode func(*cbor.Reader) (interface{}, error)
+		want   interface{}
+	}{
+		{
+			name: "endpoints_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteArrayHeader(1)
+				w.WriteMapHeader(7)
+				w.WriteInt(keyNodeId)
+				w.WriteInt64(7)
+				w.WriteInt(keyHostname)
+				w.WriteString("node.example.com")
+				w.WriteInt(keyAddress)
+				w.WriteBytes([]byte{10, 0, 0, 1})
+				w.WriteInt(keyPort)
+				w.WriteInt(8111)
+				w.WriteInt(keyRole)
+				w.WriteInt(roleLeader)
+				w.WriteInt(keyAvailablityZone)
+				w.WriteString("us-west-2a")
+				w.WriteInt(keyLeaderSessionId)
+				return w.WriteInt64(42)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) { return decodeEndpointsOutput(r) },
+			want: []serviceEndpoint{{
+				nodeId: 7, hostname: "node.example.com", address: []byte{10, 0, 0, 1}, port: 8111,
+				role: roleLeader, availabilityZone: "us-west-2a", leaderSessionId: 42,
+			}},
+		},
+		{
+			name: "define_attribute_list_id_response",
+			encode: func(w *cbor.Writer) error {
+				return w.WriteInt64(3)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) { return decodeDefineAttributeListIdOutput(r) },
+			want:   int64(3),
+		},
+		{
+			name: "define_attribute_list_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteArrayHeader(2)
+				w.WriteString("a")
+				return w.WriteString("b")
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) { return decodeDefineAttributeListOutput(r) },
+			want:   []string{"a", "b"},
+		},
+		{
+			name: "define_key_schema_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(2)
+				w.WriteString("pk")
+				w.WriteString("S")
+				w.WriteString("sk")
+				return w.WriteString("N")
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) { return decodeDefineKeySchemaOutput(r) },
+			want:   goldenKeySchema,
+		},
+		{
+			name: "get_item_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(2)
+				w.WriteInt(responseParamItem)
+				if err := writeGoldenNonKeyAttributes(ctx, goldenItem("p", "1", "a", "x", "b", "y"), attrNamesListToId, w); err != nil {
+					return err
+				}
+				w.WriteInt(responseParamConsumedCapacity)
+				return writeGoldenConsumedCapacity(0.5, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeGetItemOutput(ctx, r, getInput, attrListIdToNames, nil)
+			},
+			want: &dynamodb.GetItemOutput{Item: goldenItem("p", "1", "a", "x", "b", "y"), ConsumedCapacity: consumed},
+		},
+		{
+			name: "put_item_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(1)
+				w.WriteInt(responseParamAttributes)
+				return writeGoldenNonKeyAttributes(ctx, goldenItem("p", "1", "a", "x"), attrNamesListToId, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodePutItemOutput(ctx, r, putInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.PutItemOutput{Attributes: goldenItem("p", "1", "a", "x")},
+		},
+		{
+			name: "query_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(4)
+				w.WriteInt(responseParamItems)
+				w.WriteArrayHeader(2)
+				for _, item := range []map[string]types.AttributeValue{goldenItem("p", "1", "a", "x"), goldenItem("p", "2")} {
+					w.WriteArrayHeader(2)
+					if err := cbor.EncodeItemKey(item, goldenKeySchema, w); err != nil {
+						return err
+					}
+					if err := writeGoldenNonKeyAttributes(ctx, item, attrNamesListToId, w); err != nil {
+						return err
+					}
+				}
+				w.WriteInt(responseParamCount)
+				w.WriteInt(2)
+				w.WriteInt(responseParamScannedCount)
+				w.WriteInt(3)
+				w.WriteInt(responseParamLastEvaluatedKey)
+				return w.WriteBytes(keyBytes("p", "2"))
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeQueryOutput(ctx, r, queryInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.QueryOutput{
+				Items:            []map[string]types.AttributeValue{goldenItem("p", "1", "a", "x"), goldenItem("p", "2")},
+				Count:            2,
+				ScannedCount:     3,
+				LastEvaluatedKey: goldenKey("p", "2"),
+			},
+		},
+		{
+			name: "query_response_empty",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(3)
+				w.WriteInt(responseParamItems)
+				w.WriteArrayHeader(0)
+				w.WriteInt(responseParamCount)
+				w.WriteInt(0)
+				w.WriteInt(responseParamScannedCount)
+				return w.WriteInt(5)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeQueryOutput(ctx, r, queryInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{}, ScannedCount: 5},
+		},
+		{
+			name: "query_response_count",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(4)
+				w.WriteInt(responseParamCount)
+				w.WriteInt(7)
+				w.WriteInt(responseParamScannedCount)
+				w.WriteInt(9)
+				w.WriteInt(responseParamLastEvaluatedKey)
+				w.WriteBytes(keyBytes("p", "9"))
+				w.WriteInt(responseParamConsumedCapacity)
+				return writeGoldenConsumedCapacity(0.5, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeQueryOutput(ctx, r, countInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.QueryOutput{Count: 7, ScannedCount: 9, LastEvaluatedKey: goldenKey("p", "9"), ConsumedCapacity: consumed},
+		},
+		{
+			name: "query_response_without_counts",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(1)
+				w.WriteInt(responseParamItems)
+				w.WriteArrayHeader(1)
+				item := goldenItem("p", "1")
+				w.WriteArrayHeader(2)
+				if err := cbor.EncodeItemKey(item, goldenKeySchema, w); err != nil {
+					return err
+				}
+				return writeGoldenNonKeyAttributes(ctx, item, attrNamesListToId, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeQueryOutput(ctx, r, queryInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{goldenItem("p", "1")}, Count: 1, ScannedCount: 1},
+		},
+		{
+			name: "scan_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(4)
+				w.WriteInt(responseParamItems)
+				w.WriteArrayHeader(0)
+				w.WriteInt(responseParamCount)
+				w.WriteInt(0)
+				w.WriteInt(responseParamScannedCount)
+				w.WriteInt(0)
+				w.WriteInt(responseParamConsumedCapacity)
+				return writeGoldenConsumedCapacity(0.5, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeScanOutput(ctx, r, scanInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{}, ConsumedCapacity: consumed},
+		},
+		{
+			name: "scan_response_nil",
+			encode: func(w *cbor.Writer) error {
+				return w.WriteNull()
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeScanOutput(ctx, r, scanInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.ScanOutput{Items: []map[string]types.AttributeValue{}},
+		},
+		{
+			name: "batch_get_item_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteArrayHeader(2)
+				w.WriteMapHeader(1)
+				w.WriteString(goldenTable)
+				w.WriteArrayHeader(2)
+				w.WriteBytes(keyBytes("p", "1"))
+				if err := writeGoldenNonKeyAttributes(ctx, goldenItem("p", "1", "a", "x"), attrNamesListToId, w); err != nil {
+					return err
+				}
+				w.WriteMapHeader(1)
+				w.WriteString(goldenTable)
+				w.WriteArrayHeader(1)
+				w.WriteBytes(keyBytes("p", "2"))
+				w.WriteArrayHeader(1)
+				return writeGoldenConsumedCapacity(0.5, w)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeBatchGetItemOutput(ctx, r, batchGetInput, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.BatchGetItemOutput{
+				Responses:        map[string][]map[string]types.AttributeValue{goldenTable: {goldenItem("p", "1", "a", "x")}},
+				UnprocessedKeys:  map[string]types.KeysAndAttributes{goldenTable: {Keys: []map[string]types.AttributeValue{goldenKey("p", "2")}}},
+				ConsumedCapacity: []types.ConsumedCapacity{*consumed},
+			},
+		},
+		{
+			name: "batch_write_item_response",
+			encode: func(w *cbor.Writer) error {
+				w.WriteMapHeader(1)
+				w.WriteString(goldenTable)
+				w.WriteArrayHeader(4)
+				w.WriteBytes(keyBytes("p", "1"))
+				if err := writeGoldenNonKeyAttributes(ctx, goldenItem("p", "1", "a", "x"), attrNamesListToId, w); err != nil {
+					return err
+				}
+				w.WriteBytes(keyBytes("p", "2"))
+				w.WriteNull()
+				w.WriteArrayHeader(0)
+				return w.WriteMapHeader(0)
+			},
+			decode: func(r *cbor.Reader) (interface{}, error) {
+				return decodeBatchWriteItemOutput(ctx, r, keySchema, attrListIdToNames, nil)
+			},
+			want: &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{goldenTable: {
+				{PutRequest: &types.PutRequest{Item: goldenItem("p", "1", "a", "x")}},
+				{DeleteRequest: &types.DeleteRequest{Key: goldenKey("p", "2")}},
+			}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// every response starts with the error section, empty on success
+			frame := checkGolden(t, c.name, encodeFrame(t, func(w *cbor.Writer) error {
+				if err := w.WriteArrayHeader(0); err != nil {
+					return err
+				}
+				return c.encode(w)
+			}))
+
+			r := cbor.NewReader(bytes.NewReader(frame))
+			daxErr, err := decodeError(r)
+			require.NoError(t, err)
+			require.NoError(t, daxErr)
+			got, err := c.decode(r)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+			_, err = r.PeekHeader()
+			assert.Error(t, err, "frame has trailing bytes")
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+// Roster is a snapshot of the cluster nodes known to a client. A process taking over
+// from another one can pass it as Config.InitialRoster to route requests before its
+// first successful discovery.
+type Roster struct {
+	ExportedAt time.Time    `json:"exportedAt"`
+	Nodes      []RosterNode `json:"nodes"`
+}
+
+// RosterNode is a single node of a Roster.
+type RosterNode struct {
+	NodeID           int64  `json:"nodeId"`
+	Hostname         string `json:"hostname"`
+	Address          string `json:"address"`
+	Port             int    `json:"port"`
+	Role             int    `json:"role"`
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+}
+
+// HandoffConn is an idle, unencrypted connection detached from a client so that it can
+// be passed to another process, e.g. through exec.Cmd.ExtraFiles or a unix socket.
+// The connection has completed the DAX handshake and is adopted without a new one.
+type HandoffConn struct {
+	// Address is the ip:port of the node the connection is established to.
+	Address string
+	File    *os.File
+}
+
+// ExportRoster returns the nodes the client currently routes requests to.
+func (cc *ClusterDaxClient) ExportRoster() Roster {
+	return cc.cluster.exportRoster()
+}
+
+// ExportConnections detaches the idle connections of the client and returns them as
+// files. Only plain TCP connections can be exported, encrypted connections and
+// platforms without file descriptor support yield no connections. The client keeps
+// working and dials new connections as needed.
+func (cc *ClusterDaxClient) ExportConnections() []HandoffConn {
+	return cc.cluster.exportConnections()
+}
+
+func (c *cluster) exportRoster() Roster {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	r := Roster{ExportedAt: time.Now(), Nodes: make([]RosterNode, 0, len(c.active))}
+	for _, cc := range c.active {
+		r.Nodes = append(r.Nodes, RosterNode{
+			NodeID:           cc.cfg.nodeId,
+			Hostname:         cc.cfg.hostname,
+			Address:          net.IP(cc.cfg.address).String(),
+			Port:             cc.cfg.port,
+			Role:             cc.cfg.role,
+			AvailabilityZone: cc.cfg.availabilityZone,
+		})
+	}
+	sort.Slice(r.Nodes, func(i, j int) bool { return r.Nodes[i].NodeID < r.Nodes[j].NodeID })
+	return r
+}
+
+func (r *Roster) endpoints() ([]serviceEndpoint, error) {
+	eps := make([]serviceEndpoint, 0, len(r.Nodes))
+	for _, n := range r.Nodes {
+		ip := net.ParseIP(n.Address)
+		if ip == nil {
+			return nil, NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("invalid roster node address %q", n.Address))
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		eps = append(eps, serviceEndpoint{
+			nodeId:           n.NodeID,
+			hostname:         n.Hostname,
+			address:          ip,
+			port:             n.Port,
+			role:             n.Role,
+			availabilityZone: n.AvailabilityZone,
+		})
+	}
+	return eps, nil
+}
+
+func (c *cluster) exportConnections() []HandoffConn {
+	c.lock.RLock()
+	var pools []*tubePool
+	for _, cc := range c.active {
+		if sc, ok := cc.client.(*SingleDaxClient); ok {
+			pools = append(pools, sc.pool)
+		}
+	}
+	c.lock.RUnlock()
+
+	var out []HandoffConn
+	for _, p := range pools {
+		for _, t := range p.detachIdle() {
+			if nt, ok := t.(*netConnTube); ok {
+				if f, err := nt.file(); err == nil {
+					out = append(out, HandoffConn{Address: p.address, File: f})
+				} else {
+					c.debugLog("Unable to export connection to %s : %s", p.address, err)
+				}
+			}
+			// The exported file holds its own descriptor, the socket stays open.
+			t.Close()
+		}
+	}
+	return out
+}
+
+// adoptHandoffConns converts the files of cfg.HandoffConnections into connections,
+// grouped by address.
+func adoptHandoffConns(cfg *Config) map[string][]net.Conn {
+	if len(cfg.HandoffConnections) == 0 {
+		return nil
+	}
+	warn := func(format string, args ...interface{}) {
+		if cfg.logger != nil {
+			cfg.logger.Logf(logging.Warn, format, args...)
+		}
+	}
+	if cfg.connConfig.isEncrypted {
+		warn("ignoring %d handed off connections, encrypted clusters do not support connection handoff", len(cfg.HandoffConnections))
+		for _, h := range cfg.HandoffConnections {
+			h.File.Close()
+		}
+		return nil
+	}
+	conns := make(map[string][]net.Conn)
+	for _, h := range cfg.HandoffConnections {
+		conn, err := net.FileConn(h.File)
+		h.File.Close()
+		if err != nil {
+			warn("ignoring handed off connection to %s: %s", h.Address, err)
+			continue
+		}
+		conns[h.Address] = append(conns[h.Address], conn)
+	}
+	return conns
+}
+
+// adoptConnections hands the connections received for the address of cli to its pool.
+// c.lock must be held when calling this method.
+func (c *cluster) adoptConnections(cli DaxAPI) {
+	sc, ok := cli.(*SingleDaxClient)
+	if !ok || len(c.handoff) == 0 {
+		return
+	}
+	conns := c.handoff[sc.pool.address]
+	delete(c.handoff, sc.pool.address)
+	sc.pool.adopt(conns)
+}
+
+// closeHandoff closes the handed off connections which never matched a node.
+// c.lock must be held when calling this method.
+func (c *cluster) closeHandoff() {
+	for _, conns := range c.handoff {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	c.handoff = nil
+}
+
+// detachIdle removes all idle tubes from the pool and returns them.
+func (p *tubePool) detachIdle() []tube {
+	p.mutex.Lock()
+	head := p.clearIdleConnections()
+	p.mutex.Unlock()
+
+	var out []tube
+	for head != nil {
+		next := head.Next()
+		head.SetNext(nil)
+		out = append(out, head)
+		head = next
+	}
+	return out
+}
+
+// adopt adds connections which already completed the DAX handshake to the idle tubes.
+func (p *tubePool) adopt(conns []net.Conn) {
+	for _, conn := range conns {
+		if !p.reserveConn() {
+			// Connections beyond the limit of the pool are not adopted.
+			conn.Close()
+			continue
+		}
+		p.mutex.Lock()
+		s := p.session
+		p.mutex.Unlock()
+		t := adoptTube(p.limitConn(conn), s)
+		t.CborReader().SetTolerant(p.connConfig.tolerantDecoding)
+		p.put(t)
+	}
+}
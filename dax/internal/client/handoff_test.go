@@ -0,0 +1,102 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster_rosterRoundTrip(t *testing.T) {
+	endpoints := []serviceEndpoint{
+		{nodeId: 1, hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111, role: 1, availabilityZone: "us-west-2a"},
+		{nodeId: 2, hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111, role: 2},
+	}
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	require.NoError(t, cluster.update(endpoints))
+
+	roster := cluster.exportRoster()
+	require.Len(t, roster.Nodes, 2)
+	assert.Equal(t, "127.0.0.1", roster.Nodes[0].Address)
+	assert.Equal(t, "us-west-2a", roster.Nodes[0].AvailabilityZone)
+
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Schedule.ClusterRefresh.Disabled = true
+	cfg.InitialRoster = &roster
+	restarted, b := newTestClusterWithConfig(cfg)
+	defer restarted.Close()
+	setExpectation(restarted, endpoints)
+	require.NoError(t, restarted.start(context.Background()))
+
+	// The routes are created from the roster before the discovery client is.
+	require.Len(t, b.clients, 3)
+	assert.Equal(t, hostPort{"127.0.0.2", 8111}, b.clients[1].hp)
+	assert.Len(t, restarted.active, 2)
+	assert.Equal(t, endpoints, []serviceEndpoint{
+		restarted.active[hostPort{"127.0.0.1", 8111}].cfg,
+		restarted.active[hostPort{"127.0.0.2", 8111}].cfg,
+	})
+}
+
+func TestTubePool_handoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	server := <-accepted
+	defer server.Close()
+
+	sdkMetrics, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	pool := newTubePool(ln.Addr().String(), connConfig{}, sdkMetrics)
+	defer pool.Close()
+	pool.adopt([]net.Conn{conn})
+	assert.Equal(t, int64(1), pool.idle)
+
+	tubes := pool.detachIdle()
+	require.Len(t, tubes, 1)
+	assert.Nil(t, pool.top)
+
+	f, err := tubes[0].(*netConnTube).file()
+	require.NoError(t, err)
+	require.NoError(t, tubes[0].Close())
+
+	// The exported descriptor keeps the connection to the server open.
+	adopted, err := net.FileConn(f)
+	require.NoError(t, err)
+	f.Close()
+	defer adopted.Close()
+	_, err = adopted.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = server.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
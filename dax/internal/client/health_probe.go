@@ -0,0 +1,91 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthCheckTimeout = time.Second
+	// defaultHealthCheckRetries is the retry budget of a health check, which the node
+	// layer owns as health checks are not issued through the cluster.
+	defaultHealthCheckRetries = 3
+)
+
+// healthCheckTimeout returns the timeout of a health check probe, retries included.
+func (cfg *Config) healthCheckTimeout() time.Duration {
+	if cfg.HealthCheckTimeout > 0 {
+		return cfg.HealthCheckTimeout
+	}
+	return defaultHealthCheckTimeout
+}
+
+// healthCheckRetries returns the number of retries of a failed health check probe.
+func (cfg *Config) healthCheckRetries() int {
+	switch {
+	case cfg.HealthCheckRetries < 0:
+		return 0
+	case cfg.HealthCheckRetries == 0:
+		return defaultHealthCheckRetries
+	default:
+		return cfg.HealthCheckRetries
+	}
+}
+
+// validateHealthCheck rejects probe timeouts which would let a probe run into the next
+// one of the same node.
+func (cfg *Config) validateHealthCheck() error {
+	hc := cfg.schedule().HealthCheck
+	if hc.Disabled {
+		return nil
+	}
+	if timeout := cfg.healthCheckTimeout(); timeout >= hc.Interval {
+		return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("HealthCheckTimeout of %s must be shorter than the health check interval of %s", timeout, hc.Interval))
+	}
+	return nil
+}
+
+// probes tracks the nodes with a health check probe in flight. A failed probe replaces
+// the client of the node, whose own health checks start while the probes of the previous
+// client may still be running; the probes of a node are skipped rather than stacked up.
+type probes struct {
+	mu       sync.Mutex
+	inFlight map[hostPort]struct{}
+}
+
+// begin reports whether a probe of host may start, and marks it in flight when it may.
+func (p *probes) begin(host hostPort) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.inFlight[host]; ok {
+		return false
+	}
+	if p.inFlight == nil {
+		p.inFlight = make(map[hostPort]struct{})
+	}
+	p.inFlight[host] = struct{}{}
+	return true
+}
+
+// end marks the probe of host as completed.
+func (p *probes) end(host hostPort) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inFlight, host)
+}
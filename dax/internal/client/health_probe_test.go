@@ -0,0 +1,53 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_healthCheckProbes(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, defaultHealthCheckTimeout, cfg.healthCheckTimeout())
+	assert.Equal(t, defaultHealthCheckRetries, cfg.healthCheckRetries())
+	assert.NoError(t, cfg.validateHealthCheck())
+
+	cfg.HealthCheckRetries = -1
+	assert.Equal(t, 0, cfg.healthCheckRetries())
+	cfg.HealthCheckRetries = 1
+	assert.Equal(t, 1, cfg.healthCheckRetries())
+
+	cfg.HealthCheckTimeout = cfg.ClientHealthCheckInterval
+	assert.Error(t, cfg.validateHealthCheck(), "probes would overlap")
+	cfg.Schedule.HealthCheck.Interval = 2 * cfg.HealthCheckTimeout
+	assert.NoError(t, cfg.validateHealthCheck())
+	cfg.Schedule.HealthCheck = TaskSchedule{Disabled: true}
+	cfg.HealthCheckTimeout = time.Hour
+	assert.NoError(t, cfg.validateHealthCheck(), "no probes to overlap")
+}
+
+func TestProbes_inFlight(t *testing.T) {
+	var p probes
+	a, b := hostPort{"10.0.0.1", 8111}, hostPort{"10.0.0.2", 8111}
+	assert.True(t, p.begin(a))
+	assert.False(t, p.begin(a), "a probe of the host is in flight")
+	assert.True(t, p.begin(b))
+	p.end(a)
+	assert.True(t, p.begin(a))
+}
@@ -0,0 +1,76 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+)
+
+// errHedgeSettled is the error of the call of a hedged request cancelled because the
+// other call succeeded. It does not count against the health of the node.
+var errHedgeSettled = errors.New("hedged request completed on another node")
+
+type hedgeResult[T any] struct {
+	out   T
+	err   error
+	hedge bool
+}
+
+// hedgedCall runs call on client and, when it didn't complete within Config.HedgeDelay,
+// on a second route as well. The output of the first call to succeed is returned and the
+// other call is cancelled with errHedgeSettled, aborting its request if already sent;
+// when both fail, the error of the last one is. The calls must not share their output.
+func hedgedCall[T any](cc *ClusterDaxClient, op string, client DaxAPI, o RequestOptions, call func(client DaxAPI, o RequestOptions) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancelCause(cc.newContext(nil, o))
+	defer cancel(errHedgeSettled)
+	o.Context = ctx
+	results := make(chan hedgeResult[T], 2)
+	run := func(client DaxAPI, hedge bool) {
+		go func() {
+			out, err := call(client, o)
+			results <- hedgeResult[T]{out: out, err: err, hedge: hedge}
+		}()
+	}
+
+	run(client, false)
+	timer := clockOrSystem(cc.config.Clock).NewTimer(cc.config.HedgeDelay)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.out, r.err
+	case <-timer.C():
+	}
+
+	pending := 1
+	var sdkMetrics *daxSdkMetrics
+	if second, err := cc.cluster.clientExcluding([]DaxAPI{client}, op, nil); err == nil && second != client {
+		run(second, true)
+		pending++
+		sdkMetrics = cc.cluster.daxSdkMetrics
+		countMetricInt64(ctx, sdkMetrics, daxHedgeSent, 1, operationAttr(op))
+	}
+	var r hedgeResult[T]
+	for ; pending > 0; pending-- {
+		if r = <-results; r.err == nil {
+			if r.hedge {
+				countMetricInt64(ctx, sdkMetrics, daxHedgeWon, 1, operationAttr(op))
+			}
+			return r.out, nil
+		}
+	}
+	return r.out, r.err
+}
@@ -0,0 +1,128 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hedgeClient answers GetItem after delay, or fails once the request is cancelled.
+type hedgeClient struct {
+	testClient
+	name      string
+	delay     time.Duration
+	calls     int32
+	cancelled int32
+}
+
+func (c *hedgeClient) GetItemWithOptions(_ context.Context, _ *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, o RequestOptions) (*dynamodb.GetItemOutput, error) {
+	atomic.AddInt32(&c.calls, 1)
+	select {
+	case <-time.After(c.delay):
+	case <-o.Context.Done():
+		atomic.AddInt32(&c.cancelled, 1)
+		return output, o.Context.Err()
+	}
+	output.Item = map[string]types.AttributeValue{"node": &types.AttributeValueMemberS{Value: c.name}}
+	return output, nil
+}
+
+func newHedgingClient(routes ...DaxAPI) *ClusterDaxClient {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.MeterProvider = &testMeterProvider{}
+	cfg.HedgeDelay = 10 * time.Millisecond
+	cluster, _ := newTestClusterWithConfig(cfg)
+	cluster.routeManager.setRoutes(routes)
+	return &ClusterDaxClient{config: cfg, cluster: cluster}
+}
+
+func TestHedgedCall(t *testing.T) {
+	stalled := &hedgeClient{name: "stalled", delay: time.Minute}
+	fast := &hedgeClient{name: "fast"}
+	cc := newHedgingClient(stalled, fast)
+	call := func(client DaxAPI, o RequestOptions) (*dynamodb.GetItemOutput, error) {
+		return client.GetItemWithOptions(context.Background(), nil, &dynamodb.GetItemOutput{}, o)
+	}
+	o := RequestOptions{Context: context.Background()}
+
+	out, err := hedgedCall(cc, OpGetItem, stalled, o, call)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "fast"}, out.Item["node"], "the response of the hedge is taken")
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&stalled.cancelled) == 1 }, time.Second, time.Millisecond, "the stalled request is cancelled")
+	expectCounters(t, cc.cluster.daxSdkMetrics, map[string]int{daxHedgeSent: 1, daxHedgeWon: 1})
+
+	out, err = hedgedCall(cc, OpGetItem, fast, o, call)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "fast"}, out.Item["node"])
+	assert.EqualValues(t, 1, atomic.LoadInt32(&stalled.calls), "no hedge is sent for requests completing within the delay")
+
+	single := newHedgingClient(stalled)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = hedgedCall(single, OpGetItem, stalled, RequestOptions{Context: ctx}, call)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "no hedge is sent without another node")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&stalled.calls))
+}
+
+func TestClusterDaxClient_getItemHedged(t *testing.T) {
+	slow := &hedgeClient{name: "slow", delay: 200 * time.Millisecond}
+	fast := &hedgeClient{name: "fast"}
+	cc := newHedgingClient(slow, fast)
+
+	for i := 0; i < 10; i++ {
+		out, err := cc.GetItemWithOptions(context.Background(), &dynamodb.GetItemInput{}, &dynamodb.GetItemOutput{}, RequestOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "fast"}, out.Item["node"])
+	}
+}
+
+func TestExecuteWithContext_hedgeSettled(t *testing.T) {
+	// The node reads requests but never answers.
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go io.Copy(io.Discard, server)
+		return client, nil
+	}
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	client, err := newSingleClientWithOptions("127.0.0.1:8111", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, dial, nil, om)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(20*time.Millisecond, func() { cancel(errHedgeSettled) })
+	encoder := func(writer *cbor.Writer) error { return writer.WriteNull() }
+	decoder := func(reader *cbor.Reader) error { return nil }
+	opt := RequestOptions{}
+	opt.ReadTimeout = time.Minute
+	start := time.Now()
+	err = client.executeWithContext(ctx, OpGetItem, encoder, decoder, opt)
+	assert.Less(t, time.Since(start), 10*time.Second, "the request sent is aborted")
+	assert.ErrorIs(t, err, errHedgeSettled)
+	assert.False(t, isIOError(err), "a lost hedge does not count against the node")
+}
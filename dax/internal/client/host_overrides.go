@@ -0,0 +1,54 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+// validateHostOverrides rejects overrides which don't map a hostname to an IP address.
+func validateHostOverrides(overrides map[string]string) error {
+	for host, ip := range overrides {
+		if host == "" {
+			return NewCustomInvalidParamError("ConfigValidation", "HostOverrides cannot have an empty hostname")
+		}
+		if net.ParseIP(ip) == nil {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("HostOverrides of %q: %q is not an IP address", host, ip))
+		}
+	}
+	return nil
+}
+
+// warnHostOverrides logs the overrides in use, since requests then go to addresses other
+// than the ones advertised by the cluster.
+func (cfg *Config) warnHostOverrides() {
+	for host, ip := range cfg.HostOverrides {
+		cfg.logger.Logf(logging.Warn, "Connecting to %s for node %s instead of the address advertised by the cluster", ip, host)
+	}
+}
+
+// nodeAddress returns the address to connect to for the node ep, the override of its
+// hostname when there is one.
+func (c *cluster) nodeAddress(ep serviceEndpoint) net.IP {
+	if ip, ok := c.config.HostOverrides[ep.hostname]; ok {
+		c.debugLog("Overriding address %s of node %s with %s", net.IP(ep.address), ep.hostname, ip)
+		return net.ParseIP(ip)
+	}
+	return net.IP(ep.address)
+}
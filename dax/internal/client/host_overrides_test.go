@@ -0,0 +1,62 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_validateHostOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"localhost:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Credentials = &testCredentialProvider{}
+
+	cfg.HostOverrides = map[string]string{"node-1.example.com": "10.1.2.3", "node-2.example.com": "fd00::2"}
+	assert.NoError(t, cfg.validate())
+
+	for _, overrides := range []map[string]string{
+		{"": "10.1.2.3"},
+		{"node-1.example.com": "node-1.internal"},
+		{"node-1.example.com": "10.1.2.3:8111"},
+	} {
+		cfg.HostOverrides = overrides
+		assert.Error(t, cfg.validate(), overrides)
+	}
+}
+
+func TestCluster_hostOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.HostOverrides = map[string]string{"node-1.example.com": "10.1.2.3"}
+	cluster, clientBuilder := newTestClusterWithConfig(cfg)
+
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node-1.example.com", address: []byte{172, 16, 0, 1}, port: 8111},
+		{hostname: "node-2.example.com", address: []byte{172, 16, 0, 2}, port: 8111},
+	}))
+
+	require.Len(t, clientBuilder.clients, 2)
+	assert.Equal(t, hostPort{"10.1.2.3", 8111}, clientBuilder.clients[0].hp)
+	assert.Equal(t, hostPort{"172.16.0.2", 8111}, clientBuilder.clients[1].hp)
+	_, ok := cluster.active[hostPort{net.IP([]byte{172, 16, 0, 1}).String(), 8111}]
+	assert.True(t, ok, "the node is still tracked by its advertised address")
+}
@@ -0,0 +1,299 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	sketchDepth = 4
+	sketchWidth = 2048
+	// hotKeyCandidates bounds the number of keys tracked as potential top keys.
+	hotKeyCandidates = 64
+)
+
+// HotKey is a frequently accessed partition key.
+type HotKey struct {
+	Table string
+	// Key renders the partition key attribute as a name=value pair.
+	Key string
+	// Count estimates the number of accesses, extrapolated from the sampled ones. Being
+	// based on a count-min sketch it may overestimate, but never underestimates.
+	Count uint64
+}
+
+type sketchKey struct {
+	table string
+	key   string
+}
+
+// keySketch counts sampled item key accesses in a count-min sketch and keeps the keys
+// with the highest estimates as top key candidates.
+type keySketch struct {
+	rate float64
+
+	mu         sync.Mutex
+	counts     [sketchDepth][sketchWidth]uint32
+	candidates map[sketchKey]uint32
+}
+
+func newKeySketch(rate float64) *keySketch {
+	if rate <= 0 {
+		return nil
+	}
+	return &keySketch{rate: rate, candidates: make(map[sketchKey]uint32, hotKeyCandidates)}
+}
+
+func (s *keySketch) sample() bool {
+	return s != nil && (s.rate >= 1 || rand.Float64() < s.rate)
+}
+
+func (s *keySketch) add(table string, key map[string]types.AttributeValue) {
+	s.count(sketchKey{table: table, key: renderKey(key)})
+}
+
+// count counts an access to k, reporting whether k is among the top key candidates
+// afterwards.
+func (s *keySketch) count(k sketchKey) bool {
+	h := fnv.New64a()
+	h.Write([]byte(k.table))
+	h.Write([]byte{0})
+	h.Write([]byte(k.key))
+	sum := h.Sum64()
+	h1, h2 := uint32(sum), uint32(sum>>32)|1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	estimate := ^uint32(0)
+	for i := range s.counts {
+		c := &s.counts[i][(h1+uint32(i)*h2)%sketchWidth]
+		if *c < ^uint32(0) {
+			*c++
+		}
+		if *c < estimate {
+			estimate = *c
+		}
+	}
+	if _, ok := s.candidates[k]; ok || len(s.candidates) < hotKeyCandidates {
+		s.candidates[k] = estimate
+		return true
+	}
+	var minKey sketchKey
+	minCount := ^uint32(0)
+	for ck, c := range s.candidates {
+		if c < minCount {
+			minKey, minCount = ck, c
+		}
+	}
+	if estimate > minCount {
+		delete(s.candidates, minKey)
+		s.candidates[k] = estimate
+		return true
+	}
+	return false
+}
+
+type rankedKey struct {
+	sketchKey
+	count uint64
+}
+
+// ranked returns up to n of the candidates with the highest estimates, extrapolated from
+// the sampling rate, most frequent first.
+func (s *keySketch) ranked(n int) []rankedKey {
+	if s == nil || n <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	out := make([]rankedKey, 0, len(s.candidates))
+	for k, c := range s.candidates {
+		out = append(out, rankedKey{sketchKey: k, count: uint64(float64(c) / s.rate)})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].count != out[j].count {
+			return out[i].count > out[j].count
+		}
+		if out[i].table != out[j].table {
+			return out[i].table < out[j].table
+		}
+		return out[i].key < out[j].key
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func (s *keySketch) top(n int) []HotKey {
+	ranked := s.ranked(n)
+	if ranked == nil {
+		return nil
+	}
+	out := make([]HotKey, len(ranked))
+	for i, k := range ranked {
+		out[i] = HotKey{Table: k.table, Key: k.key, Count: k.count}
+	}
+	return out
+}
+
+func renderKey(key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name)
+		sb.WriteByte('=')
+		switch v := key[name].(type) {
+		case *types.AttributeValueMemberS:
+			sb.WriteString(v.Value)
+		case *types.AttributeValueMemberN:
+			sb.WriteString(v.Value)
+		case *types.AttributeValueMemberB:
+			sb.WriteString(base64.StdEncoding.EncodeToString(v.Value))
+		}
+	}
+	return sb.String()
+}
+
+// sampleKeys adds the partition keys accessed by input to the hot key sketch, if the
+// request is sampled. Keys and items are reduced to their partition key using the cached
+// key schema, queries of an index are not sampled.
+func (client *SingleDaxClient) sampleKeys(ctx context.Context, input interface{}) {
+	s := client.hotKeys
+	if !s.sample() {
+		return
+	}
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		if in != nil {
+			client.samplePartition(ctx, s, aws.ToString(in.TableName), in.Key)
+		}
+	case *dynamodb.UpdateItemInput:
+		if in != nil {
+			client.samplePartition(ctx, s, aws.ToString(in.TableName), in.Key)
+		}
+	case *dynamodb.DeleteItemInput:
+		if in != nil {
+			client.samplePartition(ctx, s, aws.ToString(in.TableName), in.Key)
+		}
+	case *dynamodb.PutItemInput:
+		if in != nil {
+			client.samplePartition(ctx, s, aws.ToString(in.TableName), in.Item)
+		}
+	case *dynamodb.QueryInput:
+		if in != nil && in.IndexName == nil {
+			client.sampleQuery(ctx, s, in)
+		}
+	case *dynamodb.BatchGetItemInput:
+		if in == nil {
+			return
+		}
+		for table, kaa := range in.RequestItems {
+			for _, key := range kaa.Keys {
+				client.samplePartition(ctx, s, table, key)
+			}
+		}
+	case *dynamodb.BatchWriteItemInput:
+		if in == nil {
+			return
+		}
+		for table, wrs := range in.RequestItems {
+			for _, wr := range wrs {
+				switch {
+				case wr.PutRequest != nil:
+					client.samplePartition(ctx, s, table, wr.PutRequest.Item)
+				case wr.DeleteRequest != nil:
+					client.samplePartition(ctx, s, table, wr.DeleteRequest.Key)
+				}
+			}
+		}
+	}
+}
+
+// partitionKeyName returns the name of the HASH key attribute of table.
+func (client *SingleDaxClient) partitionKeyName(ctx context.Context, table string) (string, bool) {
+	schema, err := getKeySchema(ctx, client.keySchema, table)
+	if err != nil || len(schema) == 0 {
+		return "", false
+	}
+	return aws.ToString(schema[0].AttributeName), true
+}
+
+func (client *SingleDaxClient) samplePartition(ctx context.Context, s *keySketch, table string, attrs map[string]types.AttributeValue) {
+	name, ok := client.partitionKeyName(ctx, table)
+	if !ok {
+		return
+	}
+	if av, ok := attrs[name]; ok {
+		s.add(table, map[string]types.AttributeValue{name: av})
+	}
+}
+
+var keyEquality = regexp.MustCompile(`([#:]?[A-Za-z0-9_]+)\s*=\s*([#:]?[A-Za-z0-9_]+)`)
+
+// sampleQuery counts the partition key a query is conditioned on with an equality.
+func (client *SingleDaxClient) sampleQuery(ctx context.Context, s *keySketch, in *dynamodb.QueryInput) {
+	table := aws.ToString(in.TableName)
+	name, ok := client.partitionKeyName(ctx, table)
+	if !ok {
+		return
+	}
+	if c, ok := in.KeyConditions[name]; ok {
+		if c.ComparisonOperator == types.ComparisonOperatorEq && len(c.AttributeValueList) == 1 {
+			s.add(table, map[string]types.AttributeValue{name: c.AttributeValueList[0]})
+		}
+		return
+	}
+	attribute := func(operand string) string {
+		if strings.HasPrefix(operand, "#") {
+			return in.ExpressionAttributeNames[operand]
+		}
+		return operand
+	}
+	for _, m := range keyEquality.FindAllStringSubmatch(aws.ToString(in.KeyConditionExpression), -1) {
+		l, r := m[1], m[2]
+		if attribute(r) == name {
+			l, r = r, l
+		}
+		if attribute(l) != name || !strings.HasPrefix(r, ":") {
+			continue
+		}
+		if av, ok := in.ExpressionAttributeValues[r]; ok {
+			s.add(table, map[string]types.AttributeValue{name: av})
+		}
+		return
+	}
+}
@@ -0,0 +1,123 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hotKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: id}}
+}
+
+func TestKeySketch_top(t *testing.T) {
+	s := newKeySketch(1)
+	for i := 0; i < 1000; i++ {
+		s.add("t", hotKey(fmt.Sprint(i)))
+		if i%2 == 0 {
+			s.add("t", hotKey("hot"))
+		}
+		if i%10 == 0 {
+			s.add("t", hotKey("warm"))
+		}
+	}
+	top := s.top(2)
+	require.Len(t, top, 2)
+	assert.Equal(t, "pk=hot", top[0].Key)
+	assert.InDelta(t, 500, top[0].Count, 5)
+	assert.Equal(t, "pk=warm", top[1].Key)
+	assert.GreaterOrEqual(t, top[1].Count, uint64(100))
+
+	var disabled *keySketch
+	assert.False(t, disabled.sample())
+	assert.Nil(t, disabled.top(10))
+}
+
+func TestSingleClient_sampleKeys(t *testing.T) {
+	client := &SingleDaxClient{
+		hotKeys: newKeySketch(1),
+		keySchema: &lru.Lru{
+			MaxEntries: 10,
+			LoadFunc: func(context.Context, lru.Key) (interface{}, error) {
+				return []types.AttributeDefinition{{AttributeName: aws.String("pk")}, {AttributeName: aws.String("sk")}}, nil
+			},
+		},
+	}
+	item := map[string]types.AttributeValue{
+		"pk":    &types.AttributeValueMemberS{Value: "a"},
+		"sk":    &types.AttributeValueMemberN{Value: "1"},
+		"other": &types.AttributeValueMemberS{Value: "x"},
+	}
+	client.sampleKeys(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item})
+	client.sampleKeys(context.Background(), &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"t": {{PutRequest: &types.PutRequest{Item: item}}},
+	}})
+	client.sampleKeys(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: map[string]types.AttributeValue{
+		"pk": item["pk"],
+		"sk": item["sk"],
+	}})
+	client.sampleKeys(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: map[string]types.AttributeValue{
+		"pk": item["pk"],
+		"sk": &types.AttributeValueMemberN{Value: "2"},
+	}})
+	assert.Equal(t, []HotKey{{Table: "t", Key: "pk=a", Count: 4}}, client.hotKeys.top(5), "items of a partition count for its key")
+
+	client.sampleKeys(context.Background(), &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		KeyConditionExpression:    aws.String("#p = :p AND sk >= :s"),
+		ExpressionAttributeNames:  map[string]string{"#p": "pk"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":p": item["pk"], ":s": item["sk"]},
+	})
+	client.sampleKeys(context.Background(), &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		KeyConditionExpression:    aws.String(":p = pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":p": item["pk"]},
+	})
+	client.sampleKeys(context.Background(), &dynamodb.QueryInput{
+		TableName: aws.String("t"),
+		KeyConditions: map[string]types.Condition{
+			"pk": {ComparisonOperator: types.ComparisonOperatorEq, AttributeValueList: []types.AttributeValue{item["pk"]}},
+		},
+	})
+	client.sampleKeys(context.Background(), &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		IndexName:                 aws.String("i"),
+		KeyConditionExpression:    aws.String("pk = :p"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":p": item["pk"]},
+	})
+	assert.Equal(t, []HotKey{{Table: "t", Key: "pk=a", Count: 7}}, client.hotKeys.top(5), "queries on the table are counted")
+
+	for _, input := range []interface{}{
+		(*dynamodb.GetItemInput)(nil),
+		(*dynamodb.PutItemInput)(nil),
+		(*dynamodb.UpdateItemInput)(nil),
+		(*dynamodb.DeleteItemInput)(nil),
+		(*dynamodb.QueryInput)(nil),
+		(*dynamodb.BatchGetItemInput)(nil),
+		(*dynamodb.BatchWriteItemInput)(nil),
+	} {
+		assert.NotPanics(t, func() { client.sampleKeys(context.Background(), input) })
+	}
+}
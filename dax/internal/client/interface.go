@@ -36,5 +36,9 @@ type DaxAPI interface {
 	TransactWriteItemsWithOptions(ctx context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, opt RequestOptions) (*dynamodb.TransactWriteItemsOutput, error)
 	TransactGetItemsWithOptions(ctx context.Context, input *dynamodb.TransactGetItemsInput, output *dynamodb.TransactGetItemsOutput, opt RequestOptions) (*dynamodb.TransactGetItemsOutput, error)
 
+	ExecuteStatementWithOptions(ctx context.Context, input *dynamodb.ExecuteStatementInput, output *dynamodb.ExecuteStatementOutput, opt RequestOptions) (*dynamodb.ExecuteStatementOutput, error)
+	BatchExecuteStatementWithOptions(ctx context.Context, input *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error)
+	ExecuteTransactionWithOptions(ctx context.Context, input *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt RequestOptions) (*dynamodb.ExecuteTransactionOutput, error)
+
 	endpoints(ctx context.Context, opt RequestOptions) ([]serviceEndpoint, error)
 }
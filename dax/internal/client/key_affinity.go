@@ -0,0 +1,144 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// keySchemaSource is implemented by the routes which cache the key schemas of tables.
+type keySchemaSource interface {
+	keySchemaOf(ctx context.Context, table string) ([]types.AttributeDefinition, error)
+}
+
+func (client *SingleDaxClient) keySchemaOf(ctx context.Context, table string) ([]types.AttributeDefinition, error) {
+	return getKeySchema(ctx, client.keySchema, table)
+}
+
+type affinityKeyContextKey struct{}
+
+func withAffinityKey(ctx context.Context, key []byte) context.Context {
+	if len(key) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, affinityKeyContextKey{}, key)
+}
+
+func affinityKeyFrom(ctx context.Context) []byte {
+	if ctx == nil {
+		return nil
+	}
+	key, _ := ctx.Value(affinityKeyContextKey{}).([]byte)
+	return key
+}
+
+// affinityKey returns the table and partition key of the item accessed by input with key
+// affinity routing, or nil when input doesn't access a single item or the key schema of
+// its table is unavailable. c.lock must not be held.
+func (c *cluster) affinityKey(ctx context.Context, input interface{}) []byte {
+	if !c.config.KeyAffinityRouting {
+		return nil
+	}
+	var table *string
+	var attrs map[string]types.AttributeValue
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		if in != nil {
+			table, attrs = in.TableName, in.Key
+		}
+	case *dynamodb.PutItemInput:
+		if in != nil {
+			table, attrs = in.TableName, in.Item
+		}
+	case *dynamodb.UpdateItemInput:
+		if in != nil {
+			table, attrs = in.TableName, in.Key
+		}
+	case *dynamodb.DeleteItemInput:
+		if in != nil {
+			table, attrs = in.TableName, in.Key
+		}
+	}
+	if table == nil {
+		return nil
+	}
+
+	c.lock.RLock()
+	var source keySchemaSource
+	for _, cc := range c.active {
+		if s, ok := cc.client.(keySchemaSource); ok {
+			source = s
+			break
+		}
+	}
+	c.lock.RUnlock()
+	if source == nil {
+		return nil
+	}
+	schema, err := source.keySchemaOf(ctx, aws.ToString(table))
+	if err != nil || len(schema) == 0 {
+		return nil
+	}
+
+	key := append([]byte(aws.ToString(table)), 0)
+	switch v := attrs[aws.ToString(schema[0].AttributeName)].(type) {
+	case *types.AttributeValueMemberS:
+		key = append(append(key, 'S'), v.Value...)
+	case *types.AttributeValueMemberN:
+		key = append(append(key, 'N'), v.Value...)
+	case *types.AttributeValueMemberB:
+		key = append(append(key, 'B'), v.Value...)
+	default:
+		return nil
+	}
+	return key
+}
+
+// affinityRoute returns the route among candidates the key is assigned to by rendezvous
+// hashing of the node endpoints, so that adding or removing a node only moves the keys
+// of that node. c.lock must be held.
+func (c *cluster) affinityRoute(candidates []DaxAPI, key []byte) DaxAPI {
+	var route DaxAPI
+	var best uint64
+	for hp, cc := range c.active {
+		if !containsRoute(candidates, cc.client) {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write(key)
+		h.Write([]byte(hp.String()))
+		if score := h.Sum64(); route == nil || score > best {
+			route, best = cc.client, score
+		}
+	}
+	return route
+}
+
+// untriedRoutes returns the routes which are not in tried.
+func untriedRoutes(routes, tried []DaxAPI) []DaxAPI {
+	var untried []DaxAPI
+	for _, r := range routes {
+		if !containsRoute(tried, r) {
+			untried = append(untried, r)
+		}
+	}
+	return untried
+}
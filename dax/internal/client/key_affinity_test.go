@@ -0,0 +1,86 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (c *testClient) keySchemaOf(ctx context.Context, table string) ([]types.AttributeDefinition, error) {
+	return []types.AttributeDefinition{
+		{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+	}, nil
+}
+
+func TestCluster_keyAffinityRouting(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.KeyAffinityRouting = true
+	cluster, _ := newTestClusterWithConfig(cfg)
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111},
+		{hostname: "node3", address: []byte{127, 0, 0, 3}, port: 8111},
+	}))
+	ctx := context.Background()
+	key := func(pk string, sk string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: pk}, "sk": &types.AttributeValueMemberN{Value: sk}}
+	}
+
+	get := cluster.affinityKey(ctx, &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key("a", "1")})
+	put := cluster.affinityKey(ctx, &dynamodb.PutItemInput{TableName: aws.String("t"), Item: key("a", "2")})
+	assert.Equal(t, get, put, "items of a partition share the affinity key")
+	assert.NotEqual(t, get, cluster.affinityKey(ctx, &dynamodb.GetItemInput{TableName: aws.String("u"), Key: key("a", "1")}))
+	assert.Nil(t, cluster.affinityKey(ctx, &dynamodb.QueryInput{TableName: aws.String("t")}))
+	assert.Nil(t, cluster.affinityKey(ctx, &dynamodb.GetItemInput{TableName: aws.String("t"), Key: map[string]types.AttributeValue{}}), "the partition key is missing")
+	assert.Nil(t, cluster.affinityKey(ctx, (*dynamodb.GetItemInput)(nil)))
+	assert.Nil(t, cluster.affinityKey(ctx, (*dynamodb.PutItemInput)(nil)))
+	assert.Nil(t, cluster.affinityKey(ctx, (*dynamodb.UpdateItemInput)(nil)))
+	assert.Nil(t, cluster.affinityKey(ctx, (*dynamodb.DeleteItemInput)(nil)))
+
+	first, err := cluster.clientExcluding(nil, OpGetItem, get)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		route, err := cluster.clientExcluding(nil, OpGetItem, get)
+		require.NoError(t, err)
+		assert.Same(t, first, route, "requests for a key stick to a node")
+	}
+	route, err := cluster.clientExcluding([]DaxAPI{first}, OpGetItem, get)
+	require.NoError(t, err)
+	assert.NotSame(t, first, route, "retries go to another node")
+
+	used := map[DaxAPI]bool{}
+	for i := 0; i < 100; i++ {
+		k := cluster.affinityKey(ctx, &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key(fmt.Sprint(i), "1")})
+		route, err := cluster.clientExcluding(nil, OpGetItem, k)
+		require.NoError(t, err)
+		used[route] = true
+	}
+	assert.Len(t, used, 3, "keys are spread across the nodes")
+
+	cluster.config.KeyAffinityRouting = false
+	assert.Nil(t, cluster.affinityKey(ctx, &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key("a", "1")}))
+}
@@ -0,0 +1,143 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+)
+
+// keySchemaMismatchMessages are the parts of the ValidationException messages reporting a
+// key which doesn't match the schema of the table, as when it was recreated with other
+// keys. A request missing a key attribute is rejected with "Missing the key", which is
+// the mistake of the caller rather than a schema change, and isn't matched.
+var keySchemaMismatchMessages = []string{
+	"does not match the schema",
+	"type mismatch for key",
+}
+
+// isKeySchemaMismatch reports whether err rejects a request because of a key which doesn't
+// match the key schema of the table.
+func isKeySchemaMismatch(err error) bool {
+	if err == nil {
+		return false
+	}
+	var de daxError
+	if errors.As(err, &de) {
+		err = convertDaxError(de)
+	}
+	var ae smithy.APIError
+	if !errors.As(err, &ae) || ae.ErrorCode() != ErrCodeValidationException {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range keySchemaMismatchMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeWithKeySchema executes input, a request encoded with the cached key schemas of
+// its tables. A table recreated with different keys keeps failing with a
+// ValidationException until its schema is loaded again, so on such an error the schemas of
+// the tables are reloaded and, when one of them changed, the request is executed once more.
+func (client *SingleDaxClient) executeWithKeySchema(ctx context.Context, op string, input interface{}, o RequestOptions, encoder func(writer *cbor.Writer) error, decoder func(reader *cbor.Reader) error) error {
+	err := client.executeWithRetries(ctx, op, o, encoder, decoder)
+	if isKeySchemaMismatch(err) && client.reloadKeySchemas(client.newContext(ctx, o), requestTables(input)) {
+		err = client.executeWithRetries(ctx, op, o, encoder, decoder)
+	}
+	return err
+}
+
+// requestTables returns the names of the tables accessed by input.
+func requestTables(input interface{}) []string {
+	var tables []string
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.PutItemInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.UpdateItemInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.DeleteItemInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.ScanInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.QueryInput:
+		tables = append(tables, aws.ToString(in.TableName))
+	case *dynamodb.BatchGetItemInput:
+		for table := range in.RequestItems {
+			tables = append(tables, table)
+		}
+	case *dynamodb.BatchWriteItemInput:
+		for table := range in.RequestItems {
+			tables = append(tables, table)
+		}
+	case *dynamodb.TransactGetItemsInput:
+		for _, item := range in.TransactItems {
+			if item.Get != nil {
+				tables = append(tables, aws.ToString(item.Get.TableName))
+			}
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range in.TransactItems {
+			switch {
+			case item.ConditionCheck != nil:
+				tables = append(tables, aws.ToString(item.ConditionCheck.TableName))
+			case item.Put != nil:
+				tables = append(tables, aws.ToString(item.Put.TableName))
+			case item.Delete != nil:
+				tables = append(tables, aws.ToString(item.Delete.TableName))
+			case item.Update != nil:
+				tables = append(tables, aws.ToString(item.Update.TableName))
+			}
+		}
+	}
+	return tables
+}
+
+// reloadKeySchemas drops the cached key schemas of tables and loads them again, reporting
+// whether any of them changed. The schemas which are no longer cached are left alone, as
+// there is nothing to compare the loaded ones with.
+func (client *SingleDaxClient) reloadKeySchemas(ctx context.Context, tables []string) bool {
+	changed := false
+	seen := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		if seen[table] {
+			continue
+		}
+		seen[table] = true
+		before, ok := client.keySchema.Peek(table)
+		if !ok {
+			continue
+		}
+		client.keySchema.Remove(table)
+		after, err := getKeySchema(ctx, client.keySchema, table)
+		if err == nil && !reflect.DeepEqual(before, after) {
+			changed = true
+		}
+	}
+	return changed
+}
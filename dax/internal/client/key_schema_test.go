@@ -0,0 +1,89 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKeySchemaMismatch(t *testing.T) {
+	mismatch := newDaxRequestFailure([]int{4, 37, 38, 39, 46}, "ValidationException",
+		"The provided key element does not match the schema", "", 400, smithy.FaultClient)
+	assert.True(t, isKeySchemaMismatch(mismatch))
+	assert.True(t, isKeySchemaMismatch(&smithy.OperationError{Err: mismatch}))
+
+	other := newDaxRequestFailure([]int{4, 37, 38, 39, 46}, "ValidationException",
+		"ExpressionAttributeValues must not be empty", "", 400, smithy.FaultClient)
+	assert.False(t, isKeySchemaMismatch(other))
+	missing := newDaxRequestFailure([]int{4, 37, 38, 39, 46}, "ValidationException",
+		"One or more parameter values were invalid: Missing the key pk in the item", "", 400, smithy.FaultClient)
+	assert.False(t, isKeySchemaMismatch(missing), "a missing key is a mistake of the request")
+	retyped := newDaxRequestFailure([]int{4, 37, 38, 39, 46}, "ValidationException",
+		"One or more parameter values were invalid: Type mismatch for key pk expected: S actual: N", "", 400, smithy.FaultClient)
+	assert.True(t, isKeySchemaMismatch(retyped))
+	assert.False(t, isKeySchemaMismatch(errors.New("the provided key element does not match the schema")))
+	assert.False(t, isKeySchemaMismatch(nil))
+}
+
+func TestSingleClient_reloadKeySchemas(t *testing.T) {
+	recreated := false
+	loads := 0
+	client := &SingleDaxClient{
+		keySchema: &lru.Lru{
+			LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+				loads++
+				if recreated && key == "recreated" {
+					return []types.AttributeDefinition{{AttributeName: aws.String("id")}}, nil
+				}
+				return []types.AttributeDefinition{{AttributeName: aws.String("pk")}}, nil
+			},
+		},
+	}
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String("same")}},
+		{Delete: &types.Delete{TableName: aws.String("recreated")}},
+		{ConditionCheck: &types.ConditionCheck{TableName: aws.String("same")}},
+	}}
+	tables := requestTables(input)
+	assert.Equal(t, []string{"same", "recreated", "same"}, tables)
+
+	ctx := context.Background()
+	for _, table := range tables {
+		getKeySchema(ctx, client.keySchema, table)
+	}
+	loads = 0
+	assert.False(t, client.reloadKeySchemas(ctx, tables), "the schemas did not change")
+	assert.Equal(t, 2, loads, "each table is loaded once")
+
+	recreated = true
+	assert.True(t, client.reloadKeySchemas(ctx, tables))
+
+	loads = 0
+	assert.False(t, client.reloadKeySchemas(ctx, []string{"uncached"}))
+	assert.Zero(t, loads, "schemas which aren't cached are not loaded")
+	keys, err := getKeySchema(ctx, client.keySchema, "recreated")
+	assert.NoError(t, err)
+	assert.Equal(t, "id", aws.ToString(keys[0].AttributeName))
+}
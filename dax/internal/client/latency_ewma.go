@@ -0,0 +1,108 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// latencyEWMAWeight is the weight of a new sample in the latency moving average of a node.
+// With 0.2, a node turning slow dominates its average within about ten requests.
+const latencyEWMAWeight = 0.2
+
+// latencyEWMA is an exponentially weighted moving average of the request latency of a
+// node, safe for concurrent use.
+type latencyEWMA struct {
+	bits uint64 // float64 nanoseconds, zero until the first sample
+}
+
+func (e *latencyEWMA) observe(d time.Duration) {
+	for {
+		old := atomic.LoadUint64(&e.bits)
+		avg := float64(d)
+		if old != 0 {
+			prev := math.Float64frombits(old)
+			avg = prev + latencyEWMAWeight*(avg-prev)
+		}
+		if avg <= 0 {
+			avg = 1
+		}
+		if atomic.CompareAndSwapUint64(&e.bits, old, math.Float64bits(avg)) {
+			return
+		}
+	}
+}
+
+// value returns the average, zero before the first sample.
+func (e *latencyEWMA) value() time.Duration {
+	return time.Duration(math.Float64frombits(atomic.LoadUint64(&e.bits)))
+}
+
+// routeLoadReporter is implemented by the routes reporting their load to route selectors.
+type routeLoadReporter interface {
+	routeLoad() (latency time.Duration, inFlight int)
+}
+
+// routeLoad returns the latency moving average of the requests to the node and the number
+// of requests in flight.
+func (client *SingleDaxClient) routeLoad() (time.Duration, int) {
+	_, inFlight := client.pool.stats()
+	return client.latency.value(), inFlight
+}
+
+// P2CRouteSelector applies the power of two choices: it draws two random routes among the
+// ones not yet tried and picks the least loaded, comparing their latency moving averages
+// weighted by their requests in flight. Consistently slow nodes get fewer requests, while
+// drawing at random keeps every node sampled so that a recovered node gets its share back.
+// Nodes without a latency sample yet are preferred.
+type P2CRouteSelector struct{}
+
+func (P2CRouteSelector) SelectRoute(prev DaxAPI, op string, routes []RouteState) DaxAPI {
+	candidates := make([]int, 0, len(routes))
+	for i, r := range routes {
+		if !r.Tried {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range routes {
+			candidates = append(candidates, i)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil
+	case 1:
+		return routes[candidates[0]].Route
+	}
+	i := rand.Intn(len(candidates))
+	j := rand.Intn(len(candidates) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := routes[candidates[i]], routes[candidates[j]]
+	if routeCost(b) < routeCost(a) {
+		return b.Route
+	}
+	return a.Route
+}
+
+func routeCost(r RouteState) float64 {
+	return float64(r.Latency) * float64(r.InFlight+1)
+}
@@ -0,0 +1,85 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyEWMA(t *testing.T) {
+	var e latencyEWMA
+	assert.Zero(t, e.value())
+	e.observe(10 * time.Millisecond)
+	assert.Equal(t, 10*time.Millisecond, e.value(), "the first sample sets the average")
+	e.observe(20 * time.Millisecond)
+	assert.Equal(t, 12*time.Millisecond, e.value())
+	for i := 0; i < 30; i++ {
+		e.observe(50 * time.Millisecond)
+	}
+	assert.InDelta(t, float64(50*time.Millisecond), float64(e.value()), float64(time.Millisecond))
+}
+
+type loadedClient struct {
+	testClient
+	latency  time.Duration
+	inFlight int
+}
+
+func (c *loadedClient) routeLoad() (time.Duration, int) {
+	return c.latency, c.inFlight
+}
+
+func TestP2CRouteSelector(t *testing.T) {
+	fast := &loadedClient{latency: time.Millisecond}
+	slow := &loadedClient{latency: 50 * time.Millisecond}
+	var s P2CRouteSelector
+
+	routes := []RouteState{{Route: fast, Latency: fast.latency}, {Route: slow, Latency: slow.latency}}
+	for i := 0; i < 20; i++ {
+		assert.Same(t, fast, s.SelectRoute(nil, OpGetItem, routes))
+	}
+
+	routes[0].InFlight = 100
+	assert.Same(t, slow, s.SelectRoute(nil, OpGetItem, routes), "requests in flight weigh the latency")
+
+	routes[0].InFlight = 0
+	routes[0].Tried = true
+	assert.Same(t, slow, s.SelectRoute(fast, OpGetItem, routes), "tried routes are avoided")
+	routes[1].Tried = true
+	assert.NotNil(t, s.SelectRoute(slow, OpGetItem, routes))
+	assert.Nil(t, s.SelectRoute(nil, OpGetItem, nil))
+}
+
+func TestCluster_selectRouteLoad(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	selector := &recordingSelector{pick: func(routes []RouteState) DaxAPI { return routes[0].Route }}
+	cfg.RouteSelector = selector
+	cluster, _ := newTestClusterWithConfig(cfg)
+	route := &loadedClient{latency: 3 * time.Millisecond, inFlight: 2}
+	cluster.routeManager.setRoutes([]DaxAPI{route})
+
+	_, err := cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	require.Len(t, selector.routes, 1)
+	assert.Equal(t, 3*time.Millisecond, selector.routes[0].Latency)
+	assert.Equal(t, 2, selector.routes[0].InFlight)
+}
@@ -152,6 +152,9 @@ func translateLegacyScanInput(input *dynamodb.ScanInput) (*dynamodb.ScanInput, e
 	return output, nil
 }
 
+// translateLegacyQueryInput rewrites the legacy AttributesToGet, QueryFilter and KeyConditions
+// parameters into the projection, filter and key condition expressions, the form produced by
+// the expression package. A parameter cannot be given in both forms.
 func translateLegacyQueryInput(input *dynamodb.QueryInput) (*dynamodb.QueryInput, error) {
 	pf, err := hasAttributesToGet(input.AttributesToGet, input.ProjectionExpression)
 	if err != nil {
@@ -0,0 +1,262 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	smithy "github.com/aws/smithy-go"
+)
+
+// Limits DynamoDB enforces on requests. The requests exceeding them are rejected before
+// they are sent with the ValidationException the service would return.
+const (
+	maxExpressionLength    = 4096  // bytes of a condition, update, projection or filter expression
+	maxPlaceholderLength   = 255   // bytes of an expression attribute name or value placeholder
+	maxAttributeNameLength = 65535 // bytes of an attribute name
+	maxNestingDepth        = 32    // levels of nested maps and lists in an attribute value
+	maxTransactItems       = 100   // items of TransactWriteItems and TransactGetItems
+	maxBatchWriteRequests  = 25    // put and delete requests of BatchWriteItem, all tables included
+	maxBatchGetKeys        = 100   // keys of BatchGetItem, all tables included
+)
+
+func validationError(format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{
+		Code:    ErrCodeValidationException,
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// expression is an expression parameter of a request, named as in the service errors.
+type expression struct {
+	name  string
+	value *string
+}
+
+// checkExpressions checks the length of the expressions of a request and of the
+// placeholders and the attribute names and values they refer to.
+func checkExpressions(exprs []expression, names map[string]string, values map[string]types.AttributeValue) error {
+	for _, e := range exprs {
+		if e.value != nil && len(*e.value) > maxExpressionLength {
+			return validationError("Invalid %s: Expression size has exceeded the maximum allowed size; expression size: %d", e.name, len(*e.value))
+		}
+	}
+	for k, n := range names {
+		if len(k) > maxPlaceholderLength {
+			return validationError("ExpressionAttributeNames contains invalid key: Key length exceeds %d bytes; key: \"%s\"", maxPlaceholderLength, k)
+		}
+		if n == "" {
+			return validationError("ExpressionAttributeNames contains invalid value: Empty attribute name for key %s", k)
+		}
+		if len(n) > maxAttributeNameLength {
+			return validationError("ExpressionAttributeNames contains invalid value: Attribute name exceeds %d bytes for key %s", maxAttributeNameLength, k)
+		}
+	}
+	for k, v := range values {
+		if len(k) > maxPlaceholderLength {
+			return validationError("ExpressionAttributeValues contains invalid key: Key length exceeds %d bytes; key: \"%s\"", maxPlaceholderLength, k)
+		}
+		if err := checkAttributeValue(v, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkItem checks the attribute names and the nesting of the attribute values of an
+// item or a key.
+func checkItem(item map[string]types.AttributeValue) error {
+	return checkAttributes(item, 1)
+}
+
+func checkAttributes(m map[string]types.AttributeValue, depth int) error {
+	for n, v := range m {
+		if n == "" {
+			return validationError("One or more parameter values were invalid: Empty attribute name")
+		}
+		if len(n) > maxAttributeNameLength {
+			return validationError("One or more parameter values were invalid: Attribute name exceeds %d bytes", maxAttributeNameLength)
+		}
+		if err := checkAttributeValue(v, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAttributeValue checks the nesting of v, found depth levels deep.
+func checkAttributeValue(v types.AttributeValue, depth int) error {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberM:
+		if depth > maxNestingDepth {
+			return validationError("Nesting Levels have exceeded supported limits")
+		}
+		return checkAttributes(v.Value, depth+1)
+	case *types.AttributeValueMemberL:
+		if depth > maxNestingDepth {
+			return validationError("Nesting Levels have exceeded supported limits")
+		}
+		for _, e := range v.Value {
+			if err := checkAttributeValue(e, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkPutItemLimits(v *dynamodb.PutItemInput) error {
+	if err := checkItem(v.Item); err != nil {
+		return err
+	}
+	return checkExpressions([]expression{{"ConditionExpression", v.ConditionExpression}}, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+}
+
+func checkDeleteItemLimits(v *dynamodb.DeleteItemInput) error {
+	if err := checkItem(v.Key); err != nil {
+		return err
+	}
+	return checkExpressions([]expression{{"ConditionExpression", v.ConditionExpression}}, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+}
+
+func checkUpdateItemLimits(v *dynamodb.UpdateItemInput) error {
+	if err := checkItem(v.Key); err != nil {
+		return err
+	}
+	exprs := []expression{{"UpdateExpression", v.UpdateExpression}, {"ConditionExpression", v.ConditionExpression}}
+	return checkExpressions(exprs, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+}
+
+func checkGetItemLimits(v *dynamodb.GetItemInput) error {
+	if err := checkItem(v.Key); err != nil {
+		return err
+	}
+	return checkExpressions([]expression{{"ProjectionExpression", v.ProjectionExpression}}, v.ExpressionAttributeNames, nil)
+}
+
+func checkScanLimits(v *dynamodb.ScanInput) error {
+	if err := checkItem(v.ExclusiveStartKey); err != nil {
+		return err
+	}
+	exprs := []expression{{"ProjectionExpression", v.ProjectionExpression}, {"FilterExpression", v.FilterExpression}}
+	return checkExpressions(exprs, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+}
+
+func checkQueryLimits(v *dynamodb.QueryInput) error {
+	if err := checkItem(v.ExclusiveStartKey); err != nil {
+		return err
+	}
+	exprs := []expression{{"KeyConditionExpression", v.KeyConditionExpression}, {"ProjectionExpression", v.ProjectionExpression}, {"FilterExpression", v.FilterExpression}}
+	return checkExpressions(exprs, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+}
+
+func checkBatchWriteItemLimits(v *dynamodb.BatchWriteItemInput) error {
+	n := 0
+	for _, requests := range v.RequestItems {
+		n += len(requests)
+		for _, r := range requests {
+			if r.PutRequest != nil {
+				if err := checkItem(r.PutRequest.Item); err != nil {
+					return err
+				}
+			}
+			if r.DeleteRequest != nil {
+				if err := checkItem(r.DeleteRequest.Key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if n > maxBatchWriteRequests {
+		return validationError("Too many items requested for the BatchWriteItem call")
+	}
+	return nil
+}
+
+func checkBatchGetItemLimits(v *dynamodb.BatchGetItemInput) error {
+	n := 0
+	for _, kaa := range v.RequestItems {
+		n += len(kaa.Keys)
+		for _, k := range kaa.Keys {
+			if err := checkItem(k); err != nil {
+				return err
+			}
+		}
+		if err := checkExpressions([]expression{{"ProjectionExpression", kaa.ProjectionExpression}}, kaa.ExpressionAttributeNames, nil); err != nil {
+			return err
+		}
+	}
+	if n > maxBatchGetKeys {
+		return validationError("Too many items requested for the BatchGetItem call")
+	}
+	return nil
+}
+
+func checkTransactWriteItemsLimits(v *dynamodb.TransactWriteItemsInput) error {
+	if len(v.TransactItems) > maxTransactItems {
+		return validationError("1 validation error detected: Value at 'transactItems' failed to satisfy constraint: Member must have length less than or equal to %d", maxTransactItems)
+	}
+	for _, ti := range v.TransactItems {
+		var err error
+		switch {
+		case ti.ConditionCheck != nil:
+			c := ti.ConditionCheck
+			if err = checkItem(c.Key); err == nil {
+				err = checkExpressions([]expression{{"ConditionExpression", c.ConditionExpression}}, c.ExpressionAttributeNames, c.ExpressionAttributeValues)
+			}
+		case ti.Put != nil:
+			p := ti.Put
+			if err = checkItem(p.Item); err == nil {
+				err = checkExpressions([]expression{{"ConditionExpression", p.ConditionExpression}}, p.ExpressionAttributeNames, p.ExpressionAttributeValues)
+			}
+		case ti.Delete != nil:
+			d := ti.Delete
+			if err = checkItem(d.Key); err == nil {
+				err = checkExpressions([]expression{{"ConditionExpression", d.ConditionExpression}}, d.ExpressionAttributeNames, d.ExpressionAttributeValues)
+			}
+		case ti.Update != nil:
+			u := ti.Update
+			if err = checkItem(u.Key); err == nil {
+				exprs := []expression{{"UpdateExpression", u.UpdateExpression}, {"ConditionExpression", u.ConditionExpression}}
+				err = checkExpressions(exprs, u.ExpressionAttributeNames, u.ExpressionAttributeValues)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkTransactGetItemsLimits(v *dynamodb.TransactGetItemsInput) error {
+	if len(v.TransactItems) > maxTransactItems {
+		return validationError("1 validation error detected: Value at 'transactItems' failed to satisfy constraint: Member must have length less than or equal to %d", maxTransactItems)
+	}
+	for _, ti := range v.TransactItems {
+		if g := ti.Get; g != nil {
+			if err := checkItem(g.Key); err != nil {
+				return err
+			}
+			if err := checkExpressions([]expression{{"ProjectionExpression", g.ProjectionExpression}}, g.ExpressionAttributeNames, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// nestedAttr returns a value made of depth nested maps.
+func nestedAttr(depth int) types.AttributeValue {
+	v := stringAttr("leaf")
+	for i := 0; i < depth; i++ {
+		v = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"m": v}}
+	}
+	return v
+}
+
+func TestValidateOp_limits(t *testing.T) {
+	key := map[string]types.AttributeValue{"id": stringAttr("1")}
+	longExpr := aws.String("attribute_exists(id) AND " + strings.Repeat("a", maxExpressionLength))
+	writes := func(n int) map[string][]types.WriteRequest {
+		m := map[string][]types.WriteRequest{}
+		for i := 0; i < n; i++ {
+			table := "t" + strconv.Itoa(i%2)
+			m[table] = append(m[table], types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+		return m
+	}
+	keys := func(n int) []map[string]types.AttributeValue {
+		out := make([]map[string]types.AttributeValue, n)
+		for i := range out {
+			out[i] = key
+		}
+		return out
+	}
+	transactGets := func(n int) []types.TransactGetItem {
+		out := make([]types.TransactGetItem, n)
+		for i := range out {
+			out[i] = types.TransactGetItem{Get: &types.Get{TableName: aws.String("t"), Key: key}}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{
+			name: "valid put",
+			err: ValidateOpPutItemInput(&dynamodb.PutItemInput{
+				TableName: aws.String("t"),
+				Item:      map[string]types.AttributeValue{"id": stringAttr("1"), "doc": nestedAttr(maxNestingDepth)},
+			}),
+		},
+		{
+			name: "put nested too deep",
+			err: ValidateOpPutItemInput(&dynamodb.PutItemInput{
+				TableName: aws.String("t"),
+				Item:      map[string]types.AttributeValue{"id": stringAttr("1"), "doc": nestedAttr(maxNestingDepth + 1)},
+			}),
+			message: "Nesting Levels",
+		},
+		{
+			name: "put empty attribute name",
+			err: ValidateOpPutItemInput(&dynamodb.PutItemInput{
+				TableName: aws.String("t"),
+				Item:      map[string]types.AttributeValue{"": stringAttr("1")},
+			}),
+			message: "Empty attribute name",
+		},
+		{
+			name: "update expression too long",
+			err: ValidateOpUpdateItemInput(&dynamodb.UpdateItemInput{
+				TableName:        aws.String("t"),
+				Key:              key,
+				UpdateExpression: longExpr,
+			}),
+			message: "Invalid UpdateExpression",
+		},
+		{
+			name: "query placeholder too long",
+			err: ValidateOpQueryInput(&dynamodb.QueryInput{
+				TableName:                aws.String("t"),
+				KeyConditionExpression:   aws.String("#k = :v"),
+				ExpressionAttributeNames: map[string]string{"#" + strings.Repeat("k", maxPlaceholderLength): "id"},
+			}),
+			message: "ExpressionAttributeNames contains invalid key",
+		},
+		{
+			name: "scan empty expression attribute name",
+			err: ValidateOpScanInput(&dynamodb.ScanInput{
+				TableName:                aws.String("t"),
+				ExpressionAttributeNames: map[string]string{"#k": ""},
+			}),
+			message: "Empty attribute name for key #k",
+		},
+		{
+			name: "get projection too long",
+			err: ValidateOpGetItemInput(&dynamodb.GetItemInput{
+				TableName:            aws.String("t"),
+				Key:                  key,
+				ProjectionExpression: longExpr,
+			}),
+			message: "Invalid ProjectionExpression",
+		},
+		{
+			name: "delete condition too long",
+			err: ValidateOpDeleteItemInput(&dynamodb.DeleteItemInput{
+				TableName:           aws.String("t"),
+				Key:                 key,
+				ConditionExpression: longExpr,
+			}),
+			message: "Invalid ConditionExpression",
+		},
+		{
+			name: "batch write at the limit",
+			err:  ValidateOpBatchWriteItemInput(&dynamodb.BatchWriteItemInput{RequestItems: writes(maxBatchWriteRequests)}),
+		},
+		{
+			name:    "batch write over the limit across tables",
+			err:     ValidateOpBatchWriteItemInput(&dynamodb.BatchWriteItemInput{RequestItems: writes(maxBatchWriteRequests + 1)}),
+			message: "Too many items requested for the BatchWriteItem call",
+		},
+		{
+			name: "batch get over the limit",
+			err: ValidateOpBatchGetItemInput(&dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+				"t": {Keys: keys(maxBatchGetKeys + 1)},
+			}}),
+			message: "Too many items requested for the BatchGetItem call",
+		},
+		{
+			name: "transact get at the limit",
+			err:  ValidateOpTransactGetItemsInput(&dynamodb.TransactGetItemsInput{TransactItems: transactGets(maxTransactItems)}),
+		},
+		{
+			name:    "transact get over the limit",
+			err:     ValidateOpTransactGetItemsInput(&dynamodb.TransactGetItemsInput{TransactItems: transactGets(maxTransactItems + 1)}),
+			message: "length less than or equal to 100",
+		},
+		{
+			name: "transact write condition too long",
+			err: ValidateOpTransactWriteItemsInput(&dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+				{ConditionCheck: &types.ConditionCheck{TableName: aws.String("t"), Key: key, ConditionExpression: longExpr}},
+			}}),
+			message: "Invalid ConditionExpression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.message == "" {
+				assert.NoError(t, tt.err)
+				return
+			}
+			var apiErr smithy.APIError
+			if assert.True(t, errors.As(tt.err, &apiErr), "%v", tt.err) {
+				assert.Equal(t, ErrCodeValidationException, apiErr.ErrorCode())
+				assert.Contains(t, apiErr.ErrorMessage(), tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/smithy-go/logging"
+)
+
+// RequestLogError is returned for a failed request made with RequestOptions.CaptureLog
+// set. It wraps the error of the request with the debug log of all its attempts.
+type RequestLogError struct {
+	Err error
+	// Log holds the messages logged for the request, each prefixed with the time
+	// elapsed since the request started.
+	Log []string
+}
+
+func (e *RequestLogError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestLogError) Unwrap() error {
+	return e.Err
+}
+
+// logCapture records the messages logged for a single request, at debug level whatever
+// the level of the client, and passes those the client level enables on to its logger.
+type logCapture struct {
+	logger logging.Logger
+	level  utils.LogLevelType
+	start  time.Time
+
+	mu    sync.Mutex
+	lines []string
+}
+
+var _ utils.AttrLogger = (*logCapture)(nil)
+
+func newLogCapture(logger logging.Logger, level utils.LogLevelType) *logCapture {
+	return &logCapture{logger: logger, level: level, start: time.Now()}
+}
+
+func (c *logCapture) Logf(classification logging.Classification, format string, v ...interface{}) {
+	c.add(classification, fmt.Sprintf(format, v...))
+	if c.forward(classification) {
+		c.logger.Logf(classification, format, v...)
+	}
+}
+
+func (c *logCapture) LogAttrs(ctx context.Context, classification logging.Classification, msg string, attrs ...slog.Attr) {
+	line := msg
+	for _, a := range attrs {
+		line += " " + a.String()
+	}
+	c.add(classification, line)
+	if c.forward(classification) {
+		logAttrs(ctx, c.logger, classification, attrs, "%s", msg)
+	}
+}
+
+// forward reports whether the client logger would have received a message.
+func (c *logCapture) forward(classification logging.Classification) bool {
+	return c.logger != nil && (classification != logging.Debug || c.level.AtLeast(utils.LogDebug))
+}
+
+func (c *logCapture) add(classification logging.Classification, msg string) {
+	elapsed := time.Since(c.start).Round(time.Microsecond)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, fmt.Sprintf("+%s %s %s", elapsed, classification, strings.TrimRight(msg, "\n")))
+}
+
+// addf records a message which only goes to the captured log. It is a no-op on a nil
+// capture.
+func (c *logCapture) addf(format string, v ...interface{}) {
+	if c == nil {
+		return
+	}
+	c.add(logging.Debug, fmt.Sprintf(format, v...))
+}
+
+func (c *logCapture) log() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+// routeName returns the endpoint of route, for the captured log.
+func routeName(route DaxAPI) string {
+	if single, ok := route.(*SingleDaxClient); ok {
+		return single.pool.address
+	}
+	return fmt.Sprintf("%T", route)
+}
@@ -0,0 +1,53 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/smithy-go/logging"
+)
+
+// logAttrs logs the formatted message with logger, passing attrs along when the logger
+// accepts structured attributes.
+func logAttrs(ctx context.Context, logger logging.Logger, classification logging.Classification, attrs []slog.Attr, format string, args ...interface{}) {
+	if l, ok := logger.(utils.AttrLogger); ok {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		l.LogAttrs(ctx, classification, fmt.Sprintf(format, args...), attrs...)
+		return
+	}
+	logger.Logf(classification, format, args...)
+}
+
+// requestAttrs returns the structured attributes describing an attempt of op. attempt is
+// omitted when negative and the request ID is taken from err when it has one.
+func requestAttrs(op string, attempt int, err error) []slog.Attr {
+	attrs := []slog.Attr{slog.String("op", op)}
+	if attempt >= 0 {
+		attrs = append(attrs, slog.Int("attempt", attempt))
+	}
+	var de daxError
+	if errors.As(err, &de) && de.RequestID() != "" {
+		attrs = append(attrs, slog.String("requestID", de.RequestID()))
+	}
+	return attrs
+}
@@ -0,0 +1,131 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger_levels(t *testing.T) {
+	for _, level := range []utils.LogLevelType{utils.LogOff, utils.LogDebug, utils.LogDebugWithRequestRetries} {
+		h := slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: level.SlogLevel()})
+		assert.Equal(t, level, utils.LogLevelForSlog(h))
+	}
+
+	assert.Equal(t, slog.LevelDebug, utils.SlogLevel(logging.Debug))
+	assert.Equal(t, slog.LevelWarn, utils.SlogLevel(logging.Warn))
+	assert.Equal(t, slog.LevelError, utils.SlogLevel("ERROR"))
+	assert.Equal(t, slog.LevelInfo, utils.SlogLevel("INFO"))
+}
+
+func TestClusterDaxClient_retryLogAttrs(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: utils.LevelTrace})
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		calls++
+		if calls == 1 {
+			return newDaxRequestFailure([]int{1}, "RetryableError", "", "req-1", 500, smithy.FaultServer)
+		}
+		return nil
+	}
+	opt := RequestOptions{
+		Options: dynamodb.Options{RetryMaxAttempts: 1},
+		Retryer: DaxRetryer{BaseThrottleDelay: time.Millisecond, MaxBackoffDelay: time.Millisecond},
+	}
+	opt.Logger = utils.NewSlogLogger(slog.New(h))
+	opt.LogLevel = utils.LogLevelForSlog(h)
+	require.NoError(t, cc.retry(context.Background(), OpGetItem, action, opt))
+
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		require.NoError(t, dec.Decode(&r))
+		records = append(records, r)
+	}
+	require.Len(t, records, 2)
+	for i, r := range records {
+		assert.Equal(t, "DEBUG", r["level"])
+		assert.Equal(t, OpGetItem, r["op"])
+		assert.Equal(t, "req-1", r["requestID"])
+		assert.EqualValues(t, i, r["attempt"])
+	}
+}
+
+func TestLogAttrs_plainLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.LoggerFunc(func(classification logging.Classification, format string, v ...interface{}) {
+		buf.WriteString(string(classification) + " " + format)
+	})
+	logAttrs(context.Background(), logger, logging.Debug, requestAttrs(OpGetItem, 1, nil), "message %d", 1)
+	assert.Equal(t, "DEBUG message %d", buf.String())
+}
+
+func TestClusterDaxClient_retryCaptureLog(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+
+	var forwarded []string
+	logger := logging.LoggerFunc(func(classification logging.Classification, format string, v ...interface{}) {
+		forwarded = append(forwarded, format)
+	})
+	action := func(client DaxAPI, o RequestOptions) error {
+		o.Logger.Logf(logging.Warn, "node warning")
+		return newDaxRequestFailure([]int{1}, "RetryableError", "", "req-1", 500, smithy.FaultServer)
+	}
+	opt := RequestOptions{
+		Options:    dynamodb.Options{RetryMaxAttempts: 1},
+		Retryer:    DaxRetryer{BaseThrottleDelay: time.Millisecond, MaxBackoffDelay: time.Millisecond},
+		CaptureLog: true,
+	}
+	opt.Logger = logger
+	opt.LogLevel = utils.LogOff
+	err := cc.retry(context.Background(), OpGetItem, action, opt)
+
+	var rle *RequestLogError
+	require.ErrorAs(t, err, &rle)
+	var de daxError
+	assert.ErrorAs(t, err, &de, "the error of the request is wrapped")
+	log := strings.Join(rle.Log, "\n")
+	assert.Contains(t, log, "GetItem attempt 0 on")
+	assert.Contains(t, log, "GetItem attempt 1 on")
+	assert.Contains(t, log, "Retrying Request dax/GetItem, attempt 1")
+	assert.Equal(t, []string{"node warning", "node warning"}, forwarded, "debug messages are not forwarded at LogOff")
+
+	opt.CaptureLog = false
+	err = cc.retry(context.Background(), OpGetItem, action, opt)
+	assert.False(t, errors.As(err, &rle))
+}
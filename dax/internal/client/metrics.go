@@ -17,10 +17,13 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
+	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/metrics"
 )
 
@@ -29,47 +32,120 @@ const (
 
 	daxOpNameSuccess                = "dax.op.%s.success"
 	daxOpNameFailure                = "dax.op.%s.failure"
-	daxOpNameLatencyUs              = "dax.op.%s.latency_us"     // histogram
+	daxOpNameLatencyUs              = "dax.op.%s.latency_us" // histogram
+	daxAuthSuccess                  = "dax.auth.success"
+	daxAuthFailure                  = "dax.auth.failure"
+	daxAuthLatencyUs                = "dax.auth.latency_us"      // histogram
 	daxConnectionsIdle              = "dax.connections.idle"     // gauge
 	daxConcurrentConnectionAttempts = "dax.connections.attempts" // gauge
 	daxConnectionsCreated           = "dax.connections.created"
 	daxConnectionsClosedError       = "dax.connections.closed.error"
 	daxConnectionsClosedIdle        = "dax.connections.closed.idle"
 	daxConnectionsClosedSession     = "dax.connections.closed.session"
+	daxConnectionsBytesSent         = "dax.connections.bytes.sent"
+	daxConnectionsBytesReceived     = "dax.connections.bytes.received"
 	daxRouteManagerRoutesAdded      = "dax.route_manager.routes.added"
 	daxRouteManagerRoutesRemoved    = "dax.route_manager.routes.removed"
 	daxRouteManagerFailOpenEvents   = "dax.route_manager.fail_open.events"
+	daxClusterRosterMismatches      = "dax.cluster.roster.mismatches"
+	daxQueryShapesSampled           = "dax.query_shapes.sampled"
+	daxHedgeSent                    = "dax.hedge.sent"
+	daxHedgeWon                     = "dax.hedge.won"
+
+	// The clients of the nodes failing their health checks are rebuilt in the background.
+	daxRebuildSuccess  = "dax.rebuild.success"
+	daxRebuildFailure  = "dax.rebuild.failure"
+	daxRebuildSkipped  = "dax.rebuild.skipped"
+	daxRebuildsPending = "dax.rebuild.pending" // gauge
+
+	// The requests discovering the cluster nodes are kept apart from the data path ones.
+	daxDiscoverySuccess       = "dax.discovery.success"
+	daxDiscoveryFailure       = "dax.discovery.failure"
+	daxDiscoveryLatencyUs     = "dax.discovery.latency_us" // histogram
+	daxDiscoverySeedUsed      = "dax.discovery.seed_used"
+	daxDiscoveryFailureStreak = "dax.discovery.failure_streak" // gauge
+
+	daxWorkloadReads                  = "dax.workload.reads"
+	daxWorkloadWrites                 = "dax.workload.writes"
+	daxWorkloadBatchGetKeys           = "dax.workload.batch_get.keys"                // histogram
+	daxWorkloadBatchGetUnprocessed    = "dax.workload.batch_get.unprocessed_keys"    // histogram
+	daxWorkloadBatchGetUnprocessedPct = "dax.workload.batch_get.unprocessed_percent" // histogram
+	daxWorkloadBatchGetRounds         = "dax.workload.batch_get.rounds"              // histogram
+	daxWorkloadBatchWriteRequests     = "dax.workload.batch_write.requests"          // histogram
+	daxWorkloadTransactGetItems       = "dax.workload.transact_get.items"            // histogram
+	daxWorkloadTransactWriteItems     = "dax.workload.transact_write.items"          // histogram
+	daxWorkloadQueryPageItems         = "dax.workload.query.page_items"              // histogram
+	daxWorkloadScanPageItems          = "dax.workload.scan.page_items"               // histogram
+)
+
+// int64Meter is the part of metrics.Meter the client creates its instruments with.
+type int64Meter interface {
+	Int64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Int64Counter, error)
+	Int64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error)
+	Int64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Int64Gauge, error)
+}
+
+// The instruments the client records its measurements with. They take the attributes
+// of the client rather than smithy-go record options, so that the smithy-go metrics
+// interfaces are only used by the adapters at the end of this file and a change of those
+// interfaces between smithy-go versions doesn't spread through the client.
+type (
+	int64Counter interface {
+		add(ctx context.Context, v int64, attrs []metricAttr)
+	}
+	int64Histogram interface {
+		record(ctx context.Context, v int64, attrs []metricAttr)
+	}
+	int64Gauge interface {
+		sample(ctx context.Context, v int64, attrs []metricAttr)
+	}
 )
 
 type daxSdkMetrics struct {
-	counters   map[string]metrics.Int64Counter
-	histograms map[string]metrics.Int64Histogram
-	gauges     map[string]metrics.Int64Gauge
+	counters   map[string]int64Counter
+	histograms map[string]int64Histogram
+	gauges     map[string]int64Gauge
 }
 
-func (m *daxSdkMetrics) counterFor(name string) metrics.Int64Counter {
+func (m *daxSdkMetrics) counterFor(name string) int64Counter {
 	return m.counters[name]
 }
 
-func (m *daxSdkMetrics) histogramFor(name string) metrics.Int64Histogram {
+func (m *daxSdkMetrics) histogramFor(name string) int64Histogram {
 	return m.histograms[name]
 }
 
-func (m *daxSdkMetrics) gaugeFor(name string) metrics.Int64Gauge {
+func (m *daxSdkMetrics) gaugeFor(name string) int64Gauge {
 	return m.gauges[name]
 }
 
-func buildCounters(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err error) {
+func buildCounters(meter int64Meter, om *daxSdkMetrics, ops []string) (err error) {
 	counters := map[string]string{
 		daxOpNameSuccess:              "Operations %s success",
 		daxOpNameFailure:              "Operations %s failure",
+		daxAuthSuccess:                "Number of successful connection authentications",
+		daxAuthFailure:                "Number of failed connection authentications",
 		daxConnectionsCreated:         "Total amount of created connections",
 		daxConnectionsClosedError:     "Number of closed connections due to errors",
 		daxConnectionsClosedIdle:      "Number of closed connections due to inactivity",
 		daxConnectionsClosedSession:   "Number of closed connections due to poll session change",
+		daxConnectionsBytesSent:       "Number of bytes sent over the connections to the node",
+		daxConnectionsBytesReceived:   "Number of bytes received over the connections to the node",
 		daxRouteManagerRoutesAdded:    "The number of routes added back to the active pool.",
 		daxRouteManagerRoutesRemoved:  "The number of routes removed from the active pool due to problems.",
 		daxRouteManagerFailOpenEvents: `The number of events when the manager enters the "fail-open" state.`,
+		daxClusterRosterMismatches:    "The number of refreshes where two nodes reported different cluster rosters.",
+		daxWorkloadReads:              "The number of read requests.",
+		daxWorkloadWrites:             "The number of write requests.",
+		daxQueryShapesSampled:         "The number of sampled queries and scans with one of the most frequent shapes.",
+		daxHedgeSent:                  "The number of hedged reads sent to a second node.",
+		daxHedgeWon:                   "The number of hedged reads answered first by the second node.",
+		daxRebuildSuccess:             "The number of clients of nodes rebuilt after a failed health check.",
+		daxRebuildFailure:             "The number of failed rebuilds of the clients of nodes.",
+		daxRebuildSkipped:             "The number of failed health checks ignored while a rebuild of the node was pending.",
+		daxDiscoverySuccess:           "The number of successful requests for the cluster nodes.",
+		daxDiscoveryFailure:           "The number of failed requests for the cluster nodes.",
+		daxDiscoverySeedUsed:          "The number of successful discoveries, per seed endpoint.",
 	}
 
 	for name, description := range counters {
@@ -98,9 +174,36 @@ func buildCounters(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err er
 	return
 }
 
-func buildHistograms(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err error) {
+// DefaultLatencyHistogramBuckets are the bucket boundaries, in microseconds, hinted for
+// the latency histograms. DAX hits are commonly served in well under a millisecond, which
+// the default OpenTelemetry boundaries (0, 5, 10, 25, ... 10000) do not resolve when
+// recording microseconds.
+var DefaultLatencyHistogramBuckets = []float64{
+	100, 200, 300, 400, 500, 750, 1000, 1500, 2000, 3000, 5000, 7500,
+	10000, 25000, 50000, 100000, 250000, 500000, 1000000,
+}
+
+// HistogramBucketsMeter is implemented by meters which accept explicit bucket boundaries.
+// Since metrics.InstrumentOptions cannot carry them, the latency histograms are created
+// through this interface when the meter implements it.
+type HistogramBucketsMeter interface {
+	Int64HistogramWithBuckets(name string, buckets []float64, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error)
+}
+
+func validateHistogramBuckets(buckets []float64) error {
+	for i, b := range buckets {
+		if b <= 0 || (i > 0 && b <= buckets[i-1]) {
+			return NewCustomInvalidParamError("ConfigValidation", "LatencyHistogramBuckets must be positive and strictly increasing")
+		}
+	}
+	return nil
+}
+
+func buildHistograms(meter int64Meter, om *daxSdkMetrics, ops []string, buckets []float64) (err error) {
 	histograms := map[string]string{
-		daxOpNameLatencyUs: "Operations %s latency in microseconds",
+		daxOpNameLatencyUs:    "Operations %s latency in microseconds",
+		daxAuthLatencyUs:      "Connection authentication latency in microseconds",
+		daxDiscoveryLatencyUs: "Latency of the requests for the cluster nodes in microseconds",
 	}
 
 	// build histograms
@@ -110,7 +213,7 @@ func buildHistograms(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err
 				metricName := fmt.Sprintf(name, op)
 				metricDescription := fmt.Sprintf(description, op)
 
-				om.histograms[metricName], err = operationHistogram(meter, metricName, metricDescription)
+				om.histograms[metricName], err = operationHistogram(meter, metricName, metricDescription, buckets)
 
 				if err != nil {
 					return
@@ -120,7 +223,7 @@ func buildHistograms(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err
 			continue
 		}
 
-		om.histograms[name], err = operationHistogram(meter, name, description)
+		om.histograms[name], err = operationHistogram(meter, name, description, buckets)
 		if err != nil {
 			return
 		}
@@ -129,10 +232,39 @@ func buildHistograms(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err
 	return
 }
 
-func buildGauges(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err error) {
+// workloadSizeBuckets are the bucket boundaries hinted for the workload size histograms,
+// covering the limits of batch and transaction requests.
+var workloadSizeBuckets = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+func buildSizeHistograms(meter int64Meter, om *daxSdkMetrics) (err error) {
+	histograms := map[string]string{
+		daxWorkloadBatchGetKeys:           "Number of keys per BatchGetItem request",
+		daxWorkloadBatchGetUnprocessed:    "Number of unprocessed keys per BatchGetItem request",
+		daxWorkloadBatchGetUnprocessedPct: "Percentage of the keys of a BatchGetItem request left unprocessed",
+		daxWorkloadBatchGetRounds:         "Number of BatchGetItem rounds needed to fetch a set of keys",
+		daxWorkloadBatchWriteRequests:     "Number of put and delete requests per BatchWriteItem request",
+		daxWorkloadTransactGetItems:       "Number of items per TransactGetItems request",
+		daxWorkloadTransactWriteItems:     "Number of items per TransactWriteItems request",
+		daxWorkloadQueryPageItems:         "Number of items per Query page",
+		daxWorkloadScanPageItems:          "Number of items per Scan page",
+	}
+
+	for name, description := range histograms {
+		om.histograms[name], err = sizeHistogram(meter, name, description)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func buildGauges(meter int64Meter, om *daxSdkMetrics, ops []string) (err error) {
 	gauges := map[string]string{
 		daxConnectionsIdle:              "Current number of inactive connections in the pool",
 		daxConcurrentConnectionAttempts: "Current number of concurrent connection attempts",
+		daxDiscoveryFailureStreak:       "Current number of consecutive failed refreshes of the cluster nodes",
+		daxRebuildsPending:              "Current number of queued or running rebuilds of the clients of nodes",
 	}
 
 	// build gauges
@@ -148,32 +280,29 @@ func buildGauges(meter metrics.Meter, om *daxSdkMetrics, ops []string) (err erro
 }
 
 func buildDaxSdkMetrics(mp metrics.MeterProvider) (*daxSdkMetrics, error) {
+	return buildDaxSdkMetricsWithBuckets(mp, DefaultLatencyHistogramBuckets)
+}
+
+func buildDaxSdkMetricsWithBuckets(mp metrics.MeterProvider, buckets []float64) (*daxSdkMetrics, error) {
 	meter := mp.Meter(daxMeterScope)
 
 	sdkMetrics := &daxSdkMetrics{
-		counters:   make(map[string]metrics.Int64Counter),
-		histograms: make(map[string]metrics.Int64Histogram),
-		gauges:     make(map[string]metrics.Int64Gauge),
+		counters:   make(map[string]int64Counter),
+		histograms: make(map[string]int64Histogram),
+		gauges:     make(map[string]int64Gauge),
 	}
 
-	ops := []string{
-		OpGetItem,
-		OpPutItem,
-		OpUpdateItem,
-		OpDeleteItem,
-		OpBatchGetItem,
-		OpBatchWriteItem,
-		OpTransactGetItems,
-		OpTransactWriteItems,
-		OpQuery,
-		OpScan,
-	}
+	ops := Operations()
 
 	if err := buildCounters(meter, sdkMetrics, ops); err != nil {
 		return nil, err
 	}
 
-	if err := buildHistograms(meter, sdkMetrics, ops); err != nil {
+	if err := buildHistograms(meter, sdkMetrics, ops, buckets); err != nil {
+		return nil, err
+	}
+
+	if err := buildSizeHistograms(meter, sdkMetrics); err != nil {
 		return nil, err
 	}
 
@@ -184,63 +313,207 @@ func buildDaxSdkMetrics(mp metrics.MeterProvider) (*daxSdkMetrics, error) {
 	return sdkMetrics, nil
 }
 
-func operationCounter(m metrics.Meter, name string, description string) (metrics.Int64Counter, error) {
-	return m.Int64Counter(name, func(o *metrics.InstrumentOptions) {
+func operationCounter(m int64Meter, name string, description string) (int64Counter, error) {
+	return newSmithyCounter(m.Int64Counter(name, func(o *metrics.InstrumentOptions) {
 		o.Description = description
-	})
+	}))
 }
 
-func operationHistogram(m metrics.Meter, name string, description string) (metrics.Int64Histogram, error) {
-	return m.Int64Histogram(name, func(o *metrics.InstrumentOptions) {
+func operationHistogram(m int64Meter, name string, description string, buckets []float64) (int64Histogram, error) {
+	opt := func(o *metrics.InstrumentOptions) {
 		o.UnitLabel = "Microseconds"
 		o.Description = description
-	})
+	}
+	if bm, ok := m.(HistogramBucketsMeter); ok && len(buckets) > 0 {
+		return newSmithyHistogram(bm.Int64HistogramWithBuckets(name, buckets, opt))
+	}
+	return newSmithyHistogram(m.Int64Histogram(name, opt))
+}
+
+func sizeHistogram(m int64Meter, name string, description string) (int64Histogram, error) {
+	opt := func(o *metrics.InstrumentOptions) {
+		o.UnitLabel = "Items"
+		o.Description = description
+	}
+	if bm, ok := m.(HistogramBucketsMeter); ok {
+		return newSmithyHistogram(bm.Int64HistogramWithBuckets(name, workloadSizeBuckets, opt))
+	}
+	return newSmithyHistogram(m.Int64Histogram(name, opt))
 }
 
-func operationGauge(m metrics.Meter, name string, description string) (metrics.Int64Gauge, error) {
-	return m.Int64Gauge(name, func(o *metrics.InstrumentOptions) {
+func operationGauge(m int64Meter, name string, description string) (int64Gauge, error) {
+	return newSmithyGauge(m.Int64Gauge(name, func(o *metrics.InstrumentOptions) {
 		o.Description = description
-	})
+	}))
 }
 
 type metricFunction[T any] func() (T, error)
 
-func countMetricInt64(ctx context.Context, om *daxSdkMetrics, name string, v int64) {
+// Attributes attached to the emitted measurements, so that dashboards can break the
+// metrics down per node, operation or kind of failure.
+const (
+	metricAttrEndpoint   = "endpoint"   // host:port of the node
+	metricAttrOperation  = "operation"  // DynamoDB operation name
+	metricAttrErrorType  = "error_type" // errorClass of a failed request
+	metricAttrTable      = "table"
+	metricAttrQueryShape = "query_shape" // QueryShape.ID
+	metricAttrSeed       = "seed"        // host:port of the endpoint the nodes were discovered through
+)
+
+type metricAttr struct {
+	key   string
+	value string
+}
+
+func endpointAttr(endpoint string) metricAttr {
+	return metricAttr{key: metricAttrEndpoint, value: endpoint}
+}
+
+func operationAttr(op string) metricAttr {
+	return metricAttr{key: metricAttrOperation, value: op}
+}
+
+func tableAttr(table string) metricAttr {
+	return metricAttr{key: metricAttrTable, value: table}
+}
+
+func queryShapeAttr(id string) metricAttr {
+	return metricAttr{key: metricAttrQueryShape, value: id}
+}
+
+func seedAttr(seed hostPort) metricAttr {
+	return metricAttr{key: metricAttrSeed, value: seed.String()}
+}
+
+func errorTypeAttr(err error) metricAttr {
+	return metricAttr{key: metricAttrErrorType, value: errorClass(err)}
+}
+
+// recordOptions returns the options attaching attrs to a measurement.
+func recordOptions(attrs []metricAttr) []metrics.RecordMetricOption {
+	if len(attrs) == 0 {
+		return nil
+	}
+	return []metrics.RecordMetricOption{func(o *metrics.RecordMetricOptions) {
+		for _, a := range attrs {
+			o.Properties.Set(a.key, a.value)
+		}
+	}}
+}
+
+// errorClass returns a coarse, low cardinality classification of err suitable as a
+// metric attribute.
+func errorClass(err error) string {
+	var ae smithy.APIError
+	var ne net.Error
+	switch {
+	case err == nil:
+		return ""
+	case IsThrottleError(err):
+		return "throttling"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrFirstByteTimeout):
+		return "timeout"
+	case errors.As(err, &ae) && ae.ErrorFault() == smithy.FaultClient:
+		return "client"
+	case errors.As(err, &ae) && ae.ErrorFault() == smithy.FaultServer:
+		return "server"
+	case errors.As(err, &ne), isIOError(err):
+		return "network"
+	default:
+		return "unknown"
+	}
+}
+
+func countMetricInt64(ctx context.Context, om *daxSdkMetrics, name string, v int64, attrs ...metricAttr) {
 	c := om.counterFor(name)
 
 	if c == nil {
 		return
 	}
 
-	c.Add(ctx, v)
+	c.add(ctx, v, attrs)
 }
 
-func gaugeInt64(ctx context.Context, om *daxSdkMetrics, name string, v int64) {
+func gaugeInt64(ctx context.Context, om *daxSdkMetrics, name string, v int64, attrs ...metricAttr) {
 	g := om.gaugeFor(name)
 
 	if g == nil {
 		return
 	}
 
-	g.Sample(ctx, v)
+	g.sample(ctx, v, attrs)
+}
+
+func histogramInt64(ctx context.Context, om *daxSdkMetrics, name string, v int64, attrs ...metricAttr) {
+	h := om.histogramFor(name)
+
+	if h == nil {
+		return
+	}
+
+	h.record(ctx, v, attrs)
 }
 
-func histogramMicrosecondsInt64(ctx context.Context, om *daxSdkMetrics, name string, t time.Time) {
+func histogramMicrosecondsInt64(ctx context.Context, om *daxSdkMetrics, name string, t time.Time, attrs ...metricAttr) {
 	h := om.histogramFor(name)
 
 	if h == nil {
 		return
 	}
 
-	h.Record(ctx, time.Since(t).Microseconds())
+	h.record(ctx, time.Since(t).Microseconds(), attrs)
 }
 
-func withMicrosecondHistogramInt64[T any](ctx context.Context, om *daxSdkMetrics, name string, fn metricFunction[T]) (T, error) {
+func withMicrosecondHistogramInt64[T any](ctx context.Context, om *daxSdkMetrics, name string, fn metricFunction[T], attrs ...metricAttr) (T, error) {
 	startTime := time.Now()
 
 	out, err := fn()
 
-	histogramMicrosecondsInt64(ctx, om, name, startTime)
+	histogramMicrosecondsInt64(ctx, om, name, startTime, attrs...)
 
 	return out, err
 }
+
+// smithyCounter, smithyHistogram and smithyGauge adapt the instruments of a smithy-go
+// meter. A meter returning nil instruments, such as one written against a version of
+// smithy-go without some of them, leaves the matching metrics unrecorded.
+type smithyCounter struct{ metrics.Int64Counter }
+
+func newSmithyCounter(c metrics.Int64Counter, err error) (int64Counter, error) {
+	if c == nil {
+		return nil, err
+	}
+	return smithyCounter{c}, err
+}
+
+func (c smithyCounter) add(ctx context.Context, v int64, attrs []metricAttr) {
+	c.Add(ctx, v, recordOptions(attrs)...)
+}
+
+type smithyHistogram struct{ metrics.Int64Histogram }
+
+func newSmithyHistogram(h metrics.Int64Histogram, err error) (int64Histogram, error) {
+	if h == nil {
+		return nil, err
+	}
+	return smithyHistogram{h}, err
+}
+
+func (h smithyHistogram) record(ctx context.Context, v int64, attrs []metricAttr) {
+	h.Record(ctx, v, recordOptions(attrs)...)
+}
+
+type smithyGauge struct{ metrics.Int64Gauge }
+
+func newSmithyGauge(g metrics.Int64Gauge, err error) (int64Gauge, error) {
+	if g == nil {
+		return nil, err
+	}
+	return smithyGauge{g}, err
+}
+
+func (g smithyGauge) sample(ctx context.Context, v int64, attrs []metricAttr) {
+	g.Sample(ctx, v, recordOptions(attrs)...)
+}
@@ -17,11 +17,18 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCountMetricInt64(t *testing.T) {
@@ -55,6 +62,43 @@ func TestCountMetricInt64(t *testing.T) {
 	}
 }
 
+func TestCountMetricInt64_attributes(t *testing.T) {
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	name := fmt.Sprintf(daxOpNameFailure, OpGetItem)
+	err := newDaxRequestFailure([]int{4, 23, 24}, "ThrottlingException", "", "", 400, smithy.FaultClient)
+
+	countMetricInt64(context.TODO(), om, name, 1, endpointAttr("127.0.0.1:8111"), operationAttr(OpGetItem), errorTypeAttr(err))
+	countMetricInt64(context.TODO(), om, name, 1)
+
+	i, _ := testInstrumentOf(om.counters[name])
+	if assert.Len(t, i.props, 2) {
+		assert.Equal(t, "127.0.0.1:8111", i.props[0].Get(metricAttrEndpoint))
+		assert.Equal(t, OpGetItem, i.props[0].Get(metricAttrOperation))
+		assert.Equal(t, "throttling", i.props[0].Get(metricAttrErrorType))
+		assert.Empty(t, i.props[1].Values())
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ""},
+		{newDaxRequestFailure([]int{4, 23, 24}, "ThrottlingException", "", "", 400, smithy.FaultClient), "throttling"},
+		{newDaxRequestFailure([]int{4, 37, 38, 39, 43}, "ValidationException", "", "", 400, smithy.FaultClient), "client"},
+		{newDaxRequestFailure([]int{1}, "InternalServerError", "", "", 500, smithy.FaultServer), "server"},
+		{fmt.Errorf("request: %w", context.Canceled), "canceled"},
+		{context.DeadlineExceeded, "timeout"},
+		{&firstByteTimeoutError{op: OpGetItem}, "timeout"},
+		{io.EOF, "network"},
+		{errors.New("boom"), "unknown"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, errorClass(c.err), fmt.Sprint(c.err))
+	}
+}
+
 func TestGaugeInt64(t *testing.T) {
 	mp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(mp)
@@ -223,3 +267,96 @@ func BenchmarkHistogramMicrosecondsInt64Test(b *testing.B) {
 		histogramMicrosecondsInt64(ctx, om, name, startTime)
 	}
 }
+
+type bucketsMeter struct {
+	testMeter
+	buckets map[string][]float64
+}
+
+func (m *bucketsMeter) Int64HistogramWithBuckets(name string, buckets []float64, _ ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	m.buckets[name] = buckets
+	return m.instrumentInt64(name), nil
+}
+
+type bucketsMeterProvider struct {
+	meter *bucketsMeter
+}
+
+func (p *bucketsMeterProvider) Meter(string, ...metrics.MeterOption) metrics.Meter {
+	return p.meter
+}
+
+func TestLatencyHistogramBuckets(t *testing.T) {
+	m := &bucketsMeter{buckets: map[string][]float64{}}
+	_, err := buildDaxSdkMetricsWithBuckets(&bucketsMeterProvider{meter: m}, []float64{10, 20})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 20}, m.buckets[fmt.Sprintf(daxOpNameLatencyUs, OpGetItem)])
+	assert.Equal(t, []float64{10, 20}, m.buckets[daxAuthLatencyUs])
+	assert.Equal(t, workloadSizeBuckets, m.buckets[daxWorkloadQueryPageItems])
+	assert.Equal(t, []float64{10, 20}, m.buckets[daxDiscoveryLatencyUs])
+	assert.Len(t, m.buckets, 21)
+
+	assert.NoError(t, validateHistogramBuckets(DefaultLatencyHistogramBuckets))
+	assert.NoError(t, validateHistogramBuckets(nil))
+	assert.Error(t, validateHistogramBuckets([]float64{10, 10}))
+	assert.Error(t, validateHistogramBuckets([]float64{0, 10}))
+}
+
+// nilGaugeMeter returns no gauges, as a meter written against a version of smithy-go
+// without them would.
+type nilGaugeMeter struct {
+	testMeter
+}
+
+func (m *nilGaugeMeter) Int64Gauge(string, ...metrics.InstrumentOption) (metrics.Int64Gauge, error) {
+	return nil, nil
+}
+
+type nilGaugeMeterProvider struct {
+	meter *nilGaugeMeter
+}
+
+func (p *nilGaugeMeterProvider) Meter(string, ...metrics.MeterOption) metrics.Meter {
+	return p.meter
+}
+
+func TestMetrics_nilInstruments(t *testing.T) {
+	om, err := buildDaxSdkMetrics(&nilGaugeMeterProvider{meter: &nilGaugeMeter{}})
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		gaugeInt64(context.TODO(), om, daxConnectionsIdle, 3, endpointAttr("127.0.0.1:8111"))
+	})
+	countMetricInt64(context.TODO(), om, daxConnectionsCreated, 1)
+	expectCounters(t, om, map[string]int{daxConnectionsCreated: 1})
+}
+
+func TestDiscoveryMetrics(t *testing.T) {
+	mp := &testMeterProvider{}
+	om, err := buildDaxSdkMetrics(mp)
+	require.NoError(t, err)
+	client, err := newSingleClientWithOptions("127.0.0.1:9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, om)
+	require.NoError(t, err)
+	defer client.Close()
+
+	noop := func(*cbor.Writer) error { return nil }
+	require.NoError(t, client.executeWithContext(context.Background(), opEndpoints, noop, func(*cbor.Reader) error { return nil }, RequestOptions{}))
+	tm := mp.meters[daxMeterScope].(*testMeter)
+	assert.Equal(t, []int64{1}, tm.i64s[daxDiscoverySuccess].data)
+	assert.Len(t, tm.i64s[daxDiscoveryLatencyUs].data, 1)
+	assert.Empty(t, tm.i64s[fmt.Sprintf(daxOpNameSuccess, OpGetItem)].data, "data path metrics are not affected")
+
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.MeterProvider = mp
+	cluster, _ := newTestClusterWithConfig(cfg)
+	defer cluster.Close()
+	setExpectation(cluster, []serviceEndpoint{{hostname: "localhost", port: 8121}})
+	require.NoError(t, cluster.refresh(false))
+	seeds := tm.i64s[daxDiscoverySeedUsed]
+	assert.Equal(t, []int64{1}, seeds.data)
+	assert.Equal(t, "127.0.0.1:8111", seeds.props[0].Get(metricAttrSeed))
+}
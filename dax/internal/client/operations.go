@@ -0,0 +1,58 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+var readOperations = map[string]bool{
+	OpGetItem:          true,
+	OpBatchGetItem:     true,
+	OpTransactGetItems: true,
+	OpQuery:            true,
+	OpScan:             true,
+}
+
+var writeOperations = map[string]bool{
+	OpPutItem:            true,
+	OpUpdateItem:         true,
+	OpDeleteItem:         true,
+	OpBatchWriteItem:     true,
+	OpTransactWriteItems: true,
+}
+
+// Operations returns the names of the data plane operations supported by the client.
+func Operations() []string {
+	return []string{
+		OpGetItem,
+		OpPutItem,
+		OpUpdateItem,
+		OpDeleteItem,
+		OpBatchGetItem,
+		OpBatchWriteItem,
+		OpTransactGetItems,
+		OpTransactWriteItems,
+		OpQuery,
+		OpScan,
+	}
+}
+
+// IsReadOperation reports whether op is a data plane operation which does not modify items.
+func IsReadOperation(op string) bool {
+	return readOperations[op]
+}
+
+// IsWriteOperation reports whether op is a data plane operation which modifies items.
+func IsWriteOperation(op string) bool {
+	return writeOperations[op]
+}
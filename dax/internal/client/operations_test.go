@@ -0,0 +1,33 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationClassification(t *testing.T) {
+	for _, op := range Operations() {
+		assert.NotEqual(t, IsReadOperation(op), IsWriteOperation(op), op)
+	}
+	assert.True(t, IsReadOperation(OpTransactGetItems))
+	assert.True(t, IsWriteOperation(OpBatchWriteItem))
+	assert.False(t, IsReadOperation(opEndpoints))
+	assert.False(t, IsWriteOperation(opEndpoints))
+	assert.False(t, IsReadOperation("getitem"))
+}
@@ -0,0 +1,1441 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// DAX has no PartiQL operation, so statements are translated on the client into the
+// equivalent GetItem, Query, Scan, PutItem, UpdateItem or DeleteItem request, which are
+// cached like any other request of these kinds. The supported subset is:
+//
+//	SELECT * | path, ... FROM "table"[."index"] [WHERE condition] [ORDER BY key [ASC|DESC]]
+//	INSERT INTO "table" VALUE {'attr': value, ...}
+//	UPDATE "table" SET path = value [, ...] [REMOVE path, ...] WHERE key = value AND ... [RETURNING ...]
+//	DELETE FROM "table" WHERE key = value AND ... [RETURNING ALL OLD *]
+//
+// A SELECT with equality conditions on the whole primary key becomes a GetItem, one with
+// an equality condition on the partition key a Query and any other a Scan. Selects from
+// an index are always translated into a Scan of the index, as the key schema of indexes
+// is not known to the client.
+
+const (
+	// maxBatchStatements is the maximum number of statements of a BatchExecuteStatement.
+	maxBatchStatements = 25
+	// maxTransactionStatements is the maximum number of statements of an ExecuteTransaction.
+	maxTransactionStatements = 100
+)
+
+type statementKind int
+
+const (
+	statementSelect statementKind = iota
+	statementInsert
+	statementUpdate
+	statementDelete
+)
+
+type pqTokenKind int
+
+const (
+	pqIdent  pqTokenKind = iota // bare identifier or keyword
+	pqQuoted                    // "quoted identifier"
+	pqString                    // 'string literal'
+	pqNumber
+	pqParam
+	pqPunct
+)
+
+type pqToken struct {
+	kind  pqTokenKind
+	text  string
+	param int
+}
+
+func (t pqToken) is(s string) bool {
+	switch t.kind {
+	case pqIdent:
+		return strings.EqualFold(t.text, s)
+	case pqPunct:
+		return t.text == s
+	}
+	return false
+}
+
+func (t pqToken) isName() bool {
+	return t.kind == pqIdent || t.kind == pqQuoted
+}
+
+type statement struct {
+	kind       statementKind
+	table      string
+	index      string
+	projection [][]pqToken
+	where      []pqToken
+	orderBy    string
+	descending bool
+	item       []pqToken
+	set        [][]pqToken
+	remove     [][]pqToken
+	returning  types.ReturnValue
+	params     int
+}
+
+func statementError(format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{
+		Code:    ErrCodeValidationException,
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// StatementTarget returns the table a PartiQL statement operates on and whether it is a
+// SELECT. The table is empty if the statement cannot be parsed.
+func StatementTarget(statement string) (table string, read bool) {
+	st, err := parseStatement(statement)
+	if err != nil {
+		return "", false
+	}
+	return st.table, st.kind == statementSelect
+}
+
+func lexStatement(s string) ([]pqToken, error) {
+	var toks []pqToken
+	params := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for {
+				if j >= len(s) {
+					return nil, statementError("Unterminated %c at position %d", c, i)
+				}
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						sb.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(s[j])
+				j++
+			}
+			kind := pqString
+			if c == '"' {
+				kind = pqQuoted
+			}
+			toks = append(toks, pqToken{kind: kind, text: sb.String()})
+			i = j + 1
+		case c == '?':
+			toks = append(toks, pqToken{kind: pqParam, text: "?", param: params})
+			params++
+			i++
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(s) && (isDigit(s[j]) || s[j] == '.' || s[j] == 'e' || s[j] == 'E' ||
+				((s[j] == '+' || s[j] == '-') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			toks = append(toks, pqToken{kind: pqNumber, text: s[i:j]})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && (isIdentStart(s[j]) || isDigit(s[j])) {
+				j++
+			}
+			toks = append(toks, pqToken{kind: pqIdent, text: s[i:j]})
+			i = j
+		default:
+			if i+1 < len(s) {
+				switch op := s[i : i+2]; op {
+				case "<=", ">=", "<>", "!=", "<<", ">>":
+					toks = append(toks, pqToken{kind: pqPunct, text: op})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("=<>(),[]{}.:*+-", rune(c)) {
+				return nil, statementError("Unexpected character %q at position %d", c, i)
+			}
+			toks = append(toks, pqToken{kind: pqPunct, text: string(c)})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+type statementParser struct {
+	toks []pqToken
+	pos  int
+}
+
+func (p *statementParser) more() bool {
+	return p.pos < len(p.toks)
+}
+
+func (p *statementParser) accept(s string) bool {
+	if p.more() && p.toks[p.pos].is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *statementParser) expect(s string) error {
+	if !p.accept(s) {
+		return p.unexpected(s)
+	}
+	return nil
+}
+
+func (p *statementParser) unexpected(want string) error {
+	if !p.more() {
+		return statementError("Unexpected end of statement, expected %s", want)
+	}
+	return statementError("Unexpected token %q, expected %s", p.toks[p.pos].text, want)
+}
+
+func (p *statementParser) name() (string, error) {
+	if !p.more() || !p.toks[p.pos].isName() {
+		return "", p.unexpected("a name")
+	}
+	p.pos++
+	return p.toks[p.pos-1].text, nil
+}
+
+// until returns the tokens up to the first of stop outside of brackets.
+func (p *statementParser) until(stop ...string) []pqToken {
+	start, depth := p.pos, 0
+	for ; p.more(); p.pos++ {
+		t := p.toks[p.pos]
+		if depth == 0 && t.kind != pqQuoted && t.kind != pqString {
+			for _, s := range stop {
+				if t.is(s) {
+					return p.toks[start:p.pos]
+				}
+			}
+		}
+		depth += bracketDepth(t)
+	}
+	return p.toks[start:p.pos]
+}
+
+func bracketDepth(t pqToken) int {
+	if t.kind != pqPunct {
+		return 0
+	}
+	switch t.text {
+	case "(", "[", "{", "<<":
+		return 1
+	case ")", "]", "}", ">>":
+		return -1
+	}
+	return 0
+}
+
+// splitTokens splits toks at sep outside of brackets.
+func splitTokens(toks []pqToken, sep string) [][]pqToken {
+	var parts [][]pqToken
+	start, depth := 0, 0
+	for i, t := range toks {
+		if depth == 0 && t.is(sep) {
+			parts = append(parts, toks[start:i])
+			start = i + 1
+		}
+		depth += bracketDepth(t)
+	}
+	return append(parts, toks[start:])
+}
+
+// splitConjuncts splits a condition into the operands of its top level AND, keeping
+// the AND of BETWEEN. A condition with a top level OR is a single conjunct.
+func splitConjuncts(toks []pqToken) [][]pqToken {
+	if len(toks) == 0 {
+		return nil
+	}
+	var parts [][]pqToken
+	start, depth, between := 0, 0, false
+	for i, t := range toks {
+		if depth == 0 {
+			switch {
+			case t.is("OR"):
+				return [][]pqToken{toks}
+			case t.is("BETWEEN"):
+				between = true
+			case t.is("AND") && between:
+				between = false
+			case t.is("AND"):
+				parts = append(parts, toks[start:i])
+				start = i + 1
+			}
+		}
+		depth += bracketDepth(t)
+	}
+	return append(parts, toks[start:])
+}
+
+func parseStatement(s string) (*statement, error) {
+	toks, err := lexStatement(s)
+	if err != nil {
+		return nil, err
+	}
+	st := &statement{}
+	for _, t := range toks {
+		if t.kind == pqParam {
+			st.params++
+		}
+	}
+
+	p := &statementParser{toks: toks}
+	switch {
+	case p.accept("SELECT"):
+		err = p.parseSelect(st)
+	case p.accept("INSERT"):
+		err = p.parseInsert(st)
+	case p.accept("UPDATE"):
+		err = p.parseUpdate(st)
+	case p.accept("DELETE"):
+		err = p.parseDelete(st)
+	default:
+		err = p.unexpected("SELECT, INSERT, UPDATE or DELETE")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.more() {
+		return nil, p.unexpected("end of statement")
+	}
+	return st, nil
+}
+
+func (p *statementParser) parseSelect(st *statement) error {
+	st.kind = statementSelect
+	if !p.accept("*") {
+		st.projection = splitTokens(p.until("FROM"), ",")
+	}
+	if err := p.expect("FROM"); err != nil {
+		return err
+	}
+	var err error
+	if st.table, err = p.name(); err != nil {
+		return err
+	}
+	if p.accept(".") {
+		if st.index, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if p.accept("WHERE") {
+		st.where = p.until("ORDER")
+	}
+	if p.accept("ORDER") {
+		if err = p.expect("BY"); err != nil {
+			return err
+		}
+		if st.orderBy, err = p.name(); err != nil {
+			return err
+		}
+		if !p.accept("ASC") {
+			st.descending = p.accept("DESC")
+		}
+	}
+	return nil
+}
+
+func (p *statementParser) parseInsert(st *statement) error {
+	st.kind = statementInsert
+	if err := p.expect("INTO"); err != nil {
+		return err
+	}
+	var err error
+	if st.table, err = p.name(); err != nil {
+		return err
+	}
+	if err = p.expect("VALUE"); err != nil {
+		return err
+	}
+	st.item = p.until()
+	return nil
+}
+
+func (p *statementParser) parseUpdate(st *statement) error {
+	st.kind = statementUpdate
+	var err error
+	if st.table, err = p.name(); err != nil {
+		return err
+	}
+	for {
+		switch {
+		case p.accept("SET"):
+			st.set = append(st.set, splitTokens(p.until("SET", "REMOVE", "WHERE"), ",")...)
+			continue
+		case p.accept("REMOVE"):
+			st.remove = append(st.remove, splitTokens(p.until("SET", "REMOVE", "WHERE"), ",")...)
+			continue
+		}
+		break
+	}
+	if len(st.set) == 0 && len(st.remove) == 0 {
+		return p.unexpected("SET or REMOVE")
+	}
+	if err = p.expect("WHERE"); err != nil {
+		return err
+	}
+	st.where = p.until("RETURNING")
+	return p.parseReturning(st)
+}
+
+func (p *statementParser) parseDelete(st *statement) error {
+	st.kind = statementDelete
+	if err := p.expect("FROM"); err != nil {
+		return err
+	}
+	var err error
+	if st.table, err = p.name(); err != nil {
+		return err
+	}
+	if err = p.expect("WHERE"); err != nil {
+		return err
+	}
+	st.where = p.until("RETURNING")
+	return p.parseReturning(st)
+}
+
+func (p *statementParser) parseReturning(st *statement) error {
+	if !p.accept("RETURNING") {
+		return nil
+	}
+	var which string
+	switch {
+	case p.accept("ALL"):
+		which = "ALL"
+	case p.accept("MODIFIED"):
+		which = "UPDATED"
+	default:
+		return p.unexpected("ALL or MODIFIED")
+	}
+	switch {
+	case p.accept("OLD"):
+		st.returning = types.ReturnValue(which + "_OLD")
+	case p.accept("NEW"):
+		st.returning = types.ReturnValue(which + "_NEW")
+	default:
+		return p.unexpected("OLD or NEW")
+	}
+	if st.kind == statementDelete && st.returning != types.ReturnValueAllOld {
+		return statementError("DELETE only supports RETURNING ALL OLD *")
+	}
+	return p.expect("*")
+}
+
+var conditionKeywords = map[string]bool{
+	"AND":     true,
+	"OR":      true,
+	"NOT":     true,
+	"BETWEEN": true,
+}
+
+var expressionFunctions = map[string]bool{
+	"attribute_exists":     true,
+	"attribute_not_exists": true,
+	"attribute_type":       true,
+	"begins_with":          true,
+	"contains":             true,
+	"size":                 true,
+	"list_append":          true,
+	"if_not_exists":        true,
+}
+
+var expressionOperators = map[string]bool{
+	"=":  true,
+	"<>": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+	"(":  true,
+	")":  true,
+	",":  true,
+	"+":  true,
+	"-":  true,
+}
+
+// exprBuilder rewrites PartiQL conditions into expressions, substituting placeholders
+// for all attribute names and values.
+type exprBuilder struct {
+	params []types.AttributeValue
+	names  map[string]string
+	ids    map[string]string
+	values map[string]types.AttributeValue
+}
+
+func newExprBuilder(params []types.AttributeValue) *exprBuilder {
+	return &exprBuilder{
+		params: params,
+		names:  map[string]string{},
+		ids:    map[string]string{},
+		values: map[string]types.AttributeValue{},
+	}
+}
+
+func (b *exprBuilder) name(n string) string {
+	if id, ok := b.ids[n]; ok {
+		return id
+	}
+	id := fmt.Sprintf("#s%d", len(b.names))
+	b.names[id] = n
+	b.ids[n] = id
+	return id
+}
+
+func (b *exprBuilder) value(av types.AttributeValue) string {
+	id := fmt.Sprintf(":s%d", len(b.values))
+	b.values[id] = av
+	return id
+}
+
+func (b *exprBuilder) attributeNames() map[string]string {
+	if len(b.names) == 0 {
+		return nil
+	}
+	return b.names
+}
+
+func (b *exprBuilder) attributeValues() map[string]types.AttributeValue {
+	if len(b.values) == 0 {
+		return nil
+	}
+	return b.values
+}
+
+func (b *exprBuilder) path(toks []pqToken, i int) (string, int) {
+	var sb strings.Builder
+	sb.WriteString(b.name(toks[i].text))
+	for i++; i < len(toks); {
+		if toks[i].is(".") && i+1 < len(toks) && toks[i+1].isName() {
+			sb.WriteString("." + b.name(toks[i+1].text))
+			i += 2
+			continue
+		}
+		if toks[i].is("[") && i+2 < len(toks) && toks[i+1].kind == pqNumber && toks[i+2].is("]") {
+			sb.WriteString("[" + toks[i+1].text + "]")
+			i += 3
+			continue
+		}
+		break
+	}
+	return sb.String(), i
+}
+
+// fullPath rewrites toks which must consist of a single path.
+func (b *exprBuilder) fullPath(toks []pqToken) (string, error) {
+	if len(toks) == 0 || !toks[0].isName() {
+		return "", statementError("Expected a path")
+	}
+	p, i := b.path(toks, 0)
+	if i != len(toks) {
+		return "", statementError("Unexpected token %q in path", toks[i].text)
+	}
+	return p, nil
+}
+
+func isOperand(t pqToken) bool {
+	switch t.kind {
+	case pqIdent:
+		return !conditionKeywords[strings.ToUpper(t.text)] && !t.is("IN")
+	case pqPunct:
+		return t.text == ")" || t.text == "]" || t.text == "}" || t.text == ">>"
+	}
+	return true
+}
+
+func isValueStart(toks []pqToken, i int) bool {
+	t := toks[i]
+	switch t.kind {
+	case pqString, pqNumber, pqParam:
+		return true
+	case pqIdent:
+		return t.is("TRUE") || t.is("FALSE") || t.is("NULL")
+	case pqPunct:
+		switch t.text {
+		case "{", "[", "<<":
+			return true
+		case "-":
+			return i+1 < len(toks) && toks[i+1].kind == pqNumber && (i == 0 || !isOperand(toks[i-1]))
+		}
+	}
+	return false
+}
+
+// parseValue parses the literal or parameter starting at toks[i].
+func (b *exprBuilder) parseValue(toks []pqToken, i int) (types.AttributeValue, int, error) {
+	if i >= len(toks) {
+		return nil, i, statementError("Unexpected end of statement, expected a value")
+	}
+	t := toks[i]
+	switch {
+	case t.kind == pqParam:
+		return b.params[t.param], i + 1, nil
+	case t.kind == pqString:
+		return &types.AttributeValueMemberS{Value: t.text}, i + 1, nil
+	case t.kind == pqNumber:
+		return &types.AttributeValueMemberN{Value: t.text}, i + 1, nil
+	case t.is("-") && i+1 < len(toks) && toks[i+1].kind == pqNumber:
+		return &types.AttributeValueMemberN{Value: "-" + toks[i+1].text}, i + 2, nil
+	case t.is("TRUE"), t.is("FALSE"):
+		return &types.AttributeValueMemberBOOL{Value: t.is("TRUE")}, i + 1, nil
+	case t.is("NULL"):
+		return &types.AttributeValueMemberNULL{Value: true}, i + 1, nil
+	case t.is("{"):
+		m := map[string]types.AttributeValue{}
+		for i++; i < len(toks) && !toks[i].is("}"); {
+			if toks[i].kind != pqString || i+1 >= len(toks) || !toks[i+1].is(":") {
+				return nil, i, statementError("Expected 'name': value in tuple")
+			}
+			v, j, err := b.parseValue(toks, i+2)
+			if err != nil {
+				return nil, j, err
+			}
+			m[toks[i].text] = v
+			if i = j; i < len(toks) && toks[i].is(",") {
+				i++
+			}
+		}
+		if i >= len(toks) {
+			return nil, i, statementError("Unterminated tuple")
+		}
+		return &types.AttributeValueMemberM{Value: m}, i + 1, nil
+	case t.is("["), t.is("<<"):
+		end := "]"
+		if t.is("<<") {
+			end = ">>"
+		}
+		var l []types.AttributeValue
+		for i++; i < len(toks) && !toks[i].is(end); {
+			v, j, err := b.parseValue(toks, i)
+			if err != nil {
+				return nil, j, err
+			}
+			l = append(l, v)
+			if i = j; i < len(toks) && toks[i].is(",") {
+				i++
+			}
+		}
+		if i >= len(toks) {
+			return nil, i, statementError("Unterminated %s", t.text)
+		}
+		if end == "]" {
+			return &types.AttributeValueMemberL{Value: l}, i + 1, nil
+		}
+		set, err := setValue(l)
+		return set, i + 1, err
+	}
+	return nil, i, statementError("Unexpected token %q, expected a value", t.text)
+}
+
+// setValue converts the elements of a PartiQL bag into a string, number or binary set.
+func setValue(l []types.AttributeValue) (types.AttributeValue, error) {
+	var ss, ns []string
+	var bs [][]byte
+	for _, v := range l {
+		switch e := v.(type) {
+		case *types.AttributeValueMemberS:
+			ss = append(ss, e.Value)
+		case *types.AttributeValueMemberN:
+			ns = append(ns, e.Value)
+		case *types.AttributeValueMemberB:
+			bs = append(bs, e.Value)
+		default:
+			return nil, statementError("Sets can only contain strings, numbers or binary values")
+		}
+	}
+	switch {
+	case len(ss) == len(l) && len(l) > 0:
+		return &types.AttributeValueMemberSS{Value: ss}, nil
+	case len(ns) == len(l) && len(l) > 0:
+		return &types.AttributeValueMemberNS{Value: ns}, nil
+	case len(bs) == len(l) && len(l) > 0:
+		return &types.AttributeValueMemberBS{Value: bs}, nil
+	}
+	return nil, statementError("Sets must be non-empty and contain elements of a single type")
+}
+
+// expression rewrites a PartiQL condition or update value into expression syntax.
+func (b *exprBuilder) expression(toks []pqToken) (string, error) {
+	if len(toks) == 0 {
+		return "", statementError("Expected an expression")
+	}
+	var out []string
+	for i := 0; i < len(toks); {
+		t := toks[i]
+		switch {
+		case t.kind == pqIdent && conditionKeywords[strings.ToUpper(t.text)]:
+			out = append(out, strings.ToUpper(t.text))
+			i++
+		case t.is("IN") && i+1 < len(toks) && toks[i+1].is("["):
+			var vals []string
+			for i += 2; i < len(toks) && !toks[i].is("]"); {
+				v, j, err := b.parseValue(toks, i)
+				if err != nil {
+					return "", err
+				}
+				vals = append(vals, b.value(v))
+				if i = j; i < len(toks) && toks[i].is(",") {
+					i++
+				}
+			}
+			if i >= len(toks) {
+				return "", statementError("Unterminated IN list")
+			}
+			out = append(out, "IN", "("+strings.Join(vals, ", ")+")")
+			i++
+		case t.is("IS"):
+			// path IS [NOT] MISSING
+			j := i + 1
+			not := j < len(toks) && toks[j].is("NOT")
+			if not {
+				j++
+			}
+			if len(out) == 0 || j >= len(toks) || !toks[j].is("MISSING") {
+				return "", statementError("Only IS MISSING and IS NOT MISSING are supported")
+			}
+			fn := "attribute_not_exists"
+			if not {
+				fn = "attribute_exists"
+			}
+			out[len(out)-1] = fn + "(" + out[len(out)-1] + ")"
+			i = j + 1
+		case t.kind == pqIdent && i+1 < len(toks) && toks[i+1].is("("):
+			if !expressionFunctions[strings.ToLower(t.text)] {
+				return "", statementError("Unsupported function %s", t.text)
+			}
+			out = append(out, strings.ToLower(t.text))
+			i++
+		case isValueStart(toks, i):
+			v, j, err := b.parseValue(toks, i)
+			if err != nil {
+				return "", err
+			}
+			out = append(out, b.value(v))
+			i = j
+		case t.isName():
+			var p string
+			p, i = b.path(toks, i)
+			out = append(out, p)
+		case t.kind == pqPunct:
+			op := t.text
+			if op == "!=" {
+				op = "<>"
+			}
+			if !expressionOperators[op] {
+				return "", statementError("Unexpected token %q", t.text)
+			}
+			out = append(out, op)
+			i++
+		default:
+			return "", statementError("Unexpected token %q", t.text)
+		}
+	}
+	return strings.Join(out, " "), nil
+}
+
+// conjunction rewrites the conjuncts into a single condition expression.
+func (b *exprBuilder) conjunction(conjuncts [][]pqToken, extra ...string) (*string, error) {
+	parts := extra
+	for _, c := range conjuncts {
+		e, err := b.expression(c)
+		if err != nil {
+			return nil, err
+		}
+		if len(conjuncts)+len(extra) > 1 {
+			e = "(" + e + ")"
+		}
+		parts = append(parts, e)
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	return aws.String(strings.Join(parts, " AND ")), nil
+}
+
+// keyEquality returns the attribute and value of a conjunct of the form name = value.
+func (b *exprBuilder) keyEquality(c []pqToken) (string, types.AttributeValue, bool) {
+	if len(c) < 3 || !c[1].is("=") {
+		return "", nil, false
+	}
+	name, val := c[0], 2
+	if !name.isName() {
+		name, val = c[len(c)-1], 0
+		if !name.isName() || !c[len(c)-2].is("=") {
+			return "", nil, false
+		}
+	}
+	v, j, err := b.parseValue(c, val)
+	if err != nil || (val == 2 && j != len(c)) || (val == 0 && j != len(c)-2) {
+		return "", nil, false
+	}
+	return name.text, v, true
+}
+
+// splitKey extracts the equality conditions on the key attributes from the conjuncts.
+func (b *exprBuilder) splitKey(conjuncts [][]pqToken, keys []string) (map[string]types.AttributeValue, [][]pqToken) {
+	key := map[string]types.AttributeValue{}
+	var rest [][]pqToken
+	for _, c := range conjuncts {
+		name, v, ok := b.keyEquality(c)
+		if ok && key[name] == nil && containsString(keys, name) {
+			key[name] = v
+			continue
+		}
+		rest = append(rest, c)
+	}
+	return key, rest
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// isSortKeyCondition reports whether c can be used as the sort key condition of a Query.
+func isSortKeyCondition(c []pqToken, sortKey string) bool {
+	if len(c) < 3 {
+		return false
+	}
+	if c[0].isName() && c[0].text == sortKey {
+		switch {
+		case c[1].is("BETWEEN"), c[1].is("<"), c[1].is("<="), c[1].is(">"), c[1].is(">="):
+			return true
+		}
+	}
+	return c[0].is("begins_with") && c[1].is("(") && c[2].isName() && c[2].text == sortKey
+}
+
+func keyNames(keys []types.AttributeDefinition) []string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = aws.ToString(k.AttributeName)
+	}
+	return names
+}
+
+// translate returns the request equivalent to the statement, given the key schema of
+// its table.
+func (st *statement) translate(input *dynamodb.ExecuteStatementInput, keys []types.AttributeDefinition) (interface{}, error) {
+	if st.params != len(input.Parameters) {
+		return nil, statementError("Number of parameters in request and statement don't match.")
+	}
+	if len(keys) == 0 {
+		return nil, statementError("Unknown key schema of table %s", st.table)
+	}
+	names := keyNames(keys)
+	b := newExprBuilder(input.Parameters)
+	switch st.kind {
+	case statementSelect:
+		return st.translateSelect(input, names, b)
+	case statementInsert:
+		item, i, err := b.parseValue(st.item, 0)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := item.(*types.AttributeValueMemberM)
+		if !ok || i != len(st.item) {
+			return nil, statementError("INSERT requires a single tuple value")
+		}
+		return &dynamodb.PutItemInput{
+			TableName:                           aws.String(st.table),
+			Item:                                m.Value,
+			ConditionExpression:                 aws.String("attribute_not_exists(" + b.name(names[0]) + ")"),
+			ExpressionAttributeNames:            b.attributeNames(),
+			ReturnConsumedCapacity:              input.ReturnConsumedCapacity,
+			ReturnValuesOnConditionCheckFailure: input.ReturnValuesOnConditionCheckFailure,
+		}, nil
+	}
+
+	key, rest := b.splitKey(splitConjuncts(st.where), names)
+	if len(key) != len(names) {
+		return nil, statementError("Where clause does not contain a mandatory equality on all key attributes")
+	}
+	if st.kind == statementDelete {
+		cond, err := b.conjunction(rest)
+		if err != nil {
+			return nil, err
+		}
+		return &dynamodb.DeleteItemInput{
+			TableName:                           aws.String(st.table),
+			Key:                                 key,
+			ConditionExpression:                 cond,
+			ExpressionAttributeNames:            b.attributeNames(),
+			ExpressionAttributeValues:           b.attributeValues(),
+			ReturnValues:                        st.returning,
+			ReturnConsumedCapacity:              input.ReturnConsumedCapacity,
+			ReturnValuesOnConditionCheckFailure: input.ReturnValuesOnConditionCheckFailure,
+		}, nil
+	}
+
+	var clauses []string
+	if len(st.set) > 0 {
+		sets := make([]string, len(st.set))
+		for i, s := range st.set {
+			e, err := b.expression(s)
+			if err != nil {
+				return nil, err
+			}
+			sets[i] = e
+		}
+		clauses = append(clauses, "SET "+strings.Join(sets, ", "))
+	}
+	if len(st.remove) > 0 {
+		removes := make([]string, len(st.remove))
+		for i, r := range st.remove {
+			p, err := b.fullPath(r)
+			if err != nil {
+				return nil, err
+			}
+			removes[i] = p
+		}
+		clauses = append(clauses, "REMOVE "+strings.Join(removes, ", "))
+	}
+	// Unlike UpdateItem, an UPDATE statement does not create missing items.
+	cond, err := b.conjunction(rest, "attribute_exists("+b.name(names[0])+")")
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(st.table),
+		Key:                                 key,
+		UpdateExpression:                    aws.String(strings.Join(clauses, " ")),
+		ConditionExpression:                 cond,
+		ExpressionAttributeNames:            b.attributeNames(),
+		ExpressionAttributeValues:           b.attributeValues(),
+		ReturnValues:                        st.returning,
+		ReturnConsumedCapacity:              input.ReturnConsumedCapacity,
+		ReturnValuesOnConditionCheckFailure: input.ReturnValuesOnConditionCheckFailure,
+	}, nil
+}
+
+func (st *statement) translateSelect(input *dynamodb.ExecuteStatementInput, keys []string, b *exprBuilder) (interface{}, error) {
+	var projection *string
+	if len(st.projection) > 0 {
+		paths := make([]string, len(st.projection))
+		for i, p := range st.projection {
+			var err error
+			if paths[i], err = b.fullPath(p); err != nil {
+				return nil, err
+			}
+		}
+		projection = aws.String(strings.Join(paths, ", "))
+	}
+	startKey, err := decodeNextToken(input.NextToken)
+	if err != nil {
+		return nil, err
+	}
+
+	conjuncts := splitConjuncts(st.where)
+	key, rest := b.splitKey(conjuncts, keys)
+	if st.index == "" && key[keys[0]] != nil {
+		if st.orderBy == "" && len(rest) == 0 && len(key) == len(keys) {
+			return &dynamodb.GetItemInput{
+				TableName:                aws.String(st.table),
+				Key:                      key,
+				ConsistentRead:           input.ConsistentRead,
+				ProjectionExpression:     projection,
+				ExpressionAttributeNames: b.attributeNames(),
+				ReturnConsumedCapacity:   input.ReturnConsumedCapacity,
+			}, nil
+		}
+		if st.orderBy != "" && (len(keys) < 2 || st.orderBy != keys[1]) {
+			return nil, statementError("ORDER BY is only supported on the sort key")
+		}
+		keyCond := b.name(keys[0]) + " = " + b.value(key[keys[0]])
+		if len(keys) > 1 {
+			if v, ok := key[keys[1]]; ok {
+				keyCond += " AND " + b.name(keys[1]) + " = " + b.value(v)
+			} else {
+				for i, c := range rest {
+					if isSortKeyCondition(c, keys[1]) {
+						e, err := b.expression(c)
+						if err != nil {
+							return nil, err
+						}
+						keyCond += " AND " + e
+						rest = append(rest[:i:i], rest[i+1:]...)
+						break
+					}
+				}
+			}
+		}
+		filter, err := b.conjunction(rest)
+		if err != nil {
+			return nil, err
+		}
+		var forward *bool
+		if st.descending {
+			forward = aws.Bool(false)
+		}
+		return &dynamodb.QueryInput{
+			TableName:                 aws.String(st.table),
+			KeyConditionExpression:    aws.String(keyCond),
+			FilterExpression:          filter,
+			ProjectionExpression:      projection,
+			ExpressionAttributeNames:  b.attributeNames(),
+			ExpressionAttributeValues: b.attributeValues(),
+			ConsistentRead:            input.ConsistentRead,
+			Limit:                     input.Limit,
+			ExclusiveStartKey:         startKey,
+			ScanIndexForward:          forward,
+			ReturnConsumedCapacity:    input.ReturnConsumedCapacity,
+		}, nil
+	}
+
+	if st.orderBy != "" {
+		return nil, statementError("ORDER BY requires an equality condition on the partition key")
+	}
+	filter, err := b.conjunction(conjuncts)
+	if err != nil {
+		return nil, err
+	}
+	var index *string
+	if st.index != "" {
+		index = aws.String(st.index)
+	}
+	return &dynamodb.ScanInput{
+		TableName:                 aws.String(st.table),
+		IndexName:                 index,
+		FilterExpression:          filter,
+		ProjectionExpression:      projection,
+		ExpressionAttributeNames:  b.attributeNames(),
+		ExpressionAttributeValues: b.attributeValues(),
+		ConsistentRead:            input.ConsistentRead,
+		Limit:                     input.Limit,
+		ExclusiveStartKey:         startKey,
+		ReturnConsumedCapacity:    input.ReturnConsumedCapacity,
+	}, nil
+}
+
+// The next token of a paginated SELECT is the CBOR encoding of its last evaluated key.
+func encodeNextToken(key map[string]types.AttributeValue) (*string, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	w := cbor.NewWriter(&buf)
+	defer w.Close()
+	if err := cbor.EncodeAttributeValue(&types.AttributeValueMemberM{Value: key}, w); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return aws.String(base64.RawURLEncoding.EncodeToString(buf.Bytes())), nil
+}
+
+func decodeNextToken(token *string) (map[string]types.AttributeValue, error) {
+	if aws.ToString(token) == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(*token)
+	if err != nil {
+		return nil, statementError("Invalid NextToken")
+	}
+	r := cbor.NewReader(bytes.NewReader(b))
+	defer r.Close()
+	av, err := cbor.DecodeAttributeValue(r)
+	if err != nil {
+		return nil, statementError("Invalid NextToken")
+	}
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, statementError("Invalid NextToken")
+	}
+	return m.Value, nil
+}
+
+// executeStatement runs the request translated from a statement on api and converts its
+// output.
+func executeStatement(ctx context.Context, api DaxAPI, req interface{}, output *dynamodb.ExecuteStatementOutput, opt RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	if output == nil {
+		output = &dynamodb.ExecuteStatementOutput{}
+	}
+	output.Items = []map[string]types.AttributeValue{}
+	var attrs map[string]types.AttributeValue
+	switch in := req.(type) {
+	case *dynamodb.GetItemInput:
+		out, err := api.GetItemWithOptions(ctx, in, &dynamodb.GetItemOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		attrs, output.ConsumedCapacity = out.Item, out.ConsumedCapacity
+	case *dynamodb.QueryInput:
+		out, err := api.QueryWithOptions(ctx, in, &dynamodb.QueryOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		output.Items, output.ConsumedCapacity, output.LastEvaluatedKey = out.Items, out.ConsumedCapacity, out.LastEvaluatedKey
+	case *dynamodb.ScanInput:
+		out, err := api.ScanWithOptions(ctx, in, &dynamodb.ScanOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		output.Items, output.ConsumedCapacity, output.LastEvaluatedKey = out.Items, out.ConsumedCapacity, out.LastEvaluatedKey
+	case *dynamodb.PutItemInput:
+		out, err := api.PutItemWithOptions(ctx, in, &dynamodb.PutItemOutput{}, opt)
+		if err != nil {
+			var ccf *types.ConditionalCheckFailedException
+			if errors.As(err, &ccf) {
+				err = &types.DuplicateItemException{Message: aws.String("Duplicate primary key exists in table")}
+			}
+			return output, err
+		}
+		output.ConsumedCapacity = out.ConsumedCapacity
+	case *dynamodb.UpdateItemInput:
+		out, err := api.UpdateItemWithOptions(ctx, in, &dynamodb.UpdateItemOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		attrs, output.ConsumedCapacity = out.Attributes, out.ConsumedCapacity
+	case *dynamodb.DeleteItemInput:
+		out, err := api.DeleteItemWithOptions(ctx, in, &dynamodb.DeleteItemOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		attrs, output.ConsumedCapacity = out.Attributes, out.ConsumedCapacity
+	default:
+		return output, fmt.Errorf("unexpected statement request %T", req)
+	}
+	if len(attrs) > 0 {
+		output.Items = append(output.Items, attrs)
+	}
+	var err error
+	output.NextToken, err = encodeNextToken(output.LastEvaluatedKey)
+	return output, err
+}
+
+// batchStatementErrorCodes maps the error codes of failed statements to the codes
+// reported in their batch responses.
+var batchStatementErrorCodes = map[string]types.BatchStatementErrorCodeEnum{
+	"ConditionalCheckFailedException":          types.BatchStatementErrorCodeEnumConditionalCheckFailed,
+	"DuplicateItemException":                   types.BatchStatementErrorCodeEnumDuplicateItem,
+	"ItemCollectionSizeLimitExceededException": types.BatchStatementErrorCodeEnumItemCollectionSizeLimitExceeded,
+	"RequestLimitExceeded":                     types.BatchStatementErrorCodeEnumRequestLimitExceeded,
+	ErrCodeValidationException:                 types.BatchStatementErrorCodeEnumValidationError,
+	"ProvisionedThroughputExceededException":   types.BatchStatementErrorCodeEnumProvisionedThroughputExceeded,
+	"TransactionConflictException":             types.BatchStatementErrorCodeEnumTransactionConflict,
+	ErrCodeThrottlingException:                 types.BatchStatementErrorCodeEnumThrottlingError,
+	ErrCodeInternalServerError:                 types.BatchStatementErrorCodeEnumInternalServerError,
+	"ResourceNotFoundException":                types.BatchStatementErrorCodeEnumResourceNotFound,
+	"AccessDeniedException":                    types.BatchStatementErrorCodeEnumAccessDenied,
+}
+
+// batchStatementError returns the error to report in the response of a failed statement,
+// or false if err is not specific to the statement, e.g. a network error, and fails the
+// whole batch.
+func batchStatementError(err error) (*types.BatchStatementError, bool) {
+	if isIOError(err) {
+		return nil, false
+	}
+	var invalid smithy.InvalidParamsError
+	if errors.As(err, &invalid) {
+		return &types.BatchStatementError{Code: types.BatchStatementErrorCodeEnumValidationError, Message: aws.String(err.Error())}, true
+	}
+	var de *daxRequestFailure
+	if errors.As(err, &de) && de.recoverable() {
+		return nil, false
+	}
+	var ae smithy.APIError
+	if !errors.As(err, &ae) {
+		return nil, false
+	}
+	code, ok := batchStatementErrorCodes[ae.ErrorCode()]
+	if !ok {
+		return nil, false
+	}
+	bse := &types.BatchStatementError{Code: code, Message: aws.String(ae.ErrorMessage())}
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		bse.Item = ccf.Item
+	}
+	return bse, true
+}
+
+// batchExecuteStatement runs the statements of a batch concurrently. Failed statements
+// are reported in their responses, while errors not specific to a statement fail the batch.
+func batchExecuteStatement(ctx context.Context, api DaxAPI, keySchema *lru.Lru, input *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	if input == nil {
+		return output, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	if err := ValidateOpBatchExecuteStatementInput(input); err != nil {
+		return output, err
+	}
+	if output == nil {
+		output = &dynamodb.BatchExecuteStatementOutput{}
+	}
+	n := len(input.Statements)
+	responses := make([]types.BatchStatementResponse, n)
+	capacities := make([]*types.ConsumedCapacity, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range input.Statements {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], capacities[i], errs[i] = batchStatement(ctx, api, keySchema, input, i, opt)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return output, err
+		}
+	}
+	output.Responses = responses
+	output.ConsumedCapacity = mergeConsumedCapacity(capacities)
+	return output, nil
+}
+
+func batchStatement(ctx context.Context, api DaxAPI, keySchema *lru.Lru, input *dynamodb.BatchExecuteStatementInput, i int, opt RequestOptions) (types.BatchStatementResponse, *types.ConsumedCapacity, error) {
+	req := input.Statements[i]
+	var resp types.BatchStatementResponse
+	out, err := func() (*dynamodb.ExecuteStatementOutput, error) {
+		st, err := parseStatement(aws.ToString(req.Statement))
+		if err != nil {
+			return nil, err
+		}
+		resp.TableName = aws.String(st.table)
+		keys, err := getKeySchema(ctx, keySchema, st.table)
+		if err != nil {
+			return nil, err
+		}
+		translated, err := st.translate(&dynamodb.ExecuteStatementInput{
+			Statement:                           req.Statement,
+			Parameters:                          req.Parameters,
+			ConsistentRead:                      req.ConsistentRead,
+			ReturnConsumedCapacity:              input.ReturnConsumedCapacity,
+			ReturnValuesOnConditionCheckFailure: req.ReturnValuesOnConditionCheckFailure,
+		}, keys)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := translated.(*dynamodb.GetItemInput); st.kind == statementSelect && !ok {
+			return nil, statementError("Batch reads must select a single item by its primary key")
+		}
+		return executeStatement(ctx, api, translated, nil, opt)
+	}()
+	if err != nil {
+		bse, ok := batchStatementError(err)
+		if !ok {
+			return resp, nil, err
+		}
+		resp.Error = bse
+		return resp, nil, nil
+	}
+	if _, read := StatementTarget(aws.ToString(req.Statement)); read && len(out.Items) > 0 {
+		resp.Item = out.Items[0]
+	}
+	return resp, out.ConsumedCapacity, nil
+}
+
+// mergeConsumedCapacity sums the capacity consumed per table.
+func mergeConsumedCapacity(capacities []*types.ConsumedCapacity) []types.ConsumedCapacity {
+	var merged []types.ConsumedCapacity
+	index := map[string]int{}
+	for _, c := range capacities {
+		if c == nil {
+			continue
+		}
+		table := aws.ToString(c.TableName)
+		i, ok := index[table]
+		if !ok {
+			index[table] = len(merged)
+			merged = append(merged, types.ConsumedCapacity{TableName: c.TableName})
+			i = len(merged) - 1
+		}
+		m := &merged[i]
+		m.CapacityUnits = addUnits(m.CapacityUnits, c.CapacityUnits)
+		m.ReadCapacityUnits = addUnits(m.ReadCapacityUnits, c.ReadCapacityUnits)
+		m.WriteCapacityUnits = addUnits(m.WriteCapacityUnits, c.WriteCapacityUnits)
+	}
+	return merged
+}
+
+func addUnits(a, b *float64) *float64 {
+	if b == nil {
+		return a
+	}
+	return aws.Float64(aws.ToFloat64(a) + *b)
+}
+
+// executeTransaction translates the statements of a transaction into the items of a
+// TransactGetItems request if they are all reads, each of which must select a single
+// item by its primary key, or else of a TransactWriteItems request. Since statements and
+// items correspond one to one, the cancellation reasons of a canceled transaction are
+// in the order of the statements.
+func executeTransaction(ctx context.Context, api DaxAPI, keySchema *lru.Lru, input *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	if input == nil {
+		return output, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	if err := ValidateOpExecuteTransactionInput(input); err != nil {
+		return output, err
+	}
+	if output == nil {
+		output = &dynamodb.ExecuteTransactionOutput{}
+	}
+	var gets []types.TransactGetItem
+	var writes []types.TransactWriteItem
+	inserts := make([]bool, len(input.TransactStatements))
+	for i, s := range input.TransactStatements {
+		st, err := parseStatement(aws.ToString(s.Statement))
+		if err != nil {
+			return output, err
+		}
+		keys, err := getKeySchema(ctx, keySchema, st.table)
+		if err != nil {
+			return output, err
+		}
+		translated, err := st.translate(&dynamodb.ExecuteStatementInput{
+			Statement:                           s.Statement,
+			Parameters:                          s.Parameters,
+			ReturnValuesOnConditionCheckFailure: s.ReturnValuesOnConditionCheckFailure,
+		}, keys)
+		if err != nil {
+			return output, err
+		}
+		switch in := translated.(type) {
+		case *dynamodb.GetItemInput:
+			gets = append(gets, types.TransactGetItem{Get: &types.Get{
+				TableName:                in.TableName,
+				Key:                      in.Key,
+				ProjectionExpression:     in.ProjectionExpression,
+				ExpressionAttributeNames: in.ExpressionAttributeNames,
+			}})
+		case *dynamodb.PutItemInput:
+			inserts[i] = true
+			writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+				TableName:                           in.TableName,
+				Item:                                in.Item,
+				ConditionExpression:                 in.ConditionExpression,
+				ExpressionAttributeNames:            in.ExpressionAttributeNames,
+				ReturnValuesOnConditionCheckFailure: in.ReturnValuesOnConditionCheckFailure,
+			}})
+		case *dynamodb.UpdateItemInput:
+			if in.ReturnValues != "" {
+				return output, statementError("RETURNING is not supported in transactions")
+			}
+			writes = append(writes, types.TransactWriteItem{Update: &types.Update{
+				TableName:                           in.TableName,
+				Key:                                 in.Key,
+				UpdateExpression:                    in.UpdateExpression,
+				ConditionExpression:                 in.ConditionExpression,
+				ExpressionAttributeNames:            in.ExpressionAttributeNames,
+				ExpressionAttributeValues:           in.ExpressionAttributeValues,
+				ReturnValuesOnConditionCheckFailure: in.ReturnValuesOnConditionCheckFailure,
+			}})
+		case *dynamodb.DeleteItemInput:
+			if in.ReturnValues != "" {
+				return output, statementError("RETURNING is not supported in transactions")
+			}
+			writes = append(writes, types.TransactWriteItem{Delete: &types.Delete{
+				TableName:                           in.TableName,
+				Key:                                 in.Key,
+				ConditionExpression:                 in.ConditionExpression,
+				ExpressionAttributeNames:            in.ExpressionAttributeNames,
+				ExpressionAttributeValues:           in.ExpressionAttributeValues,
+				ReturnValuesOnConditionCheckFailure: in.ReturnValuesOnConditionCheckFailure,
+			}})
+		default:
+			return output, statementError("Transaction reads must select a single item by its primary key")
+		}
+	}
+
+	if len(gets) > 0 {
+		out, err := api.TransactGetItemsWithOptions(ctx, &dynamodb.TransactGetItemsInput{
+			TransactItems:          gets,
+			ReturnConsumedCapacity: input.ReturnConsumedCapacity,
+		}, &dynamodb.TransactGetItemsOutput{}, opt)
+		if err != nil {
+			return output, err
+		}
+		output.Responses = make([]types.ItemResponse, len(out.Responses))
+		for i, r := range out.Responses {
+			output.Responses[i] = types.ItemResponse{Item: r.Item}
+		}
+		output.ConsumedCapacity = out.ConsumedCapacity
+		return output, nil
+	}
+
+	out, err := api.TransactWriteItemsWithOptions(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems:          writes,
+		ClientRequestToken:     input.ClientRequestToken,
+		ReturnConsumedCapacity: input.ReturnConsumedCapacity,
+	}, &dynamodb.TransactWriteItemsOutput{}, opt)
+	if err != nil {
+		statementCancellationReasons(err, inserts)
+		return output, err
+	}
+	output.ConsumedCapacity = out.ConsumedCapacity
+	return output, nil
+}
+
+// statementCancellationReasons reports the failed condition of INSERT statements as a
+// duplicate item, like executeStatement does for a single INSERT.
+func statementCancellationReasons(err error, inserts []bool) {
+	var reasons []types.CancellationReason
+	var failure *daxTransactionCanceledFailure
+	var tce *types.TransactionCanceledException
+	switch {
+	case errors.As(err, &failure):
+		reasons = failure.cancellationReasons
+	case errors.As(err, &tce):
+		reasons = tce.CancellationReasons
+	}
+	for i := range reasons {
+		if i < len(inserts) && inserts[i] && aws.ToString(reasons[i].Code) == "ConditionalCheckFailed" {
+			reasons[i].Code = aws.String("DuplicateItem")
+			reasons[i].Message = aws.String("Duplicate primary key exists in table")
+		}
+	}
+}
@@ -0,0 +1,308 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statementKeys = []types.AttributeDefinition{
+	{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+	{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+}
+
+func translateTestStatement(t *testing.T, statement string, params ...types.AttributeValue) interface{} {
+	st, err := parseStatement(statement)
+	require.NoError(t, err)
+	req, err := st.translate(&dynamodb.ExecuteStatementInput{Statement: aws.String(statement), Parameters: params}, statementKeys)
+	require.NoError(t, err)
+	return req
+}
+
+func TestTranslateSelect(t *testing.T) {
+	get := translateTestStatement(t, `SELECT a, b.c[1] FROM "t" WHERE pk = ? AND sk = 3`, stringAttr("p")).(*dynamodb.GetItemInput)
+	assert.Equal(t, "t", *get.TableName)
+	assert.Equal(t, map[string]types.AttributeValue{"pk": stringAttr("p"), "sk": &types.AttributeValueMemberN{Value: "3"}}, get.Key)
+	assert.Equal(t, "#s0, #s1.#s2[1]", *get.ProjectionExpression)
+	assert.Equal(t, map[string]string{"#s0": "a", "#s1": "b", "#s2": "c"}, get.ExpressionAttributeNames)
+
+	query := translateTestStatement(t, `SELECT * FROM "t" WHERE pk = 'p' AND sk BETWEEN 1 AND 5 AND x <> ? ORDER BY sk DESC`, stringAttr("v")).(*dynamodb.QueryInput)
+	assert.Equal(t, "#s0 = :s0 AND #s1 BETWEEN :s1 AND :s2", *query.KeyConditionExpression)
+	assert.Equal(t, "#s2 <> :s3", *query.FilterExpression)
+	assert.Equal(t, stringAttr("v"), query.ExpressionAttributeValues[":s3"])
+	assert.False(t, *query.ScanIndexForward)
+
+	query = translateTestStatement(t, `SELECT * FROM "t" WHERE pk = 'p' AND begins_with(sk, 'a') AND y IN ['a', 2] AND z IS MISSING`).(*dynamodb.QueryInput)
+	assert.Equal(t, "#s0 = :s0 AND begins_with ( #s1 , :s1 )", *query.KeyConditionExpression)
+	assert.Equal(t, "(#s2 IN (:s2, :s3)) AND (attribute_not_exists(#s3))", *query.FilterExpression)
+
+	scan := translateTestStatement(t, `SELECT * FROM "t"."idx" WHERE pk = 'p' OR g != -1`).(*dynamodb.ScanInput)
+	assert.Equal(t, "idx", *scan.IndexName)
+	assert.Equal(t, "#s0 = :s0 OR #s1 <> :s1", *scan.FilterExpression)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "-1"}, scan.ExpressionAttributeValues[":s1"])
+}
+
+func TestTranslateWrites(t *testing.T) {
+	put := translateTestStatement(t, `INSERT INTO "t" VALUE {'pk': ?, 'sk': 1, 'l': [true, NULL], 's': <<'a', 'b'>>}`, stringAttr("p")).(*dynamodb.PutItemInput)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"pk": stringAttr("p"),
+		"sk": &types.AttributeValueMemberN{Value: "1"},
+		"l":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberBOOL{Value: true}, &types.AttributeValueMemberNULL{Value: true}}},
+		"s":  &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+	}, put.Item)
+	assert.Equal(t, "attribute_not_exists(#s0)", *put.ConditionExpression)
+
+	update := translateTestStatement(t, `UPDATE "t" SET a = a + 1 SET b = ? REMOVE c WHERE pk = 'p' AND sk = 1 AND a < 10 RETURNING MODIFIED NEW *`, stringAttr("v")).(*dynamodb.UpdateItemInput)
+	assert.Equal(t, "SET #s0 = #s0 + :s0, #s1 = :s1 REMOVE #s2", *update.UpdateExpression)
+	assert.Equal(t, "attribute_exists(#s3) AND (#s0 < :s2)", *update.ConditionExpression)
+	assert.Equal(t, types.ReturnValueUpdatedNew, update.ReturnValues)
+
+	del := translateTestStatement(t, `DELETE FROM "t" WHERE 'p' = pk AND sk = 1 RETURNING ALL OLD *`).(*dynamodb.DeleteItemInput)
+	assert.Equal(t, map[string]types.AttributeValue{"pk": stringAttr("p"), "sk": &types.AttributeValueMemberN{Value: "1"}}, del.Key)
+	assert.Nil(t, del.ConditionExpression)
+	assert.Equal(t, types.ReturnValueAllOld, del.ReturnValues)
+}
+
+func TestTranslateStatementErrors(t *testing.T) {
+	for _, s := range []string{
+		`SELECT * FROM "t" WHERE pk = ?`,
+		`DELETE FROM "t" WHERE pk = 'p'`,
+		`SELECT * FROM "t" WHERE a = 1 ORDER BY sk`,
+		`UPDATE "t" SET a = upper(a) WHERE pk = 'p' AND sk = 1`,
+	} {
+		st, err := parseStatement(s)
+		require.NoError(t, err, s)
+		_, err = st.translate(&dynamodb.ExecuteStatementInput{Statement: aws.String(s)}, statementKeys)
+		assert.Error(t, err, s)
+	}
+	for _, s := range []string{`SELECT * FROM`, `EXISTS(SELECT * FROM "t")`, `SELECT * FROM "t" WHERE a = 'x`} {
+		_, err := parseStatement(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestStatementTarget(t *testing.T) {
+	table, read := StatementTarget(`select * from "t" where pk = ?`)
+	assert.Equal(t, "t", table)
+	assert.True(t, read)
+	table, read = StatementTarget(`DELETE FROM Orders WHERE pk = ?`)
+	assert.Equal(t, "Orders", table)
+	assert.False(t, read)
+}
+
+type statementClient struct {
+	DaxAPI
+	query    *dynamodb.QueryInput
+	transact *dynamodb.TransactWriteItemsInput
+	err      error
+}
+
+func (c *statementClient) GetItemWithOptions(ctx context.Context, input *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, opt RequestOptions) (*dynamodb.GetItemOutput, error) {
+	if sk := input.Key["sk"].(*types.AttributeValueMemberN); sk.Value == "0" {
+		return output, newDaxRequestFailure([]int{4, 37, 38, 39, 41}, "ResourceNotFoundException", "not found", "", 400, smithy.FaultClient)
+	}
+	output.Item = input.Key
+	output.ConsumedCapacity = &types.ConsumedCapacity{TableName: input.TableName, CapacityUnits: aws.Float64(0.5)}
+	return output, c.err
+}
+
+func (c *statementClient) QueryWithOptions(ctx context.Context, input *dynamodb.QueryInput, output *dynamodb.QueryOutput, opt RequestOptions) (*dynamodb.QueryOutput, error) {
+	c.query = input
+	return &dynamodb.QueryOutput{
+		Items:            []map[string]types.AttributeValue{{"pk": stringAttr("p")}},
+		LastEvaluatedKey: map[string]types.AttributeValue{"pk": stringAttr("p"), "sk": &types.AttributeValueMemberN{Value: "2"}},
+	}, nil
+}
+
+func (c *statementClient) PutItemWithOptions(ctx context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, opt RequestOptions) (*dynamodb.PutItemOutput, error) {
+	return output, c.err
+}
+
+func (c *statementClient) TransactGetItemsWithOptions(ctx context.Context, input *dynamodb.TransactGetItemsInput, output *dynamodb.TransactGetItemsOutput, opt RequestOptions) (*dynamodb.TransactGetItemsOutput, error) {
+	for _, item := range input.TransactItems {
+		output.Responses = append(output.Responses, types.ItemResponse{Item: item.Get.Key})
+	}
+	return output, c.err
+}
+
+func (c *statementClient) TransactWriteItemsWithOptions(ctx context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, opt RequestOptions) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.transact = input
+	return output, c.err
+}
+
+func TestExecuteStatementPagination(t *testing.T) {
+	c := &statementClient{}
+	statement := `SELECT * FROM "t" WHERE pk = 'p'`
+	st, err := parseStatement(statement)
+	require.NoError(t, err)
+	req, err := st.translate(&dynamodb.ExecuteStatementInput{Statement: aws.String(statement)}, statementKeys)
+	require.NoError(t, err)
+	out, err := executeStatement(context.Background(), c, req, nil, RequestOptions{})
+	require.NoError(t, err)
+	assert.Len(t, out.Items, 1)
+	require.NotNil(t, out.NextToken)
+
+	req, err = st.translate(&dynamodb.ExecuteStatementInput{Statement: aws.String(statement), NextToken: out.NextToken}, statementKeys)
+	require.NoError(t, err)
+	assert.Equal(t, out.LastEvaluatedKey, req.(*dynamodb.QueryInput).ExclusiveStartKey)
+
+	_, err = st.translate(&dynamodb.ExecuteStatementInput{Statement: aws.String(statement), NextToken: aws.String("!")}, statementKeys)
+	assert.Error(t, err)
+}
+
+func TestExecuteStatementNilInput(t *testing.T) {
+	var required *smithy.ParamRequiredError
+	_, err := (&SingleDaxClient{}).ExecuteStatementWithOptions(context.Background(), nil, nil, RequestOptions{})
+	assert.ErrorAs(t, err, &required)
+}
+
+func TestExecuteStatementDuplicateItem(t *testing.T) {
+	c := &statementClient{err: &types.ConditionalCheckFailedException{}}
+	req := translateTestStatement(t, `INSERT INTO "t" VALUE {'pk': 'p', 'sk': 1}`)
+	_, err := executeStatement(context.Background(), c, req, nil, RequestOptions{})
+	var dup *types.DuplicateItemException
+	assert.ErrorAs(t, err, &dup)
+}
+
+func TestBatchExecuteStatement(t *testing.T) {
+	keySchema := &lru.Lru{
+		MaxEntries: keySchemaLruCacheSize,
+		LoadFunc: func(context.Context, lru.Key) (interface{}, error) {
+			return statementKeys, nil
+		},
+	}
+	get := func(statement string, params ...types.AttributeValue) types.BatchStatementRequest {
+		return types.BatchStatementRequest{Statement: aws.String(statement), Parameters: params}
+	}
+	input := &dynamodb.BatchExecuteStatementInput{Statements: []types.BatchStatementRequest{
+		get(`SELECT * FROM "t" WHERE pk = ? AND sk = 1`, stringAttr("a")),
+		get(`SELECT * FROM "t" WHERE pk = 'b' AND sk = 0`),
+		get(`SELECT * FROM "t" WHERE pk = 'c'`),
+		get(`SELECT * FROM "t" WHERE pk = 'd' AND sk = 2`),
+	}}
+
+	c := &statementClient{}
+	out, err := batchExecuteStatement(context.Background(), c, keySchema, input, nil, RequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, out.Responses, 4)
+	assert.Equal(t, stringAttr("a"), out.Responses[0].Item["pk"])
+	assert.Equal(t, "t", aws.ToString(out.Responses[0].TableName))
+	assert.Equal(t, types.BatchStatementErrorCodeEnumResourceNotFound, out.Responses[1].Error.Code)
+	assert.Equal(t, types.BatchStatementErrorCodeEnumValidationError, out.Responses[2].Error.Code)
+	assert.Equal(t, stringAttr("d"), out.Responses[3].Item["pk"])
+	require.Len(t, out.ConsumedCapacity, 1)
+	assert.Equal(t, 1.0, aws.ToFloat64(out.ConsumedCapacity[0].CapacityUnits))
+
+	c.err = translateError(&net.OpError{Op: "read", Err: errors.New("reset")})
+	_, err = batchExecuteStatement(context.Background(), c, keySchema, input, nil, RequestOptions{})
+	assert.Error(t, err)
+
+	var required *smithy.ParamRequiredError
+	_, err = batchExecuteStatement(context.Background(), c, keySchema, nil, nil, RequestOptions{})
+	assert.ErrorAs(t, err, &required)
+}
+
+func TestValidateBatchExecuteStatement(t *testing.T) {
+	assert.Error(t, ValidateOpBatchExecuteStatementInput(&dynamodb.BatchExecuteStatementInput{}))
+	assert.Error(t, ValidateOpBatchExecuteStatementInput(&dynamodb.BatchExecuteStatementInput{Statements: []types.BatchStatementRequest{}}))
+	assert.Error(t, ValidateOpBatchExecuteStatementInput(&dynamodb.BatchExecuteStatementInput{Statements: []types.BatchStatementRequest{{}}}))
+	assert.Error(t, ValidateOpBatchExecuteStatementInput(&dynamodb.BatchExecuteStatementInput{Statements: []types.BatchStatementRequest{
+		{Statement: aws.String(`SELECT * FROM "t" WHERE pk = 'a' AND sk = 1`)},
+		{Statement: aws.String(`DELETE FROM "t" WHERE pk = 'a' AND sk = 1`)},
+	}}))
+	assert.NoError(t, ValidateOpBatchExecuteStatementInput(&dynamodb.BatchExecuteStatementInput{Statements: []types.BatchStatementRequest{
+		{Statement: aws.String(`DELETE FROM "t" WHERE pk = 'a' AND sk = 1`)},
+		{Statement: aws.String(`INSERT INTO "t" VALUE {'pk': 'b', 'sk': 1}`)},
+	}}))
+}
+
+func TestExecuteTransaction(t *testing.T) {
+	keySchema := &lru.Lru{
+		MaxEntries: keySchemaLruCacheSize,
+		LoadFunc: func(context.Context, lru.Key) (interface{}, error) {
+			return statementKeys, nil
+		},
+	}
+	statements := func(s ...string) *dynamodb.ExecuteTransactionInput {
+		input := &dynamodb.ExecuteTransactionInput{ClientRequestToken: aws.String("token")}
+		for _, st := range s {
+			input.TransactStatements = append(input.TransactStatements, types.ParameterizedStatement{Statement: aws.String(st)})
+		}
+		return input
+	}
+
+	c := &statementClient{}
+	out, err := executeTransaction(context.Background(), c, keySchema, statements(
+		`SELECT * FROM "t" WHERE pk = 'a' AND sk = 1`,
+		`SELECT * FROM "t" WHERE pk = 'b' AND sk = 2`,
+	), nil, RequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, out.Responses, 2)
+	assert.Equal(t, stringAttr("b"), out.Responses[1].Item["pk"])
+
+	input := statements(
+		`INSERT INTO "t" VALUE {'pk': 'a', 'sk': 1}`,
+		`UPDATE "t" SET v = 1 WHERE pk = 'b' AND sk = 1`,
+		`DELETE FROM "t" WHERE pk = 'c' AND sk = 1`,
+	)
+	_, err = executeTransaction(context.Background(), c, keySchema, input, nil, RequestOptions{})
+	require.NoError(t, err)
+	require.Len(t, c.transact.TransactItems, 3)
+	assert.NotNil(t, c.transact.TransactItems[0].Put)
+	assert.NotNil(t, c.transact.TransactItems[1].Update)
+	assert.NotNil(t, c.transact.TransactItems[2].Delete)
+	assert.Equal(t, "token", aws.ToString(c.transact.ClientRequestToken))
+
+	failure := newDaxTransactionCanceledFailure([]int{4, 37, 38, 39, 58}, "TransactionCanceledException", "canceled", "", 400, nil, nil, nil)
+	failure.cancellationReasons = []types.CancellationReason{
+		{Code: aws.String("ConditionalCheckFailed")},
+		{Code: aws.String("ConditionalCheckFailed")},
+		{Code: aws.String("None")},
+	}
+	c.err = failure
+	_, err = executeTransaction(context.Background(), c, keySchema, input, nil, RequestOptions{})
+	var tce *types.TransactionCanceledException
+	require.True(t, errors.As(convertDaxError(failure), &tce))
+	assert.Equal(t, "DuplicateItem", aws.ToString(tce.CancellationReasons[0].Code))
+	assert.Equal(t, "ConditionalCheckFailed", aws.ToString(tce.CancellationReasons[1].Code))
+	assert.Equal(t, failure, err)
+
+	c.err = nil
+	_, err = executeTransaction(context.Background(), c, keySchema, statements(`SELECT * FROM "t" WHERE pk = 'a'`), nil, RequestOptions{})
+	assert.Error(t, err)
+	_, err = executeTransaction(context.Background(), c, keySchema, statements(`DELETE FROM "t" WHERE pk = 'a' AND sk = 1 RETURNING ALL OLD *`), nil, RequestOptions{})
+	assert.Error(t, err)
+	_, err = executeTransaction(context.Background(), c, keySchema, statements(
+		`SELECT * FROM "t" WHERE pk = 'a' AND sk = 1`,
+		`DELETE FROM "t" WHERE pk = 'a' AND sk = 1`,
+	), nil, RequestOptions{})
+	assert.Error(t, err)
+
+	var required *smithy.ParamRequiredError
+	_, err = executeTransaction(context.Background(), c, keySchema, nil, nil, RequestOptions{})
+	assert.ErrorAs(t, err, &required)
+}
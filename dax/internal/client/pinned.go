@@ -0,0 +1,85 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxPinnedLifetime bounds the lifetime of the clients returned by ClusterDaxClient.Pin.
+const MaxPinnedLifetime = 24 * time.Hour
+
+// ErrTopologyPinned is returned when refreshing the topology of a pinned client.
+var ErrTopologyPinned = errors.New("the topology of a pinned client cannot be refreshed")
+
+// PinnedClientExpiredError is returned for the requests made with a pinned client once
+// its lifetime elapsed.
+type PinnedClientExpiredError struct {
+	ExpiredAt time.Time
+}
+
+func (e *PinnedClientExpiredError) Error() string {
+	return fmt.Sprintf("pinned client expired at %s", e.ExpiredAt.Format(time.RFC3339))
+}
+
+// Pin returns a new client routing requests to the nodes cc currently routes requests to
+// and never discovering the nodes again, so that benchmarks run against a fixed topology
+// and topology related issues can be bisected.
+//
+// Pin is a diagnostic tool, not meant for production traffic: nodes leaving the cluster
+// are not replaced and nodes joining it are not used. As a safeguard, the pinned client
+// fails every request with a *PinnedClientExpiredError once lifetime elapsed; lifetime is
+// capped by MaxPinnedLifetime. The pinned client has its own connections and must be
+// closed independently of cc.
+func (cc *ClusterDaxClient) Pin(ctx context.Context, lifetime time.Duration) (*ClusterDaxClient, error) {
+	if lifetime <= 0 {
+		return nil, NewCustomInvalidParamError("ConfigValidation", "pinned client lifetime must be positive")
+	}
+	if lifetime > MaxPinnedLifetime {
+		lifetime = MaxPinnedLifetime
+	}
+	roster := cc.cluster.exportRoster()
+	if len(roster.Nodes) == 0 {
+		return nil, &NotReadyError{Err: cc.cluster.lastRefreshError()}
+	}
+
+	config := cc.config
+	config.InitialRoster = &roster
+	config.HandoffConnections = nil
+	cluster, err := newCluster(config)
+	if err != nil {
+		return nil, err
+	}
+	cluster.pinnedUntil = clockOrSystem(config.Clock).Now().Add(lifetime)
+	if err := cluster.start(ctx); err != nil {
+		cluster.Close()
+		return nil, err
+	}
+	return &ClusterDaxClient{config: config, cluster: cluster, accounting: newOperationAccounting(), tracer: newTracer(config.TracerProvider)}, nil
+}
+
+// PinnedUntil returns the time the client expires at when it was returned by Pin, or the
+// zero time.
+func (cc *ClusterDaxClient) PinnedUntil() time.Time {
+	return cc.cluster.pinnedUntil
+}
+
+func (c *cluster) pinned() bool {
+	return !c.pinnedUntil.IsZero()
+}
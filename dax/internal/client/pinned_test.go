@@ -0,0 +1,82 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterDaxClient_pin(t *testing.T) {
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Clock = clock
+	cluster, _ := newTestClusterWithConfig(cfg)
+	defer cluster.Close()
+	require.NoError(t, cluster.update([]serviceEndpoint{{nodeId: 1, hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111}}))
+	cc := &ClusterDaxClient{config: cfg, cluster: cluster}
+
+	_, err := cc.Pin(context.Background(), 0)
+	assert.Error(t, err)
+
+	pinned, err := cc.Pin(context.Background(), time.Hour)
+	require.NoError(t, err)
+	defer pinned.Close()
+	assert.Equal(t, clock.Now().Add(time.Hour), pinned.PinnedUntil())
+	assert.True(t, cc.PinnedUntil().IsZero())
+	assert.Equal(t, cc.ExportRoster().Nodes, pinned.ExportRoster().Nodes)
+	assert.EqualValues(t, 1, pinned.cluster.executor.tasks, "only idle connections are reaped, the topology is never refreshed")
+
+	_, err = pinned.RefreshTopology(context.Background())
+	assert.ErrorIs(t, err, ErrTopologyPinned)
+	_, err = pinned.UpdateEndpoints(context.Background(), []string{"127.0.0.2:8111"})
+	assert.ErrorIs(t, err, ErrTopologyPinned)
+	assert.Equal(t, []string{"127.0.0.1:8111"}, pinned.cluster.config.HostPorts)
+
+	client, err := pinned.cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+
+	clock.advance(time.Hour)
+	_, err = pinned.GetItemWithOptions(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t")}, &dynamodb.GetItemOutput{}, RequestOptions{})
+	var expired *PinnedClientExpiredError
+	require.True(t, errors.As(err, &expired), "%v", err)
+	assert.Equal(t, pinned.PinnedUntil(), expired.ExpiredAt)
+
+	capped, err := cc.Pin(context.Background(), 10*MaxPinnedLifetime)
+	require.NoError(t, err)
+	defer capped.Close()
+	assert.Equal(t, clock.Now().Add(MaxPinnedLifetime), capped.PinnedUntil())
+}
+
+func TestClusterDaxClient_pinNotReady(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	defer cluster.Close()
+	cc := &ClusterDaxClient{config: cluster.config, cluster: cluster}
+
+	_, err := cc.Pin(context.Background(), time.Minute)
+	var nre *NotReadyError
+	assert.ErrorAs(t, err, &nre)
+}
@@ -0,0 +1,58 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// pprof label keys set on the goroutines executing a request when Config.ProfilerLabels is enabled.
+const (
+	ProfilerLabelOperation = "dax.operation"
+	ProfilerLabelTable     = "dax.table"
+)
+
+// setProfilerLabels labels the calling goroutine with the operation and table until the
+// returned function is called, which restores the labels the caller set on ctx, the
+// context passed to the operation. The request keeps them alongside its own, whichever
+// of ctx and opt.Context it runs with. Goroutines started by the request, such as
+// connection attempts, inherit the labels. opt.Context is replaced with the labeled
+// context so that nested pprof.Do calls keep them.
+func setProfilerLabels(ctx context.Context, op, table string, opt *RequestOptions) func() {
+	var prior []string
+	if ctx != nil {
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			prior = append(prior, key, value)
+			return true
+		})
+	}
+	base := opt.Context
+	if base == nil {
+		base = context.Background()
+		if ctx != nil {
+			base = ctx
+		}
+	}
+	labeled := pprof.WithLabels(base, pprof.Labels(prior...))
+	labeled = pprof.WithLabels(labeled, pprof.Labels(ProfilerLabelOperation, op, ProfilerLabelTable, table))
+	pprof.SetGoroutineLabels(labeled)
+	opt.Context = labeled
+	previous := pprof.WithLabels(context.Background(), pprof.Labels(prior...))
+	return func() {
+		pprof.SetGoroutineLabels(previous)
+	}
+}
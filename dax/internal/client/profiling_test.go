@@ -0,0 +1,86 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterDaxClient_profilerLabels(t *testing.T) {
+	input := &dynamodb.GetItemInput{TableName: aws.String("table")}
+
+	cc := &ClusterDaxClient{config: Config{ProfilerLabels: true}}
+	opt := RequestOptions{}
+	done := cc.track(context.Background(), OpGetItem, input, &opt)
+	op, _ := pprof.Label(opt.Context, ProfilerLabelOperation)
+	table, _ := pprof.Label(opt.Context, ProfilerLabelTable)
+	done(nil, nil)
+	assert.Equal(t, OpGetItem, op)
+	assert.Equal(t, "table", table)
+
+	cc.config.ProfilerLabels = false
+	opt = RequestOptions{}
+	cc.track(context.Background(), OpGetItem, input, &opt)(nil, nil)
+	_, ok := pprof.Label(cc.newContext(context.Background(), opt), ProfilerLabelOperation)
+	assert.False(t, ok)
+}
+
+func TestClusterDaxClient_profilerLabelsRestored(t *testing.T) {
+	input := &dynamodb.GetItemInput{TableName: aws.String("table")}
+	cc := &ClusterDaxClient{config: Config{ProfilerLabels: true}}
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("caller", "test"))
+	pprof.SetGoroutineLabels(ctx)
+	defer pprof.SetGoroutineLabels(context.Background())
+	opt := RequestOptions{Context: context.Background()}
+	done := cc.track(ctx, OpGetItem, input, &opt)
+	caller, _ := pprof.Label(opt.Context, "caller")
+	assert.Equal(t, "test", caller, "the request keeps the labels of the caller")
+	done(nil, nil)
+
+	labels := goroutineLabels(t, "TestClusterDaxClient_profilerLabelsRestored")
+	assert.Contains(t, labels, `"caller":"test"`)
+	assert.NotContains(t, labels, ProfilerLabelOperation)
+}
+
+// goroutineLabels returns the pprof labels of the goroutines running fn, as listed by the
+// goroutine profile.
+func goroutineLabels(t *testing.T, fn string) string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, block := range strings.Split(buf.String(), "\n\n") {
+		if !strings.Contains(block, fn) {
+			continue
+		}
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "# labels: ") {
+				labels = append(labels, line)
+			}
+		}
+	}
+	return strings.Join(labels, "\n")
+}
@@ -0,0 +1,36 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// ProxyFromEnvironment returns the proxy to connect to address through according to the
+// HTTPS_PROXY and NO_PROXY environment variables, or their lowercase versions, the way
+// net/http does for https URLs. NO_PROXY may list the IP addresses or CIDR ranges of the
+// nodes, which are connected to by address. Like in net/http, the variables are read once.
+func ProxyFromEnvironment(address string) (*url.URL, error) {
+	return http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: address}})
+}
+
+// ProxyURL returns a Config.Proxy connecting to every node through proxyURL.
+func ProxyURL(proxyURL *url.URL) func(address string) (*url.URL, error) {
+	return func(string) (*url.URL, error) {
+		return proxyURL, nil
+	}
+}
@@ -0,0 +1,206 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryShape is a frequently issued query or scan pattern.
+type QueryShape struct {
+	Table string
+	// Shape renders the operation, the index and the structure of the key condition, filter
+	// and projection, with the expression attribute names resolved and the values replaced
+	// by ?, e.g. "Query index=byDate key=(pk = ? AND sk > ?) filter=(attribute_exists(x))".
+	Shape string
+	// ID is a hash of the table and the shape, short enough for a metric attribute.
+	ID string
+	// Count estimates the number of requests, extrapolated from the sampled ones.
+	Count uint64
+}
+
+// sampleShape adds the shape of a query or scan to the query shape sketch, if the request
+// is sampled. Sampled requests with one of the top shapes are also counted by the
+// dax.query_shapes.sampled metric.
+func (client *SingleDaxClient) sampleShape(ctx context.Context, input interface{}) {
+	s := client.queryShapes
+	if !s.sample() {
+		return
+	}
+	var k sketchKey
+	switch in := input.(type) {
+	case *dynamodb.QueryInput:
+		k = sketchKey{table: aws.ToString(in.TableName), key: queryShape(in)}
+	case *dynamodb.ScanInput:
+		k = sketchKey{table: aws.ToString(in.TableName), key: scanShape(in)}
+	default:
+		return
+	}
+	if s.count(k) && client.daxSdkMetrics != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		countMetricInt64(ctx, client.daxSdkMetrics, daxQueryShapesSampled, 1, tableAttr(k.table), queryShapeAttr(shapeID(k)))
+	}
+}
+
+func (s *keySketch) topShapes(n int) []QueryShape {
+	ranked := s.ranked(n)
+	if ranked == nil {
+		return nil
+	}
+	out := make([]QueryShape, len(ranked))
+	for i, k := range ranked {
+		out[i] = QueryShape{Table: k.table, Shape: k.key, ID: shapeID(k.sketchKey), Count: k.count}
+	}
+	return out
+}
+
+func shapeID(k sketchKey) string {
+	h := fnv.New64a()
+	h.Write([]byte(k.table))
+	h.Write([]byte{0})
+	h.Write([]byte(k.key))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func queryShape(in *dynamodb.QueryInput) string {
+	var sb strings.Builder
+	sb.WriteString(OpQuery)
+	writeIndex(&sb, in.IndexName)
+	if in.KeyConditionExpression != nil {
+		writeClause(&sb, "key", normalizeExpression(aws.ToString(in.KeyConditionExpression), in.ExpressionAttributeNames))
+	} else if len(in.KeyConditions) > 0 {
+		writeClause(&sb, "key", normalizeConditions(in.KeyConditions))
+	}
+	if in.FilterExpression != nil {
+		writeClause(&sb, "filter", normalizeExpression(aws.ToString(in.FilterExpression), in.ExpressionAttributeNames))
+	} else if len(in.QueryFilter) > 0 {
+		writeClause(&sb, "filter", normalizeConditions(in.QueryFilter))
+	}
+	writeProjection(&sb, in.Select, in.ProjectionExpression, in.AttributesToGet, in.ExpressionAttributeNames)
+	return sb.String()
+}
+
+func scanShape(in *dynamodb.ScanInput) string {
+	var sb strings.Builder
+	sb.WriteString(OpScan)
+	writeIndex(&sb, in.IndexName)
+	if in.FilterExpression != nil {
+		writeClause(&sb, "filter", normalizeExpression(aws.ToString(in.FilterExpression), in.ExpressionAttributeNames))
+	} else if len(in.ScanFilter) > 0 {
+		writeClause(&sb, "filter", normalizeConditions(in.ScanFilter))
+	}
+	writeProjection(&sb, in.Select, in.ProjectionExpression, in.AttributesToGet, in.ExpressionAttributeNames)
+	return sb.String()
+}
+
+func writeIndex(sb *strings.Builder, index *string) {
+	if index != nil {
+		sb.WriteString(" index=")
+		sb.WriteString(aws.ToString(index))
+	}
+}
+
+func writeClause(sb *strings.Builder, name, clause string) {
+	sb.WriteByte(' ')
+	sb.WriteString(name)
+	sb.WriteString("=(")
+	sb.WriteString(clause)
+	sb.WriteByte(')')
+}
+
+func writeProjection(sb *strings.Builder, sel types.Select, projection *string, attrs []string, names map[string]string) {
+	if sel != "" {
+		sb.WriteString(" select=")
+		sb.WriteString(string(sel))
+	}
+	switch {
+	case projection != nil:
+		writeClause(sb, "projection", normalizeExpression(aws.ToString(projection), names))
+	case len(attrs) > 0:
+		writeClause(sb, "projection", strings.Join(attrs, ", "))
+	}
+}
+
+// normalizeExpression resolves the expression attribute names of expr, replaces its
+// expression attribute values by ? and collapses its white space, so that requests
+// differing only by values or placeholder names share a shape.
+func normalizeExpression(expr string, names map[string]string) string {
+	var sb strings.Builder
+	space := false
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(expr) && isPlaceholderByte(expr[j]) {
+				j++
+			}
+			if space && sb.Len() > 0 {
+				sb.WriteByte(' ')
+			}
+			space = false
+			if c == ':' {
+				sb.WriteByte('?')
+			} else if name, ok := names[expr[i:j]]; ok {
+				sb.WriteString(name)
+			} else {
+				sb.WriteString(expr[i:j])
+			}
+			i = j
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		sb.WriteByte(c)
+		i++
+	}
+	return sb.String()
+}
+
+func isPlaceholderByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// normalizeConditions renders legacy conditions as their attribute names and comparison
+// operators, ordered by name.
+func normalizeConditions(conditions map[string]types.Condition) string {
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + " " + string(conditions[name].ComparisonOperator)
+	}
+	return strings.Join(parts, " AND ")
+}
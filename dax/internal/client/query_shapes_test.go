@@ -0,0 +1,92 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeExpression(t *testing.T) {
+	names := map[string]string{"#pk": "customer", "#d": "date"}
+	assert.Equal(t, "customer = ? AND date BETWEEN ? AND ?", normalizeExpression("#pk = :c  AND\n#d BETWEEN :from AND :to", names))
+	assert.Equal(t, "attribute_exists(#missing) OR size(x)>?", normalizeExpression(" attribute_exists(#missing) OR size(x)>:n1 ", names))
+	assert.Equal(t, "a IN (?, ?)", normalizeExpression("a IN (:a,  :b)", nil))
+}
+
+func TestQueryShape(t *testing.T) {
+	q := func(c string) *dynamodb.QueryInput {
+		return &dynamodb.QueryInput{
+			TableName:                 aws.String("orders"),
+			IndexName:                 aws.String("byCustomer"),
+			KeyConditionExpression:    aws.String("#c = :c"),
+			FilterExpression:          aws.String("#s <> :s"),
+			ProjectionExpression:      aws.String("id, #s"),
+			ExpressionAttributeNames:  map[string]string{"#c": "customer", "#s": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":c": &types.AttributeValueMemberS{Value: c}},
+		}
+	}
+	assert.Equal(t, "Query index=byCustomer key=(customer = ?) filter=(status <> ?) projection=(id, status)", queryShape(q("a")))
+	assert.Equal(t, queryShape(q("a")), queryShape(q("b")))
+
+	legacy := &dynamodb.QueryInput{
+		KeyConditions: map[string]types.Condition{
+			"sk": {ComparisonOperator: types.ComparisonOperatorBeginsWith},
+			"pk": {ComparisonOperator: types.ComparisonOperatorEq},
+		},
+		Select: types.SelectCount,
+	}
+	assert.Equal(t, "Query key=(pk EQ AND sk BEGINS_WITH) select=COUNT", queryShape(legacy))
+
+	scan := &dynamodb.ScanInput{ScanFilter: map[string]types.Condition{"x": {ComparisonOperator: types.ComparisonOperatorNotNull}}, AttributesToGet: []string{"a", "b"}}
+	assert.Equal(t, "Scan filter=(x NOT_NULL) projection=(a, b)", scanShape(scan))
+}
+
+func TestSingleClient_sampleShape(t *testing.T) {
+	mp := &testMeterProvider{}
+	om, err := buildDaxSdkMetrics(mp)
+	require.NoError(t, err)
+	client := &SingleDaxClient{queryShapes: newKeySketch(1), daxSdkMetrics: om}
+
+	for i := 0; i < 3; i++ {
+		client.sampleShape(context.Background(), &dynamodb.QueryInput{TableName: aws.String("t"), KeyConditionExpression: aws.String("pk = :v")})
+	}
+	client.sampleShape(context.Background(), &dynamodb.ScanInput{TableName: aws.String("t")})
+	client.sampleShape(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t")})
+
+	top := client.queryShapes.topShapes(5)
+	require.Len(t, top, 2)
+	assert.Equal(t, QueryShape{Table: "t", Shape: "Query key=(pk = ?)", ID: shapeID(sketchKey{table: "t", key: "Query key=(pk = ?)"}), Count: 3}, top[0])
+	assert.Equal(t, "Scan", top[1].Shape)
+	assert.Len(t, top[0].ID, 16)
+
+	tm := mp.meters[daxMeterScope].(*testMeter)
+	counter := tm.i64s[daxQueryShapesSampled]
+	assert.Equal(t, []int64{4}, counter.data)
+	require.Len(t, counter.props, 4)
+	assert.Equal(t, top[0].ID, counter.props[0].Get(metricAttrQueryShape))
+	assert.Equal(t, "t", counter.props[0].Get(metricAttrTable))
+
+	disabled := &SingleDaxClient{}
+	disabled.sampleShape(context.Background(), &dynamodb.ScanInput{})
+	assert.Nil(t, disabled.queryShapes.topShapes(5))
+}
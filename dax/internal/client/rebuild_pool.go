@@ -0,0 +1,120 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultMaxConcurrentRebuilds = 4
+
+// rebuildPool runs the rebuilds of the clients of the nodes failing their health checks.
+// Every rebuild takes the cluster lock, so when many nodes fail at once they are queued
+// on a bounded number of goroutines rather than blocking the health checks reporting
+// them. A node has at most one rebuild pending: the failures reported meanwhile are
+// dropped, since the pending rebuild replaces the client they are about.
+type rebuildPool struct {
+	slots   chan struct{} // nil when rebuilds run on the goroutine submitting them
+	metrics *daxSdkMetrics
+
+	mu      sync.Mutex
+	pending map[hostPort]struct{} // protected by mu
+	closed  bool                  // protected by mu
+	wg      sync.WaitGroup
+}
+
+// newRebuildPool returns a pool running up to maxConcurrent rebuilds at once, or running
+// them synchronously when maxConcurrent is not positive.
+func newRebuildPool(maxConcurrent int, metrics *daxSdkMetrics) *rebuildPool {
+	p := &rebuildPool{pending: make(map[hostPort]struct{}), metrics: metrics}
+	if maxConcurrent > 0 {
+		p.slots = make(chan struct{}, maxConcurrent)
+	}
+	return p
+}
+
+// submit schedules rebuild for host and reports whether it did, which it doesn't when a
+// rebuild of host is already pending or the pool is closed.
+func (p *rebuildPool) submit(host hostPort, rebuild func()) bool {
+	p.mu.Lock()
+	if _, ok := p.pending[host]; ok || p.closed {
+		p.mu.Unlock()
+		return false
+	}
+	p.pending[host] = struct{}{}
+	p.samplePending()
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	run := func() {
+		defer p.wg.Done()
+		defer p.done(host)
+		rebuild()
+	}
+	if p.slots == nil {
+		run()
+		return true
+	}
+	go func() {
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		run()
+	}()
+	return true
+}
+
+func (p *rebuildPool) done(host hostPort) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, host)
+	p.samplePending()
+}
+
+// samplePending records the number of pending rebuilds. p.mu must be held.
+func (p *rebuildPool) samplePending() {
+	gaugeInt64(context.Background(), p.metrics, daxRebuildsPending, int64(len(p.pending)))
+}
+
+// numPending returns the number of rebuilds queued or running.
+func (p *rebuildPool) numPending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+// close makes the pool reject the rebuilds submitted from now on.
+func (p *rebuildPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+}
+
+// wait waits for the pending rebuilds to finish, or until ctx is done.
+func (p *rebuildPool) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d rebuilds still pending: %w", p.numPending(), ctx.Err())
+	}
+}
@@ -0,0 +1,293 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RequestRecord is a sanitized description of a single request. It never contains
+// attribute values, only the names and types needed to replay a request of the same
+// shape against a test cluster.
+type RequestRecord struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Table     string    `json:"table,omitempty"`
+	IndexName string    `json:"indexName,omitempty"`
+
+	// KeyShape maps attribute names to their types for the key of single item operations,
+	// or for the whole item in the case of PutItem.
+	KeyShape map[string]string `json:"keyShape,omitempty"`
+	// Items is the number of items in batch and transaction requests.
+	Items int `json:"items,omitempty"`
+
+	ConsistentRead   bool   `json:"consistentRead,omitempty"`
+	Limit            int32  `json:"limit,omitempty"`
+	Projection       string `json:"projection,omitempty"`
+	KeyCondition     string `json:"keyCondition,omitempty"`
+	Filter           string `json:"filter,omitempty"`
+	Condition        string `json:"condition,omitempty"`
+	Update           string `json:"update,omitempty"`
+	RetryMaxAttempts int    `json:"retryMaxAttempts,omitempty"`
+
+	Duration time.Duration `json:"duration"`
+	Attempts int64         `json:"attempts"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// RequestRecorder receives a record for every request completed by the client.
+// Implementations must be safe for concurrent use and should not block.
+type RequestRecorder interface {
+	Record(RequestRecord)
+}
+
+// RingRecorder keeps the most recent records in memory.
+type RingRecorder struct {
+	mu      sync.Mutex
+	records []RequestRecord
+	next    int
+	full    bool
+}
+
+// NewRingRecorder returns a recorder retaining at most size records.
+func NewRingRecorder(size int) *RingRecorder {
+	if size < 1 {
+		size = 1
+	}
+	return &RingRecorder{records: make([]RequestRecord, size)}
+}
+
+func (r *RingRecorder) Record(rec RequestRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Records returns the retained records, oldest first.
+func (r *RingRecorder) Records() []RequestRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]RequestRecord(nil), r.records[:r.next]...)
+	}
+	out := make([]RequestRecord, 0, len(r.records))
+	out = append(out, r.records[r.next:]...)
+	return append(out, r.records[:r.next]...)
+}
+
+// WriteTo writes the retained records to w as JSON lines.
+func (r *RingRecorder) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	enc := json.NewEncoder(cw)
+	for _, rec := range r.Records() {
+		if err := enc.Encode(rec); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, nil
+}
+
+// FileRecorder appends records to a file as JSON lines. Once the file grows past
+// maxBytes it is rotated to path + ".1", replacing any previous rotation, so at most
+// twice maxBytes is kept on disk.
+type FileRecorder struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// NewFileRecorder opens, or creates, the file at path for recording.
+func NewFileRecorder(path string, maxBytes int64) (*FileRecorder, error) {
+	r := &FileRecorder{path: path, maxBytes: maxBytes}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *FileRecorder) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = st.Size()
+	return nil
+}
+
+func (r *FileRecorder) Record(rec RequestRecord) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	if r.maxBytes > 0 && r.size+int64(len(b)) > r.maxBytes && r.size > 0 {
+		r.file.Close()
+		r.file = nil
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return
+		}
+		if err := r.open(); err != nil {
+			return
+		}
+	}
+	n, _ := r.file.Write(b)
+	r.size += int64(n)
+}
+
+func (r *FileRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// ReadRequestRecords decodes records written by a FileRecorder or RingRecorder.WriteTo.
+func ReadRequestRecords(rd io.Reader) ([]RequestRecord, error) {
+	var out []RequestRecord
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec RequestRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return out, err
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newRequestRecord describes input without copying any attribute values.
+func newRequestRecord(op string, input interface{}, opt RequestOptions) RequestRecord {
+	rec := RequestRecord{Operation: op, Table: requestTable(input), RetryMaxAttempts: opt.RetryMaxAttempts}
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		if in != nil {
+			rec.KeyShape = attributeShape(in.Key)
+			rec.ConsistentRead = aws.ToBool(in.ConsistentRead)
+			rec.Projection = aws.ToString(in.ProjectionExpression)
+		}
+	case *dynamodb.PutItemInput:
+		if in != nil {
+			rec.KeyShape = attributeShape(in.Item)
+			rec.Condition = aws.ToString(in.ConditionExpression)
+		}
+	case *dynamodb.UpdateItemInput:
+		if in != nil {
+			rec.KeyShape = attributeShape(in.Key)
+			rec.Condition = aws.ToString(in.ConditionExpression)
+			rec.Update = aws.ToString(in.UpdateExpression)
+		}
+	case *dynamodb.DeleteItemInput:
+		if in != nil {
+			rec.KeyShape = attributeShape(in.Key)
+			rec.Condition = aws.ToString(in.ConditionExpression)
+		}
+	case *dynamodb.QueryInput:
+		if in != nil {
+			rec.IndexName = aws.ToString(in.IndexName)
+			rec.ConsistentRead = aws.ToBool(in.ConsistentRead)
+			rec.Limit = aws.ToInt32(in.Limit)
+			rec.Projection = aws.ToString(in.ProjectionExpression)
+			rec.KeyCondition = aws.ToString(in.KeyConditionExpression)
+			rec.Filter = aws.ToString(in.FilterExpression)
+		}
+	case *dynamodb.ScanInput:
+		if in != nil {
+			rec.IndexName = aws.ToString(in.IndexName)
+			rec.ConsistentRead = aws.ToBool(in.ConsistentRead)
+			rec.Limit = aws.ToInt32(in.Limit)
+			rec.Projection = aws.ToString(in.ProjectionExpression)
+			rec.Filter = aws.ToString(in.FilterExpression)
+		}
+	case *dynamodb.BatchGetItemInput:
+		if in != nil {
+			for _, kaas := range in.RequestItems {
+				rec.Items += len(kaas.Keys)
+			}
+		}
+	case *dynamodb.BatchWriteItemInput:
+		if in != nil {
+			for _, wrs := range in.RequestItems {
+				rec.Items += len(wrs)
+			}
+		}
+	case *dynamodb.TransactGetItemsInput:
+		if in != nil {
+			rec.Items = len(in.TransactItems)
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		if in != nil {
+			rec.Items = len(in.TransactItems)
+		}
+	}
+	return rec
+}
+
+func attributeShape(item map[string]types.AttributeValue) map[string]string {
+	if len(item) == 0 {
+		return nil
+	}
+	shape := make(map[string]string, len(item))
+	for k, v := range item {
+		shape[k] = attributeTypeName(v)
+	}
+	return shape
+}
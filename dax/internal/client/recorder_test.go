@@ -0,0 +1,89 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingRecorder(t *testing.T) {
+	r := NewRingRecorder(2)
+	assert.Empty(t, r.Records())
+
+	r.Record(RequestRecord{Operation: "a"})
+	r.Record(RequestRecord{Operation: "b"})
+	r.Record(RequestRecord{Operation: "c"})
+
+	recs := r.Records()
+	require.Len(t, recs, 2)
+	assert.Equal(t, "b", recs[0].Operation)
+	assert.Equal(t, "c", recs[1].Operation)
+
+	var buf bytes.Buffer
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	read, err := ReadRequestRecords(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, recs, read)
+}
+
+func TestFileRecorder_rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	r, err := NewFileRecorder(path, 200)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		r.Record(RequestRecord{Operation: OpGetItem, Table: "table"})
+	}
+	require.NoError(t, r.Close())
+
+	st, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, st.Size(), int64(200))
+	_, err = os.Stat(path + ".1")
+	assert.NoError(t, err)
+}
+
+func TestNewRequestRecord_sanitized(t *testing.T) {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String("table"),
+		Item: map[string]types.AttributeValue{
+			"pk":     &types.AttributeValueMemberS{Value: "secret"},
+			"amount": &types.AttributeValueMemberN{Value: "42"},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	}
+	rec := newRequestRecord(OpPutItem, input, RequestOptions{})
+	assert.Equal(t, "table", rec.Table)
+	assert.Equal(t, map[string]string{"pk": "S", "amount": "N"}, rec.KeyShape)
+	assert.Equal(t, "attribute_not_exists(pk)", rec.Condition)
+
+	var buf bytes.Buffer
+	r := NewRingRecorder(1)
+	r.Record(rec)
+	_, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "secret")
+	assert.NotContains(t, buf.String(), "42")
+}
@@ -0,0 +1,63 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+const defaultClusterUpdateMaxBackoff = time.Minute
+
+// refreshDelay returns the delay before the next background refresh of the cluster: the
+// refresh interval, or after consecutive failed refreshes an exponential backoff capped at
+// Config.ClusterUpdateMaxBackoff. The backoff is jittered so that the clients which lost
+// the cluster at the same time don't keep retrying in lockstep.
+func (c *cluster) refreshDelay(interval time.Duration) time.Duration {
+	failures := atomic.LoadInt32(&c.refreshFailures)
+	limit := c.config.ClusterUpdateMaxBackoff
+	if limit == 0 {
+		limit = defaultClusterUpdateMaxBackoff
+	}
+	if failures == 0 || limit <= interval {
+		return interval
+	}
+	backoff := interval
+	for i := int32(0); i < failures && backoff < limit; i++ {
+		backoff *= 2
+	}
+	if backoff > limit {
+		backoff = limit
+	}
+	d := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	if d < interval {
+		d = interval
+	}
+	return d
+}
+
+// recordRefresh tracks the streak of failed refreshes the backoff is based on.
+func (c *cluster) recordRefresh(err error) {
+	var streak int32
+	if err == nil {
+		atomic.StoreInt32(&c.refreshFailures, 0)
+	} else {
+		streak = atomic.AddInt32(&c.refreshFailures, 1)
+	}
+	gaugeInt64(context.Background(), c.daxSdkMetrics, daxDiscoveryFailureStreak, int64(streak))
+}
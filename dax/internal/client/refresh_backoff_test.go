@@ -0,0 +1,55 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCluster_refreshDelay(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.ClusterUpdateMaxBackoff = 8 * time.Second
+	cluster, _ := newTestClusterWithConfig(cfg)
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	cluster.daxSdkMetrics = om
+	interval := time.Second
+
+	assert.Equal(t, interval, cluster.refreshDelay(interval))
+
+	for i, backoff := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		cluster.recordRefresh(errors.New("discovery failed"))
+		expectGauges(t, om, map[string]int{daxDiscoveryFailureStreak: i + 1})
+		for j := 0; j < 20; j++ {
+			d := cluster.refreshDelay(interval)
+			assert.GreaterOrEqual(t, d, backoff/2)
+			assert.LessOrEqual(t, d, backoff)
+		}
+	}
+
+	cluster.recordRefresh(nil)
+	expectGauges(t, om, map[string]int{daxDiscoveryFailureStreak: 0})
+	assert.Equal(t, interval, cluster.refreshDelay(interval))
+
+	cluster.config.ClusterUpdateMaxBackoff = interval
+	cluster.recordRefresh(errors.New("discovery failed"))
+	assert.Equal(t, interval, cluster.refreshDelay(interval), "no backoff beyond a cap below the interval")
+}
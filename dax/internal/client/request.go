@@ -474,8 +474,12 @@ func encodeBatchWriteItemInput(ctx context.Context, input *dynamodb.BatchWriteIt
 			return err
 		}
 
-		if hasDuplicatesWriteRequests(wrs, keys) {
-			return errors.New("Provided list of item keys contains duplicates")
+		if conflicts := writeConflicts(wrs, keys); len(conflicts) > 0 {
+			return &BatchWriteConflictError{
+				Table:   table,
+				Key:     writeRequestKey(wrs[conflicts[0][0]], keys),
+				Indexes: conflicts[0],
+			}
 		}
 		for _, wr := range wrs {
 			if pr := wr.PutRequest; pr != nil {
@@ -1331,29 +1335,7 @@ func translateScanIndexForward(b *bool) int {
 }
 
 func hasDuplicatesWriteRequests(wrs []types.WriteRequest, d []types.AttributeDefinition) bool {
-	if len(wrs) <= 1 {
-		return false
-	}
-	face := make([]item, len(wrs))
-	for i, v := range wrs {
-		face[i] = (writeItem)(v)
-	}
-
-	var err error
-	sort.Sort(dupKeys{d, face, func(a, b item) int {
-		if err != nil {
-			return 0
-		}
-		for _, k := range d {
-			r := strings.Compare(a.key(k), b.key(k))
-			if r != 0 {
-				return r
-			}
-		}
-		err = fmt.Errorf("dup %v %v", a, b)
-		return 0
-	}})
-	return err != nil
+	return len(writeConflicts(wrs, d)) > 0
 }
 
 func hasDuplicateKeysAndAttributes(kaas types.KeysAndAttributes, d []types.AttributeDefinition) bool {
@@ -18,9 +18,11 @@ package client
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/smithy-go/middleware"
 )
@@ -32,6 +34,41 @@ type RequestOptions struct {
 	Context    context.Context
 	//Retryer implements equal jitter backoff stratergy for throttled requests
 	Retryer DaxRetryer
+
+	// ReadTimeout and WriteTimeout bound the time a single attempt of a read, respectively
+	// write, operation may take on the connection, in addition to the deadline of Context.
+	// Zero means only the context deadline applies.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// FirstByteTimeout, when positive, bounds the time between sending a request and
+	// receiving the first byte of its response. Once the response starts arriving it may
+	// take until the attempt deadline. Attempts of read operations that time out are
+	// retried on another node; writes are not, as they may have been applied.
+	FirstByteTimeout time.Duration
+
+	// MaxRetryElapsedTime, when positive, bounds the time spent on a request across all its
+	// attempts and backoffs. The attempt in progress when it is reached is abandoned, and no
+	// retry is made once it is reached or when its backoff would end past it; the error of
+	// the last attempt is then wrapped in a *RetryBudgetExceededError.
+	MaxRetryElapsedTime time.Duration
+
+	// CaptureLog records the debug log of the request, including the node and duration of
+	// each attempt, whatever LogLevel is. When the request fails, its error is a
+	// *RequestLogError carrying the log. Messages LogLevel enables still go to Logger.
+	CaptureLog bool
+}
+
+// attemptTimeout returns the connection timeout applying to a single attempt of op.
+func (o RequestOptions) attemptTimeout(op string) time.Duration {
+	switch {
+	case IsReadOperation(op):
+		return o.ReadTimeout
+	case IsWriteOperation(op):
+		return o.WriteTimeout
+	default:
+		return 0
+	}
 }
 
 // rejectCustomMiddleware checks if APIOptions are present and returns an error if they are.
@@ -42,3 +79,38 @@ func RejectCustomMiddleware(apiOptions []func(*middleware.Stack) error) error {
 	}
 	return nil
 }
+
+// RejectDivergentOptions returns an error when the per request options set a Region or
+// Credentials other than the ones of the client. The connections to the cluster are
+// authenticated with the region and credentials the client was created with, so a request
+// can't be made with others.
+func RejectDivergentOptions(o dynamodb.Options, region string, credentials aws.CredentialsProvider) error {
+	if o.Region != "" && o.Region != region {
+		return NewCustomInvalidParamError("Region", fmt.Sprintf("the request option %q differs from the region %q of the DAX client, create a client for the other region instead", o.Region, region))
+	}
+	if o.Credentials != nil && !sameCredentials(o.Credentials, credentials) {
+		return NewCustomInvalidParamError("Credentials", "the request option differs from the credentials of the DAX client, which authenticate its connections; create a client with the other credentials instead")
+	}
+	return nil
+}
+
+// sameCredentials reports whether a and b are the same provider. Providers which are not
+// comparable, such as aws.CredentialsProviderFunc, are compared by identity.
+func sameCredentials(a, b aws.CredentialsProvider) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if ta.Comparable() {
+		return a == b
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return va.Pointer() == vb.Pointer()
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Resolver resolves the hostnames of the seed endpoints to the addresses of the nodes the
+// cluster is discovered from. *net.Resolver implements it.
+type Resolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// seedLookups caches the addresses the seed hostnames resolved to, and the failures to
+// resolve them, see Config.SeedLookupTTL.
+type seedLookups struct {
+	mu      sync.Mutex
+	entries map[string]seedLookup
+}
+
+type seedLookup struct {
+	ips     []net.IP
+	err     error
+	expires time.Time
+}
+
+func (l *seedLookups) get(host string) (seedLookup, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[host]
+	return e, ok
+}
+
+func (l *seedLookups) set(host string, e seedLookup) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.entries == nil {
+		l.entries = make(map[string]seedLookup)
+	}
+	l.entries[host] = e
+}
+
+// lookupSeed returns the addresses of the seed host, with Config.Resolver when set. IP
+// literals are returned as is, without going through the resolver. Lookups are cached for
+// Config.SeedLookupTTL and failures for Config.SeedLookupNegativeTTL; a failure after a
+// successful lookup keeps the addresses of the latter. The addresses returned are a copy
+// the caller may reorder.
+func (c *cluster) lookupSeed(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	ttl, negativeTTL := c.config.SeedLookupTTL, c.config.SeedLookupNegativeTTL
+	if ttl <= 0 && negativeTTL <= 0 {
+		return c.resolveSeed(ctx, host)
+	}
+
+	now := clockOrSystem(c.config.Clock).Now()
+	prev, cached := c.seedLookups.get(host)
+	if cached && now.Before(prev.expires) {
+		return slices.Clone(prev.ips), prev.err
+	}
+	ips, err := c.resolveSeed(ctx, host)
+	switch {
+	case err == nil:
+		c.seedLookups.set(host, seedLookup{ips: ips, expires: now.Add(ttl)})
+		return slices.Clone(ips), nil
+	case ctx.Err() != nil:
+		// Not a failure of the resolver.
+		return nil, err
+	case len(prev.ips) > 0 && negativeTTL > 0:
+		c.debugLog("Failed to resolve seed %s, using the previous addresses %v: %v", host, prev.ips, err)
+		c.seedLookups.set(host, seedLookup{ips: prev.ips, expires: now.Add(negativeTTL)})
+		return slices.Clone(prev.ips), nil
+	default:
+		c.seedLookups.set(host, seedLookup{err: err, expires: now.Add(negativeTTL)})
+		return nil, err
+	}
+}
+
+func (c *cluster) resolveSeed(ctx context.Context, host string) ([]net.IP, error) {
+	r := c.config.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	return r.LookupIP(ctx, "ip", host)
+}
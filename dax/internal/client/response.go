@@ -16,6 +16,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -76,12 +77,13 @@ func decodeEndpointsOutput(reader *cbor.Reader) ([]serviceEndpoint, error) {
 	if len <= 0 {
 		return []serviceEndpoint{}, nil
 	}
-	o := make([]serviceEndpoint, len)
+	o := make([]serviceEndpoint, 0, cbor.PreallocLength(len))
 	for i := 0; i < len; i++ {
-		o[i], err = decodeEndpoint(reader)
+		se, err := decodeEndpoint(reader)
 		if err != nil {
 			return nil, err
 		}
+		o = append(o, se)
 	}
 	return o, nil
 }
@@ -126,6 +128,7 @@ func decodeEndpoint(reader *cbor.Reader) (serviceEndpoint, error) {
 			}
 		default:
 			// inorder to ensure backward compatibility on future field additions, new/unknown fields are ignored
+			return r.Skip()
 		}
 		return nil
 	})
@@ -145,13 +148,13 @@ func decodeDefineAttributeListOutput(reader *cbor.Reader) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	attrNames := make([]string, len)
+	attrNames := make([]string, 0, cbor.PreallocLength(len))
 	for i := 0; i < len; i++ {
 		an, err := reader.ReadString()
 		if err != nil {
 			return nil, err
 		}
-		attrNames[i] = an
+		attrNames = append(attrNames, an)
 	}
 	return attrNames, nil
 }
@@ -161,7 +164,7 @@ func decodeDefineKeySchemaOutput(reader *cbor.Reader) ([]types.AttributeDefiniti
 	if err != nil {
 		return nil, err
 	}
-	keys := make([]types.AttributeDefinition, len)
+	keys := make([]types.AttributeDefinition, 0, cbor.PreallocLength(len))
 	for i := 0; i < len; i++ {
 		name, err := reader.ReadString()
 		if err != nil {
@@ -171,7 +174,7 @@ func decodeDefineKeySchemaOutput(reader *cbor.Reader) ([]types.AttributeDefiniti
 		if err != nil {
 			return nil, err
 		}
-		keys[i] = types.AttributeDefinition{AttributeName: &name, AttributeType: types.ScalarAttributeType(typ)}
+		keys = append(keys, types.AttributeDefinition{AttributeName: &name, AttributeType: types.ScalarAttributeType(typ)})
 	}
 	return keys, nil
 }
@@ -204,7 +207,7 @@ func decodePutItemOutput(ctx context.Context, reader *cbor.Reader, input *dynamo
 			}
 		case responseParamAttributes:
 			attrs, err := decodeNonKeyAttributes(ctx, reader, attrListIdToNames, nil)
-			if err != nil {
+			if err != nil || attrs == nil {
 				return err
 			}
 			keys, err := getKeySchema(ctx, keySchemaCache, tableName)
@@ -217,7 +220,7 @@ func decodePutItemOutput(ctx context.Context, reader *cbor.Reader, input *dynamo
 			}
 			output.Attributes = attrs
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -256,7 +259,7 @@ func decodeDeleteItemOutput(ctx context.Context, reader *cbor.Reader, input *dyn
 			}
 		case responseParamAttributes:
 			attrs, err := decodeNonKeyAttributes(ctx, reader, attrListIdToNames, nil)
-			if err != nil {
+			if err != nil || attrs == nil {
 				return err
 			}
 			for k, v := range input.Key {
@@ -264,7 +267,7 @@ func decodeDeleteItemOutput(ctx context.Context, reader *cbor.Reader, input *dyn
 			}
 			output.Attributes = attrs
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -306,7 +309,7 @@ func decodeUpdateItemOutput(ctx context.Context, reader *cbor.Reader, input *dyn
 			switch rv {
 			case types.ReturnValueAllNew, types.ReturnValueAllOld:
 				attrs, err := decodeNonKeyAttributes(ctx, reader, attrListIdToNames, nil)
-				if err != nil {
+				if err != nil || attrs == nil {
 					return err
 				}
 				for k, v := range input.Key {
@@ -321,7 +324,7 @@ func decodeUpdateItemOutput(ctx context.Context, reader *cbor.Reader, input *dyn
 				return &smithy.SerializationError{Err: fmt.Errorf("unexpected return value %s", rv)}
 			}
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -354,7 +357,7 @@ func decodeGetItemOutput(ctx context.Context, reader *cbor.Reader, input *dynamo
 			}
 		case responseParamItem:
 			item, err := decodeNonKeyAttributes(ctx, reader, attrListIdToNames, projectionOrdinals)
-			if err != nil {
+			if err != nil || item == nil {
 				return err
 			}
 			if len(projectionOrdinals) == 0 {
@@ -364,7 +367,7 @@ func decodeGetItemOutput(ctx context.Context, reader *cbor.Reader, input *dynamo
 			}
 			output.Item = item
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -376,24 +379,18 @@ func decodeGetItemOutput(ctx context.Context, reader *cbor.Reader, input *dynamo
 }
 
 func decodeScanOutput(ctx context.Context, reader *cbor.Reader, input *dynamodb.ScanInput, keySchemaCache *lru.Lru, attrNamesListToId *lru.Lru, output *dynamodb.ScanOutput) (*dynamodb.ScanOutput, error) {
-	out, err := decodeScanQueryOutput(ctx, reader, *input.TableName, input.IndexName != nil, input.ProjectionExpression, input.ExpressionAttributeNames, keySchemaCache, attrNamesListToId)
+	out, err := decodeScanQueryOutput(ctx, reader, *input.TableName, input.IndexName != nil, input.Select == types.SelectCount, input.ProjectionExpression, input.ExpressionAttributeNames, keySchemaCache, attrNamesListToId)
 	if err != nil {
 		return output, err
 	}
-	if out == nil {
-		return output, nil
-	}
 	return out.scanOutput(output), nil
 }
 
 func decodeQueryOutput(ctx context.Context, reader *cbor.Reader, input *dynamodb.QueryInput, keySchemaCache *lru.Lru, attrNamesListToId *lru.Lru, output *dynamodb.QueryOutput) (*dynamodb.QueryOutput, error) {
-	out, err := decodeScanQueryOutput(ctx, reader, *input.TableName, input.IndexName != nil, input.ProjectionExpression, input.ExpressionAttributeNames, keySchemaCache, attrNamesListToId)
+	out, err := decodeScanQueryOutput(ctx, reader, *input.TableName, input.IndexName != nil, input.Select == types.SelectCount, input.ProjectionExpression, input.ExpressionAttributeNames, keySchemaCache, attrNamesListToId)
 	if err != nil {
 		return output, err
 	}
-	if out == nil {
-		return output, nil
-	}
 	return out.queryOutput(output), nil
 }
 
@@ -431,16 +428,24 @@ func (o *scanQueryOutput) queryOutput(output *dynamodb.QueryOutput) *dynamodb.Qu
 	}
 }
 
-func decodeScanQueryOutput(ctx context.Context, reader *cbor.Reader, table string, indexed bool, projection *string, exprAttrNames map[string]string, keySchemaCache *lru.Lru, attrNamesListToId *lru.Lru) (*scanQueryOutput, error) {
+// decodeScanQueryOutput decodes a Query or Scan page with the semantics of DynamoDB: Items
+// is empty rather than nil on an empty page, and nil when only counting, Count and
+// ScannedCount are always set and LastEvaluatedKey is nil on the last page. A nil response
+// is an empty page.
+func decodeScanQueryOutput(ctx context.Context, reader *cbor.Reader, table string, indexed, selectCount bool, projection *string, exprAttrNames map[string]string, keySchemaCache *lru.Lru, attrNamesListToId *lru.Lru) (*scanQueryOutput, error) {
+	out := &scanQueryOutput{}
+	out.Items = []map[string]types.AttributeValue{}
+	if selectCount {
+		out.Items = nil
+	}
 	if consumed, err := consumeNil(reader); err != nil {
 		return nil, err
 	} else if consumed {
-		return nil, nil
+		return out, nil
 	}
 
-	out := &scanQueryOutput{}
-	out.Items = []map[string]types.AttributeValue{}
 	var err error
+	hasCount, hasScannedCount := false, false
 	err = consumeMap(reader, func(key int, reader *cbor.Reader) error {
 		switch key {
 		case responseParamItems:
@@ -461,12 +466,14 @@ func decodeScanQueryOutput(ctx context.Context, reader *cbor.Reader, table strin
 				return err
 			}
 			out.Count = int32(c)
+			hasCount = true
 		case responseParamScannedCount:
 			c, err := reader.ReadInt64()
 			if err != nil {
 				return err
 			}
 			out.ScannedCount = int32(c)
+			hasScannedCount = true
 		case responseParamLastEvaluatedKey:
 			k, err := decodeLastEvaluatedKey(ctx, reader, table, indexed, keySchemaCache)
 			if err != nil {
@@ -476,13 +483,22 @@ func decodeScanQueryOutput(ctx context.Context, reader *cbor.Reader, table strin
 				out.LastEvaluatedKey = k
 			}
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if selectCount && len(out.Items) == 0 {
+		out.Items = nil
+	}
+	if !hasCount {
+		out.Count = int32(len(out.Items))
+	}
+	if !hasScannedCount {
+		out.ScannedCount = out.Count
+	}
 	return out, nil
 }
 
@@ -503,7 +519,7 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 		output = &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}
 	}
 	if numTables > 0 {
-		unprocessed := make(map[string][]types.WriteRequest, numTables)
+		unprocessed := make(map[string][]types.WriteRequest, cbor.PreallocLength(numTables))
 		for i := 0; i < numTables; i++ {
 			table, err := reader.ReadString()
 			if err != nil {
@@ -518,7 +534,7 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 				return output, err
 			}
 			numItems := numObjs / 2
-			wrs := make([]types.WriteRequest, numItems)
+			wrs := make([]types.WriteRequest, 0, cbor.PreallocLength(numItems))
 			for j := 0; j < numItems; j++ {
 				keys, err := decodeKey(reader, tableKeys)
 				if err != nil {
@@ -537,7 +553,7 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 					}
 					wr.PutRequest = &types.PutRequest{Item: item}
 				}
-				wrs[j] = wr
+				wrs = append(wrs, wr)
 			}
 			unprocessed[table] = wrs
 		}
@@ -551,13 +567,13 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 		return output, err
 	}
 	if numCC > 0 {
-		output.ConsumedCapacity = make([]types.ConsumedCapacity, numCC)
+		output.ConsumedCapacity = make([]types.ConsumedCapacity, 0, cbor.PreallocLength(numCC))
 		for i := 0; i < numCC; i++ {
 			capacity, err := decodeConsumedCapacity(reader)
 			if err != nil {
 				return output, err
 			}
-			output.ConsumedCapacity[i] = *capacity
+			output.ConsumedCapacity = append(output.ConsumedCapacity, *capacity)
 		}
 	}
 
@@ -566,7 +582,7 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 		return output, err
 	}
 	if icmLen > 0 {
-		output.ItemCollectionMetrics = make(map[string][]types.ItemCollectionMetrics, icmLen)
+		output.ItemCollectionMetrics = make(map[string][]types.ItemCollectionMetrics, cbor.PreallocLength(icmLen))
 		for i := 0; i < icmLen; i++ {
 			table, err := reader.ReadString()
 			if err != nil {
@@ -581,13 +597,13 @@ func decodeBatchWriteItemOutput(ctx context.Context, reader *cbor.Reader, keySch
 			if err != nil {
 				return output, err
 			}
-			metrics := make([]types.ItemCollectionMetrics, numMetrics)
+			metrics := make([]types.ItemCollectionMetrics, 0, cbor.PreallocLength(numMetrics))
 			for j := 0; j < numMetrics; j++ {
 				itemCollectionMetric, err := decodeItemCollectionMetrics(reader, pkey)
 				if err != nil {
 					return output, err
 				}
-				metrics[j] = *itemCollectionMetric
+				metrics = append(metrics, *itemCollectionMetric)
 			}
 			output.ItemCollectionMetrics[table] = metrics
 		}
@@ -631,7 +647,7 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 		output = &dynamodb.BatchGetItemOutput{}
 	}
 	if numTables > 0 {
-		output.Responses = make(map[string][]map[string]types.AttributeValue, numTables)
+		output.Responses = make(map[string][]map[string]types.AttributeValue, cbor.PreallocLength(numTables))
 		for i := 0; i < numTables; i++ {
 			table, err := reader.ReadString()
 			if err != nil {
@@ -644,11 +660,16 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 				if err != nil {
 					return output, err
 				}
-				items := make([]map[string]types.AttributeValue, numItems)
+				items := make([]map[string]types.AttributeValue, 0, cbor.PreallocLength(numItems))
 				for j := 0; j < numItems; j++ {
-					if items[j], err = decodeNonKeyAttributes(ctx, reader, attrNamesListToId, projections); err != nil {
+					if err = checkDone(ctx); err != nil {
+						return output, err
+					}
+					item, err := decodeNonKeyAttributes(ctx, reader, attrNamesListToId, projections)
+					if err != nil {
 						return output, err
 					}
+					items = append(items, item)
 				}
 				output.Responses[table] = items
 			} else {
@@ -661,8 +682,11 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 					return output, err
 				}
 				numItems := numObjs / 2
-				items := make([]map[string]types.AttributeValue, numItems)
+				items := make([]map[string]types.AttributeValue, 0, cbor.PreallocLength(numItems))
 				for j := 0; j < numItems; j++ {
+					if err := checkDone(ctx); err != nil {
+						return output, err
+					}
 					keys, err := decodeKey(reader, tableKeys)
 					if err != nil {
 						return output, err
@@ -671,10 +695,13 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 					if err != nil {
 						return output, err
 					}
+					if item == nil {
+						item = make(map[string]types.AttributeValue, len(keys))
+					}
 					for k, v := range keys {
 						item[k] = v
 					}
-					items[j] = item
+					items = append(items, item)
 				}
 				output.Responses[table] = items
 			}
@@ -686,7 +713,7 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 		return output, err
 	}
 	if numUnprocessed > 0 {
-		unprocessed := make(map[string]types.KeysAndAttributes, numUnprocessed)
+		unprocessed := make(map[string]types.KeysAndAttributes, cbor.PreallocLength(numUnprocessed))
 		for i := 0; i < numUnprocessed; i++ {
 			table, err := reader.ReadString()
 			if err != nil {
@@ -703,11 +730,13 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 			if numKeys <= 0 {
 				continue
 			}
-			keys := make([]map[string]types.AttributeValue, numKeys)
+			keys := make([]map[string]types.AttributeValue, 0, cbor.PreallocLength(numKeys))
 			for j := 0; j < numKeys; j++ {
-				if keys[j], err = decodeKey(reader, tableKeys); err != nil {
+				key, err := decodeKey(reader, tableKeys)
+				if err != nil {
 					return output, err
 				}
+				keys = append(keys, key)
 			}
 			outKaas := types.KeysAndAttributes{Keys: keys}
 			if inKaas, ok := input.RequestItems[table]; ok {
@@ -729,13 +758,13 @@ func decodeBatchGetItemOutput(ctx context.Context, reader *cbor.Reader, input *d
 		return output, err
 	}
 	if numCC > 0 {
-		output.ConsumedCapacity = make([]types.ConsumedCapacity, numCC)
+		output.ConsumedCapacity = make([]types.ConsumedCapacity, 0, cbor.PreallocLength(numCC))
 		for i := 0; i < numCC; i++ {
 			capacity, err := decodeConsumedCapacity(reader)
 			if err != nil {
 				return output, err
 			}
-			output.ConsumedCapacity[i] = *capacity
+			output.ConsumedCapacity = append(output.ConsumedCapacity, *capacity)
 		}
 	}
 
@@ -765,13 +794,13 @@ func decodeTransactWriteItemsOutput(ctx context.Context, reader *cbor.Reader, in
 		return output, err
 	}
 	if numCC > 0 {
-		output.ConsumedCapacity = make([]types.ConsumedCapacity, numCC)
+		output.ConsumedCapacity = make([]types.ConsumedCapacity, 0, cbor.PreallocLength(numCC))
 		for i := 0; i < numCC; i++ {
 			capacity, err := decodeConsumedCapacityExtended(reader)
 			if err != nil {
 				return output, err
 			}
-			output.ConsumedCapacity[i] = *capacity
+			output.ConsumedCapacity = append(output.ConsumedCapacity, *capacity)
 		}
 	}
 
@@ -780,7 +809,7 @@ func decodeTransactWriteItemsOutput(ctx context.Context, reader *cbor.Reader, in
 		return output, err
 	}
 	if icmLen > 0 {
-		output.ItemCollectionMetrics = make(map[string][]types.ItemCollectionMetrics, icmLen)
+		output.ItemCollectionMetrics = make(map[string][]types.ItemCollectionMetrics, cbor.PreallocLength(icmLen))
 		for i := 0; i < icmLen; i++ {
 			table, err := reader.ReadString()
 			if err != nil {
@@ -795,13 +824,13 @@ func decodeTransactWriteItemsOutput(ctx context.Context, reader *cbor.Reader, in
 			if err != nil {
 				return output, err
 			}
-			metrics := make([]types.ItemCollectionMetrics, numMetrics)
+			metrics := make([]types.ItemCollectionMetrics, 0, cbor.PreallocLength(numMetrics))
 			for j := 0; j < numMetrics; j++ {
 				itemCollectionMetric, err := decodeItemCollectionMetrics(reader, pkey)
 				if err != nil {
 					return output, err
 				}
-				metrics[j] = *itemCollectionMetric
+				metrics = append(metrics, *itemCollectionMetric)
 			}
 			output.ItemCollectionMetrics[table] = metrics
 		}
@@ -858,13 +887,13 @@ func decodeTransactGetItemsOutput(ctx context.Context, reader *cbor.Reader, inpu
 		return output, err
 	}
 	if numCC > 0 {
-		output.ConsumedCapacity = make([]types.ConsumedCapacity, numCC)
+		output.ConsumedCapacity = make([]types.ConsumedCapacity, 0, cbor.PreallocLength(numCC))
 		for i := 0; i < numCC; i++ {
 			capacity, err := decodeConsumedCapacityExtended(reader)
 			if err != nil {
 				return output, err
 			}
-			output.ConsumedCapacity[i] = *capacity
+			output.ConsumedCapacity = append(output.ConsumedCapacity, *capacity)
 		}
 	}
 
@@ -883,6 +912,9 @@ func decodeScanQueryItems(ctx context.Context, reader *cbor.Reader, table string
 	items := []map[string]types.AttributeValue{}
 	if len(projectionOrdinals) > 0 {
 		err := consumeArray(reader, func(reader *cbor.Reader) error {
+			if err := checkDone(ctx); err != nil {
+				return err
+			}
 			i, err := decodeProjection(reader, projectionOrdinals)
 			if err != nil {
 				return err
@@ -899,6 +931,9 @@ func decodeScanQueryItems(ctx context.Context, reader *cbor.Reader, table string
 			return nil, err
 		}
 		err = consumeArray(reader, func(reader *cbor.Reader) error {
+			if err := checkDone(ctx); err != nil {
+				return err
+			}
 			len, err := reader.ReadArrayLength()
 			if err != nil {
 				return err
@@ -914,6 +949,9 @@ func decodeScanQueryItems(ctx context.Context, reader *cbor.Reader, table string
 			if err != nil {
 				return err
 			}
+			if item == nil {
+				item = make(map[string]types.AttributeValue)
+			}
 			for k, v := range key {
 				item[k] = v
 			}
@@ -947,6 +985,30 @@ func decodeLastEvaluatedKey(ctx context.Context, reader *cbor.Reader, table stri
 	}
 }
 
+// unknownResponseParam skips the value of an unrecognized response field when the
+// reader is tolerant, and otherwise fails with the key and where it was found.
+func unknownResponseParam(reader *cbor.Reader, key int) error {
+	if reader.Tolerant() {
+		return reader.Skip()
+	}
+	return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d at offset %d", key, reader.Offset())}
+}
+
+// checkDone returns the context error once ctx is done. Streaming decoders call it
+// between items so a canceled request stops reading a large response instead of
+// draining it; the partially read tube is then discarded by the caller.
+func checkDone(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
 func consumeArray(reader *cbor.Reader, consumer func(reader *cbor.Reader) error) error {
 	hdr, err := reader.PeekHeader()
 	if err != nil {
@@ -1116,16 +1178,13 @@ func decodeNonKeyAttributes(ctx context.Context, reader *cbor.Reader, attrNamesL
 
 	switch hdr & cbor.MajorTypeMask {
 	case cbor.Bytes:
-		r, err := reader.BytesReader()
-		if err != nil {
-			return nil, err
-		}
-		defer r.Close()
-		item, err := cbor.DecodeItemNonKeyAttributes(ctx, r, attrNamesListToId)
+		b, err := reader.ReadBytes()
 		if err != nil {
 			return nil, err
 		}
-		return item, nil
+		return decodeAttributeList(b, attrNamesListToId, func(r *cbor.Reader) (map[string]types.AttributeValue, error) {
+			return cbor.DecodeItemNonKeyAttributes(ctx, r, attrNamesListToId)
+		})
 	case cbor.Map:
 		return decodeProjection(reader, projectionOrdinals)
 	}
@@ -1133,10 +1192,29 @@ func decodeNonKeyAttributes(ctx context.Context, reader *cbor.Reader, attrNamesL
 
 }
 
+// decodeAttributeList decodes b, holding attributes encoded with an attribute list id,
+// with decode. The names of a cached id go stale when the server reassigns it, e.g. after
+// the projections of a table changed, in which case the id is dropped from
+// attrListIdToNames and b is decoded once more with the names loaded from the server.
+func decodeAttributeList(b []byte, attrListIdToNames *lru.Lru, decode func(r *cbor.Reader) (map[string]types.AttributeValue, error)) (map[string]types.AttributeValue, error) {
+	decodeBytes := func() (map[string]types.AttributeValue, error) {
+		r := cbor.NewReader(bytes.NewReader(b))
+		defer r.Close()
+		return decode(r)
+	}
+	item, err := decodeBytes()
+	var mismatch *cbor.AttributeListMismatchError
+	if errors.As(err, &mismatch) {
+		attrListIdToNames.Remove(mismatch.ID)
+		item, err = decodeBytes()
+	}
+	return item, err
+}
+
 func decodeProjection(reader *cbor.Reader, projectionOrdinals []documentPath) (map[string]types.AttributeValue, error) {
 	ib := &itemBuilder{}
 	err := consumeMap(reader, func(ord int, r *cbor.Reader) error {
-		if ord > len(projectionOrdinals) {
+		if ord < 0 || ord >= len(projectionOrdinals) {
 			return &smithy.SerializationError{Err: fmt.Errorf("unexpected ordinal %v", ord)}
 		}
 		p := projectionOrdinals[ord]
@@ -1154,37 +1232,40 @@ func decodeProjection(reader *cbor.Reader, projectionOrdinals []documentPath) (m
 }
 
 func decodeAttributeProjection(ctx context.Context, reader *cbor.Reader, attrListIdToNames *lru.Lru) (map[string]types.AttributeValue, error) {
-	r, err := reader.BytesReader()
+	b, err := reader.ReadBytes()
 	if err != nil {
 		return nil, err
 	}
-	defer r.Close()
-
-	attrListId, err := r.ReadInt64()
-	if err != nil {
-		return nil, err
-	}
-	attrNames, err := attrListIdToNames.GetWithContext(ctx, attrListId)
-	if err != nil {
-		return nil, err
-	}
-	ans, ok := attrNames.([]string)
-	if !ok {
-		return nil, &smithy.SerializationError{Err: errors.New("invalid type for attribute names list")}
-	}
-	attrs := make(map[string]types.AttributeValue)
-	err = consumeMap(r, func(ord int, reader *cbor.Reader) error {
-		if ord > len(ans) {
-			return &smithy.SerializationError{Err: errors.New("invalid ordinal")}
+	return decodeAttributeList(b, attrListIdToNames, func(r *cbor.Reader) (map[string]types.AttributeValue, error) {
+		attrListId, err := r.ReadInt64()
+		if err != nil {
+			return nil, err
 		}
-		av, err := cbor.DecodeAttributeValue(reader)
+		attrNames, err := attrListIdToNames.GetWithContext(ctx, attrListId)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		attrs[ans[ord]] = av
-		return nil
+		ans, ok := attrNames.([]string)
+		if !ok {
+			return nil, &smithy.SerializationError{Err: errors.New("invalid type for attribute names list")}
+		}
+		attrs := make(map[string]types.AttributeValue)
+		err = consumeMap(r, func(ord int, reader *cbor.Reader) error {
+			if ord < 0 || ord >= len(ans) {
+				return &cbor.AttributeListMismatchError{ID: attrListId}
+			}
+			av, err := cbor.DecodeAttributeValue(reader)
+			if err != nil {
+				return err
+			}
+			attrs[ans[ord]] = av
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return attrs, nil
 	})
-	return attrs, nil
 }
 
 func decodeConsumedCapacity(reader *cbor.Reader) (*types.ConsumedCapacity, error) {
@@ -1295,7 +1376,7 @@ func decodeConsumedCapacityExtended(reader *cbor.Reader) (*types.ConsumedCapacit
 			}
 			cc.LocalSecondaryIndexes = c
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -1336,7 +1417,7 @@ func decodeCapacity(reader *cbor.Reader) (*types.Capacity, error) {
 			}
 			c.WriteCapacityUnits = &f
 		default:
-			return &smithy.SerializationError{Err: fmt.Errorf("unknown response param key %d", key)}
+			return unknownResponseParam(reader, key)
 		}
 		return nil
 	})
@@ -1359,7 +1440,7 @@ func decodeIndexConsumedCapacity(reader *cbor.Reader, extended bool) (map[string
 	if err != nil {
 		return nil, err
 	}
-	index := make(map[string]types.Capacity, len)
+	index := make(map[string]types.Capacity, cbor.PreallocLength(len))
 	for len > 0 {
 		len--
 		i, err := reader.ReadString()
@@ -0,0 +1,103 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/internal/lru"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeProjectedItems(t *testing.T, n int) *bytes.Buffer {
+	var b bytes.Buffer
+	w := cbor.NewWriter(&b)
+	require.NoError(t, w.WriteArrayStreamHeader())
+	for i := 0; i < n; i++ {
+		require.NoError(t, w.WriteMapHeader(1))
+		require.NoError(t, w.WriteInt(0))
+		require.NoError(t, cbor.EncodeAttributeValue(&types.AttributeValueMemberS{Value: "v"}, w))
+	}
+	require.NoError(t, w.WriteStreamBreak())
+	require.NoError(t, w.Flush())
+	return &b
+}
+
+func TestDecodeScanQueryItems_canceled(t *testing.T) {
+	projection, err := buildProjectionOrdinals(aws.String("a"), nil)
+	require.NoError(t, err)
+
+	items, err := decodeScanQueryItems(context.Background(), cbor.NewReader(encodeProjectedItems(t, 3)), "table", nil, nil, projection)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = decodeScanQueryItems(ctx, cbor.NewReader(encodeProjectedItems(t, 3)), "table", nil, nil, projection)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDecodeNonKeyAttributes_staleAttributeList(t *testing.T) {
+	var attrs bytes.Buffer
+	w := cbor.NewWriter(&attrs)
+	require.NoError(t, w.WriteInt64(7))
+	require.NoError(t, cbor.EncodeAttributeValue(&types.AttributeValueMemberS{Value: "x"}, w))
+	require.NoError(t, cbor.EncodeAttributeValue(&types.AttributeValueMemberS{Value: "y"}, w))
+	require.NoError(t, w.Flush())
+
+	var b bytes.Buffer
+	w = cbor.NewWriter(&b)
+	require.NoError(t, w.WriteBytes(attrs.Bytes()))
+	require.NoError(t, w.Flush())
+
+	// The first load returns the names the id had before the server reassigned it.
+	loads := 0
+	attrListIdToNames := &lru.Lru{
+		LoadFunc: func(ctx context.Context, key lru.Key) (interface{}, error) {
+			loads++
+			if loads == 1 {
+				return []string{"a"}, nil
+			}
+			return []string{"a", "b"}, nil
+		},
+	}
+	item, err := decodeNonKeyAttributes(context.Background(), cbor.NewReader(bytes.NewReader(b.Bytes())), attrListIdToNames, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"a": &types.AttributeValueMemberS{Value: "x"},
+		"b": &types.AttributeValueMemberS{Value: "y"},
+	}, item)
+	assert.Equal(t, 2, loads)
+
+	// A list which still doesn't match after reloading it is reported.
+	attrListIdToNames.Remove(int64(7))
+	loads = 0
+	attrListIdToNames.LoadFunc = func(ctx context.Context, key lru.Key) (interface{}, error) {
+		loads++
+		return []string{"a", "b", "c"}, nil
+	}
+	_, err = decodeNonKeyAttributes(context.Background(), cbor.NewReader(bytes.NewReader(b.Bytes())), attrListIdToNames, nil)
+	var mismatch *cbor.AttributeListMismatchError
+	require.ErrorAs(t, err, &mismatch)
+	assert.EqualValues(t, 7, mismatch.ID)
+	assert.Equal(t, 2, loads)
+}
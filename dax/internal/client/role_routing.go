@@ -0,0 +1,58 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+// roleRoutes returns the routes to the untried nodes whose role suits op with role aware
+// routing: the leader for writes, which replicas would forward to it, and the replicas
+// for reads, leaving the leader to the writes. It returns nil without role aware routing
+// or when no such node is left, in which case any node serves the request.
+// c.lock must be held.
+func (c *cluster) roleRoutes(routes, tried []DaxAPI, op string) []DaxAPI {
+	if !c.config.RoleAwareRouting {
+		return nil
+	}
+	var role int
+	switch {
+	case IsWriteOperation(op):
+		role = roleLeader
+	case IsReadOperation(op):
+		role = roleReplica
+	default:
+		return nil
+	}
+	var preferred []DaxAPI
+	for _, cc := range c.active {
+		if cc.cfg.role == role && containsRoute(routes, cc.client) && !containsRoute(tried, cc.client) {
+			preferred = append(preferred, cc.client)
+		}
+	}
+	return preferred
+}
+
+// preferredRoutes narrows routes down to the nodes of the role suiting op, then to the
+// ones in the availability zone of the client, skipping either step when it leaves no
+// node. It returns nil when the requests can go to any node. c.lock must be held.
+func (c *cluster) preferredRoutes(routes, tried []DaxAPI, op string) []DaxAPI {
+	preferred := c.roleRoutes(routes, tried, op)
+	candidates := preferred
+	if len(candidates) == 0 {
+		candidates = routes
+	}
+	if local := c.localRoutes(candidates, tried); len(local) > 0 {
+		return local
+	}
+	return preferred
+}
@@ -0,0 +1,70 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster_roleAwareRouting(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.RoleAwareRouting = true
+	cluster, _ := newTestClusterWithConfig(cfg)
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111, role: roleLeader, availabilityZone: "us-west-2a"},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111, role: roleReplica, availabilityZone: "us-west-2a"},
+		{hostname: "node3", address: []byte{127, 0, 0, 3}, port: 8111, role: roleReplica, availabilityZone: "us-west-2b"},
+	}))
+	leader := cluster.active[hostPort{"127.0.0.1", 8111}].client
+	localReplica := cluster.active[hostPort{"127.0.0.2", 8111}].client
+
+	for i := 0; i < 20; i++ {
+		route, err := cluster.client(nil, OpPutItem)
+		require.NoError(t, err)
+		assert.Same(t, leader, route, "writes go to the leader")
+
+		route, err = cluster.client(nil, OpGetItem)
+		require.NoError(t, err)
+		assert.NotSame(t, leader, route, "reads go to the replicas")
+	}
+
+	route, err := cluster.client(leader, OpTransactWriteItems)
+	require.NoError(t, err)
+	assert.NotSame(t, leader, route, "retries of writes go to replicas once the leader was tried")
+
+	cluster.config.AvailabilityZoneAffinity = true
+	cluster.config.AvailabilityZone = "us-west-2a"
+	for i := 0; i < 20; i++ {
+		route, err = cluster.client(nil, OpQuery)
+		require.NoError(t, err)
+		assert.Same(t, localReplica, route, "reads prefer the replicas in the zone of the client")
+	}
+	route, err = cluster.client(nil, OpDeleteItem)
+	require.NoError(t, err)
+	assert.Same(t, leader, route)
+
+	selector := &recordingSelector{pick: func(routes []RouteState) DaxAPI { return routes[0].Route }}
+	cluster.config.RouteSelector = selector
+	_, err = cluster.client(nil, OpUpdateItem)
+	require.NoError(t, err)
+	require.Len(t, selector.routes, 1, "the selector picks among the nodes of the role")
+	assert.True(t, selector.routes[0].Leader)
+}
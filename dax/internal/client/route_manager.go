@@ -33,10 +33,16 @@ type routeManager struct {
 	failOpenTimeList       []time.Time   // recent times when fail open was enabled
 	multipleFailOpenWindow time.Duration // if we see multiple fail open events within this window, we will disable route manager.
 	disableDuration        time.Duration // disable route manager for this duration after multiple fail open in a row
-	timer                  *time.Timer
+	timer                  Timer
+	clock                  Clock
 	logger                 logging.Logger
 	logLevel               utils.LogLevelType
 	daxSdkMetrics          *daxSdkMetrics
+	events                 *eventBus
+	failedOpen             bool               // routing to all nodes since the last fail-open
+	forcedUntil            time.Time          // routes are not removed before then, see forceFailOpen
+	weights                map[DaxAPI]float64 // static weights of the routes, see setWeights
+	latencyWeighted        bool               // weights are scaled down with the latency, see pickRoute
 }
 
 func newRouteManager(
@@ -55,6 +61,7 @@ func newRouteManager(
 		logger:                 logger,
 		logLevel:               logLevel,
 		daxSdkMetrics:          daxSdkMetrics,
+		clock:                  SystemClock,
 	}
 }
 
@@ -85,6 +92,41 @@ func (r *routeManager) getRoute(prev DaxAPI) DaxAPI {
 	return r.routes[randInt]
 }
 
+// getRouteExcluding returns a random route which is not in tried, weighted when weights
+// apply. Once every route was tried, it falls back to avoiding only the most recently
+// tried one.
+func (r *routeManager) getRouteExcluding(tried []DaxAPI) DaxAPI {
+	numRoutes := len(r.routes)
+	if numRoutes == 0 {
+		return nil
+	}
+	if r.weighted() {
+		if untried := untriedRoutes(r.routes, tried); len(untried) > 0 {
+			return r.pickRoute(untried)
+		}
+	}
+	if len(tried) == 0 {
+		return r.getRoute(nil)
+	}
+	start := rand.Intn(numRoutes)
+	for i := 0; i < numRoutes; i++ {
+		route := r.routes[(start+i)%numRoutes]
+		if !containsRoute(tried, route) {
+			return route
+		}
+	}
+	return r.getRoute(tried[len(tried)-1])
+}
+
+func containsRoute(routes []DaxAPI, route DaxAPI) bool {
+	for _, r := range routes {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *routeManager) addRoute(endpoint string, route DaxAPI) {
 	if !r.isEnabled {
 		return
@@ -96,7 +138,8 @@ func (r *routeManager) addRoute(endpoint string, route DaxAPI) {
 	}
 	r.routes = append(r.routes, route)
 
-	countMetricInt64(context.Background(), r.daxSdkMetrics, daxRouteManagerRoutesAdded, 1)
+	countMetricInt64(context.Background(), r.daxSdkMetrics, daxRouteManagerRoutesAdded, 1, endpointAttr(endpoint))
+	r.events.publish(Event{Type: EventRouteAdded, Endpoint: endpoint})
 
 	r.debugLog("Added route: %s to active routes", endpoint)
 }
@@ -105,17 +148,23 @@ func (r *routeManager) removeRoute(endpoint string, route DaxAPI, allClients map
 	if !r.isEnabled {
 		return
 	}
+	if r.clock.Now().Before(r.forcedUntil) {
+		r.debugLog("FailOpen: forced, keeping route: %s in active routes", endpoint)
+		return
+	}
 
 	// Never remove more than one third of nodes
 	if float32(len(r.routes)-1) < 2*float32(len(allClients))/3 {
 		r.debugLog("FailOpen: Added all routes back to active routes")
-		curTime := time.Now()
+		curTime := r.clock.Now()
 
 		// Fail Open to all routes.
 		r.rebuildRoutes(allClients)
 		r.verifyAndDisable(curTime)
 
 		countMetricInt64(context.Background(), r.daxSdkMetrics, daxRouteManagerFailOpenEvents, 1)
+		r.failedOpen = true
+		r.events.publish(Event{Type: EventFailOpenEntered})
 
 		return
 	}
@@ -125,7 +174,9 @@ func (r *routeManager) removeRoute(endpoint string, route DaxAPI, allClients map
 			r.routes = append(r.routes[:i], r.routes[i+1:]...)
 			r.debugLog("Removed route: %s from active routes", endpoint)
 
-			countMetricInt64(context.Background(), r.daxSdkMetrics, daxRouteManagerRoutesRemoved, 1)
+			countMetricInt64(context.Background(), r.daxSdkMetrics, daxRouteManagerRoutesRemoved, 1, endpointAttr(endpoint))
+			r.events.publish(Event{Type: EventRouteRemoved, Endpoint: endpoint})
+			r.exitFailOpen()
 
 			return
 		}
@@ -152,11 +203,36 @@ func (r *routeManager) verifyAndDisable(failOpenTime time.Time) {
 
 	r.isEnabled = false
 
-	r.timer = time.AfterFunc(r.disableDuration, func() {
+	r.timer = r.clock.AfterFunc(r.disableDuration, func() {
 		r.isEnabled = true
+		r.exitFailOpen()
 	})
 }
 
+// forceFailOpen routes to all the nodes of allClients, whatever their health, and keeps
+// routing to them until until. A zero until ends a forced fail-open right away; the
+// routes are then removed again as the health checks fail.
+func (r *routeManager) forceFailOpen(until time.Time, allClients map[hostPort]clientAndConfig) {
+	r.forcedUntil = until
+	if until.IsZero() {
+		r.debugLog("FailOpen: forced fail-open ended")
+		return
+	}
+	r.rebuildRoutes(allClients)
+	r.debugLog("FailOpen: forced until %s, added all routes back to active routes", until)
+	if !r.failedOpen {
+		r.failedOpen = true
+		r.events.publish(Event{Type: EventFailOpenEntered})
+	}
+}
+
+func (r *routeManager) exitFailOpen() {
+	if r.failedOpen {
+		r.failedOpen = false
+		r.events.publish(Event{Type: EventFailOpenExited})
+	}
+}
+
 func (r *routeManager) rebuildRoutes(allClients map[hostPort]clientAndConfig) {
 	newRoutes := make([]DaxAPI, 0, len(allClients))
 	for _, cliAndCfg := range allClients {
@@ -182,7 +258,11 @@ type RouteManager interface {
 	setRoutes(routes []DaxAPI)
 	getAllRoutes() []DaxAPI
 	getRoute(prev DaxAPI) DaxAPI
+	getRouteExcluding(tried []DaxAPI) DaxAPI
+	pickRoute(candidates []DaxAPI) DaxAPI
+	setWeights(weights map[DaxAPI]float64)
 	addRoute(endpoint string, route DaxAPI)
 	removeRoute(endpoint string, route DaxAPI, allClients map[hostPort]clientAndConfig)
+	forceFailOpen(until time.Time, allClients map[hostPort]clientAndConfig)
 	close()
 }
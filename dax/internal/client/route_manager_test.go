@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-dax-go-v2/dax/leakcheck"
 	"github.com/aws/aws-dax-go-v2/dax/utils"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
@@ -70,6 +71,18 @@ func (m mockDaxAPI) TransactGetItemsWithOptions(ctx context.Context, input *dyna
 	panic("implement me")
 }
 
+func (m mockDaxAPI) BatchExecuteStatementWithOptions(ctx context.Context, input *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	panic("implement me")
+}
+
+func (m mockDaxAPI) ExecuteTransactionWithOptions(ctx context.Context, input *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	panic("implement me")
+}
+
+func (m mockDaxAPI) ExecuteStatementWithOptions(ctx context.Context, input *dynamodb.ExecuteStatementInput, output *dynamodb.ExecuteStatementOutput, opt RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	panic("implement me")
+}
+
 func (m mockDaxAPI) endpoints(ctx context.Context, opt RequestOptions) ([]serviceEndpoint, error) {
 	panic("implement me")
 }
@@ -137,6 +150,32 @@ func Test_getRoute(t *testing.T) {
 	}
 }
 
+func Test_getRouteExcluding(t *testing.T) {
+	tmp := &testMeterProvider{}
+	om, _ := buildDaxSdkMetrics(tmp)
+
+	rm := newRouteManager(true, time.Second, nil, utils.LogOff, om)
+	defer rm.close()
+	if rm.getRouteExcluding([]DaxAPI{mockDaxAPI{1}}) != nil {
+		t.Errorf("Expected nil route, got other")
+	}
+
+	daxAPI1 := mockDaxAPI{1}
+	daxAPI2 := mockDaxAPI{2}
+	daxAPI3 := mockDaxAPI{3}
+	rm.setRoutes(append([]DaxAPI{}, daxAPI1, daxAPI2, daxAPI3))
+
+	for i := 0; i < 100; i++ {
+		if r := rm.getRouteExcluding([]DaxAPI{daxAPI1, daxAPI2}); r != daxAPI3 {
+			t.Fatalf("Expected untried route daxAPI3, got %v", r)
+		}
+		// Once every route failed, only the last one is avoided.
+		if r := rm.getRouteExcluding([]DaxAPI{daxAPI1, daxAPI2, daxAPI3}); r == daxAPI3 {
+			t.Fatalf("Expected route other than the last tried one, got %v", r)
+		}
+	}
+}
+
 func Test_addRoute(t *testing.T) {
 	tmp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(tmp)
@@ -248,23 +287,29 @@ func Test_verifyAndDisable(t *testing.T) {
 	tmp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(tmp)
 
+	clk := newFakeClock()
 	rm := newRouteManager(true, time.Second, nil, utils.LogOff, om)
 	defer rm.close()
+	rm.clock = clk
 	rm.disableDuration = 100 * time.Millisecond
-	rm.failOpenTimeList = []time.Time{time.Now(), time.Now(), time.Now()}
-	rm.verifyAndDisable(time.Now())
+	rm.failOpenTimeList = []time.Time{clk.Now(), clk.Now(), clk.Now()}
+	rm.verifyAndDisable(clk.Now())
 	if rm.isEnabled {
 		t.Errorf("Expected isRouteManagerEnabled false but got true")
 	}
 
 	// this part tests the timer function
-	time.Sleep(105 * time.Millisecond)
+	clk.advance(99 * time.Millisecond)
+	if rm.isEnabled {
+		t.Errorf("Fail Open Callback re-opened the routeManager early")
+	}
+	clk.advance(time.Millisecond)
 	if !rm.isEnabled {
 		t.Errorf("Fail Open Callback didn't re-open the routeManager")
 	}
 
-	rm.failOpenTimeList = []time.Time{time.Now(), time.Now().Add(-5 * time.Second), time.Now().Add(-5 * time.Second)}
-	curTime := time.Now()
+	rm.failOpenTimeList = []time.Time{clk.Now(), clk.Now().Add(-5 * time.Second), clk.Now().Add(-5 * time.Second)}
+	curTime := clk.Now()
 	rm.verifyAndDisable(curTime)
 	if !rm.isEnabled {
 		t.Errorf("Fail Open are not continuous so, it shouldn't disable routeManager")
@@ -272,6 +317,7 @@ func Test_verifyAndDisable(t *testing.T) {
 }
 
 func Test_rebuildRoutes(t *testing.T) {
+	leakcheck.Check(t)
 	tmp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(tmp)
 
@@ -295,13 +341,14 @@ func Test_rebuildRoutes(t *testing.T) {
 }
 
 func Test_stopTimer(t *testing.T) {
+	leakcheck.Check(t)
 	tmp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(tmp)
 
 	rm := newRouteManager(true, time.Second, nil, utils.LogOff, om)
 	defer rm.close()
 	timer := time.AfterFunc(rm.disableDuration, func() { rm.isEnabled = true })
-	rm.timer = timer
+	rm.timer = systemTimer{timer}
 	rm.stopTimer()
 	if rm.timer != nil {
 		t.Errorf("stopTimer didn't set timer to nil")
@@ -357,3 +404,53 @@ func Test_daxConnectionsIdle(t *testing.T) {
 		daxConcurrentConnectionAttempts: 0,
 	})
 }
+
+func Test_forceFailOpen(t *testing.T) {
+	clk := newFakeClock()
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	rm := newRouteManager(true, time.Second, nil, utils.LogOff, om)
+	rm.clock = clk
+	rm.events = newEventBus()
+	events, cancel := rm.events.subscribe(4)
+	defer cancel()
+	defer rm.close()
+	daxAPI1 := mockDaxAPI{1}
+	allClients := map[hostPort]clientAndConfig{
+		hostPort{"dummy.1", 9111}: {client: daxAPI1},
+		hostPort{"dummy.2", 9111}: {client: mockDaxAPI{2}},
+		hostPort{"dummy.3", 9111}: {client: mockDaxAPI{3}},
+		hostPort{"dummy.4", 9111}: {client: mockDaxAPI{4}},
+	}
+	rm.setRoutes([]DaxAPI{mockDaxAPI{2}, mockDaxAPI{3}, mockDaxAPI{4}})
+
+	rm.forceFailOpen(clk.Now().Add(time.Minute), allClients)
+	if len(rm.routes) != 4 {
+		t.Errorf("Expected all four routes but got %v", rm.routes)
+	}
+	if e := <-events; e.Type != EventFailOpenEntered {
+		t.Errorf("Expected %s event but got %s", EventFailOpenEntered, e.Type)
+	}
+
+	rm.removeRoute("dummy.1:9111", daxAPI1, allClients)
+	if len(rm.routes) != 4 {
+		t.Errorf("Expected routes to be kept while fail-open is forced but got %v", rm.routes)
+	}
+
+	clk.advance(time.Minute)
+	rm.removeRoute("dummy.1:9111", daxAPI1, allClients)
+	if len(rm.routes) != 3 || containsRoute(rm.routes, daxAPI1) {
+		t.Errorf("Expected route to be removed once the forced fail-open elapsed but got %v", rm.routes)
+	}
+	for _, want := range []EventType{EventRouteRemoved, EventFailOpenExited} {
+		if e := <-events; e.Type != want {
+			t.Errorf("Expected %s event but got %s", want, e.Type)
+		}
+	}
+
+	rm.forceFailOpen(clk.Now().Add(time.Minute), allClients)
+	rm.forceFailOpen(time.Time{}, allClients)
+	rm.removeRoute("dummy.1:9111", daxAPI1, allClients)
+	if len(rm.routes) != 3 {
+		t.Errorf("Expected a zero time to end the forced fail-open but got %v", rm.routes)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RouteSelector picks the node a request is sent to, see Config.RouteSelector.
+// SelectRoute is called concurrently and must not block. prev is the route the previous
+// attempt of the request failed on, or nil for the first attempt. The routes are the
+// nodes currently routed to; returning nil, or a route which isn't one of them, falls
+// back to the default random selection.
+type RouteSelector interface {
+	SelectRoute(prev DaxAPI, op string, routes []RouteState) DaxAPI
+}
+
+// RouteState describes a route offered to a RouteSelector.
+type RouteState struct {
+	Route DaxAPI
+	// Endpoint is the host:port of the node.
+	Endpoint string
+	// AvailabilityZone is the availability zone of the node.
+	AvailabilityZone string
+	// Leader reports whether the node is the leader of the cluster, which writes go
+	// through.
+	Leader bool
+	// Tried reports whether an attempt of the request already failed on the route.
+	Tried bool
+	// Latency is the exponentially weighted moving average of the latency of the requests
+	// to the node, zero before its first request.
+	Latency time.Duration
+	// InFlight is the number of requests to the node in progress.
+	InFlight int
+}
+
+// RoundRobinRouteSelector cycles through the routes, skipping the ones already tried by
+// the request while untried ones are left.
+type RoundRobinRouteSelector struct {
+	next uint64
+}
+
+func (s *RoundRobinRouteSelector) SelectRoute(prev DaxAPI, op string, routes []RouteState) DaxAPI {
+	if len(routes) == 0 {
+		return nil
+	}
+	start := atomic.AddUint64(&s.next, 1)
+	for i := range routes {
+		if r := routes[(start+uint64(i))%uint64(len(routes))]; !r.Tried {
+			return r.Route
+		}
+	}
+	return routes[start%uint64(len(routes))].Route
+}
+
+// selectRoute returns the route picked by the configured selector among the current
+// routes, narrowed down by role and availability zone when enabled, or nil when the
+// default selection applies. Requests with an affinity key go to the node the key is
+// assigned to instead. c.lock must be held.
+func (c *cluster) selectRoute(tried []DaxAPI, op string, affinity []byte) DaxAPI {
+	routes := c.routeManager.getAllRoutes()
+	preferred := c.preferredRoutes(routes, tried, op)
+	if len(affinity) > 0 {
+		candidates := preferred
+		if len(candidates) == 0 {
+			candidates = untriedRoutes(routes, tried)
+		}
+		if route := c.affinityRoute(candidates, affinity); route != nil {
+			return route
+		}
+	}
+	if len(preferred) > 0 {
+		if c.config.RouteSelector == nil {
+			return c.routeManager.pickRoute(preferred)
+		}
+		routes = preferred
+	}
+	if c.config.RouteSelector == nil || len(routes) == 0 {
+		return nil
+	}
+	states := make([]RouteState, len(routes))
+	for i, r := range routes {
+		states[i] = RouteState{Route: r, Tried: containsRoute(tried, r)}
+		if lr, ok := r.(routeLoadReporter); ok {
+			states[i].Latency, states[i].InFlight = lr.routeLoad()
+		}
+	}
+	for hp, cc := range c.active {
+		for i := range states {
+			if states[i].Route == cc.client {
+				states[i].Endpoint = hp.String()
+				states[i].AvailabilityZone = cc.cfg.availabilityZone
+				states[i].Leader = cc.cfg.role == roleLeader
+			}
+		}
+	}
+	var prev DaxAPI
+	if len(tried) > 0 {
+		prev = tried[len(tried)-1]
+	}
+	route := c.config.RouteSelector.SelectRoute(prev, op, states)
+	if route == nil || !containsRoute(routes, route) {
+		return nil
+	}
+	return route
+}
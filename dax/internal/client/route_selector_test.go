@@ -0,0 +1,90 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSelector struct {
+	prev   DaxAPI
+	op     string
+	routes []RouteState
+	pick   func(routes []RouteState) DaxAPI
+}
+
+func (s *recordingSelector) SelectRoute(prev DaxAPI, op string, routes []RouteState) DaxAPI {
+	s.prev, s.op, s.routes = prev, op, routes
+	return s.pick(routes)
+}
+
+func TestCluster_routeSelector(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	selector := &recordingSelector{pick: func(routes []RouteState) DaxAPI { return routes[len(routes)-1].Route }}
+	cfg.RouteSelector = selector
+	cluster, _ := newTestClusterWithConfig(cfg)
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111},
+	}))
+
+	route, err := cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	require.Len(t, selector.routes, 2)
+	assert.Same(t, selector.routes[1].Route, route)
+	assert.Equal(t, OpGetItem, selector.op)
+	assert.Nil(t, selector.prev)
+	for _, r := range selector.routes {
+		assert.Contains(t, []string{"127.0.0.1:8111", "127.0.0.2:8111"}, r.Endpoint)
+		assert.False(t, r.Tried)
+	}
+
+	_, err = cluster.client(route, OpGetItem)
+	require.NoError(t, err)
+	assert.Same(t, route, selector.prev)
+	assert.True(t, selector.routes[1].Tried)
+	assert.False(t, selector.routes[0].Tried)
+
+	selector.pick = func([]RouteState) DaxAPI { return &testClient{} }
+	route, err = cluster.client(nil, OpGetItem)
+	require.NoError(t, err)
+	assert.Contains(t, cluster.getAllRoutes(), route, "unknown routes fall back to the default selection")
+}
+
+func TestRoundRobinRouteSelector(t *testing.T) {
+	a, b, c := &testClient{}, &testClient{}, &testClient{}
+	routes := []RouteState{{Route: a}, {Route: b}, {Route: c}}
+	s := &RoundRobinRouteSelector{}
+
+	seen := map[DaxAPI]int{}
+	for i := 0; i < 6; i++ {
+		seen[s.SelectRoute(nil, OpGetItem, routes)]++
+	}
+	assert.Equal(t, map[DaxAPI]int{a: 2, b: 2, c: 2}, seen)
+
+	routes[0].Tried, routes[1].Tried = true, true
+	for i := 0; i < 3; i++ {
+		assert.Same(t, c, s.SelectRoute(a, OpGetItem, routes))
+	}
+	routes[2].Tried = true
+	assert.NotNil(t, s.SelectRoute(c, OpGetItem, routes))
+	assert.Nil(t, s.SelectRoute(nil, OpGetItem, nil))
+}
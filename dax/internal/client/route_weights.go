@@ -0,0 +1,113 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// minLatencyWeight is the smallest fraction of its weight latency weighting leaves a node,
+// so that a slow node keeps serving a few requests and its latency recovers once it does.
+const minLatencyWeight = 0.1
+
+// validateNodeWeights rejects weights which can't scale a share of the requests.
+func validateNodeWeights(weights map[string]float64) error {
+	for host, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("NodeWeights of %q must be a non-negative number", host))
+		}
+	}
+	return nil
+}
+
+// setWeights sets the static weights of the routes. Routes missing from weights have a
+// weight of 1.
+func (r *routeManager) setWeights(weights map[DaxAPI]float64) {
+	r.weights = weights
+}
+
+func (r *routeManager) weighted() bool {
+	return len(r.weights) > 0 || r.latencyWeighted
+}
+
+// pickRoute returns one of candidates, chosen with a probability proportional to its
+// weight. With latency weighting, the weight of a node is scaled by the ratio of the
+// latency of the fastest candidate to its own; nodes without a latency yet keep their
+// weight. Candidates are picked uniformly when none has a positive weight.
+func (r *routeManager) pickRoute(candidates []DaxAPI) DaxAPI {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if !r.weighted() {
+		return candidates[rand.Intn(len(candidates))]
+	}
+
+	latencies := make([]time.Duration, len(candidates))
+	var fastest time.Duration
+	if r.latencyWeighted {
+		for i, c := range candidates {
+			if lr, ok := c.(routeLoadReporter); ok {
+				latencies[i], _ = lr.routeLoad()
+			}
+			if latencies[i] > 0 && (fastest == 0 || latencies[i] < fastest) {
+				fastest = latencies[i]
+			}
+		}
+	}
+
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, c := range candidates {
+		w := 1.0
+		if sw, ok := r.weights[c]; ok {
+			w = sw
+		}
+		if fastest > 0 && latencies[i] > 0 {
+			w *= math.Max(float64(fastest)/float64(latencies[i]), minLatencyWeight)
+		}
+		weights[i] = w
+		total += w
+	}
+	if total <= 0 {
+		return candidates[rand.Intn(len(candidates))]
+	}
+	x := rand.Float64() * total
+	for i, w := range weights {
+		if x < w {
+			return candidates[i]
+		}
+		x -= w
+	}
+	return candidates[len(candidates)-1]
+}
+
+// updateRouteWeights hands the weights of Config.NodeWeights to the route manager for the
+// current clients of the nodes. c.lock must be held.
+func (c *cluster) updateRouteWeights() {
+	if len(c.config.NodeWeights) == 0 {
+		return
+	}
+	weights := make(map[DaxAPI]float64, len(c.config.NodeWeights))
+	for _, cc := range c.active {
+		if w, ok := c.config.NodeWeights[cc.cfg.hostname]; ok {
+			weights[cc.client] = w
+		}
+	}
+	c.routeManager.setWeights(weights)
+}
@@ -0,0 +1,95 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shares returns the fraction of n picks of rm each route received.
+func shares(rm *routeManager, tried []DaxAPI, n int) map[DaxAPI]float64 {
+	counts := map[DaxAPI]float64{}
+	for i := 0; i < n; i++ {
+		counts[rm.getRouteExcluding(tried)]++
+	}
+	for r := range counts {
+		counts[r] /= float64(n)
+	}
+	return counts
+}
+
+func TestRouteManager_weights(t *testing.T) {
+	a, b, c := &testClient{}, &testClient{}, &testClient{}
+	rm := newRouteManager(true, time.Second, nil, 0, nil)
+	rm.setRoutes([]DaxAPI{a, b, c})
+
+	rm.setWeights(map[DaxAPI]float64{b: 0.5, c: 0})
+	s := shares(rm, nil, 30000)
+	assert.InDelta(t, 2.0/3, s[a], 0.03)
+	assert.InDelta(t, 1.0/3, s[b], 0.03)
+	assert.Zero(t, s[c], "a node with a weight of 0 receives no first attempts")
+	assert.Equal(t, map[DaxAPI]float64{c: 1}, shares(rm, []DaxAPI{a, b}, 100), "retries reach it once the others were tried")
+
+	rm.setWeights(map[DaxAPI]float64{a: 0, b: 0, c: 0})
+	assert.Len(t, shares(rm, nil, 1000), 3, "routes are picked uniformly without a positive weight")
+}
+
+func TestRouteManager_latencyWeights(t *testing.T) {
+	fast := &loadedClient{latency: 2 * time.Millisecond}
+	slow := &loadedClient{latency: 8 * time.Millisecond}
+	stalled := &loadedClient{latency: time.Second}
+	fresh := &loadedClient{}
+	rm := newRouteManager(true, time.Second, nil, 0, nil)
+	rm.latencyWeighted = true
+	rm.setRoutes([]DaxAPI{fast, slow, stalled, fresh})
+
+	s := shares(rm, nil, 40000)
+	total := 1 + 0.25 + minLatencyWeight + 1
+	assert.InDelta(t, 1/total, s[fast], 0.02)
+	assert.InDelta(t, 0.25/total, s[slow], 0.02)
+	assert.InDelta(t, minLatencyWeight/total, s[stalled], 0.02, "a slow node keeps a minimum share")
+	assert.InDelta(t, 1/total, s[fresh], 0.02, "nodes without a latency keep their weight")
+}
+
+func TestCluster_nodeWeights(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Credentials = &testCredentialProvider{}
+	cfg.NodeWeights = map[string]float64{"node2": 0}
+	require.NoError(t, cfg.validate())
+	cluster, _ := newTestClusterWithConfig(cfg)
+	require.NoError(t, cluster.update([]serviceEndpoint{
+		{hostname: "node1", address: []byte{127, 0, 0, 1}, port: 8111},
+		{hostname: "node2", address: []byte{127, 0, 0, 2}, port: 8111},
+	}))
+	node1 := cluster.active[hostPort{"127.0.0.1", 8111}].client
+	for i := 0; i < 20; i++ {
+		route, err := cluster.client(nil, OpGetItem)
+		require.NoError(t, err)
+		assert.Same(t, node1, route)
+	}
+
+	for _, w := range []float64{-1, math.NaN(), math.Inf(1)} {
+		cfg.NodeWeights = map[string]float64{"node2": w}
+		assert.Error(t, cfg.validate(), w)
+	}
+}
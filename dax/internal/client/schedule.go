@@ -0,0 +1,172 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+// TaskSchedule controls a single background task of the client.
+type TaskSchedule struct {
+	// Interval between two runs of the task. Zero keeps the value of the corresponding
+	// Config field (e.g. ClusterUpdateInterval), or the default when that is unset too.
+	Interval time.Duration
+	// Disabled stops the task from being started at all.
+	Disabled bool
+}
+
+// Schedule groups the background tasks run by the client. Disabling tasks is meant
+// for embedding scenarios where the caller drives refreshes itself; a client without
+// cluster refresh only learns about the nodes present when it was created, and one
+// without health checks doesn't remove the routes to the nodes facing network errors, as
+// nothing would add them back.
+type Schedule struct {
+	ClusterRefresh     TaskSchedule
+	IdleConnectionReap TaskSchedule
+	HealthCheck        TaskSchedule
+	OperationReport    TaskSchedule
+}
+
+const (
+	defaultClusterUpdateInterval     = 4 * time.Second
+	defaultIdleConnectionReapDelay   = 30 * time.Second
+	defaultClientHealthCheckInterval = 5 * time.Second
+	defaultSeedLookupTTL             = 30 * time.Second
+	defaultSeedLookupNegativeTTL     = 5 * time.Second
+)
+
+// Minimum values for the Config durations. Smaller values are raised to the minimum
+// with a warning, since very short intervals mostly add load on the cluster, in
+// particular on the discovery endpoint.
+const (
+	MinClusterUpdateInterval     = time.Second
+	MinClusterUpdateThreshold    = 10 * time.Millisecond
+	MinIdleConnectionReapDelay   = time.Second
+	MinClientHealthCheckInterval = time.Second
+	MinHealthCheckTimeout        = 10 * time.Millisecond
+	MinOperationReportInterval   = time.Second
+	MinSeedLookupTTL             = time.Second
+	MinHedgeDelay                = time.Millisecond
+)
+
+type durationField struct {
+	name string
+	val  *time.Duration
+	min  time.Duration
+}
+
+func (cfg *Config) durationFields() []durationField {
+	return []durationField{
+		{"ClusterUpdateInterval", &cfg.ClusterUpdateInterval, MinClusterUpdateInterval},
+		{"ClusterUpdateThreshold", &cfg.ClusterUpdateThreshold, MinClusterUpdateThreshold},
+		{"ClusterUpdateMaxBackoff", &cfg.ClusterUpdateMaxBackoff, MinClusterUpdateInterval},
+		{"IdleConnectionReapDelay", &cfg.IdleConnectionReapDelay, MinIdleConnectionReapDelay},
+		{"ClientHealthCheckInterval", &cfg.ClientHealthCheckInterval, MinClientHealthCheckInterval},
+		{"HealthCheckTimeout", &cfg.HealthCheckTimeout, MinHealthCheckTimeout},
+		{"OperationReportInterval", &cfg.OperationReportInterval, MinOperationReportInterval},
+		{"Schedule.ClusterRefresh.Interval", &cfg.Schedule.ClusterRefresh.Interval, MinClusterUpdateInterval},
+		{"Schedule.IdleConnectionReap.Interval", &cfg.Schedule.IdleConnectionReap.Interval, MinIdleConnectionReapDelay},
+		{"Schedule.HealthCheck.Interval", &cfg.Schedule.HealthCheck.Interval, MinClientHealthCheckInterval},
+		{"Schedule.OperationReport.Interval", &cfg.Schedule.OperationReport.Interval, MinOperationReportInterval},
+		{"SeedLookupTTL", &cfg.SeedLookupTTL, MinSeedLookupTTL},
+		{"SeedLookupNegativeTTL", &cfg.SeedLookupNegativeTTL, MinSeedLookupTTL},
+		{"HedgeDelay", &cfg.HedgeDelay, MinHedgeDelay},
+	}
+}
+
+// validateDurations rejects negative durations. Zero is allowed everywhere and means
+// the default is used, or no caching for the seed lookup durations.
+func (cfg *Config) validateDurations() error {
+	for _, f := range cfg.durationFields() {
+		if *f.val < 0 {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("%s cannot be negative", f.name))
+		}
+	}
+	return nil
+}
+
+// clampDurations raises every configured duration below its minimum to that minimum.
+func (cfg *Config) clampDurations() {
+	for _, f := range cfg.durationFields() {
+		if *f.val > 0 && *f.val < f.min {
+			if cfg.logger != nil {
+				cfg.logger.Logf(logging.Warn, "%s of %s is below the minimum of %s, using %s", f.name, *f.val, f.min, f.min)
+			}
+			*f.val = f.min
+		}
+	}
+}
+
+// schedule resolves the effective schedule, falling back to the legacy interval fields
+// and then to the defaults for tasks without an explicit interval.
+func (cfg *Config) schedule() Schedule {
+	s := cfg.Schedule
+	resolve := func(t *TaskSchedule, legacy, def time.Duration) {
+		if t.Interval == 0 {
+			t.Interval = legacy
+		}
+		if t.Interval == 0 {
+			t.Interval = def
+		}
+	}
+	resolve(&s.ClusterRefresh, cfg.ClusterUpdateInterval, defaultClusterUpdateInterval)
+	resolve(&s.IdleConnectionReap, cfg.IdleConnectionReapDelay, defaultIdleConnectionReapDelay)
+	resolve(&s.HealthCheck, cfg.ClientHealthCheckInterval, defaultClientHealthCheckInterval)
+	resolve(&s.OperationReport, cfg.OperationReportInterval, 0)
+	// The operation report is opt-in, so an unset interval means it is off.
+	if s.OperationReport.Interval == 0 {
+		s.OperationReport.Disabled = true
+	}
+	return s
+}
+
+func (s Schedule) validate() error {
+	tasks := []struct {
+		name string
+		task TaskSchedule
+	}{
+		{"ClusterRefresh", s.ClusterRefresh},
+		{"IdleConnectionReap", s.IdleConnectionReap},
+		{"HealthCheck", s.HealthCheck},
+		{"OperationReport", s.OperationReport},
+	}
+	for _, t := range tasks {
+		if !t.task.Disabled && t.task.Interval <= 0 {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("Schedule.%s.Interval must be positive unless the task is disabled", t.name))
+		}
+	}
+	return nil
+}
+
+// startTask runs action every t.Interval on e, unless the task is disabled.
+func (e *taskExecutor) startTask(t TaskSchedule, action func() error) {
+	if t.Disabled {
+		return
+	}
+	e.start(t.Interval, action)
+}
+
+// startTaskWithDelay is startTask with the delay before every run returned by delay, given
+// the interval of t.
+func (e *taskExecutor) startTaskWithDelay(t TaskSchedule, delay func(interval time.Duration) time.Duration, action func() error) {
+	if t.Disabled {
+		return
+	}
+	e.startWithDelay(func() time.Duration { return delay(t.Interval) }, action)
+}
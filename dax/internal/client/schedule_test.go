@@ -0,0 +1,124 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_schedule(t *testing.T) {
+	cfg := Config{ClusterUpdateInterval: time.Second}
+	cfg.Schedule.IdleConnectionReap.Interval = time.Minute
+	cfg.Schedule.HealthCheck.Disabled = true
+
+	s := cfg.schedule()
+	assert.Equal(t, TaskSchedule{Interval: time.Second}, s.ClusterRefresh)
+	assert.Equal(t, TaskSchedule{Interval: time.Minute}, s.IdleConnectionReap)
+	assert.Equal(t, TaskSchedule{Interval: defaultClientHealthCheckInterval, Disabled: true}, s.HealthCheck)
+	assert.True(t, s.OperationReport.Disabled)
+	assert.NoError(t, s.validate())
+
+	cfg.Schedule.ClusterRefresh.Interval = -time.Second
+	assert.Error(t, cfg.schedule().validate())
+	cfg.Schedule.ClusterRefresh.Disabled = true
+	assert.NoError(t, cfg.schedule().validate())
+}
+
+func TestCluster_startDisabledTasks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.Schedule.ClusterRefresh.Disabled = true
+	cfg.Schedule.IdleConnectionReap.Disabled = true
+
+	cluster, _ := newTestClusterWithConfig(cfg)
+	defer cluster.Close()
+	assert.NoError(t, cluster.start(context.Background()))
+	assert.Equal(t, int32(0), cluster.executor.numTasks())
+
+	cfg.Schedule.IdleConnectionReap.Disabled = false
+	cluster2, _ := newTestClusterWithConfig(cfg)
+	defer cluster2.Close()
+	assert.NoError(t, cluster2.start(context.Background()))
+	assert.Equal(t, int32(1), cluster2.executor.numTasks())
+}
+
+func TestCluster_healthCheckDisabled(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.RouteManagerEnabled = true
+
+	cluster, _ := newTestClusterWithConfig(cfg)
+	assert.True(t, cluster.isRouteManagerEnabled())
+	assert.True(t, cluster.routeManager.(*routeManager).isEnabled)
+
+	// without health checks, routes removed after network errors would never come back
+	cfg.Schedule.HealthCheck.Disabled = true
+	cluster, _ = newTestClusterWithConfig(cfg)
+	assert.False(t, cluster.isRouteManagerEnabled())
+	assert.False(t, cluster.routeManager.(*routeManager).isEnabled)
+}
+
+func TestConfig_durationBoundaries(t *testing.T) {
+	fields := (&Config{}).durationFields()
+	for i := range fields {
+		name := fields[i].name
+		min := fields[i].min
+		cases := []struct {
+			in, out time.Duration
+			err     bool
+		}{
+			{in: 0, out: 0},
+			{in: time.Nanosecond, out: min},
+			{in: min - time.Nanosecond, out: min},
+			{in: min, out: min},
+			{in: min + time.Nanosecond, out: min + time.Nanosecond},
+			{in: -time.Nanosecond, err: true},
+		}
+		for _, c := range cases {
+			cfg := Config{logger: &logging.Nop{}}
+			*cfg.durationFields()[i].val = c.in
+			err := cfg.validateDurations()
+			if c.err {
+				assert.Error(t, err, "%s=%s", name, c.in)
+				continue
+			}
+			assert.NoError(t, err, "%s=%s", name, c.in)
+			cfg.clampDurations()
+			assert.Equal(t, c.out, *cfg.durationFields()[i].val, "%s=%s", name, c.in)
+		}
+	}
+}
+
+func TestConfig_validateNegativeDuration(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8111"}
+	cfg.Region = "us-west-2"
+	cfg.ClusterUpdateInterval = -time.Second
+	_, err := newCluster(cfg)
+	assert.Error(t, err)
+
+	cfg.ClusterUpdateInterval = time.Millisecond
+	c, err := newCluster(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, MinClusterUpdateInterval, c.config.ClusterUpdateInterval)
+}
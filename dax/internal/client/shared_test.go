@@ -19,6 +19,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/metrics"
 	"github.com/stretchr/testify/assert"
 )
@@ -131,11 +132,21 @@ func (t *testMeter) Float64AsyncGauge(name string, _ metrics.Float64Callback, _
 
 type testInstrument[N int64 | float64] struct {
 	data      []N
+	props     []smithy.Properties
 	callbacks []any
 	stopCh    chan bool
 }
 
-func (t *testInstrument[N]) Add(_ context.Context, n N, _ ...metrics.RecordMetricOption) {
+func (t *testInstrument[N]) record(opts []metrics.RecordMetricOption) {
+	var o metrics.RecordMetricOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	t.props = append(t.props, o.Properties)
+}
+
+func (t *testInstrument[N]) Add(_ context.Context, n N, opts ...metrics.RecordMetricOption) {
+	t.record(opts)
 	if len(t.data) == 0 {
 		t.data = append(t.data, n)
 	} else {
@@ -143,43 +154,59 @@ func (t *testInstrument[N]) Add(_ context.Context, n N, _ ...metrics.RecordMetri
 	}
 }
 
-func (t *testInstrument[N]) Sample(_ context.Context, n N, _ ...metrics.RecordMetricOption) {
+func (t *testInstrument[N]) Sample(_ context.Context, n N, opts ...metrics.RecordMetricOption) {
+	t.record(opts)
 	t.data = []N{n}
 }
 
-func (t *testInstrument[N]) Record(_ context.Context, n N, _ ...metrics.RecordMetricOption) {
+func (t *testInstrument[N]) Record(_ context.Context, n N, opts ...metrics.RecordMetricOption) {
+	t.record(opts)
 	t.data = append(t.data, n)
 }
 
 func (testInstrument[_]) Stop() {}
 
-func counter(om *daxSdkMetrics, name string) (metrics.Int64Counter, bool, int) {
+// testInstrumentOf returns the test instrument behind the smithy-go adapter i.
+func testInstrumentOf(i any) (*testInstrument[int64], bool) {
+	switch a := i.(type) {
+	case smithyCounter:
+		i = a.Int64Counter
+	case smithyHistogram:
+		i = a.Int64Histogram
+	case smithyGauge:
+		i = a.Int64Gauge
+	}
+	t, ok := i.(*testInstrument[int64])
+	return t, ok
+}
+
+func counter(om *daxSdkMetrics, name string) (int64Counter, bool, int) {
 	c, ok := om.counters[name]
 	val := 0
 	if c != nil {
-		if i, iOk := c.(*testInstrument[int64]); iOk && len(i.data) > 0 {
+		if i, iOk := testInstrumentOf(c); iOk && len(i.data) > 0 {
 			val = int(i.data[len(i.data)-1])
 		}
 	}
 	return c, ok, val
 }
 
-func gauge(om *daxSdkMetrics, name string) (metrics.Int64Gauge, bool, int) {
+func gauge(om *daxSdkMetrics, name string) (int64Gauge, bool, int) {
 	g, ok := om.gauges[name]
 	val := 0
 	if g != nil {
-		if i, iOk := g.(*testInstrument[int64]); iOk && len(i.data) > 0 {
+		if i, iOk := testInstrumentOf(g); iOk && len(i.data) > 0 {
 			val = int(i.data[0])
 		}
 	}
 	return g, ok, val
 }
 
-func histogram(om *daxSdkMetrics, name string) (metrics.Int64Histogram, bool, int) {
+func histogram(om *daxSdkMetrics, name string) (int64Histogram, bool, int) {
 	h, ok := om.histograms[name]
 	val := 0
 	if h != nil {
-		if i, iOk := h.(*testInstrument[int64]); iOk && len(i.data) > 0 {
+		if i, iOk := testInstrumentOf(h); iOk && len(i.data) > 0 {
 			val = len(i.data)
 		}
 	}
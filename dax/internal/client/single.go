@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
@@ -31,6 +33,7 @@ import (
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/logging"
 	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/tracing"
 )
 
 const (
@@ -39,6 +42,9 @@ const (
 
 	authTtlSecs          = 5 * 60
 	tubeAuthWindowScalar = 0.75
+	// defaultAuthTimeout bounds retrieving the credentials for authenticating a connection
+	// unless Config.AuthTimeout is set.
+	defaultAuthTimeout = 5 * time.Second
 
 	emptyAttributeListId = 1
 )
@@ -59,6 +65,9 @@ const (
 	OpTransactWriteItems    = "TransactWriteItems"
 	OpQuery                 = "Query"
 	OpScan                  = "Scan"
+	OpExecuteStatement      = "ExecuteStatement"
+	OpBatchExecuteStatement = "BatchExecuteStatement"
+	OpExecuteTransaction    = "ExecuteTransaction"
 )
 
 const (
@@ -70,6 +79,7 @@ type SingleDaxClient struct {
 	region             string
 	credentials        aws.CredentialsProvider
 	tubeAuthWindowSecs int64
+	authTimeout        time.Duration
 	executor           *taskExecutor
 
 	pool              *tubePool
@@ -80,6 +90,13 @@ type SingleDaxClient struct {
 	healthStatus HealthStatus
 
 	daxSdkMetrics *daxSdkMetrics
+	hotKeys       *keySketch
+	queryShapes   *keySketch
+	events        *eventBus
+	clock         Clock // SystemClock when nil
+
+	batchWriteConflicts BatchWriteConflicts
+	latency             latencyEWMA
 }
 
 func NewSingleClient(endpoint string, connConfigData connConfig, region string, credentials aws.CredentialsProvider, routeListener RouteListener, sdkMetrics *daxSdkMetrics) (*SingleDaxClient, error) {
@@ -115,6 +132,7 @@ func newSingleClientWithOptions(
 		region:             region,
 		credentials:        credentials,
 		tubeAuthWindowSecs: authTtlSecs * tubeAuthWindowScalar,
+		authTimeout:        defaultAuthTimeout,
 		pool:               newTubePoolWithOptions(endpoint, po, connConfigData, sdkMetrics),
 		executor:           newExecutor(),
 		healthStatus:       newHealthStatus(endpoint, routeListener),
@@ -184,18 +202,37 @@ func (client *SingleDaxClient) Close() error {
 	return nil
 }
 
+// CloseWithContext closes the client like Close, then waits for its health checks to
+// exit and for requests in flight to finish until ctx is done.
+func (client *SingleDaxClient) CloseWithContext(ctx context.Context) error {
+	client.executor.stopAll()
+	var errs []error
+	if client.pool != nil {
+		errs = append(errs, client.pool.CloseWithContext(ctx))
+	}
+	if err := client.executor.wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("health checks: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
 func (client *SingleDaxClient) startHealthChecks(cc *cluster, host hostPort) {
 	cc.debugLog("Starting health checks for :: " + host.host)
-	client.executor.start(cc.config.ClientHealthCheckInterval, func() error {
-		ctx, cfn := context.WithTimeout(context.Background(), 1*time.Second)
+	client.executor.startTask(cc.config.schedule().HealthCheck, func() error {
+		if !cc.probes.begin(host) {
+			cc.debugLog("Skipping health check, a probe is already in flight for host :: " + host.host)
+			return nil
+		}
+		defer cc.probes.end(host)
+		ctx, cfn := context.WithTimeout(context.Background(), cc.config.healthCheckTimeout())
 		defer cfn()
 		var err error
 		opts := RequestOptions{}
-		opts.RetryMaxAttempts = 3
+		opts.RetryMaxAttempts = cc.config.healthCheckRetries()
 		_, err = client.endpoints(ctx, opts)
 		if err != nil {
 			cc.debugLog("Health checks failed with error " + err.Error() + " for host :: " + host.host)
-			cc.onHealthCheckFailed(host)
+			cc.onHealthCheckFailed(host, client)
 		} else {
 			client.healthStatus.onHealthCheckSuccess(client)
 			cc.debugLog("Health checks succeeded for host:: " + host.host)
@@ -278,6 +315,7 @@ func (client *SingleDaxClient) defineKeySchema(ctx context.Context, table string
 }
 
 func (client *SingleDaxClient) PutItemWithOptions(ctx context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, opt RequestOptions) (*dynamodb.PutItemOutput, error) {
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodePutItemInput(ctx, input, client.keySchema, client.attrNamesListToId, writer)
 	}
@@ -287,13 +325,14 @@ func (client *SingleDaxClient) PutItemWithOptions(ctx context.Context, input *dy
 		return err
 	}
 
-	if err = client.executeWithRetries(ctx, OpPutItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpPutItem, input, opt, encoder, decoder); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func (client *SingleDaxClient) DeleteItemWithOptions(ctx context.Context, input *dynamodb.DeleteItemInput, output *dynamodb.DeleteItemOutput, opt RequestOptions) (*dynamodb.DeleteItemOutput, error) {
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeDeleteItemInput(ctx, input, client.keySchema, writer)
 	}
@@ -302,13 +341,14 @@ func (client *SingleDaxClient) DeleteItemWithOptions(ctx context.Context, input
 		output, err = decodeDeleteItemOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpDeleteItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpDeleteItem, input, opt, encoder, decoder); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func (client *SingleDaxClient) UpdateItemWithOptions(ctx context.Context, input *dynamodb.UpdateItemInput, output *dynamodb.UpdateItemOutput, opt RequestOptions) (*dynamodb.UpdateItemOutput, error) {
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeUpdateItemInput(ctx, input, client.keySchema, writer)
 	}
@@ -317,13 +357,14 @@ func (client *SingleDaxClient) UpdateItemWithOptions(ctx context.Context, input
 		output, err = decodeUpdateItemOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpUpdateItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpUpdateItem, input, opt, encoder, decoder); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func (client *SingleDaxClient) GetItemWithOptions(ctx context.Context, input *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, opt RequestOptions) (*dynamodb.GetItemOutput, error) {
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeGetItemInput(ctx, input, client.keySchema, writer)
 	}
@@ -332,7 +373,7 @@ func (client *SingleDaxClient) GetItemWithOptions(ctx context.Context, input *dy
 		output, err = decodeGetItemOutput(ctx, reader, input, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpGetItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpGetItem, input, opt, encoder, decoder); err != nil {
 		client.healthStatus.onErrorInReadRequest(err, client)
 		return output, err
 	}
@@ -341,6 +382,7 @@ func (client *SingleDaxClient) GetItemWithOptions(ctx context.Context, input *dy
 }
 
 func (client *SingleDaxClient) ScanWithOptions(ctx context.Context, input *dynamodb.ScanInput, output *dynamodb.ScanOutput, opt RequestOptions) (*dynamodb.ScanOutput, error) {
+	client.sampleShape(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeScanInput(ctx, input, client.keySchema, writer)
 	}
@@ -349,7 +391,7 @@ func (client *SingleDaxClient) ScanWithOptions(ctx context.Context, input *dynam
 		output, err = decodeScanOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpScan, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpScan, input, opt, encoder, decoder); err != nil {
 		client.healthStatus.onErrorInReadRequest(err, client)
 		return output, err
 	}
@@ -358,6 +400,8 @@ func (client *SingleDaxClient) ScanWithOptions(ctx context.Context, input *dynam
 }
 
 func (client *SingleDaxClient) QueryWithOptions(ctx context.Context, input *dynamodb.QueryInput, output *dynamodb.QueryOutput, opt RequestOptions) (*dynamodb.QueryOutput, error) {
+	client.sampleShape(ctx, input)
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeQueryInput(ctx, input, client.keySchema, writer)
 	}
@@ -366,7 +410,7 @@ func (client *SingleDaxClient) QueryWithOptions(ctx context.Context, input *dyna
 		output, err = decodeQueryOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpQuery, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpQuery, input, opt, encoder, decoder); err != nil {
 		client.healthStatus.onErrorInReadRequest(err, client)
 		return output, err
 	}
@@ -375,6 +419,22 @@ func (client *SingleDaxClient) QueryWithOptions(ctx context.Context, input *dyna
 }
 
 func (client *SingleDaxClient) BatchWriteItemWithOptions(ctx context.Context, input *dynamodb.BatchWriteItemInput, output *dynamodb.BatchWriteItemOutput, opt RequestOptions) (*dynamodb.BatchWriteItemOutput, error) {
+	client.sampleKeys(ctx, input)
+	if client.batchWriteConflicts == BatchWriteConflictsSerialize && input != nil {
+		rounds, err := client.batchWriteRounds(ctx, input)
+		if err != nil {
+			return output, err
+		}
+		if rounds != nil {
+			return batchWriteSerialized(rounds, output, func(round *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+				return client.batchWriteItem(ctx, round, nil, opt)
+			})
+		}
+	}
+	return client.batchWriteItem(ctx, input, output, opt)
+}
+
+func (client *SingleDaxClient) batchWriteItem(ctx context.Context, input *dynamodb.BatchWriteItemInput, output *dynamodb.BatchWriteItemOutput, opt RequestOptions) (*dynamodb.BatchWriteItemOutput, error) {
 	encoder := func(writer *cbor.Writer) error {
 		return encodeBatchWriteItemInput(ctx, input, client.keySchema, client.attrNamesListToId, writer)
 	}
@@ -383,13 +443,14 @@ func (client *SingleDaxClient) BatchWriteItemWithOptions(ctx context.Context, in
 		output, err = decodeBatchWriteItemOutput(ctx, reader, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpBatchWriteItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpBatchWriteItem, input, opt, encoder, decoder); err != nil {
 		return output, err
 	}
 	return output, nil
 }
 
 func (client *SingleDaxClient) BatchGetItemWithOptions(ctx context.Context, input *dynamodb.BatchGetItemInput, output *dynamodb.BatchGetItemOutput, opt RequestOptions) (*dynamodb.BatchGetItemOutput, error) {
+	client.sampleKeys(ctx, input)
 	encoder := func(writer *cbor.Writer) error {
 		return encodeBatchGetItemInput(ctx, input, client.keySchema, writer)
 	}
@@ -398,7 +459,7 @@ func (client *SingleDaxClient) BatchGetItemWithOptions(ctx context.Context, inpu
 		output, err = decodeBatchGetItemOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpBatchGetItem, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpBatchGetItem, input, opt, encoder, decoder); err != nil {
 		client.healthStatus.onErrorInReadRequest(err, client)
 		return output, err
 	}
@@ -406,6 +467,39 @@ func (client *SingleDaxClient) BatchGetItemWithOptions(ctx context.Context, inpu
 	return output, nil
 }
 
+// ExecuteStatementWithOptions translates the PartiQL statement into the equivalent
+// item or query request, which is cached by DAX like any other.
+func (client *SingleDaxClient) ExecuteStatementWithOptions(ctx context.Context, input *dynamodb.ExecuteStatementInput, output *dynamodb.ExecuteStatementOutput, opt RequestOptions) (*dynamodb.ExecuteStatementOutput, error) {
+	if input == nil {
+		return output, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	st, err := parseStatement(aws.ToString(input.Statement))
+	if err != nil {
+		return output, err
+	}
+	keys, err := getKeySchema(ctx, client.keySchema, st.table)
+	if err != nil {
+		return output, err
+	}
+	req, err := st.translate(input, keys)
+	if err != nil {
+		return output, err
+	}
+	return executeStatement(ctx, client, req, output, opt)
+}
+
+// BatchExecuteStatementWithOptions runs each PartiQL statement of the batch like
+// ExecuteStatementWithOptions. Reads must select single items by their primary key.
+func (client *SingleDaxClient) BatchExecuteStatementWithOptions(ctx context.Context, input *dynamodb.BatchExecuteStatementInput, output *dynamodb.BatchExecuteStatementOutput, opt RequestOptions) (*dynamodb.BatchExecuteStatementOutput, error) {
+	return batchExecuteStatement(ctx, client, client.keySchema, input, output, opt)
+}
+
+// ExecuteTransactionWithOptions runs the PartiQL statements of a transaction as a
+// TransactGetItems request if they are all reads, or a TransactWriteItems request.
+func (client *SingleDaxClient) ExecuteTransactionWithOptions(ctx context.Context, input *dynamodb.ExecuteTransactionInput, output *dynamodb.ExecuteTransactionOutput, opt RequestOptions) (*dynamodb.ExecuteTransactionOutput, error) {
+	return executeTransaction(ctx, client, client.keySchema, input, output, opt)
+}
+
 func (client *SingleDaxClient) TransactWriteItemsWithOptions(ctx context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, opt RequestOptions) (*dynamodb.TransactWriteItemsOutput, error) {
 	extractedKeys := make([]map[string]types.AttributeValue, len(input.TransactItems))
 	encoder := func(writer *cbor.Writer) error {
@@ -416,7 +510,7 @@ func (client *SingleDaxClient) TransactWriteItemsWithOptions(ctx context.Context
 		output, err = decodeTransactWriteItemsOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpTransactWriteItems, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpTransactWriteItems, input, opt, encoder, decoder); err != nil {
 		if failure, ok := err.(*daxTransactionCanceledFailure); ok {
 			var cancellationReasons []types.CancellationReason
 			if cancellationReasons, err = decodeTransactionCancellationReasons(ctx, failure, extractedKeys, client.attrListIdToNames); err != nil {
@@ -440,7 +534,7 @@ func (client *SingleDaxClient) TransactGetItemsWithOptions(ctx context.Context,
 		output, err = decodeTransactGetItemsOutput(ctx, reader, input, client.keySchema, client.attrListIdToNames, output)
 		return err
 	}
-	if err = client.executeWithRetries(ctx, OpTransactGetItems, opt, encoder, decoder); err != nil {
+	if err = client.executeWithKeySchema(ctx, OpTransactGetItems, input, opt, encoder, decoder); err != nil {
 		if failure, ok := err.(*daxTransactionCanceledFailure); ok {
 			var cancellationReasons []types.CancellationReason
 			if cancellationReasons, err = decodeTransactionCancellationReasons(ctx, failure, extractedKeys, client.attrListIdToNames); err != nil {
@@ -469,13 +563,24 @@ func (client *SingleDaxClient) executeWithRetries(ctx context.Context, op string
 
 	var err error
 	attempts := o.RetryMaxAttempts
+	owned := false
+	if _, ok := retryOwner(ctx); ok {
+		// An outer layer retries the request, see RetryLayer.
+		attempts = 0
+	} else {
+		ctx = withRetryOwner(ctx, RetryLayerNode)
+		owned = true
+	}
 	// Start from 0 to accommodate for the initial request
 	for i := 0; i <= attempts; i++ {
 		if i > 0 && o.Logger != nil && o.LogLevel.Matches(utils.LogDebugWithRequestRetries) {
-			o.Logger.Logf(logging.Debug, "Retrying Request %s/%s, attempt %d", service, op, i)
+			logAttrs(ctx, o.Logger, logging.Debug, client.logAttrs(op, i, err), "Retrying Request %s/%s, attempt %d", service, op, i)
 		}
 
 		err = client.executeWithContext(ctx, op, encoder, decoder, o)
+		if owned {
+			requestStatsFromContext(ctx).addAttempt(RetryLayerNode, err)
+		}
 		if err == nil {
 			return nil
 		}
@@ -484,14 +589,18 @@ func (client *SingleDaxClient) executeWithRetries(ctx context.Context, op string
 			return &smithy.CanceledError{Err: err}
 		}
 
+		if isConditionalCheckFailed(err) {
+			break
+		}
+
 		if i != attempts {
 			delay := o.RetryDelay
-			if sleepErr := SleepWithContext(ctx, op, delay); sleepErr != nil {
+			if sleepErr := sleepWithClock(ctx, client.clock, op, delay); sleepErr != nil {
 				return &smithy.OperationError{Err: sleepErr, ServiceID: service, OperationName: op}
 			}
 
 			if o.Logger != nil && o.LogLevel.Matches(utils.LogDebugWithRequestRetries) {
-				o.Logger.Logf(logging.Debug, "Error in executing %s%s : %s", service, op, err)
+				logAttrs(ctx, o.Logger, logging.Debug, client.logAttrs(op, i, err), "Error in executing %s%s : %s", service, op, err)
 			}
 		}
 	}
@@ -499,26 +608,77 @@ func (client *SingleDaxClient) executeWithRetries(ctx context.Context, op string
 	return translateError(err)
 }
 
+// awaitFirstByte waits up to timeout for the response to start arriving, then extends
+// the connection deadline to the attempt deadline for the rest of the response.
+func awaitFirstByte(ctx context.Context, t tube, reader *cbor.Reader, op string, timeout time.Duration, deadline time.Time) error {
+	if timeout <= 0 {
+		return nil
+	}
+	first := time.Now().Add(timeout)
+	if !deadline.IsZero() && !first.Before(deadline) {
+		return nil
+	}
+	if err := t.SetDeadline(first); err != nil {
+		return err
+	}
+	if _, err := reader.PeekHeader(); err != nil {
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() && ctx.Err() == nil {
+			return &firstByteTimeoutError{op: op}
+		}
+		return err
+	}
+	return t.SetDeadline(deadline)
+}
+
 func (client *SingleDaxClient) executeWithContext(ctx context.Context, op string, encoder func(writer *cbor.Writer) error, decoder func(reader *cbor.Reader) error, opt RequestOptions) (out error) {
 	startTime := time.Now()
 
 	defer func() {
-		histogramMicrosecondsInt64(ctx, client.daxSdkMetrics, fmt.Sprintf(daxOpNameLatencyUs, op), startTime)
+		if out != nil && errors.Is(context.Cause(ctx), errHedgeSettled) {
+			out = errHedgeSettled
+		}
+	}()
+
+	defer func() {
+		var ae *AuthError
+		if errors.As(out, &ae) {
+			// The request was not sent, authentication metrics account for the failure.
+			return
+		}
+		endpoint, operation := endpointAttr(client.pool.address), operationAttr(op)
+		latency, failure, success := fmt.Sprintf(daxOpNameLatencyUs, op), fmt.Sprintf(daxOpNameFailure, op), fmt.Sprintf(daxOpNameSuccess, op)
+		if op == opEndpoints {
+			latency, failure, success = daxDiscoveryLatencyUs, daxDiscoveryFailure, daxDiscoverySuccess
+		}
+		histogramMicrosecondsInt64(ctx, client.daxSdkMetrics, latency, startTime, endpoint, operation)
+		if op != opEndpoints {
+			client.latency.observe(time.Since(startTime))
+		}
 
 		if out != nil {
-			countMetricInt64(ctx, client.daxSdkMetrics, fmt.Sprintf(daxOpNameFailure, op), 1)
+			countMetricInt64(ctx, client.daxSdkMetrics, failure, 1, endpoint, operation, errorTypeAttr(out))
 
 			return
 		}
 
-		countMetricInt64(ctx, client.daxSdkMetrics, fmt.Sprintf(daxOpNameSuccess, op), 1)
+		countMetricInt64(ctx, client.daxSdkMetrics, success, 1, endpoint, operation)
 	}()
 
+	if err := client.pool.begin(); err != nil {
+		return err
+	}
+	defer client.pool.end()
+
+	setSpanEndpoint(ctx, client.pool.address)
+	_, span := tracing.StartSpan(ctx, spanAcquireConnection)
 	t, err := client.pool.getWithContext(ctx, client.isHighPriority(op), opt)
+	endSpan(span, err)
 	if err != nil {
 		return err
 	}
-	if err = client.pool.setDeadline(ctx, t); err != nil {
+	deadline := attemptDeadline(ctx, opt.attemptTimeout(op))
+	if err = client.pool.setDeadline(ctx, t, opt.attemptTimeout(op)); err != nil {
 		// If the error is just due to context cancelled or timeout
 		// then the tube is still usable because we have not written anything to tube
 		if err == ctx.Err() {
@@ -531,7 +691,18 @@ func (client *SingleDaxClient) executeWithContext(ctx context.Context, op string
 		return err
 	}
 
-	if err = client.auth(ctx, t); err != nil {
+	// Once ctx is done the pending read or write fails right away, rather than holding the
+	// tube until the attempt deadline. The tube is closed on that failure.
+	stop := context.AfterFunc(ctx, func() { _ = t.SetDeadline(time.Now()) })
+	defer stop()
+
+	usage := beginTubeUsage(ctx, t)
+	defer usage.end()
+
+	_, span = tracing.StartSpan(ctx, spanAuthenticate)
+	err = client.auth(ctx, t)
+	endSpan(span, err)
+	if err != nil {
 		// Auth method writes in the tube and
 		// it is not guaranteed that it will be drained completely on error
 		client.pool.closeTube(t)
@@ -539,7 +710,10 @@ func (client *SingleDaxClient) executeWithContext(ctx context.Context, op string
 	}
 
 	writer := t.CborWriter()
-	if err = encoder(writer); err != nil {
+	_, span = tracing.StartSpan(ctx, spanEncode)
+	err = encoder(writer)
+	endSpan(span, err)
+	if err != nil {
 		// Validation errors will cause connection to be closed as there is no guarantee
 		// that the validation was performed before any data was written into tube
 		client.pool.closeTube(t)
@@ -547,27 +721,43 @@ func (client *SingleDaxClient) executeWithContext(ctx context.Context, op string
 	}
 
 	// actual request is sent here
+	_, span = tracing.StartSpan(ctx, spanRoundTrip)
 	if err := writer.Flush(); err != nil {
+		endSpan(span, err)
 		client.pool.closeTube(t)
 
 		return err
 	}
 
 	reader := t.CborReader()
+	if err = awaitFirstByte(ctx, t, reader, op, opt.FirstByteTimeout, deadline); err != nil {
+		endSpan(span, err)
+		client.pool.closeTube(t)
+		return err
+	}
 	ex, err := decodeError(reader)
+	endSpan(span, err)
 
 	if err != nil { // decode or network error - doesn't guarantee completely drained tube
 		client.pool.closeTube(t)
 		return err
 	}
 	if ex != nil { // user or server error
-		client.recycleTube(t, ex)
+		usage.end()
+		if stop() {
+			client.recycleTube(t, ex)
+		} else {
+			client.pool.closeTube(t)
+		}
 		return ex
 	}
 
+	_, span = tracing.StartSpan(ctx, spanDecode)
 	err = decoder(reader)
-	if err != nil {
-		// we are not able to completely drain tube
+	endSpan(span, err)
+	usage.end()
+	if err != nil || !stop() {
+		// we are not able to completely drain tube, or its deadline was cut short
 		client.pool.closeTube(t)
 	} else {
 		client.pool.put(t)
@@ -576,6 +766,11 @@ func (client *SingleDaxClient) executeWithContext(ctx context.Context, op string
 	return err
 }
 
+// logAttrs returns the structured attributes describing an attempt of op on the node.
+func (client *SingleDaxClient) logAttrs(op string, attempt int, err error) []slog.Attr {
+	return append([]slog.Attr{slog.String("endpoint", client.pool.address)}, requestAttrs(op, attempt, err)...)
+}
+
 func (client *SingleDaxClient) isHighPriority(op string) bool {
 	switch op {
 	case opDefineAttributeListId, opDefineAttributeList, opDefineKeySchema:
@@ -607,14 +802,28 @@ func (client *SingleDaxClient) recycleTube(t tube, err error) {
 		client.pool.closeTube(t)
 	}
 }
-func (client *SingleDaxClient) auth(ctx context.Context, t tube) error {
-	// TODO credentials.Get() cause a throughput drop of ~25 with 250 goroutines with DefaultCredentialChain (only instance profile credentials available)
+func (client *SingleDaxClient) auth(ctx context.Context, t tube) (err error) {
+	startTime := time.Now()
+	defer func() {
+		endpoint := endpointAttr(client.pool.address)
+		histogramMicrosecondsInt64(ctx, client.daxSdkMetrics, daxAuthLatencyUs, startTime, endpoint)
+		if err != nil {
+			countMetricInt64(ctx, client.daxSdkMetrics, daxAuthFailure, 1, endpoint, errorTypeAttr(err))
+			err = &AuthError{Err: err}
+			return
+		}
+		countMetricInt64(ctx, client.daxSdkMetrics, daxAuthSuccess, 1, endpoint)
+	}()
 
-	creds, err := client.credentials.Retrieve(ctx)
+	// TODO credentials.Get() cause a throughput drop of ~25 with 250 goroutines with DefaultCredentialChain (only instance profile credentials available)
+	authCtx, cancel := context.WithTimeout(ctx, client.authTimeout)
+	defer cancel()
+	creds, err := client.credentials.Retrieve(authCtx)
 
 	if err != nil {
 		return err
 	}
+	client.events.credentialsRetrieved(creds)
 
 	now := time.Now().UTC()
 	if t.CompareAndSwapAuthID(creds.AccessKeyID) || t.AuthExpiryUnix() <= now.Unix() {
@@ -23,10 +23,13 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/leakcheck"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
@@ -130,9 +133,58 @@ func TestExecuteErrorHandling(t *testing.T) {
 		daxConnectionsClosedError:                4,
 		fmt.Sprintf(daxOpNameSuccess, OpGetItem): 1,
 	})
+	expectCounters(t, om, map[string]int{
+		daxAuthSuccess: 6,
+	})
 	expectHistograms(t, om, map[string]int{
 		fmt.Sprintf(daxOpNameLatencyUs, OpGetItem): 7,
+		daxAuthLatencyUs: 6,
+	})
+}
+
+func TestSingleDaxClient_CloseStopsHealthChecks(t *testing.T) {
+	leakcheck.Check(t)
+	tmp := &testMeterProvider{}
+	om, _ := buildDaxSdkMetrics(tmp)
+	cc, _ := newTestCluster([]string{"127.0.0.1:8111"})
+
+	client, err := newSingleClientWithOptions(":9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, om)
+	require.NoError(t, err)
+	client.startHealthChecks(cc, hostPort{"127.0.0.1", 9121})
+	assert.EqualValues(t, 1, client.executor.numTasks())
+
+	require.NoError(t, client.Close())
+}
+
+func TestSingleDaxClient_CloseWithContext(t *testing.T) {
+	leakcheck.Check(t)
+	tmp := &testMeterProvider{}
+	om, _ := buildDaxSdkMetrics(tmp)
+
+	client, err := newSingleClientWithOptions(":9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, om)
+	require.NoError(t, err)
+	running := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	client.executor.start(time.Millisecond, func() error {
+		once.Do(func() { close(running) })
+		<-release
+		return nil
 	})
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = client.CloseWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "health checks: 1 tasks still running")
+
+	close(release)
+	assert.NoError(t, client.CloseWithContext(context.Background()))
 }
 
 func TestRetryPropagatesContextError(t *testing.T) {
@@ -251,6 +303,159 @@ func TestRetryPropagatesOtherErrors(t *testing.T) {
 	})
 }
 
+func TestRetryStopsOnConditionalCheckFailed(t *testing.T) {
+	tmp := &testMeterProvider{}
+	om, _ := buildDaxSdkMetrics(tmp)
+
+	client, clientErr := newSingleClientWithOptions(":9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, om)
+	defer client.Close()
+	if clientErr != nil {
+		t.Fatalf("unexpected error %v", clientErr)
+	}
+
+	client.pool.closeTubeImmediately = true
+
+	requestOptions := RequestOptions{
+		Options: dynamodb.Options{
+			RetryMaxAttempts: 3,
+		},
+	}
+	ccf := newDaxRequestFailure([]int{4, 37, 38, 39, 43}, "ConditionalCheckFailedException", "The conditional request failed", "", 400, smithy.FaultClient)
+
+	writer := func(writer *cbor.Writer) error { return nil }
+	reader := func(reader *cbor.Reader) error { return ccf }
+
+	err := client.executeWithRetries(context.Background(), OpPutItem, requestOptions, writer, reader)
+	if err != ccf {
+		t.Fatalf("expected condition failure, got %v", err)
+	}
+
+	expectCounters(t, om, map[string]int{
+		fmt.Sprintf(daxOpNameFailure, OpPutItem): 1,
+	})
+}
+
+type credentialsProviderFunc func(ctx context.Context) (aws.Credentials, error)
+
+func (f credentialsProviderFunc) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return f(ctx)
+}
+
+func TestAuthError(t *testing.T) {
+	denied := errors.New("no credentials")
+	cases := []struct {
+		creds     credentialsProviderFunc
+		cause     error
+		retryable bool
+	}{
+		{
+			creds: func(ctx context.Context) (aws.Credentials, error) {
+				return aws.Credentials{}, denied
+			},
+			cause: denied,
+		},
+		{
+			creds: func(ctx context.Context) (aws.Credentials, error) {
+				<-ctx.Done()
+				return aws.Credentials{}, ctx.Err()
+			},
+			cause:     context.DeadlineExceeded,
+			retryable: true,
+		},
+	}
+
+	for i, c := range cases {
+		tmp := &testMeterProvider{}
+		om, _ := buildDaxSdkMetrics(tmp)
+		client, err := newSingleClientWithOptions(":9121", unEncryptedConnConfig, "us-west-2", c.creds, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+			return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+		}, nil, om)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		client.authTimeout = 10 * time.Millisecond
+
+		writer := func(writer *cbor.Writer) error { return nil }
+		reader := func(reader *cbor.Reader) error { return nil }
+		err = client.executeWithRetries(context.Background(), OpGetItem, RequestOptions{}, writer, reader)
+		client.Close()
+
+		var ae *AuthError
+		if !errors.As(err, &ae) {
+			t.Fatalf("case[%d] expected AuthError, got %T %v", i, err, err)
+		}
+		if !errors.Is(err, c.cause) {
+			t.Errorf("case[%d] expected cause %v, got %v", i, c.cause, ae.Err)
+		}
+		if r := (DaxRetryer{}).IsErrorRetryable(err); r != c.retryable {
+			t.Errorf("case[%d] expected retryable %v, got %v", i, c.retryable, r)
+		}
+		expectCounters(t, om, map[string]int{
+			daxAuthFailure:                           1,
+			fmt.Sprintf(daxOpNameFailure, OpGetItem): 0,
+		})
+		expectHistograms(t, om, map[string]int{
+			daxAuthLatencyUs: 1,
+			fmt.Sprintf(daxOpNameLatencyUs, OpGetItem): 0,
+		})
+	}
+}
+
+func TestRetryBudgetOwnedByOuterLayer(t *testing.T) {
+	client, clientErr := newSingleClientWithOptions(":9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, nil)
+	defer client.Close()
+	if clientErr != nil {
+		t.Fatalf("unexpected error %v", clientErr)
+	}
+
+	client.pool.closeTubeImmediately = true
+
+	requestOptions := RequestOptions{}
+	requestOptions.RetryMaxAttempts = 2
+	failure := newDaxRequestFailure([]int{2}, ErrCodeInternalServerError, "IO", "", 500, smithy.FaultServer)
+
+	calls := 0
+	writer := func(writer *cbor.Writer) error { return nil }
+	reader := func(reader *cbor.Reader) error {
+		calls++
+		return failure
+	}
+
+	rs := &requestStats{}
+	ctx := withRequestStats(context.Background(), rs)
+	if err := client.executeWithRetries(ctx, OpGetItem, requestOptions, writer, reader); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts on the node, got %d", calls)
+	}
+	for _, a := range rs.attemptHistory() {
+		if a.Layer != RetryLayerNode {
+			t.Errorf("expected node attempts, got %q", a.Layer)
+		}
+	}
+	if n := len(rs.attemptHistory()); n != 3 {
+		t.Errorf("expected 3 recorded attempts, got %d", n)
+	}
+
+	calls = 0
+	rs = &requestStats{}
+	ctx = withRetryOwner(withRequestStats(context.Background(), rs), RetryLayerCluster)
+	if err := client.executeWithRetries(ctx, OpGetItem, requestOptions, writer, reader); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("expected a single attempt when the cluster owns the budget, got %d", calls)
+	}
+	if n := len(rs.attemptHistory()); n != 0 {
+		t.Errorf("expected the owning layer to record attempts, got %d from the node", n)
+	}
+}
+
 func TestRetryPropagatesOtherErrorsWithDelay(t *testing.T) {
 	tmp := &testMeterProvider{}
 	om, _ := buildDaxSdkMetrics(tmp)
@@ -568,3 +773,19 @@ func (m *mockConn) SetReadDeadline(t time.Time) error {
 func (m *mockConn) SetWriteDeadline(t time.Time) error {
 	return nil
 }
+
+func TestAwaitFirstByte(t *testing.T) {
+	server, conn := net.Pipe()
+	defer server.Close()
+	tb := adoptTube(conn, 0)
+	defer tb.Close()
+
+	err := awaitFirstByte(context.Background(), tb, tb.CborReader(), OpGetItem, 20*time.Millisecond, time.Time{})
+	require.ErrorIs(t, err, ErrFirstByteTimeout)
+	var ne net.Error
+	require.True(t, errors.As(err, &ne))
+	assert.True(t, ne.Timeout())
+
+	go server.Write([]byte{0x80})
+	require.NoError(t, awaitFirstByte(context.Background(), tb, tb.CborReader(), OpGetItem, time.Second, time.Time{}))
+}
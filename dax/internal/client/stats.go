@@ -0,0 +1,101 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the internal state of a client.
+type Stats struct {
+	// Nodes lists the cluster nodes known to the client, ordered by address.
+	Nodes []NodeStats
+	// ActiveRoutes is the number of nodes requests are currently routed to.
+	ActiveRoutes int
+	// LastRefresh is the time of the last cluster discovery, and LastRefreshError
+	// its error, if it failed.
+	LastRefresh      time.Time
+	LastRefreshError error
+}
+
+// NodeStats describes the connections and caches of the client to a single node.
+type NodeStats struct {
+	Address string
+	// Routed reports whether requests are routed to the node. The route manager stops
+	// routing to nodes which time out repeatedly until their health checks succeed.
+	Routed bool
+	// IdleConnections is the number of connections in the pool, and InFlightRequests
+	// the number of requests holding or waiting for one.
+	IdleConnections  int
+	InFlightRequests int
+	// The number of entries of the key schema and attribute list caches.
+	KeySchemaCacheSize     int
+	AttributeListCacheSize int
+	AttributeIdCacheSize   int
+}
+
+// Stats returns a snapshot of the state of the client, for health endpoints and
+// troubleshooting.
+func (cc *ClusterDaxClient) Stats() Stats {
+	return cc.cluster.stats()
+}
+
+func (c *cluster) stats() Stats {
+	c.lock.RLock()
+	var routes []DaxAPI
+	if c.routeManager != nil { // nil once closed
+		routes = c.routeManager.getAllRoutes()
+	}
+	routed := make(map[*SingleDaxClient]struct{}, len(routes))
+	for _, r := range routes {
+		if single, ok := r.(*SingleDaxClient); ok {
+			routed[single] = struct{}{}
+		}
+	}
+	var clients []*SingleDaxClient
+	for _, cc := range c.active {
+		if single, ok := cc.client.(*SingleDaxClient); ok {
+			clients = append(clients, single)
+		}
+	}
+	s := Stats{ActiveRoutes: len(routes), LastRefreshError: c.lastRefreshErr}
+	c.lock.RUnlock()
+
+	if ns := atomic.LoadInt64(&c.lastUpdateNs); ns != 0 {
+		s.LastRefresh = time.Unix(0, ns)
+	}
+	for _, single := range clients {
+		n := single.nodeStats()
+		_, n.Routed = routed[single]
+		s.Nodes = append(s.Nodes, n)
+	}
+	sort.Slice(s.Nodes, func(i, j int) bool { return s.Nodes[i].Address < s.Nodes[j].Address })
+	return s
+}
+
+func (client *SingleDaxClient) nodeStats() NodeStats {
+	idle, inFlight := client.pool.stats()
+	return NodeStats{
+		Address:                client.pool.address,
+		IdleConnections:        idle,
+		InFlightRequests:       inFlight,
+		KeySchemaCacheSize:     client.keySchema.Len(),
+		AttributeListCacheSize: client.attrListIdToNames.Len(),
+		AttributeIdCacheSize:   client.attrNamesListToId.Len(),
+	}
+}
@@ -0,0 +1,63 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster_stats(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	om, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	dial := func(ctx context.Context, a, n string) (net.Conn, error) { return &mockConn{}, nil }
+
+	var clients []*SingleDaxClient
+	for _, addr := range []string{"127.0.0.2:8111", "127.0.0.1:8111"} {
+		c, err := newSingleClientWithOptions(addr, unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, dial, nil, om)
+		require.NoError(t, err)
+		defer c.Close()
+		clients = append(clients, c)
+	}
+	cluster.active = map[hostPort]clientAndConfig{
+		{"127.0.0.2", 8111}: {client: clients[0]},
+		{"127.0.0.1", 8111}: {client: clients[1]},
+	}
+	cluster.routeManager.setRoutes([]DaxAPI{clients[1]})
+	cluster.lastRefreshErr = errors.New("refresh failed")
+
+	tb, err := clients[1].pool.get()
+	require.NoError(t, err)
+	clients[1].pool.put(tb)
+	require.NoError(t, clients[1].pool.begin())
+	defer clients[1].pool.end()
+
+	s := cluster.stats()
+	assert.Equal(t, 1, s.ActiveRoutes)
+	assert.EqualError(t, s.LastRefreshError, "refresh failed")
+	assert.Equal(t, []NodeStats{
+		{Address: "127.0.0.1:8111", Routed: true, IdleConnections: 1, InFlightRequests: 1},
+		{Address: "127.0.0.2:8111"},
+	}, s.Nodes)
+
+	cluster.Close()
+	assert.Empty(t, cluster.stats().Nodes)
+}
@@ -0,0 +1,73 @@
+//go:build go1.25
+
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests of this file run the timing dependent logic with the system clock inside a
+// synctest bubble, where time only advances once every goroutine of the bubble is blocked.
+
+func TestSynctest_retryDelays(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+		cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+		cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster}
+
+		var attempts []time.Time
+		action := func(client DaxAPI, o RequestOptions) error {
+			attempts = append(attempts, time.Now())
+			return newDaxRequestFailure([]int{}, "ThrottlingException", "", "", 400, smithy.FaultClient)
+		}
+		opt := RequestOptions{
+			Options: dynamodb.Options{RetryMaxAttempts: 3},
+			Retryer: DaxRetryer{BaseThrottleDelay: time.Second, MaxBackoffDelay: time.Minute},
+		}
+		require.Error(t, cc.retry(context.Background(), OpGetItem, action, opt))
+
+		// The throttled attempts back off exponentially, with up to 50% jitter.
+		require.Len(t, attempts, 4)
+		for i := 1; i < len(attempts); i++ {
+			base := time.Duration(1<<uint(i)) * time.Second
+			delay := attempts[i].Sub(attempts[i-1])
+			assert.GreaterOrEqual(t, delay, base/2, i)
+			assert.LessOrEqual(t, delay, base, i)
+		}
+	})
+}
+
+func TestSynctest_sleepCanceled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		start := time.Now()
+		err := SleepWithContext(ctx, OpGetItem, time.Hour)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t, time.Minute, time.Since(start))
+	})
+}
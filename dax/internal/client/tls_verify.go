@@ -0,0 +1,111 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// validateHostnamePattern rejects patterns which can't match any hostname.
+func validateHostnamePattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	for _, label := range strings.Split(pattern, ".") {
+		if label == "" {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("ExpectedHostnamePattern %q has an empty label", pattern))
+		}
+		if _, err := path.Match(label, ""); err != nil {
+			return NewCustomInvalidParamError("ConfigValidation", fmt.Sprintf("ExpectedHostnamePattern %q: %v", pattern, err))
+		}
+	}
+	return nil
+}
+
+// matchHostnamePattern reports whether the certificate name, possibly a wildcard name such
+// as *.example.com, is valid for a hostname matching pattern. Names and patterns are
+// matched label by label; a * in the pattern matches any characters within a label and
+// the wildcard label of a name matches any label of the pattern.
+func matchHostnamePattern(pattern, name string) bool {
+	pl := strings.Split(strings.ToLower(pattern), ".")
+	nl := strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".")
+	if len(pl) != len(nl) {
+		return false
+	}
+	for i := range pl {
+		if i == 0 && nl[i] == "*" {
+			continue
+		}
+		if ok, _ := path.Match(pl[i], nl[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyHostnamePolicy sets up cfg to verify the certificate chain of the nodes as usual
+// but to check the hostname the certificate is valid for with the pattern or the verifier
+// of cc instead of the server name.
+func (cc connConfig) verifyHostnamePolicy(cfg *tls.Config) {
+	base := cfg.VerifyConnection
+	roots, now := cfg.RootCAs, cfg.Time
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return &tls.CertificateVerificationError{Err: fmt.Errorf("no certificate presented by %s", cc.hostname)}
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		if now != nil {
+			opts.CurrentTime = now()
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		leaf := cs.PeerCertificates[0]
+		if _, err := leaf.Verify(opts); err != nil {
+			return &tls.CertificateVerificationError{UnverifiedCertificates: cs.PeerCertificates, Err: err}
+		}
+
+		if cc.verifyHostname != nil {
+			if err := cc.verifyHostname(cc.hostname, leaf); err != nil {
+				return &tls.CertificateVerificationError{UnverifiedCertificates: cs.PeerCertificates, Err: err}
+			}
+		} else if !certificateMatches(cc.hostnamePattern, leaf) {
+			return &tls.CertificateVerificationError{
+				UnverifiedCertificates: cs.PeerCertificates,
+				Err:                    fmt.Errorf("certificate is valid for %v, not for a hostname matching %s", leaf.DNSNames, cc.hostnamePattern),
+			}
+		}
+
+		if base != nil {
+			return base(cs)
+		}
+		return nil
+	}
+}
+
+func certificateMatches(pattern string, cert *x509.Certificate) bool {
+	for _, name := range cert.DNSNames {
+		if matchHostnamePattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
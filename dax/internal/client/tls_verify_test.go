@@ -0,0 +1,156 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChain returns a CA and a leaf certificate it signed for dnsNames.
+func newTestChain(t *testing.T, dnsNames ...string) (ca, leaf *x509.Certificate) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err = x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	leaf, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return ca, leaf
+}
+
+func TestMatchHostnamePattern(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.abc123.dax-clusters.us-west-2.amazonaws.com", "*.abc123.dax-clusters.us-west-2.amazonaws.com", true},
+		{"*.abc123.dax-clusters.us-west-2.amazonaws.com", "orders.abc123.dax-clusters.us-west-2.amazonaws.com", true},
+		{"orders.abc123.dax-clusters.us-west-2.amazonaws.com", "*.abc123.dax-clusters.us-west-2.amazonaws.com", true},
+		{"orders-*.abc123.dax-clusters.us-west-2.amazonaws.com", "orders-0001.abc123.dax-clusters.us-west-2.amazonaws.com", true},
+		{"*.ABC123.dax-clusters.us-west-2.amazonaws.com", "orders.abc123.dax-clusters.us-west-2.amazonaws.com.", true},
+		{"*.abc123.dax-clusters.us-west-2.amazonaws.com", "orders.xyz789.dax-clusters.us-west-2.amazonaws.com", false},
+		{"*.abc123.dax-clusters.us-west-2.amazonaws.com", "abc123.dax-clusters.us-west-2.amazonaws.com", false},
+		{"orders.abc123.example.com", "orders.*.example.com", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, matchHostnamePattern(c.pattern, c.name), "%s %s", c.pattern, c.name)
+	}
+
+	assert.NoError(t, validateHostnamePattern(""))
+	assert.NoError(t, validateHostnamePattern("*.abc123.dax-clusters.us-west-2.amazonaws.com"))
+	assert.Error(t, validateHostnamePattern("*..amazonaws.com"))
+	assert.Error(t, validateHostnamePattern("[.amazonaws.com"))
+}
+
+func TestConnConfig_tlsConfigHostnamePattern(t *testing.T) {
+	ca, leaf := newTestChain(t, "*.abc123.dax-clusters.us-west-2.amazonaws.com")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	cc := connConfig{
+		isEncrypted:     true,
+		hostname:        "dax.example.com",
+		baseTLS:         &tls.Config{RootCAs: roots},
+		hostnamePattern: "*.abc123.dax-clusters.us-west-2.amazonaws.com",
+	}
+	cfg := cc.tlsConfig()
+	assert.True(t, cfg.InsecureSkipVerify, "the hostname is verified by VerifyConnection")
+	assert.Equal(t, "dax.example.com", cfg.ServerName)
+	assert.NoError(t, cfg.VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}))
+	assert.Nil(t, cc.baseTLS.VerifyConnection, "the base configuration is not modified")
+
+	cc.hostnamePattern = "*.xyz789.dax-clusters.us-west-2.amazonaws.com"
+	var cve *tls.CertificateVerificationError
+	assert.ErrorAs(t, cc.tlsConfig().VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}), &cve)
+
+	cc.hostnamePattern = "*.abc123.dax-clusters.us-west-2.amazonaws.com"
+	cc.baseTLS = &tls.Config{}
+	assert.Error(t, cc.tlsConfig().VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}),
+		"the chain is still verified")
+}
+
+func TestConnConfig_tlsConfigVerifyHostname(t *testing.T) {
+	ca, leaf := newTestChain(t, "node.internal")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	var got string
+	denied := errors.New("denied")
+	cc := connConfig{
+		isEncrypted: true,
+		hostname:    "dax.example.com",
+		baseTLS:     &tls.Config{RootCAs: roots},
+		verifyHostname: func(hostname string, cert *x509.Certificate) error {
+			got = hostname
+			if cert.DNSNames[0] != "node.internal" {
+				return denied
+			}
+			return nil
+		},
+	}
+	assert.NoError(t, cc.tlsConfig().VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}))
+	assert.Equal(t, "dax.example.com", got)
+
+	_, other := newTestChain(t, "other.internal")
+	assert.Error(t, cc.tlsConfig().VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}}),
+		"certificates of another CA are rejected before the verifier")
+
+	baseCalled := false
+	cc.baseTLS.VerifyConnection = func(tls.ConnectionState) error {
+		baseCalled = true
+		return nil
+	}
+	require.NoError(t, cc.tlsConfig().VerifyConnection(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}))
+	assert.True(t, baseCalled)
+
+	cc.skipHostnameVerification = true
+	cfg := cc.tlsConfig()
+	assert.True(t, cfg.InsecureSkipVerify)
+	require.NoError(t, cfg.VerifyConnection(tls.ConnectionState{}), "only the base VerifyConnection is kept")
+}
@@ -0,0 +1,103 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/tracing"
+)
+
+// tracerScope is the instrumentation scope of the spans emitted by the client.
+const tracerScope = "github.com/aws/aws-dax-go-v2/dax"
+
+// Span names of the phases of a request attempt.
+const (
+	spanAttempt           = "Attempt"
+	spanAcquireConnection = "AcquireConnection"
+	spanAuthenticate      = "Authenticate"
+	spanEncode            = "Encode"
+	spanRoundTrip         = "RoundTrip"
+	spanDecode            = "Decode"
+)
+
+func newTracer(tp tracing.TracerProvider) tracing.Tracer {
+	if tp == nil {
+		tp = tracing.NopTracerProvider{}
+	}
+	return tp.Tracer(tracerScope)
+}
+
+// startOperationSpan starts the span covering op and its retries. The tracer is embedded
+// in the returned context, where the nodes executing the attempts find it.
+func startOperationSpan(ctx context.Context, tracer tracing.Tracer, op string) (context.Context, tracing.Span) {
+	ctx = tracing.WithOperationTracer(ctx, tracer)
+	return tracer.StartSpan(ctx, service+"."+op, func(o *tracing.SpanOptions) {
+		o.Kind = tracing.SpanKindClient
+		o.Properties.Set("rpc.system", "aws-dax")
+		o.Properties.Set("rpc.service", service)
+		o.Properties.Set("rpc.method", op)
+	})
+}
+
+// startAttemptSpan starts the span of the attempt-th attempt of op, counting from 0.
+func startAttemptSpan(ctx context.Context, op string, attempt int) (context.Context, tracing.Span) {
+	return tracing.StartSpan(ctx, spanAttempt, func(o *tracing.SpanOptions) {
+		o.Properties.Set("rpc.method", op)
+		o.Properties.Set("dax.attempt", attempt)
+	})
+}
+
+// setSpanEndpoint records the node address on the span of the current attempt.
+func setSpanEndpoint(ctx context.Context, address string) {
+	span, ok := tracing.GetSpan(ctx)
+	if !ok {
+		return
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		span.SetProperty("server.address", address)
+		return
+	}
+	span.SetProperty("server.address", host)
+	if p, err := strconv.Atoi(port); err == nil {
+		span.SetProperty("server.port", p)
+	}
+}
+
+// endSpan sets the status of span according to err and ends it.
+func endSpan(span tracing.Span, err error) {
+	if err != nil {
+		span.SetStatus(tracing.SpanStatusError)
+		span.SetProperty("error.type", errorType(err))
+	} else {
+		span.SetStatus(tracing.SpanStatusOK)
+	}
+	span.End()
+}
+
+func errorType(err error) string {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		return ae.ErrorCode()
+	}
+	return fmt.Sprintf("%T", err)
+}
@@ -0,0 +1,165 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTracerProvider struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (p *recordingTracerProvider) Tracer(scope string, opts ...tracing.TracerOption) tracing.Tracer {
+	return &recordingTracer{provider: p}
+}
+
+func (p *recordingTracerProvider) named(name string) []*recordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var spans []*recordedSpan
+	for _, s := range p.spans {
+		if s.name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+type recordingTracer struct {
+	provider *recordingTracerProvider
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, name string, opts ...tracing.SpanOption) (context.Context, tracing.Span) {
+	var o tracing.SpanOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	s := &recordedSpan{name: name, kind: o.Kind, props: o.Properties}
+	if parent, ok := tracing.GetSpan(ctx); ok {
+		s.parent = parent.(*recordedSpan)
+	}
+	t.provider.mu.Lock()
+	t.provider.spans = append(t.provider.spans, s)
+	t.provider.mu.Unlock()
+	return tracing.WithSpan(ctx, s), s
+}
+
+type recordedSpan struct {
+	name   string
+	kind   tracing.SpanKind
+	parent *recordedSpan
+	props  smithy.Properties
+	status tracing.SpanStatus
+	ended  bool
+}
+
+func (s *recordedSpan) Name() string                            { return s.name }
+func (s *recordedSpan) Context() tracing.SpanContext            { return tracing.SpanContext{} }
+func (s *recordedSpan) AddEvent(string, ...tracing.EventOption) {}
+func (s *recordedSpan) SetStatus(status tracing.SpanStatus)     { s.status = status }
+func (s *recordedSpan) SetProperty(k, v any)                    { s.props.Set(k, v) }
+func (s *recordedSpan) End()                                    { s.ended = true }
+
+func TestClusterDaxClient_retryTracing(t *testing.T) {
+	cluster, _ := newTestCluster([]string{"127.0.0.1:8111"})
+	cluster.update([]serviceEndpoint{{hostname: "localhost", port: 8121}})
+	tp := &recordingTracerProvider{}
+	cc := ClusterDaxClient{config: DefaultConfig(), cluster: cluster, tracer: newTracer(tp)}
+
+	calls := 0
+	action := func(client DaxAPI, o RequestOptions) error {
+		calls++
+		_, span := tracing.StartSpan(o.Context, "child")
+		span.End()
+		if calls == 1 {
+			return newDaxRequestFailure([]int{1}, "RetryableError", "", "", 500, smithy.FaultServer)
+		}
+		return nil
+	}
+	opt := RequestOptions{
+		Options: dynamodb.Options{RetryMaxAttempts: 2},
+		Retryer: DaxRetryer{BaseThrottleDelay: time.Millisecond, MaxBackoffDelay: time.Millisecond},
+	}
+	require.NoError(t, cc.retry(context.Background(), OpGetItem, action, opt))
+
+	ops := tp.named("dax.GetItem")
+	require.Len(t, ops, 1)
+	op := ops[0]
+	assert.Equal(t, tracing.SpanKindClient, op.kind)
+	assert.Equal(t, OpGetItem, op.props.Get("rpc.method"))
+	assert.Equal(t, tracing.SpanStatusOK, op.status)
+	assert.True(t, op.ended)
+
+	attempts := tp.named(spanAttempt)
+	require.Len(t, attempts, 2)
+	for i, a := range attempts {
+		assert.Same(t, op, a.parent)
+		assert.Equal(t, i, a.props.Get("dax.attempt"))
+		assert.True(t, a.ended)
+	}
+	assert.Equal(t, tracing.SpanStatusError, attempts[0].status)
+	assert.Equal(t, "RetryableError", attempts[0].props.Get("error.type"))
+	assert.Equal(t, tracing.SpanStatusOK, attempts[1].status)
+
+	children := tp.named("child")
+	require.Len(t, children, 2)
+	assert.Same(t, attempts[0], children[0].parent)
+	assert.Same(t, attempts[1], children[1].parent)
+}
+
+func TestSingleDaxClient_executeTracing(t *testing.T) {
+	tmp := &testMeterProvider{}
+	om, _ := buildDaxSdkMetrics(tmp)
+	client, err := newSingleClientWithOptions("127.0.0.1:9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{cbor.Array + 0}}, nil
+	}, nil, om)
+	require.NoError(t, err)
+	defer client.Close()
+
+	tp := &recordingTracerProvider{}
+	ctx, op := startOperationSpan(context.Background(), newTracer(tp), OpGetItem)
+	ctx, attempt := startAttemptSpan(ctx, OpGetItem, 0)
+	writer := func(writer *cbor.Writer) error { return nil }
+	reader := func(reader *cbor.Reader) error { return nil }
+	require.NoError(t, client.executeWithContext(ctx, OpGetItem, writer, reader, RequestOptions{}))
+	endSpan(attempt, nil)
+	endSpan(op, nil)
+
+	a := attempt.(*recordedSpan)
+	assert.Equal(t, "127.0.0.1", a.props.Get("server.address"))
+	assert.Equal(t, 9121, a.props.Get("server.port"))
+	for _, name := range []string{spanAcquireConnection, spanAuthenticate, spanEncode, spanRoundTrip, spanDecode} {
+		spans := tp.named(name)
+		if assert.Len(t, spans, 1, name) {
+			assert.Same(t, a, spans[0].parent, name)
+			assert.Equal(t, tracing.SpanStatusOK, spans[0].status, name)
+			assert.True(t, spans[0].ended, name)
+		}
+	}
+}
@@ -17,8 +17,11 @@ package client
 
 import (
 	"bufio"
+	"errors"
 	"net"
+	"os"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
@@ -46,21 +49,56 @@ type tube interface {
 	Close() error
 }
 
+// byteCounter is implemented by tubes which track the bytes transferred over their connection.
+type byteCounter interface {
+	BytesSent() int64
+	BytesReceived() int64
+}
+
+// byteReporter is implemented by tubes which track the bytes transferred over their
+// connection since they were last reported to the metrics.
+type byteReporter interface {
+	takeBytes() (sent, received int64)
+}
+
+// countingConn counts the bytes written to and read from the wrapped connection.
+type countingConn struct {
+	net.Conn
+	sent     int64
+	received int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.received, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.sent, int64(n))
+	return n, err
+}
+
 // A concrete tube implementation based on net.Conn
 type netConnTube struct {
 	sess       session
-	conn       net.Conn
+	conn       *countingConn
 	cborReader *cbor.Reader
 	cborWriter *cbor.Writer
 	next       tube
 
 	authExpiryUnix int64
 	authID         string
+
+	reportedSent     int64
+	reportedReceived int64
 }
 
 // Creates and initializes a new tube belonging to the given session
 // and using the provided connection.
-func newTube(c net.Conn, s session) (tube, error) {
+func newTube(nc net.Conn, s session) (tube, error) {
+	c := &countingConn{Conn: nc}
 	w := cbor.NewWriter(bufio.NewWriter(c))
 	closeResources := func() {
 		w.Close()
@@ -101,6 +139,30 @@ func newTube(c net.Conn, s session) (tube, error) {
 
 }
 
+// Wraps a connection which already completed the initialization, e.g. one handed off
+// by another process, into a tube without writing the connection header again.
+func adoptTube(nc net.Conn, s session) tube {
+	c := &countingConn{Conn: nc}
+	return &netConnTube{
+		sess:       s,
+		conn:       c,
+		cborReader: cbor.NewReader(bufio.NewReader(c)),
+		cborWriter: cbor.NewWriter(bufio.NewWriter(c)),
+	}
+}
+
+// Returns a duplicate of the file descriptor of the underlying connection.
+func (t *netConnTube) file() (*os.File, error) {
+	conn := t.conn.Conn
+	if lc, ok := conn.(*limitedConn); ok {
+		conn = lc.Conn
+	}
+	if f, ok := conn.(interface{ File() (*os.File, error) }); ok {
+		return f.File()
+	}
+	return nil, errors.New("connection does not support file descriptors")
+}
+
 func (t *netConnTube) AuthExpiryUnix() int64 {
 	return t.authExpiryUnix
 }
@@ -124,6 +186,21 @@ func (t *netConnTube) SetDeadline(time time.Time) error {
 	return t.conn.SetDeadline(time)
 }
 
+func (t *netConnTube) BytesSent() int64 {
+	return atomic.LoadInt64(&t.conn.sent)
+}
+
+func (t *netConnTube) BytesReceived() int64 {
+	return atomic.LoadInt64(&t.conn.received)
+}
+
+// takeBytes returns the bytes sent and received since the previous call.
+func (t *netConnTube) takeBytes() (sent, received int64) {
+	sent = atomic.LoadInt64(&t.conn.sent)
+	received = atomic.LoadInt64(&t.conn.received)
+	return sent - atomic.SwapInt64(&t.reportedSent, sent), received - atomic.SwapInt64(&t.reportedReceived, received)
+}
+
 func (t *netConnTube) Session() session {
 	return t.sess
 }
@@ -17,7 +17,8 @@ package client
 
 import (
 	"context"
-	"crypto/tls"
+	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"sync"
@@ -41,7 +42,12 @@ type tubePool struct {
 	errCh                chan error
 	timeout              time.Duration
 	dialContext          dialContext
+	dialLimiter          *dialLimiter
+	connectTimeout       time.Duration // bounds each dial, including the TLS handshake
+	events               *eventBus
 	closeTubeImmediately bool
+	conns                chan struct{} // a slot per open connection, nil when unbounded
+	minIdle              int           // idle connections kept open by the reaper
 
 	mutex      sync.Mutex
 	closed     bool    // protected by mutex
@@ -49,9 +55,17 @@ type tubePool struct {
 	lastActive tube    // protected by mutex
 	session    session // protected by mutex
 	waiters    chan tube
+	inFlight   int           // protected by mutex
+	drained    chan struct{} // protected by mutex
 
-	pending int64 // 64 bit for pending gauge convenience
-	idle    int64 // 64 bit for idle gauge convenience
+	pending   int64 // 64 bit for pending gauge convenience
+	idle      int64 // 64 bit for idle gauge convenience
+	exhausted int32 // set from EventPoolExhausted until a tube is returned
+	lost      int32 // set when a tube is closed because of an error until a new one connects
+
+	// ctx is cancelled when the pool is closed, aborting the connection attempts in progress
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	connConfig connConfig
 
@@ -80,22 +94,19 @@ func newTubePoolWithOptions(address string, options tubePoolOptions, connConfigD
 	}
 
 	if options.dialContext == nil {
+		forward := (&net.Dialer{}).DialContext
+		if connConfigData.proxy != nil {
+			forward = (&proxy.TunnelDialer{Proxy: connConfigData.proxy, Forward: forward}).DialContext
+		}
 		if connConfigData.isEncrypted {
-			dialer := &proxy.Dialer{}
-			var cfg tls.Config
-			if connConfigData.skipHostnameVerification {
-				cfg = tls.Config{InsecureSkipVerify: true}
-			} else {
-				cfg = tls.Config{ServerName: connConfigData.hostname}
-			}
-			dialer.Config = &cfg
+			dialer := &proxy.Dialer{Config: connConfigData.tlsConfig(), Forward: forward}
 			options.dialContext = dialer.DialContext
 		} else {
-			dialer := &net.Dialer{}
-			options.dialContext = dialer.DialContext
+			options.dialContext = forward
 		}
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	return &tubePool{
 		address:     address,
 		gate:        make(gate, options.maxConcurrentConnAttempts),
@@ -109,9 +120,20 @@ func newTubePoolWithOptions(address string, options tubePoolOptions, connConfigD
 
 		connConfig:    connConfigData,
 		daxSdkMetrics: sdkMetrics,
+
+		ctx:    ctx,
+		cancel: cancel,
 	}
 }
 
+// stats returns the number of idle connections and of requests holding or waiting for
+// a connection.
+func (p *tubePool) stats() (idle, inFlight int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return int(atomic.LoadInt64(&p.idle)), p.inFlight
+}
+
 // Gets a new or reuses existing tube with timeout context set to tubePool#timeout
 func (p *tubePool) get() (tube, error) {
 	ctx := context.Background()
@@ -143,7 +165,7 @@ func (p *tubePool) getWithContext(ctx context.Context, highPriority bool, opt Re
 			}
 			t.SetNext(nil)
 			atomic.AddInt64(&p.idle, -1)
-			gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle))
+			gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle), endpointAttr(p.address))
 			p.mutex.Unlock()
 			return t, nil
 		}
@@ -158,10 +180,17 @@ func (p *tubePool) getWithContext(ctx context.Context, highPriority bool, opt Re
 
 		var done chan tube
 		if p.gate.tryEnter() {
-			go p.allocAndReleaseGate(session, done, true, opt)
-		} else if highPriority {
+			if p.reserveConn() {
+				go p.allocAndReleaseGate(session, done, true, opt)
+			} else {
+				p.gate.exit()
+				p.exhaust()
+			}
+		} else if highPriority && p.reserveConn() {
 			done = make(chan tube)
 			go p.allocAndReleaseGate(session, done, false, opt)
+		} else {
+			p.exhaust()
 		}
 
 		select {
@@ -188,15 +217,83 @@ func (p *tubePool) getWithContext(ctx context.Context, highPriority bool, opt Re
 	}
 }
 
+// exhaust publishes EventPoolExhausted, unless it was already since a tube was last returned.
+func (p *tubePool) exhaust() {
+	if atomic.CompareAndSwapInt32(&p.exhausted, 0, 1) {
+		p.events.publish(Event{Type: EventPoolExhausted, Endpoint: p.address})
+	}
+}
+
+// setLimits bounds the connections the pool keeps open to maxConns, when positive, and
+// makes the reaper keep minIdle of them open while idle. It must be called before the
+// pool opens any connection.
+func (p *tubePool) setLimits(minIdle, maxConns int) {
+	p.minIdle = minIdle
+	if maxConns > 0 {
+		p.conns = make(chan struct{}, maxConns)
+	}
+}
+
+// reserveConn takes a slot for a new connection, returning false when the pool already
+// has as many connections open as it may. The slot is owned by the connection once it
+// is established and is released by closing it.
+func (p *tubePool) reserveConn() bool {
+	if p.conns == nil {
+		return true
+	}
+	select {
+	case p.conns <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseConn releases the slot of a connection and wakes up the requests waiting for a
+// tube, which may now open a connection.
+func (p *tubePool) releaseConn() {
+	if p.conns == nil {
+		return
+	}
+	<-p.conns
+	p.mutex.Lock()
+	if p.waiters != nil {
+		close(p.waiters)
+		p.waiters = nil
+	}
+	p.mutex.Unlock()
+}
+
+// limitConn makes closing conn release its slot of the connection limit of the pool.
+func (p *tubePool) limitConn(conn net.Conn) net.Conn {
+	if p.conns == nil {
+		return conn
+	}
+	return &limitedConn{Conn: conn, release: p.releaseConn}
+}
+
+// limitedConn releases its slot of the connection limit of its pool when closed.
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
+
 // Allocates a new tube and optionally releases the gate.
 // If done channel isn't nil the new tube will be send there as opposed to idle tubes stack.
 func (p *tubePool) allocAndReleaseGate(session int64, done chan tube, releaseGate bool, opt RequestOptions) {
 	atomic.AddInt64(&p.pending, 1)
-	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConcurrentConnectionAttempts, atomic.LoadInt64(&p.pending))
+	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConcurrentConnectionAttempts, atomic.LoadInt64(&p.pending), endpointAttr(p.address))
 
 	defer func() {
 		atomic.AddInt64(&p.pending, -1)
-		gaugeInt64(context.Background(), p.daxSdkMetrics, daxConcurrentConnectionAttempts, atomic.LoadInt64(&p.pending))
+		gaugeInt64(context.Background(), p.daxSdkMetrics, daxConcurrentConnectionAttempts, atomic.LoadInt64(&p.pending), endpointAttr(p.address))
 	}()
 
 	tube, err := p.alloc(session, opt)
@@ -233,14 +330,18 @@ func (p *tubePool) put(t tube) {
 		return
 	}
 
+	p.countBytes(t)
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	atomic.StoreInt32(&p.exhausted, 0)
+
 	if p.closed || t.Session() != p.session {
 		t.Close()
 		// Waiters channel was already closed in Close
 
-		countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedSession, 1)
+		countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedSession, 1, endpointAttr(p.address))
 
 		return
 	}
@@ -259,7 +360,7 @@ func (p *tubePool) put(t tube) {
 	p.top = t
 
 	atomic.AddInt64(&p.idle, 1)
-	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle))
+	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle), endpointAttr(p.address))
 }
 
 // Make sure to closeTube the tube if you are not sure that the tube is clean
@@ -270,7 +371,9 @@ func (p *tubePool) closeTube(t tube) {
 		return
 	}
 
-	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedError, 1)
+	p.countBytes(t)
+	atomic.StoreInt32(&p.lost, 1)
+	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedError, 1, endpointAttr(p.address))
 
 	if p.closeTubeImmediately {
 		t.Close()
@@ -281,18 +384,47 @@ func (p *tubePool) closeTube(t tube) {
 	}
 }
 
-// Sets the deadline on the underlying net.Conn object
-func (p *tubePool) setDeadline(ctx context.Context, tube tube) error {
+// countBytes adds the bytes transferred over t since they were last counted to the byte
+// counters of the endpoint of the pool. Tubes are counted when they are returned to the
+// pool or closed, so that the counters are not updated on every read and write.
+func (p *tubePool) countBytes(t tube) {
+	br, ok := t.(byteReporter)
+	if !ok {
+		return
+	}
+	sent, received := br.takeBytes()
+	if sent > 0 {
+		countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsBytesSent, sent, endpointAttr(p.address))
+	}
+	if received > 0 {
+		countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsBytesReceived, received, endpointAttr(p.address))
+	}
+}
+
+// Sets the deadline on the underlying net.Conn object to the earlier of the context
+// deadline and timeout from now, if timeout is positive.
+func (p *tubePool) setDeadline(ctx context.Context, tube tube, timeout time.Duration) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
+	return tube.SetDeadline(attemptDeadline(ctx, timeout))
+}
+
+// attemptDeadline returns the earlier of the context deadline and timeout from now, if
+// timeout is positive. The zero time means no deadline.
+func attemptDeadline(ctx context.Context, timeout time.Duration) time.Time {
 	var deadline time.Time
 	if d, ok := ctx.Deadline(); ok {
 		deadline = d
 	}
-	return tube.SetDeadline(deadline)
+	if timeout > 0 {
+		if d := time.Now().Add(timeout); deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline
 }
 
 // Closes the pool and all idle tubes in it.
@@ -312,10 +444,69 @@ func (p *tubePool) Close() error {
 		// cannot closeTube(p.gate) as send on closed channel will panic. new connections will be closed immediately.
 	}
 	p.mutex.Unlock()
+	p.cancel()
 	p.closeAll(head)
 	return nil
 }
 
+// CloseWithContext closes the pool, then waits until ctx is done for the requests in
+// flight to finish using their tubes.
+func (p *tubePool) CloseWithContext(ctx context.Context) error {
+	p.Close()
+
+	if err := p.waitIdle(ctx); err != nil {
+		p.mutex.Lock()
+		n := p.inFlight
+		p.mutex.Unlock()
+		return fmt.Errorf("%d requests to %s still in flight: %w", n, p.address, err)
+	}
+	return nil
+}
+
+// waitIdle waits until no request is using a tube of the pool, or until ctx is done.
+func (p *tubePool) waitIdle(ctx context.Context) error {
+	p.mutex.Lock()
+	if p.inFlight == 0 {
+		p.mutex.Unlock()
+		return nil
+	}
+	if p.drained == nil {
+		p.drained = make(chan struct{})
+	}
+	drained := p.drained
+	p.mutex.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Registers a request about to use a tube of the pool, failing if the pool is closed.
+// end must be called once the request is done with the tube.
+func (p *tubePool) begin() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return os.ErrClosed
+	}
+	p.inFlight++
+	return nil
+}
+
+// Marks a request registered with begin as done.
+func (p *tubePool) end() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.inFlight--
+	if p.inFlight == 0 && p.drained != nil {
+		close(p.drained)
+		p.drained = nil
+	}
+}
+
 // Resets the idle tube stack by detaching existing tubes from it.
 // p.mutex must be held when calling this method
 func (p *tubePool) clearIdleConnections() tube {
@@ -323,19 +514,27 @@ func (p *tubePool) clearIdleConnections() tube {
 	p.top = nil
 	p.lastActive = nil
 	atomic.StoreInt64(&p.idle, 0)
-	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle))
+	gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle), endpointAttr(p.address))
 	return head
 }
 
-// Closes tubes which weren't used since the last time this method was called.
+// Closes tubes which weren't used since the last time this method was called, keeping
+// at least minIdle idle tubes, then opens new ones up to minIdle.
 func (p *tubePool) reapIdleConnections() {
 	p.mutex.Lock()
 
 	var reapHead tube
 	if !p.closed {
 		if p.lastActive != nil {
-			reapHead = p.lastActive.Next()
-			p.lastActive.SetNext(nil)
+			last, kept := p.lastActive, 1
+			for t := p.top; t != p.lastActive; t = t.Next() {
+				kept++
+			}
+			for ; kept < p.minIdle && last.Next() != nil; kept++ {
+				last = last.Next()
+			}
+			reapHead = last.Next()
+			last.SetNext(nil)
 		}
 		p.lastActive = p.top
 	}
@@ -346,29 +545,69 @@ func (p *tubePool) reapIdleConnections() {
 	// Update the gauge after reaping
 	if reapCount > 0 {
 		atomic.AddInt64(&p.idle, -reapCount)
-		gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle))
+		gaugeInt64(context.Background(), p.daxSdkMetrics, daxConnectionsIdle, atomic.LoadInt64(&p.idle), endpointAttr(p.address))
+	}
+
+	p.fillIdle()
+}
+
+// fillIdle opens connections until minIdle tubes are idle, as far as the connection
+// limit allows. Like the reaper, it is expected to be called from a background goroutine.
+func (p *tubePool) fillIdle() {
+	p.mutex.Lock()
+	closed, session := p.closed, p.session
+	p.mutex.Unlock()
+	if closed {
+		return
+	}
+	for n := p.minIdle - int(atomic.LoadInt64(&p.idle)); n > 0; n-- {
+		if !p.reserveConn() {
+			return
+		}
+		t, err := p.alloc(session, RequestOptions{})
+		if err != nil {
+			return
+		}
+		p.put(t)
 	}
 }
 
 // Allocates a new tube by establishing a new connection and performing initialization.
+// The slot of the connection must be reserved with reserveConn, it is released when the
+// connection can't be established.
 func (p *tubePool) alloc(session int64, opt RequestOptions) (tube, error) {
-	conn, err := p.dialContext(context.TODO(), network, p.address)
+	conn, err := p.dialLimiter.dial(p.ctx, atomic.LoadInt32(&p.lost) != 0, p.dial, network, p.address)
 	if err != nil {
+		p.releaseConn()
 		p.debugLog(opt, "Error in establishing connection to address %s : %s", p.address, err)
 		return nil, err
 	}
+	atomic.StoreInt32(&p.lost, 0)
+	conn = p.limitConn(conn)
 
 	t, err := newTube(conn, session)
 	if err != nil {
 		p.debugLog(opt, "Error in allocating new tube for %s : %s", conn.RemoteAddr(), err)
 		return nil, err
 	}
+	t.CborReader().SetTolerant(p.connConfig.tolerantDecoding)
 
-	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsCreated, 1)
+	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsCreated, 1, endpointAttr(p.address))
 
 	return t, nil
 }
 
+// dial connects to address with the dialContext of the pool, giving up after the connect
+// timeout of the pool when set.
+func (p *tubePool) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if p.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.connectTimeout)
+		defer cancel()
+	}
+	return p.dialContext(ctx, network, address)
+}
+
 // Traverses the passed stack and closes all tubes in it.
 func (p *tubePool) closeAll(head tube) int64 {
 	var next tube
@@ -382,7 +621,7 @@ func (p *tubePool) closeAll(head tube) int64 {
 		c++
 	}
 
-	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedIdle, c)
+	countMetricInt64(context.Background(), p.daxSdkMetrics, daxConnectionsClosedIdle, c, endpointAttr(p.address))
 
 	return c
 }
@@ -397,7 +636,7 @@ func (p *tubePool) sessionBump() {
 // Logs debug logs if DEBUG logging is enabled.
 func (p *tubePool) debugLog(opt RequestOptions, logString string, args ...interface{}) {
 	if opt.Logger != nil && opt.LogLevel.AtLeast(utils.LogDebug) {
-		opt.Logger.Logf(logging.Debug, logString, args...)
+		logAttrs(opt.Context, opt.Logger, logging.Debug, []slog.Attr{slog.String("endpoint", p.address)}, logString, args...)
 	}
 }
 
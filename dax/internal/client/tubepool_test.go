@@ -17,10 +17,12 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -29,6 +31,8 @@ import (
 	"time"
 
 	"github.com/aws/aws-dax-go-v2/dax/internal/cbor"
+	"github.com/aws/aws-dax-go-v2/dax/leakcheck"
+	"github.com/aws/smithy-go/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -188,6 +192,7 @@ func TestTubePoolConnectionCache(t *testing.T) {
 }
 
 func TestTubePool_reapIdleTubes(t *testing.T) {
+	leakcheck.Check(t)
 	endpoint := ":8182"
 	startConnNotifier := make(chan net.Conn, 25)
 	endConnNotifier := make(chan net.Conn, 25)
@@ -263,7 +268,105 @@ func TestTubePool_reapIdleTubes(t *testing.T) {
 	})
 }
 
+func TestTubePool_maxConnections(t *testing.T) {
+	var dials int32
+	dial := func(ctx context.Context, a, n string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return &mockConn{}, nil
+	}
+	sdkMetrics, _ := buildDaxSdkMetrics(&metrics.NopMeterProvider{})
+	pool := newTubePoolWithOptions(":8182", tubePoolOptions{10, time.Second, dial}, connConfigData, sdkMetrics)
+	pool.closeTubeImmediately = true
+	pool.setLimits(0, 2)
+	defer pool.Close()
+
+	first, err := pool.get()
+	require.NoError(t, err)
+	second, err := pool.get()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.getWithContext(ctx, true, RequestOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "even high priority requests wait at the limit")
+	assert.EqualValues(t, 2, atomic.LoadInt32(&dials))
+
+	got := make(chan tube)
+	go func() {
+		tb, _ := pool.get()
+		got <- tb
+	}()
+	pool.put(first)
+	assert.Same(t, first, <-got, "a returned tube is handed to the waiting request")
+
+	go func() {
+		tb, _ := pool.get()
+		got <- tb
+	}()
+	pool.closeTube(second)
+	third := <-got
+	require.NotNil(t, third)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&dials), "closing a tube frees its slot")
+	pool.put(third)
+}
+
+func TestTubePool_minIdleConnections(t *testing.T) {
+	var dials int32
+	dial := func(ctx context.Context, a, n string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return &mockConn{}, nil
+	}
+	sdkMetrics, _ := buildDaxSdkMetrics(&metrics.NopMeterProvider{})
+	pool := newTubePoolWithOptions(":8182", tubePoolOptions{10, time.Second, dial}, connConfigData, sdkMetrics)
+	pool.setLimits(2, 0)
+	defer pool.Close()
+
+	pool.reapIdleConnections()
+	assert.Equal(t, 2, countTubes(pool), "the reaper opens the missing idle tubes")
+
+	tubes := make([]tube, 4)
+	for i := range tubes {
+		tb, err := pool.get()
+		require.NoError(t, err)
+		tubes[i] = tb
+	}
+	for _, tb := range tubes {
+		pool.put(tb)
+	}
+	pool.reapIdleConnections()
+	pool.reapIdleConnections()
+	assert.Equal(t, 2, countTubes(pool), "the reaper keeps the idle floor")
+	assert.EqualValues(t, 4, atomic.LoadInt32(&dials))
+}
+
+func TestTubePool_byteCounters(t *testing.T) {
+	dial := func(ctx context.Context, a, n string) (net.Conn, error) {
+		return &mockConn{rd: []byte{1, 2, 3}}, nil
+	}
+	sdkMetrics, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	pool := newTubePoolWithOptions("127.0.0.1:8111", tubePoolOptions{10, time.Second, dial}, connConfigData, sdkMetrics)
+	pool.closeTubeImmediately = true
+	defer pool.Close()
+
+	tb, err := pool.get()
+	require.NoError(t, err)
+	n, err := tb.(*netConnTube).conn.Read(make([]byte, 8))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	pool.put(tb)
+	expectCounters(t, sdkMetrics, map[string]int{daxConnectionsBytesSent: -1, daxConnectionsBytesReceived: 3})
+
+	received, _ := testInstrumentOf(sdkMetrics.counters[daxConnectionsBytesReceived])
+	assert.Equal(t, "127.0.0.1:8111", received.props[0].Get(metricAttrEndpoint))
+
+	tb, err = pool.get()
+	require.NoError(t, err)
+	pool.closeTube(tb)
+	assert.Len(t, received.props, 1, "a tube without traffic since it was last counted is not counted again")
+}
+
 func TestTubePool_Close(t *testing.T) {
+	leakcheck.Check(t)
 	endpoint := ":8183"
 	startConnNotifier := make(chan net.Conn, 25)
 	endConnNotifier := make(chan net.Conn, 25)
@@ -336,6 +439,33 @@ func TestTubePool_Close(t *testing.T) {
 	})
 }
 
+func TestTubePool_CloseWithContext(t *testing.T) {
+	sdkMetrics, _ := buildDaxSdkMetrics(&testMeterProvider{})
+	pool := newTubePoolWithOptions(":8184", tubePoolOptions{1, time.Second * 1, defaultDialer.DialContext}, connConfigData, sdkMetrics)
+	require.NoError(t, pool.begin())
+	require.NoError(t, pool.begin())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := pool.CloseWithContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "2 requests to :8184 still in flight")
+	assert.ErrorIs(t, pool.begin(), os.ErrClosed)
+
+	pool.end()
+	done := make(chan error)
+	go func() {
+		done <- pool.CloseWithContext(context.Background())
+	}()
+	pool.end()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("pool was not drained")
+	}
+}
+
 func TestTubePoolError(t *testing.T) {
 	endpoint := ":8184"
 
@@ -380,6 +510,78 @@ func TestTubePoolErrorWithCustomDialContext(t *testing.T) {
 	}
 }
 
+func TestTubePool_connectTimeout(t *testing.T) {
+	tmp := &testMeterProvider{}
+	sdkMetrics, _ := buildDaxSdkMetrics(tmp)
+
+	// The dial hangs like a TLS handshake with a node which stopped responding.
+	pool := newTubePoolWithOptions(":8186", tubePoolOptions{1, time.Second * 5, func(ctx context.Context, network, address string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}, connConfigData, sdkMetrics)
+	defer pool.Close()
+	pool.connectTimeout = 20 * time.Millisecond
+
+	start := time.Now()
+	_, err := pool.get()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestTubePool_reconnectJitter(t *testing.T) {
+	tmp := &testMeterProvider{}
+	sdkMetrics, _ := buildDaxSdkMetrics(tmp)
+
+	var dials int32
+	pool := newTubePoolWithOptions(":8186", tubePoolOptions{2, 50 * time.Millisecond, func(ctx context.Context, network, address string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		client, server := net.Pipe()
+		go io.Copy(io.Discard, server)
+		return client, nil
+	}}, connConfigData, sdkMetrics)
+	pool.dialLimiter = newDialLimiter(0, time.Hour)
+	pool.closeTubeImmediately = true
+
+	// new connections are not delayed
+	t1, err := pool.get()
+	require.NoError(t, err)
+	_, err = pool.get()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&dials))
+
+	// the connection replacing one closed on error is, until the pool is closed
+	pool.closeTube(t1)
+	_, err = pool.get()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualValues(t, 1, atomic.LoadInt64(&pool.pending))
+	pool.Close()
+	assert.Eventually(t, func() bool { return atomic.LoadInt64(&pool.pending) == 0 }, time.Second, time.Millisecond)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&dials))
+}
+
+func TestTubePool_proxy(t *testing.T) {
+	tmp := &testMeterProvider{}
+	sdkMetrics, _ := buildDaxSdkMetrics(tmp)
+
+	var proxied []string
+	cc := connConfigData
+	cc.proxy = func(address string) (*url.URL, error) {
+		proxied = append(proxied, address)
+		return nil, errors.New("no proxy")
+	}
+	pool := newTubePoolWithOptions("127.0.0.1:8186", tubePoolOptions{maxConcurrentConnAttempts: 1, timeout: time.Second}, cc, sdkMetrics)
+	defer pool.Close()
+
+	_, err := pool.get()
+	assert.EqualError(t, err, "no proxy")
+	assert.Equal(t, []string{"127.0.0.1:8186"}, proxied)
+
+	proxyURL := &url.URL{Scheme: "http", Host: "proxy:3128"}
+	u, err := ProxyURL(proxyURL)("10.0.0.1:8111")
+	assert.NoError(t, err)
+	assert.Same(t, proxyURL, u)
+}
+
 func TestConnectionPriority(t *testing.T) {
 	endpoint := ":8186"
 	listener, err := startServer(endpoint, nil, nil, drainAndCloseConn)
@@ -630,3 +832,30 @@ func TestTubePool_PutClosesTubesFromDifferentSession(t *testing.T) {
 		daxConnectionsClosedSession: 1,
 	})
 }
+
+func TestTubePool_setDeadlineTimeout(t *testing.T) {
+	p := &tubePool{}
+	deadlineOf := func(ctx context.Context, timeout time.Duration) time.Time {
+		tt := &mockTube{}
+		tt.On("SetDeadline", mock.Anything).Return(nil)
+		require.NoError(t, p.setDeadline(ctx, tt, timeout))
+		return tt.Calls[0].Arguments.Get(0).(time.Time)
+	}
+
+	assert.True(t, deadlineOf(context.Background(), 0).IsZero())
+
+	before := time.Now()
+	d := deadlineOf(context.Background(), time.Second)
+	assert.WithinDuration(t, before.Add(time.Second), d, 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+	assert.True(t, deadlineOf(ctx, time.Second).Before(ctxDeadline))
+	assert.Equal(t, ctxDeadline, deadlineOf(ctx, time.Hour))
+
+	opt := RequestOptions{ReadTimeout: time.Second, WriteTimeout: time.Minute}
+	assert.Equal(t, time.Second, opt.attemptTimeout(OpQuery))
+	assert.Equal(t, time.Minute, opt.attemptTimeout(OpPutItem))
+	assert.Equal(t, time.Duration(0), opt.attemptTimeout(opDefineKeySchema))
+}
@@ -0,0 +1,103 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const defaultMaxPendingConnectionsPerHost = 10
+
+// cgroupCPUFiles are the files holding the CPU quota of the container, for cgroup v2 and
+// v1 respectively.
+var cgroupCPUFiles = struct {
+	max, quota, period string
+}{
+	max:    "/sys/fs/cgroup/cpu.max",
+	quota:  "/sys/fs/cgroup/cpu/cpu.cfs_quota_us",
+	period: "/sys/fs/cgroup/cpu/cpu.cfs_period_us",
+}
+
+// availableCPUs returns the number of CPUs the process may use: GOMAXPROCS, lowered to
+// the CPU quota of the cgroup of the process when it has a smaller one. The quota may be
+// a fraction of a CPU.
+func availableCPUs() float64 {
+	cpus := float64(runtime.GOMAXPROCS(0))
+	if quota, ok := cgroupCPUQuota(); ok && quota < cpus {
+		cpus = quota
+	}
+	return cpus
+}
+
+// cgroupCPUQuota returns the CPU quota of the cgroup of the process, if it has one.
+func cgroupCPUQuota() (float64, bool) {
+	if b, err := os.ReadFile(cgroupCPUFiles.max); err == nil {
+		// "<quota> <period>", or "max <period>" when unlimited.
+		fields := strings.Fields(string(b))
+		if len(fields) != 2 {
+			return 0, false
+		}
+		return cpuQuota(fields[0], fields[1])
+	}
+	quota, err := os.ReadFile(cgroupCPUFiles.quota)
+	if err != nil {
+		return 0, false
+	}
+	period, err := os.ReadFile(cgroupCPUFiles.period)
+	if err != nil {
+		return 0, false
+	}
+	return cpuQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func cpuQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+// autoTune scales the connection limits left at their defaults to cpus: 2.5 pending
+// connections per host and per CPU, between 2 and 64, which is the historical default
+// of 10 on 4 CPUs, and 8 concurrent dials across the cluster per CPU, between 4 and 256.
+func (cfg *Config) autoTune(cpus float64) {
+	if cfg.MaxPendingConnectionsPerHost == 0 || cfg.MaxPendingConnectionsPerHost == defaultMaxPendingConnectionsPerHost {
+		cfg.MaxPendingConnectionsPerHost = scaleToCPUs(cpus, 2.5, 2, 64)
+	}
+	if cfg.MaxConcurrentDials == 0 {
+		cfg.MaxConcurrentDials = scaleToCPUs(cpus, 8, 4, 256)
+	}
+}
+
+func scaleToCPUs(cpus, perCPU float64, min, max int) int {
+	n := int(math.Ceil(cpus * perCPU))
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCgroupCPUQuota(t *testing.T) {
+	files := cgroupCPUFiles
+	defer func() { cgroupCPUFiles = files }()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	cgroupCPUFiles.max = write("cpu.max", "50000 100000\n")
+	cgroupCPUFiles.quota = filepath.Join(dir, "missing")
+	quota, ok := cgroupCPUQuota()
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, quota)
+	assert.Equal(t, 0.5, availableCPUs())
+
+	write("cpu.max", "max 100000\n")
+	_, ok = cgroupCPUQuota()
+	assert.False(t, ok, "unlimited")
+
+	// cgroup v1
+	cgroupCPUFiles.max = filepath.Join(dir, "missing")
+	cgroupCPUFiles.quota = write("cpu.cfs_quota_us", "200000\n")
+	cgroupCPUFiles.period = write("cpu.cfs_period_us", "100000\n")
+	quota, ok = cgroupCPUQuota()
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, quota)
+
+	write("cpu.cfs_quota_us", "-1\n")
+	_, ok = cgroupCPUQuota()
+	assert.False(t, ok, "unlimited")
+}
+
+func TestConfig_autoTune(t *testing.T) {
+	for _, tc := range []struct {
+		cpus           float64
+		pending, dials int
+	}{
+		{0.5, 2, 4},
+		{4, 10, 32},
+		{64, 64, 256},
+	} {
+		cfg := DefaultConfig()
+		cfg.autoTune(tc.cpus)
+		assert.Equal(t, tc.pending, cfg.MaxPendingConnectionsPerHost, tc.cpus)
+		assert.Equal(t, tc.dials, cfg.MaxConcurrentDials, tc.cpus)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxPendingConnectionsPerHost = 3
+	cfg.MaxConcurrentDials = 5
+	cfg.autoTune(64)
+	assert.Equal(t, 3, cfg.MaxPendingConnectionsPerHost, "configured limits are kept")
+	assert.Equal(t, 5, cfg.MaxConcurrentDials)
+}
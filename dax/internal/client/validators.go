@@ -389,9 +389,8 @@ func ValidateOpPutItemInput(v *dynamodb.PutItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkPutItemLimits(v)
 }
 
 func ValidateOpDeleteItemInput(v *dynamodb.DeleteItemInput) error {
@@ -407,9 +406,8 @@ func ValidateOpDeleteItemInput(v *dynamodb.DeleteItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkDeleteItemLimits(v)
 }
 
 func ValidateOpUpdateItemInput(v *dynamodb.UpdateItemInput) error {
@@ -425,9 +423,8 @@ func ValidateOpUpdateItemInput(v *dynamodb.UpdateItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkUpdateItemLimits(v)
 }
 
 func ValidateOpGetItemInput(v *dynamodb.GetItemInput) error {
@@ -443,9 +440,8 @@ func ValidateOpGetItemInput(v *dynamodb.GetItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkGetItemLimits(v)
 }
 
 func ValidateOpScanInput(v *dynamodb.ScanInput) error {
@@ -463,9 +459,8 @@ func ValidateOpScanInput(v *dynamodb.ScanInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkScanLimits(v)
 }
 
 func ValidateOpQueryInput(v *dynamodb.QueryInput) error {
@@ -488,9 +483,8 @@ func ValidateOpQueryInput(v *dynamodb.QueryInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkQueryLimits(v)
 }
 
 func ValidateOpBatchWriteItemInput(v *dynamodb.BatchWriteItemInput) error {
@@ -507,9 +501,8 @@ func ValidateOpBatchWriteItemInput(v *dynamodb.BatchWriteItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkBatchWriteItemLimits(v)
 }
 
 func ValidateOpBatchGetItemInput(v *dynamodb.BatchGetItemInput) error {
@@ -526,9 +519,8 @@ func ValidateOpBatchGetItemInput(v *dynamodb.BatchGetItemInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkBatchGetItemLimits(v)
 }
 
 func ValidateOpTransactWriteItemsInput(v *dynamodb.TransactWriteItemsInput) error {
@@ -545,9 +537,8 @@ func ValidateOpTransactWriteItemsInput(v *dynamodb.TransactWriteItemsInput) erro
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
-	} else {
-		return nil
 	}
+	return checkTransactWriteItemsLimits(v)
 }
 
 func ValidateOpTransactGetItemsInput(v *dynamodb.TransactGetItemsInput) error {
@@ -564,7 +555,126 @@ func ValidateOpTransactGetItemsInput(v *dynamodb.TransactGetItemsInput) error {
 	}
 	if invalidParams.Len() > 0 {
 		return invalidParams
+	}
+	return checkTransactGetItemsLimits(v)
+}
+
+// ValidateQueryExpressions validates a Query built from expressions more strictly than
+// ValidateOpQueryInput: a key condition is required, legacy parameters cannot be mixed
+// with their expression counterparts, and the expressions are parsed as they will be
+// encoded for DAX so that unsupported syntax and unused names or values are reported
+// before the request is sent.
+func ValidateQueryExpressions(v *dynamodb.QueryInput) error {
+	if v == nil {
+		return nil
+	}
+	if err := ValidateOpQueryInput(v); err != nil {
+		return err
+	}
+	invalidParams := smithy.InvalidParamsError{Context: "QueryInput"}
+	if v.KeyConditionExpression == nil && len(v.KeyConditions) == 0 {
+		invalidParams.Add(smithy.NewErrParamRequired("KeyConditionExpression"))
+	}
+	if v.KeyConditionExpression != nil && len(v.KeyConditions) > 0 {
+		invalidParams.Add(NewCustomInvalidParamError("KeyConditions", "cannot be used together with KeyConditionExpression"))
+	}
+	if v.FilterExpression != nil && len(v.QueryFilter) > 0 {
+		invalidParams.Add(NewCustomInvalidParamError("QueryFilter", "cannot be used together with FilterExpression"))
+	}
+	if v.ProjectionExpression != nil && len(v.AttributesToGet) > 0 {
+		invalidParams.Add(NewCustomInvalidParamError("AttributesToGet", "cannot be used together with ProjectionExpression"))
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	_, err := encodeExpressions(v.ProjectionExpression, v.FilterExpression, v.KeyConditionExpression, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+	return err
+}
+
+// ValidateOpBatchExecuteStatementInput checks that a batch has between 1 and 25
+// statements, which are either all reads or all writes.
+func ValidateOpBatchExecuteStatementInput(v *dynamodb.BatchExecuteStatementInput) error {
+	if v == nil {
+		return nil
+	}
+	invalidParams := smithy.InvalidParamsError{Context: "BatchExecuteStatementInput"}
+	if v.Statements == nil {
+		invalidParams.Add(smithy.NewErrParamRequired("Statements"))
 	} else {
+		if len(v.Statements) == 0 || len(v.Statements) > maxBatchStatements {
+			invalidParams.Add(NewCustomInvalidParamError("Statements", fmt.Sprintf("must contain between 1 and %d statements", maxBatchStatements)))
+		}
+		if err := validateBatchStatementRequests(v.Statements); err != nil {
+			invalidParams.AddNested("Statements", err.(smithy.InvalidParamsError))
+		}
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+func validateBatchStatementRequests(v []types.BatchStatementRequest) error {
+	invalidParams := smithy.InvalidParamsError{Context: "PartiQLBatchRequest"}
+	reads := 0
+	for i := range v {
+		if v[i].Statement == nil {
+			invalidParams.Add(smithy.NewErrParamRequired(fmt.Sprintf("[%d].Statement", i)))
+			continue
+		}
+		if _, read := StatementTarget(*v[i].Statement); read {
+			reads++
+		}
+	}
+	if reads > 0 && reads < len(v) {
+		invalidParams.Add(NewCustomInvalidParamError("Statements", "cannot mix reads and writes"))
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// ValidateOpExecuteTransactionInput checks that a transaction has between 1 and 100
+// statements, which are either all reads or all writes.
+func ValidateOpExecuteTransactionInput(v *dynamodb.ExecuteTransactionInput) error {
+	if v == nil {
 		return nil
 	}
+	invalidParams := smithy.InvalidParamsError{Context: "ExecuteTransactionInput"}
+	if v.TransactStatements == nil {
+		invalidParams.Add(smithy.NewErrParamRequired("TransactStatements"))
+	} else {
+		if len(v.TransactStatements) == 0 || len(v.TransactStatements) > maxTransactionStatements {
+			invalidParams.Add(NewCustomInvalidParamError("TransactStatements", fmt.Sprintf("must contain between 1 and %d statements", maxTransactionStatements)))
+		}
+		if err := validateParameterizedStatements(v.TransactStatements); err != nil {
+			invalidParams.AddNested("TransactStatements", err.(smithy.InvalidParamsError))
+		}
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+func validateParameterizedStatements(v []types.ParameterizedStatement) error {
+	invalidParams := smithy.InvalidParamsError{Context: "ParameterizedStatement"}
+	reads := 0
+	for i := range v {
+		if v[i].Statement == nil {
+			invalidParams.Add(smithy.NewErrParamRequired(fmt.Sprintf("[%d].Statement", i)))
+			continue
+		}
+		if _, read := StatementTarget(*v[i].Statement); read {
+			reads++
+		}
+	}
+	if reads > 0 && reads < len(v) {
+		invalidParams.Add(NewCustomInvalidParamError("TransactStatements", "cannot mix reads and writes"))
+	}
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
 }
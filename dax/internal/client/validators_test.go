@@ -743,3 +743,84 @@ func TestValidateOpTransactGetItemsInput(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateQueryExpressions(t *testing.T) {
+	pk := map[string]string{"#pk": "id"}
+	v1 := map[string]types.AttributeValue{":v": stringAttr("1")}
+	tests := []struct {
+		name    string
+		input   *dynamodb.QueryInput
+		wantErr bool
+	}{
+		{
+			name:    "nil input",
+			input:   nil,
+			wantErr: false,
+		},
+		{
+			name:    "missing key condition",
+			input:   &dynamodb.QueryInput{TableName: aws.String("TestTable")},
+			wantErr: true,
+		},
+		{
+			name: "valid input",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String("TestTable"),
+				KeyConditionExpression:    aws.String("#pk = :v"),
+				ExpressionAttributeNames:  pk,
+				ExpressionAttributeValues: v1,
+			},
+			wantErr: false,
+		},
+		{
+			name: "legacy key conditions",
+			input: &dynamodb.QueryInput{
+				TableName: aws.String("TestTable"),
+				KeyConditions: map[string]types.Condition{
+					"id": {ComparisonOperator: types.ComparisonOperatorEq, AttributeValueList: []types.AttributeValue{stringAttr("1")}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "key conditions mixed with expression",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String("TestTable"),
+				KeyConditionExpression:    aws.String("#pk = :v"),
+				ExpressionAttributeNames:  pk,
+				ExpressionAttributeValues: v1,
+				KeyConditions: map[string]types.Condition{
+					"id": {ComparisonOperator: types.ComparisonOperatorEq, AttributeValueList: []types.AttributeValue{stringAttr("1")}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unused value",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String("TestTable"),
+				KeyConditionExpression:    aws.String("#pk = :v"),
+				ExpressionAttributeNames:  pk,
+				ExpressionAttributeValues: map[string]types.AttributeValue{":v": stringAttr("1"), ":w": stringAttr("2")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid syntax",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String("TestTable"),
+				KeyConditionExpression:    aws.String("#pk == :v"),
+				ExpressionAttributeNames:  pk,
+				ExpressionAttributeValues: v1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateQueryExpressions(tt.input)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
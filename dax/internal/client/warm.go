@@ -0,0 +1,122 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// warmTimeout bounds the warming of the clients of the nodes joining the cluster, which
+// delays the update of the routes.
+const warmTimeout = 5 * time.Second
+
+// warmer is implemented by the clients which can open connections ahead of requests.
+type warmer interface {
+	warm(ctx context.Context, n int) (int, error)
+}
+
+// warm opens and authenticates n connections to the node and leaves them idle in the
+// pool. It returns the number of connections opened, which is less than n when it fails.
+func (client *SingleDaxClient) warm(ctx context.Context, n int) (int, error) {
+	tubes := make([]tube, 0, n)
+	defer func() {
+		for _, t := range tubes {
+			client.pool.put(t)
+		}
+	}()
+	for len(tubes) < n {
+		// Holding on to the tubes makes the pool open a new one every time.
+		t, err := client.pool.getWithContext(ctx, true, RequestOptions{})
+		if err != nil {
+			return len(tubes), err
+		}
+		if err := client.pool.setDeadline(ctx, t, 0); err != nil {
+			client.pool.closeTube(t)
+			return len(tubes), err
+		}
+		if err := client.auth(ctx, t); err != nil {
+			client.pool.closeTube(t)
+			return len(tubes), err
+		}
+		tubes = append(tubes, t)
+	}
+	return len(tubes), nil
+}
+
+// warmClients creates the clients of the endpoints of config which are not part of the
+// cluster yet and warms Config.WarmConnections connections of each, in parallel. update
+// routes requests to them once they are warm. A client failing to warm is still returned:
+// its requests open connections as usual.
+func (c *cluster) warmClients(config []serviceEndpoint) map[hostPort]clientAndConfig {
+	if c.config.WarmConnections <= 0 {
+		return nil
+	}
+
+	clients := make(map[hostPort]clientAndConfig)
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return nil
+	}
+	for _, ep := range config {
+		hp := ep.hostPort()
+		if _, ok := c.active[hp]; ok {
+			continue
+		}
+		if _, ok := clients[hp]; ok {
+			continue
+		}
+		// A failure is left to update, which creates the client again and handles it.
+		if cli, err := c.newSingleClient(ep); err == nil {
+			clients[hp] = clientAndConfig{client: cli, cfg: ep}
+		}
+	}
+	c.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), warmTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for hp, cc := range clients {
+		w, ok := cc.client.(warmer)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := w.warm(ctx, c.config.WarmConnections)
+			if err != nil {
+				c.debugLog("Warmed %d connections of %d for new node %s: %v", n, c.config.WarmConnections, hp, err)
+				return
+			}
+			c.debugLog("Warmed %d connections for new node %s", n, hp)
+		}()
+	}
+	wg.Wait()
+	return clients
+}
+
+// newWarmClient returns the client of ep warmed by warmClients, removing it from warmed,
+// or creates one. c.lock must be held when calling this method.
+func (c *cluster) newWarmClient(ep serviceEndpoint, warmed map[hostPort]clientAndConfig) (DaxAPI, error) {
+	if cc, ok := warmed[ep.hostPort()]; ok {
+		delete(warmed, ep.hostPort())
+		return cc.client, nil
+	}
+	return c.newSingleClient(ep)
+}
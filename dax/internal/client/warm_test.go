@@ -0,0 +1,102 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSingleDaxClient_warm(t *testing.T) {
+	var dials int32
+	client, err := newSingleClientWithOptions("127.0.0.1:9121", unEncryptedConnConfig, "us-west-2", &testCredentialProvider{}, 1, func(ctx context.Context, a, n string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return &mockConn{}, nil
+	}, nil, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	n, err := client.warm(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&dials))
+	idle, _ := client.pool.stats()
+	assert.Equal(t, 3, idle, "the warm connections are left idle in the pool")
+}
+
+// warmTestClient is a testClient recording whether it was routed to when it was warmed.
+type warmTestClient struct {
+	testClient
+	cluster *cluster
+	warmed  int
+	routed  bool
+}
+
+func (c *warmTestClient) warm(ctx context.Context, n int) (int, error) {
+	c.cluster.lock.RLock()
+	_, c.routed = c.cluster.active[c.hp]
+	c.cluster.lock.RUnlock()
+	c.warmed = n
+	return n, nil
+}
+
+type warmTestClientBuilder struct {
+	cluster *cluster
+	mu      sync.Mutex
+	clients []*warmTestClient
+}
+
+func (b *warmTestClientBuilder) newClient(ip net.IP, port int, _ connConfig, _ string, _ aws.CredentialsProvider, _ int, _ dialContext, _ RouteListener, _ *daxSdkMetrics) (DaxAPI, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := &warmTestClient{testClient: testClient{hp: hostPort{ip.String(), port}}, cluster: b.cluster}
+	b.clients = append(b.clients, c)
+	return c, nil
+}
+
+func TestCluster_updateWarmsNewNodes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HostPorts = []string{"127.0.0.1:8888"}
+	cfg.Region = "us-west-2"
+	cfg.WarmConnections = 2
+	cluster, _ := newTestClusterWithConfig(cfg)
+	defer cluster.Close()
+	b := &warmTestClientBuilder{cluster: cluster}
+	cluster.clientBuilder = b
+
+	first := []serviceEndpoint{{hostname: "localhost", port: 8121}, {hostname: "localhost", port: 8122}}
+	require.NoError(t, cluster.update(first))
+	assertNumRoutes(cluster, 2, t)
+	require.Len(t, b.clients, 2, "the warmed clients are the ones routed to")
+	for _, c := range b.clients {
+		assert.Equal(t, 2, c.warmed)
+		assert.False(t, c.routed, "clients are warmed before being routed to")
+	}
+
+	second := append(first, serviceEndpoint{hostname: "localhost", port: 8123})
+	require.NoError(t, cluster.update(second))
+	assertNumRoutes(cluster, 3, t)
+	require.Len(t, b.clients, 3, "only the new node is warmed")
+	assert.Equal(t, 8123, b.clients[2].hp.port)
+	assert.Equal(t, 2, b.clients[2].warmed)
+}
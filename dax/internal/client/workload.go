@@ -0,0 +1,92 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// recordWorkload records the composition of a completed request: whether it read or
+// wrote, the number of items in batch and transaction requests, the keys BatchGetItem
+// left unprocessed, and the number of items in query and scan pages.
+func recordWorkload(ctx context.Context, om *daxSdkMetrics, op string, input, output interface{}) {
+	if om == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	switch {
+	case IsReadOperation(op):
+		countMetricInt64(ctx, om, daxWorkloadReads, 1, operationAttr(op))
+	case IsWriteOperation(op):
+		countMetricInt64(ctx, om, daxWorkloadWrites, 1, operationAttr(op))
+	}
+
+	batchGetKeys := 0
+	switch in := input.(type) {
+	case *dynamodb.BatchGetItemInput:
+		batchGetKeys = countKeys(in.RequestItems)
+		histogramInt64(ctx, om, daxWorkloadBatchGetKeys, int64(batchGetKeys))
+	case *dynamodb.BatchWriteItemInput:
+		n := 0
+		for _, wrs := range in.RequestItems {
+			n += len(wrs)
+		}
+		histogramInt64(ctx, om, daxWorkloadBatchWriteRequests, int64(n))
+	case *dynamodb.TransactGetItemsInput:
+		histogramInt64(ctx, om, daxWorkloadTransactGetItems, int64(len(in.TransactItems)))
+	case *dynamodb.TransactWriteItemsInput:
+		histogramInt64(ctx, om, daxWorkloadTransactWriteItems, int64(len(in.TransactItems)))
+	}
+
+	switch out := output.(type) {
+	case *dynamodb.BatchGetItemOutput:
+		if out != nil && batchGetKeys > 0 {
+			n := countKeys(out.UnprocessedKeys)
+			histogramInt64(ctx, om, daxWorkloadBatchGetUnprocessed, int64(n))
+			histogramInt64(ctx, om, daxWorkloadBatchGetUnprocessedPct, int64(n*100/batchGetKeys))
+		}
+	case *dynamodb.QueryOutput:
+		if out != nil {
+			histogramInt64(ctx, om, daxWorkloadQueryPageItems, int64(len(out.Items)))
+		}
+	case *dynamodb.ScanOutput:
+		if out != nil {
+			histogramInt64(ctx, om, daxWorkloadScanPageItems, int64(len(out.Items)))
+		}
+	}
+}
+
+func countKeys(items map[string]types.KeysAndAttributes) int {
+	n := 0
+	for _, kaa := range items {
+		n += len(kaa.Keys)
+	}
+	return n
+}
+
+// RecordBatchGetRounds records the number of BatchGetItem rounds a helper needed to fetch
+// a set of keys, the first request and the retries of its unprocessed keys.
+func (cc *ClusterDaxClient) RecordBatchGetRounds(ctx context.Context, rounds int) {
+	if cc.cluster == nil || cc.cluster.daxSdkMetrics == nil {
+		return
+	}
+	histogramInt64(ctx, cc.cluster.daxSdkMetrics, daxWorkloadBatchGetRounds, int64(rounds))
+}
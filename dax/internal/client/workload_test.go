@@ -0,0 +1,76 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordWorkload(t *testing.T) {
+	mp := &testMeterProvider{}
+	om, err := buildDaxSdkMetrics(mp)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	recordWorkload(ctx, om, OpGetItem, &dynamodb.GetItemInput{}, &dynamodb.GetItemOutput{})
+	recordWorkload(ctx, om, OpBatchWriteItem, &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"a": make([]types.WriteRequest, 3),
+		"b": make([]types.WriteRequest, 2),
+	}}, nil)
+	recordWorkload(ctx, om, OpQuery, &dynamodb.QueryInput{}, &dynamodb.QueryOutput{Items: make([]map[string]types.AttributeValue, 7)})
+	recordWorkload(ctx, om, OpQuery, &dynamodb.QueryInput{}, (*dynamodb.QueryOutput)(nil))
+
+	tm := mp.meters[daxMeterScope].(*testMeter)
+	assert.Equal(t, []int64{3}, tm.i64s[daxWorkloadReads].data)
+	assert.Equal(t, []int64{1}, tm.i64s[daxWorkloadWrites].data)
+	assert.Equal(t, []int64{5}, tm.i64s[daxWorkloadBatchWriteRequests].data)
+	assert.Equal(t, []int64{7}, tm.i64s[daxWorkloadQueryPageItems].data)
+	assert.Empty(t, tm.i64s[daxWorkloadScanPageItems].data)
+
+	recordWorkload(ctx, nil, OpGetItem, nil, nil)
+}
+
+func TestRecordWorkload_batchGetUnprocessed(t *testing.T) {
+	mp := &testMeterProvider{}
+	om, err := buildDaxSdkMetrics(mp)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	input := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		"a": {Keys: make([]map[string]types.AttributeValue, 6)},
+		"b": {Keys: make([]map[string]types.AttributeValue, 2)},
+	}}
+	recordWorkload(ctx, om, OpBatchGetItem, input, &dynamodb.BatchGetItemOutput{UnprocessedKeys: map[string]types.KeysAndAttributes{
+		"a": {Keys: make([]map[string]types.AttributeValue, 2)},
+	}})
+	recordWorkload(ctx, om, OpBatchGetItem, input, &dynamodb.BatchGetItemOutput{})
+	recordWorkload(ctx, om, OpBatchGetItem, input, (*dynamodb.BatchGetItemOutput)(nil))
+
+	tm := mp.meters[daxMeterScope].(*testMeter)
+	assert.Equal(t, []int64{8, 8, 8}, tm.i64s[daxWorkloadBatchGetKeys].data)
+	assert.Equal(t, []int64{2, 0}, tm.i64s[daxWorkloadBatchGetUnprocessed].data)
+	assert.Equal(t, []int64{25, 0}, tm.i64s[daxWorkloadBatchGetUnprocessedPct].data)
+
+	cc := &ClusterDaxClient{cluster: &cluster{daxSdkMetrics: om}}
+	cc.RecordBatchGetRounds(ctx, 3)
+	assert.Equal(t, []int64{3}, tm.i64s[daxWorkloadBatchGetRounds].data)
+}
@@ -48,6 +48,13 @@ type entry struct {
 	prev, next *entry
 }
 
+// Len returns the number of entries in the cache.
+func (c *Lru) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.cache)
+}
+
 func (c *Lru) contains(key Key) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -116,6 +123,45 @@ func (c *Lru) GetWithContext(ctx context.Context, okey Key) (interface{}, error)
 	return v, err
 }
 
+// Peek returns the cached value of key, without loading it when it isn't cached.
+func (c *Lru) Peek(okey Key) (interface{}, bool) {
+	ikey := okey
+	if c.KeyMarshaller != nil {
+		ikey = c.KeyMarshaller(okey)
+	}
+	if en, ok := c.lookup(ikey); ok {
+		return en.value, true
+	}
+	return nil, false
+}
+
+// Remove drops the entry of key from the cache, so that the next lookup loads it again.
+func (c *Lru) Remove(okey Key) {
+	ikey := okey
+	if c.KeyMarshaller != nil {
+		ikey = c.KeyMarshaller(okey)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	en, ok := c.cache[ikey]
+	if !ok {
+		return
+	}
+	delete(c.cache, ikey)
+	if en.prev != nil {
+		en.prev.next = en.next
+	} else {
+		c.head = en.next
+	}
+	if en.next != nil {
+		en.next.prev = en.prev
+	} else {
+		c.tail = en.prev
+	}
+	en.prev, en.next = nil, nil
+}
+
 type loader struct {
 	wg    sync.WaitGroup
 	value interface{}
@@ -94,6 +94,10 @@ func TestLruEvict(t *testing.T) {
 		}
 	}
 
+	if c.Len() != 100 {
+		t.Fatalf("Lru.Len() got %v want %v", c.Len(), 100)
+	}
+
 	for i := 0; i < 23; i++ {
 		if c.contains(i) {
 			t.Fatalf("Lru.contains(%v) want false", i)
@@ -120,6 +124,54 @@ func TestLruEvict(t *testing.T) {
 	}
 }
 
+func TestLruRemove(t *testing.T) {
+	loads := 0
+	c := &Lru{
+		MaxEntries: 2,
+		LoadFunc: func(ctx context.Context, key Key) (interface{}, error) {
+			loads++
+			return key, nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		c.GetWithContext(nil, i)
+	}
+	c.Remove(0)
+	c.Remove(5)
+	if c.contains(0) || c.Len() != 1 {
+		t.Fatalf("Lru.Remove(0) left %v entries", c.Len())
+	}
+
+	// The removed entry is loaded again and the list still evicts the oldest entry.
+	c.GetWithContext(nil, 0)
+	c.GetWithContext(nil, 2)
+	if loads != 4 {
+		t.Fatalf("load calls got %v want %v", loads, 4)
+	}
+	if c.contains(1) || !c.contains(0) || !c.contains(2) {
+		t.Fatalf("Lru evicted the wrong entry")
+	}
+}
+
+func TestLruPeek(t *testing.T) {
+	loads := 0
+	c := &Lru{
+		LoadFunc: func(ctx context.Context, key Key) (interface{}, error) {
+			loads++
+			return key, nil
+		},
+	}
+
+	if _, ok := c.Peek(1); ok || loads != 0 {
+		t.Fatalf("Lru.Peek(1) of a missing entry returned ok %v after %v loads", ok, loads)
+	}
+	c.GetWithContext(nil, 1)
+	if v, ok := c.Peek(1); !ok || v != 1 || loads != 1 {
+		t.Fatalf("Lru.Peek(1) got %v, %v after %v loads", v, ok, loads)
+	}
+}
+
 func TestLruTimeout(t *testing.T) {
 	loadFn := func(ctx context.Context, key Key) (interface{}, error) {
 		select {
@@ -0,0 +1,156 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type socksRequest struct {
+	user, password string
+	target         string
+}
+
+// startSocksProxy serves SOCKS5 connect requests, requiring the password method when
+// password is set, and replying rep to the connect requests.
+func startSocksProxy(t *testing.T, password string, rep byte, requests chan<- socksRequest) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSocks(conn, password, rep, requests)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveSocks(conn net.Conn, password string, rep byte, requests chan<- socksRequest) {
+	defer conn.Close()
+	var r socksRequest
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return
+	}
+	methods := make([]byte, head[1])
+	io.ReadFull(conn, methods)
+	if password == "" {
+		conn.Write([]byte{5, 0})
+	} else {
+		conn.Write([]byte{5, 2})
+		b := make([]byte, 2)
+		io.ReadFull(conn, b)
+		user := make([]byte, b[1])
+		io.ReadFull(conn, user)
+		io.ReadFull(conn, b[:1])
+		pass := make([]byte, b[0])
+		io.ReadFull(conn, pass)
+		r.user, r.password = string(user), string(pass)
+		if r.password != password {
+			conn.Write([]byte{1, 1})
+			return
+		}
+		conn.Write([]byte{1, 0})
+	}
+
+	req := make([]byte, 4)
+	io.ReadFull(conn, req)
+	var host string
+	switch req[3] {
+	case 1, 4:
+		ip := make([]byte, map[byte]int{1: 4, 4: 16}[req[3]])
+		io.ReadFull(conn, ip)
+		host = net.IP(ip).String()
+	case 3:
+		l := make([]byte, 1)
+		io.ReadFull(conn, l)
+		name := make([]byte, l[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	}
+	port := make([]byte, 2)
+	io.ReadFull(conn, port)
+	r.target = net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	requests <- r
+
+	conn.Write([]byte{5, rep, 0, 1, 0, 0, 0, 0, 0, 0})
+	if rep != 0 {
+		return
+	}
+	target, err := net.Dial("tcp", r.target)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func TestTunnelDialer_socks5(t *testing.T) {
+	target := startGreeter(t)
+	requests := make(chan socksRequest, 1)
+	proxyAddr := startSocksProxy(t, "secret", 0, requests)
+	d := &TunnelDialer{
+		Proxy: func(string) (*url.URL, error) {
+			return &url.URL{Scheme: "socks5", Host: proxyAddr, User: url.UserPassword("user", "secret")}, nil
+		},
+		Forward: (&net.Dialer{}).DialContext,
+	}
+
+	conn, err := d.DialContext(context.Background(), "tcp", target)
+	require.NoError(t, err)
+	defer conn.Close()
+	b, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	assert.Equal(t, socksRequest{user: "user", password: "secret", target: target}, <-requests)
+}
+
+func TestTunnelDialer_socks5Errors(t *testing.T) {
+	requests := make(chan socksRequest, 1)
+	refusing := startSocksProxy(t, "", 5, requests)
+	d := &TunnelDialer{
+		Proxy:   func(string) (*url.URL, error) { return &url.URL{Scheme: "socks5h", Host: refusing}, nil },
+		Forward: (&net.Dialer{}).DialContext,
+	}
+	_, err := d.DialContext(context.Background(), "tcp", "node.internal:8111")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, "node.internal:8111", (<-requests).target)
+
+	_, err = d.DialContext(context.Background(), "tcp", "[fd00::1]:8111")
+	assert.Error(t, err)
+	assert.Equal(t, "[fd00::1]:8111", (<-requests).target)
+
+	authenticating := startSocksProxy(t, "secret", 0, requests)
+	d.Proxy = func(string) (*url.URL, error) {
+		return &url.URL{Scheme: "socks5", Host: authenticating, User: url.UserPassword("user", "wrong")}, nil
+	}
+	_, err = d.DialContext(context.Background(), "tcp", "10.0.0.1:8111")
+	assert.ErrorContains(t, err, "SOCKS authentication failed")
+}
@@ -8,13 +8,16 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
-	"strings"
 	"time"
 )
 
 type Dialer struct {
 	NetDialer *net.Dialer
 	Config    *tls.Config
+
+	// Forward, when set, establishes the connections the TLS handshakes are made over,
+	// e.g. through a ConnectDialer, instead of NetDialer.
+	Forward func(ctx context.Context, network, addr string) (net.Conn, error)
 }
 
 type timeoutError struct {
@@ -25,7 +28,7 @@ func (timeoutError) Timeout() bool   { return true }
 func (timeoutError) Temporary() bool { return true }
 
 func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
-	c, err := dial(ctx, d.netDialer(), network, addr, d.Config)
+	c, err := dial(ctx, d.netDialer(), d.Forward, network, addr, d.Config)
 	if err != nil {
 		// Don't return c (a typed nil) in an interface.
 		return nil, err
@@ -34,7 +37,7 @@ func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Con
 }
 
 // WARNING: this can leak a goroutine for as long as the underlying Dialer implementation takes to timeout
-func dial(ctx context.Context, netDialer *net.Dialer, network, addr string, config *tls.Config) (*tls.Conn, error) {
+func dial(ctx context.Context, netDialer *net.Dialer, forward func(context.Context, string, string) (net.Conn, error), network, addr string, config *tls.Config) (*tls.Conn, error) {
 	// We want the Timeout and Deadline values from dialer to cover the
 	// whole process: TCP connection and TLS handshake. This means that we
 	// also need to start our own timers now.
@@ -59,16 +62,18 @@ func dial(ctx context.Context, netDialer *net.Dialer, network, addr string, conf
 		defer timer.Stop()
 	}
 
-	rawConn, err := netDialer.DialContext(ctx, network, addr)
+	if forward == nil {
+		forward = netDialer.DialContext
+	}
+	rawConn, err := forward(ctx, network, addr)
 	if err != nil {
 		return nil, err
 	}
 
-	colonPos := strings.LastIndex(addr, ":")
-	if colonPos == -1 {
-		colonPos = len(addr)
+	hostname, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		hostname = addr
 	}
-	hostname := addr[:colonPos]
 
 	// If no ServerName is set, infer the ServerName
 	// from the hostname we're connecting to.
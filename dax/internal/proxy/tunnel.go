@@ -0,0 +1,155 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultPorts are the ports of the supported proxy schemes, used when the proxy URL has
+// none.
+var defaultPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"socks5":  "1080",
+	"socks5h": "1080",
+}
+
+// TunnelDialer establishes connections through HTTP proxies, with the CONNECT method, or
+// through SOCKS5 proxies.
+type TunnelDialer struct {
+	// Proxy returns the URL of the proxy to reach addr through, or nil to connect directly.
+	// The http, https, socks5 and socks5h schemes are supported. The user info of the URL,
+	// if any, is sent as basic proxy authorization to HTTP proxies and as username and
+	// password to SOCKS5 proxies. Addresses are always resolved by SOCKS5 proxies.
+	Proxy func(addr string) (*url.URL, error)
+	// Forward establishes the connections to the proxies and the direct connections.
+	Forward func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialContext connects to addr through the proxy returned by Proxy for addr.
+func (d *TunnelDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := d.Proxy(addr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return d.Forward(ctx, network, addr)
+	}
+
+	port, ok := defaultPorts[proxyURL.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("proxy: unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := d.Forward(ctx, network, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+	}
+
+	tunnel, err := withContext(ctx, conn, func() (net.Conn, error) {
+		switch proxyURL.Scheme {
+		case "socks5", "socks5h":
+			return conn, socks5Connect(conn, proxyURL, addr)
+		default:
+			return httpConnect(conn, proxyURL, addr)
+		}
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// withContext runs the handshake with the proxy at the other end of conn, giving up when
+// ctx is done.
+func withContext(ctx context.Context, conn net.Conn, handshake func() (net.Conn, error)) (net.Conn, error) {
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	tunnel, err := handshake()
+	if err != nil {
+		// Prefer the error of ctx, when done, over the network error it caused.
+		if e := ctx.Err(); e != nil {
+			return nil, e
+		}
+		return nil, err
+	}
+	if !stop() {
+		// ctx ended along with the handshake and expired the deadline of conn.
+		return nil, ctx.Err()
+	}
+	return tunnel, nil
+}
+
+// httpConnect asks the HTTP proxy at the other end of conn to open a tunnel to addr.
+func httpConnect(conn net.Conn, proxyURL *url.URL, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u := proxyURL.User; u != nil {
+		password, _ := u.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy: CONNECT %s through %s: %s", addr, proxyURL.Redacted(), resp.Status)
+	}
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were read ahead with the proxy response.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,145 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startConnectProxy serves CONNECT requests, tunneling them to the requested address
+// when handle returns http.StatusOK.
+func startConnectProxy(t *testing.T, handle func(*http.Request) int) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				req, err := http.ReadRequest(br)
+				if err != nil {
+					return
+				}
+				status := handle(req)
+				if status != http.StatusOK {
+					(&http.Response{StatusCode: status, ProtoMajor: 1, ProtoMinor: 1}).Write(conn)
+					return
+				}
+				target, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					return
+				}
+				defer target.Close()
+				io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go io.Copy(target, br)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func startGreeter(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			io.WriteString(conn, "hello")
+			conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestTunnelDialer(t *testing.T) {
+	target := startGreeter(t)
+	var auth string
+	proxyAddr := startConnectProxy(t, func(r *http.Request) int {
+		auth = r.Header.Get("Proxy-Authorization")
+		assert.Equal(t, http.MethodConnect, r.Method)
+		assert.Equal(t, target, r.Host)
+		return http.StatusOK
+	})
+	proxyURL := &url.URL{Scheme: "http", Host: proxyAddr, User: url.UserPassword("user", "secret")}
+	d := &TunnelDialer{
+		Proxy:   func(string) (*url.URL, error) { return proxyURL, nil },
+		Forward: (&net.Dialer{}).DialContext,
+	}
+
+	conn, err := d.DialContext(context.Background(), "tcp", target)
+	require.NoError(t, err)
+	defer conn.Close()
+	b, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+	assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", auth)
+}
+
+func TestTunnelDialer_direct(t *testing.T) {
+	target := startGreeter(t)
+	d := &TunnelDialer{
+		Proxy:   func(string) (*url.URL, error) { return nil, nil },
+		Forward: (&net.Dialer{}).DialContext,
+	}
+	conn, err := d.DialContext(context.Background(), "tcp", target)
+	require.NoError(t, err)
+	defer conn.Close()
+	b, _ := io.ReadAll(conn)
+	assert.Equal(t, "hello", string(b))
+}
+
+func TestTunnelDialer_errors(t *testing.T) {
+	denied := startConnectProxy(t, func(*http.Request) int { return http.StatusProxyAuthRequired })
+	d := &TunnelDialer{
+		Proxy:   func(string) (*url.URL, error) { return &url.URL{Scheme: "http", Host: denied}, nil },
+		Forward: (&net.Dialer{}).DialContext,
+	}
+	_, err := d.DialContext(context.Background(), "tcp", "10.0.0.1:8111")
+	assert.ErrorContains(t, err, "407")
+
+	d.Proxy = func(string) (*url.URL, error) { return &url.URL{Scheme: "ftp", Host: denied}, nil }
+	_, err = d.DialContext(context.Background(), "tcp", "10.0.0.1:8111")
+	assert.ErrorContains(t, err, "unsupported proxy scheme")
+
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer silent.Close()
+	d.Proxy = func(string) (*url.URL, error) { return &url.URL{Scheme: "http", Host: silent.Addr().String()}, nil }
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = d.DialContext(ctx, "tcp", "10.0.0.1:8111")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,439 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// DefaultLargeItemChunkSize leaves room below the 400KB item limit for the key and
+// the bookkeeping attributes of a chunk.
+const DefaultLargeItemChunkSize = 350 * 1024
+
+const (
+	manifestID         = "id"
+	manifestChunks     = "chunks"
+	manifestLength     = "length"
+	manifestDigest     = "sha256"
+	manifestRef        = "ref"
+	chunkIDAttribute   = "daxChunkId"
+	chunkDataAttribute = "daxChunkData"
+)
+
+// OverflowStore keeps values too large for DynamoDB outside of the table, e.g. as S3
+// objects. Keys are generated by PutLargeItem and are unique per write.
+type OverflowStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LargeItemOptions configures how PutLargeItem, GetLargeItem and DeleteLargeItem store a
+// binary attribute which does not fit an item.
+type LargeItemOptions struct {
+	// KeyAttributes names the key attributes of the table. Required by PutLargeItem.
+	KeyAttributes []string
+	// ChunkSize is the maximum number of bytes stored per chunk, DefaultLargeItemChunkSize
+	// when zero. Values not larger than ChunkSize are stored in the item itself.
+	ChunkSize int
+	// ChunkKey returns the key of the i-th chunk written by the write id of the item with
+	// the given key. Keys must differ per write, so that a failed write leaves the chunks
+	// of the current value intact. By default "#daxchunk#<id>#<i>" is appended to every
+	// string key attribute.
+	ChunkKey func(key map[string]types.AttributeValue, id string, i int) (map[string]types.AttributeValue, error)
+	// Overflow, when set, receives values larger than OverflowThreshold in place of chunk
+	// items, and the item holds a pointer to the stored object.
+	Overflow OverflowStore
+	// OverflowThreshold defaults to the chunk size.
+	OverflowThreshold int
+}
+
+func (o *LargeItemOptions) chunkSize() int {
+	if o.ChunkSize > 0 {
+		return o.ChunkSize
+	}
+	return DefaultLargeItemChunkSize
+}
+
+func (o *LargeItemOptions) overflows(value []byte) bool {
+	if o.Overflow == nil {
+		return false
+	}
+	if o.OverflowThreshold > 0 {
+		return len(value) > o.OverflowThreshold
+	}
+	return len(value) > o.chunkSize()
+}
+
+func (o *LargeItemOptions) chunkKey(key map[string]types.AttributeValue, id []byte, i int) (map[string]types.AttributeValue, error) {
+	if o.ChunkKey != nil {
+		return o.ChunkKey(key, hex.EncodeToString(id), i)
+	}
+	out := make(map[string]types.AttributeValue, len(key))
+	suffixed := false
+	for k, v := range key {
+		if s, ok := v.(*types.AttributeValueMemberS); ok {
+			v = &types.AttributeValueMemberS{Value: s.Value + "#daxchunk#" + hex.EncodeToString(id) + "#" + strconv.Itoa(i)}
+			suffixed = true
+		}
+		out[k] = v
+	}
+	if !suffixed {
+		return nil, client.NewCustomInvalidParamError("ChunkKey", "required for tables without string key attributes")
+	}
+	return out, nil
+}
+
+// ChunkIntegrityError is returned by GetLargeItem when the chunks of an item are missing,
+// belong to a different write or do not match the checksum of the manifest, or when the
+// overflow object does not match it. It usually
+// means the item was overwritten concurrently, reading it again is expected to succeed.
+type ChunkIntegrityError struct {
+	Table  string
+	Reason string
+}
+
+func (e *ChunkIntegrityError) Error() string {
+	return fmt.Sprintf("large item in table %s is inconsistent: %s", e.Table, e.Reason)
+}
+
+// chunkManifest describes a value stored either in chunks or, when ref is set, as the
+// overflow object ref.
+type chunkManifest struct {
+	id     []byte
+	chunks int
+	ref    string
+	length int
+	digest []byte
+}
+
+func newManifest(value []byte) (*chunkManifest, error) {
+	m := &chunkManifest{id: make([]byte, 16), length: len(value)}
+	if _, err := rand.Read(m.id); err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(value)
+	m.digest = digest[:]
+	return m, nil
+}
+
+func (m *chunkManifest) attributeValue() types.AttributeValue {
+	av := map[string]types.AttributeValue{
+		manifestID:     &types.AttributeValueMemberB{Value: m.id},
+		manifestLength: &types.AttributeValueMemberN{Value: strconv.Itoa(m.length)},
+		manifestDigest: &types.AttributeValueMemberB{Value: m.digest},
+	}
+	if m.ref != "" {
+		av[manifestRef] = &types.AttributeValueMemberS{Value: m.ref}
+	} else {
+		av[manifestChunks] = &types.AttributeValueMemberN{Value: strconv.Itoa(m.chunks)}
+	}
+	return &types.AttributeValueMemberM{Value: av}
+}
+
+func (m *chunkManifest) verify(table string, value []byte) error {
+	digest := sha256.Sum256(value)
+	if len(value) != m.length || !bytes.Equal(digest[:], m.digest) {
+		return &ChunkIntegrityError{Table: table, Reason: "checksum mismatch"}
+	}
+	return nil
+}
+
+// parseManifest returns nil if av is not a manifest.
+func parseManifest(av types.AttributeValue) *chunkManifest {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil
+	}
+	id, ok1 := m.Value[manifestID].(*types.AttributeValueMemberB)
+	length, ok2 := m.Value[manifestLength].(*types.AttributeValueMemberN)
+	digest, ok3 := m.Value[manifestDigest].(*types.AttributeValueMemberB)
+	if !ok1 || !ok2 || !ok3 {
+		return nil
+	}
+	l, err := strconv.Atoi(length.Value)
+	if err != nil {
+		return nil
+	}
+	manifest := &chunkManifest{id: id.Value, length: l, digest: digest.Value}
+	if ref, ok := m.Value[manifestRef].(*types.AttributeValueMemberS); ok {
+		manifest.ref = ref.Value
+		return manifest
+	}
+	chunks, ok := m.Value[manifestChunks].(*types.AttributeValueMemberN)
+	if !ok {
+		return nil
+	}
+	if manifest.chunks, err = strconv.Atoi(chunks.Value); err != nil {
+		return nil
+	}
+	return manifest
+}
+
+// PutLargeItem writes an item whose binary attribute attr may exceed the item size limit.
+// Larger values are split across chunk items in the same table, or stored in
+// opts.Overflow, before the item itself is written holding a manifest in place of the
+// value. Chunks are keyed by the id of the write, so the previous value stays readable
+// until the item is replaced. Chunks and overflow objects of the previous value are
+// deleted afterwards, those of a failed write right away.
+//
+// Chunks are written without the conditions and hooks applied to input, so the
+// attribute must not be encrypted with Config.Encryption.
+func (d *Dax) PutLargeItem(ctx context.Context, input *dynamodb.PutItemInput, attr string, opts LargeItemOptions, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key, value, err := validatePutLargeItem(input, attr, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	in := *input
+	in.ReturnValues = types.ReturnValueAllOld
+	var m *chunkManifest
+	switch {
+	case opts.overflows(value):
+		if m, err = newManifest(value); err != nil {
+			return nil, err
+		}
+		m.ref = fmt.Sprintf("%s/%x", aws.ToString(input.TableName), m.id)
+		if err = opts.Overflow.Put(ctx, m.ref, value); err != nil {
+			return nil, err
+		}
+	case len(value) > opts.chunkSize():
+		if m, err = newManifest(value); err != nil {
+			return nil, err
+		}
+		size := opts.chunkSize()
+		m.chunks = (len(value) + size - 1) / size
+		for i := 0; i < m.chunks; i++ {
+			end := (i + 1) * size
+			if end > len(value) {
+				end = len(value)
+			}
+			if err = d.putChunk(ctx, input.TableName, key, i, m.id, value[i*size:end], &opts, optFns); err != nil {
+				// The chunks are not referenced by any item.
+				m.chunks = i
+				_ = d.deleteStored(ctx, input.TableName, key, m, &opts, optFns)
+				return nil, err
+			}
+		}
+	}
+	if m != nil {
+		in.Item = make(map[string]types.AttributeValue, len(input.Item))
+		for k, v := range input.Item {
+			in.Item[k] = v
+		}
+		in.Item[attr] = m.attributeValue()
+	}
+
+	out, err := d.PutItem(ctx, &in, optFns...)
+	if err != nil {
+		// The chunks or the object are not referenced by any item.
+		_ = d.deleteStored(ctx, input.TableName, key, m, &opts, optFns)
+		return nil, err
+	}
+	old := parseManifest(out.Attributes[attr])
+	if input.ReturnValues != types.ReturnValueAllOld {
+		out.Attributes = nil
+	}
+	return out, d.deleteStored(ctx, input.TableName, key, old, &opts, optFns)
+}
+
+// GetLargeItem reads an item written by PutLargeItem, reassembling attr from its chunks
+// or overflow object and verifying it against the checksum of the manifest. Items which
+// hold the value itself are returned as they are.
+func (d *Dax) GetLargeItem(ctx context.Context, input *dynamodb.GetItemInput, attr string, opts LargeItemOptions, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	out, err := d.GetItem(ctx, input, optFns...)
+	if err != nil || out.Item == nil {
+		return out, err
+	}
+	m := parseManifest(out.Item[attr])
+	if m == nil {
+		return out, nil
+	}
+	table := aws.ToString(input.TableName)
+	var value []byte
+	if m.ref != "" {
+		if opts.Overflow == nil {
+			return nil, client.NewCustomInvalidParamError("Overflow", fmt.Sprintf("required to read attribute %s", attr))
+		}
+		if value, err = opts.Overflow.Get(ctx, m.ref); err != nil {
+			return nil, err
+		}
+	} else {
+		value = make([]byte, 0, m.length)
+		for i := 0; i < m.chunks; i++ {
+			chunk, err := d.getChunk(ctx, input, m.id, i, &opts, optFns)
+			if err != nil {
+				return nil, err
+			}
+			id, _ := chunk[chunkIDAttribute].(*types.AttributeValueMemberB)
+			data, _ := chunk[chunkDataAttribute].(*types.AttributeValueMemberB)
+			if id == nil || data == nil {
+				return nil, &ChunkIntegrityError{Table: table, Reason: fmt.Sprintf("chunk %d is missing", i)}
+			}
+			if !bytes.Equal(id.Value, m.id) {
+				return nil, &ChunkIntegrityError{Table: table, Reason: fmt.Sprintf("chunk %d belongs to a different write", i)}
+			}
+			value = append(value, data.Value...)
+		}
+	}
+	if err = m.verify(table, value); err != nil {
+		return nil, err
+	}
+	out.Item[attr] = &types.AttributeValueMemberB{Value: value}
+	return out, nil
+}
+
+// DeleteLargeItem deletes an item written by PutLargeItem together with its chunks or
+// overflow object.
+func (d *Dax) DeleteLargeItem(ctx context.Context, input *dynamodb.DeleteItemInput, attr string, opts LargeItemOptions, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if input == nil {
+		return nil, smithy.NewErrParamRequired("input cannot be nil")
+	}
+	in := *input
+	in.ReturnValues = types.ReturnValueAllOld
+	out, err := d.DeleteItem(ctx, &in, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	old := parseManifest(out.Attributes[attr])
+	if input.ReturnValues != types.ReturnValueAllOld {
+		out.Attributes = nil
+	}
+	return out, d.deleteStored(ctx, input.TableName, input.Key, old, &opts, optFns)
+}
+
+// deleteStored deletes the overflow object or the chunks of the value described by old.
+func (d *Dax) deleteStored(ctx context.Context, table *string, key map[string]types.AttributeValue, old *chunkManifest, opts *LargeItemOptions, optFns []func(*dynamodb.Options)) error {
+	if old == nil {
+		return nil
+	}
+	if old.ref != "" {
+		if opts.Overflow == nil {
+			return client.NewCustomInvalidParamError("Overflow", "required to delete overflow object "+old.ref)
+		}
+		return opts.Overflow.Delete(ctx, old.ref)
+	}
+	for i := 0; i < old.chunks; i++ {
+		if err := d.deleteChunk(ctx, table, key, old.id, i, opts, optFns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePutLargeItem(input *dynamodb.PutItemInput, attr string, opts *LargeItemOptions) (map[string]types.AttributeValue, []byte, error) {
+	invalidParams := smithy.InvalidParamsError{Context: "PutLargeItem"}
+	if input == nil || input.TableName == nil {
+		invalidParams.Add(smithy.NewErrParamRequired("TableName"))
+		return nil, nil, invalidParams
+	}
+	if len(opts.KeyAttributes) == 0 {
+		invalidParams.Add(smithy.NewErrParamRequired("KeyAttributes"))
+	}
+	key := make(map[string]types.AttributeValue, len(opts.KeyAttributes))
+	for _, k := range opts.KeyAttributes {
+		v, ok := input.Item[k]
+		if !ok {
+			invalidParams.Add(client.NewCustomInvalidParamError("Item", fmt.Sprintf("key attribute %s is missing", k)))
+			continue
+		}
+		key[k] = v
+	}
+	b, ok := input.Item[attr].(*types.AttributeValueMemberB)
+	if !ok {
+		invalidParams.Add(client.NewCustomInvalidParamError("Item", fmt.Sprintf("attribute %s must be binary", attr)))
+	}
+	if invalidParams.Len() > 0 {
+		return nil, nil, invalidParams
+	}
+	return key, b.Value, nil
+}
+
+// putChunk writes the i-th chunk through the client directly, chunks are accessed without
+// the versioning and encryption applying to the item holding the manifest.
+func (d *Dax) putChunk(ctx context.Context, table *string, key map[string]types.AttributeValue, i int, id, data []byte, opts *LargeItemOptions, optFns []func(*dynamodb.Options)) error {
+	chunkKey, err := opts.chunkKey(key, id, i)
+	if err != nil {
+		return err
+	}
+	item := make(map[string]types.AttributeValue, len(chunkKey)+2)
+	for k, v := range chunkKey {
+		item[k] = v
+	}
+	item[chunkIDAttribute] = &types.AttributeValueMemberB{Value: id}
+	item[chunkDataAttribute] = &types.AttributeValueMemberB{Value: data}
+
+	o, cfn, err := d.config.requestOptions(OpPutItem, false, ctx, optFns...)
+	if err != nil {
+		return err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	_, err = d.client.PutItemWithOptions(ctx, &dynamodb.PutItemInput{TableName: table, Item: item}, &dynamodb.PutItemOutput{}, o)
+	return err
+}
+
+func (d *Dax) getChunk(ctx context.Context, input *dynamodb.GetItemInput, id []byte, i int, opts *LargeItemOptions, optFns []func(*dynamodb.Options)) (map[string]types.AttributeValue, error) {
+	chunkKey, err := opts.chunkKey(input.Key, id, i)
+	if err != nil {
+		return nil, err
+	}
+	o, cfn, err := d.config.requestOptions(OpGetItem, true, ctx, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	out, err := d.client.GetItemWithOptions(ctx, &dynamodb.GetItemInput{
+		TableName:      input.TableName,
+		Key:            chunkKey,
+		ConsistentRead: input.ConsistentRead,
+	}, &dynamodb.GetItemOutput{}, o)
+	if err != nil {
+		return nil, err
+	}
+	return out.Item, nil
+}
+
+func (d *Dax) deleteChunk(ctx context.Context, table *string, key map[string]types.AttributeValue, id []byte, i int, opts *LargeItemOptions, optFns []func(*dynamodb.Options)) error {
+	chunkKey, err := opts.chunkKey(key, id, i)
+	if err != nil {
+		return err
+	}
+	o, cfn, err := d.config.requestOptions(OpDeleteItem, false, ctx, optFns...)
+	if err != nil {
+		return err
+	}
+	if cfn != nil {
+		defer cfn()
+	}
+	_, err = d.client.DeleteItemWithOptions(ctx, &dynamodb.DeleteItemInput{TableName: table, Key: chunkKey}, &dynamodb.DeleteItemOutput{}, o)
+	return err
+}
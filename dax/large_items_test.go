@@ -0,0 +1,257 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tableClient stores items of a table with a string partition key "pk".
+type tableClient struct {
+	client.DaxAPI
+	items   map[string]map[string]types.AttributeValue
+	failPut func(*dynamodb.PutItemInput) error
+}
+
+func pk(key map[string]types.AttributeValue) string {
+	return key["pk"].(*types.AttributeValueMemberS).Value
+}
+
+func (c *tableClient) PutItemWithOptions(_ context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, _ client.RequestOptions) (*dynamodb.PutItemOutput, error) {
+	if c.failPut != nil {
+		if err := c.failPut(input); err != nil {
+			return output, err
+		}
+	}
+	k := pk(input.Item)
+	if input.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = c.items[k]
+	}
+	c.items[k] = input.Item
+	return output, nil
+}
+
+func (c *tableClient) GetItemWithOptions(_ context.Context, input *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, _ client.RequestOptions) (*dynamodb.GetItemOutput, error) {
+	if item, ok := c.items[pk(input.Key)]; ok {
+		output.Item = make(map[string]types.AttributeValue, len(item))
+		for k, v := range item {
+			output.Item[k] = v
+		}
+	}
+	return output, nil
+}
+
+func (c *tableClient) DeleteItemWithOptions(_ context.Context, input *dynamodb.DeleteItemInput, output *dynamodb.DeleteItemOutput, _ client.RequestOptions) (*dynamodb.DeleteItemOutput, error) {
+	k := pk(input.Key)
+	if input.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = c.items[k]
+	}
+	delete(c.items, k)
+	return output, nil
+}
+
+func TestLargeItem_roundTrip(t *testing.T) {
+	c := &tableClient{items: map[string]map[string]types.AttributeValue{}}
+	d := &Dax{client: c}
+	opts := LargeItemOptions{KeyAttributes: []string{"pk"}, ChunkSize: 10}
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}
+	blob := bytes.Repeat([]byte("0123456789abcdef"), 3)
+
+	put := func(value []byte) {
+		_, err := d.PutLargeItem(context.Background(), &dynamodb.PutItemInput{
+			TableName: aws.String("t"),
+			Item: map[string]types.AttributeValue{
+				"pk":   key["pk"],
+				"blob": &types.AttributeValueMemberB{Value: value},
+			},
+		}, "blob", opts)
+		require.NoError(t, err)
+	}
+	get := func() (*dynamodb.GetItemOutput, error) {
+		return d.GetLargeItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+	}
+
+	put(blob)
+	assert.Len(t, c.items, 6)
+	id := hex.EncodeToString(parseManifest(c.items["a"]["blob"]).id)
+	chunk := func(i int) string { return "a#daxchunk#" + id + "#" + strconv.Itoa(i) }
+	assert.Contains(t, c.items, chunk(4))
+	out, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, blob, out.Item["blob"].(*types.AttributeValueMemberB).Value)
+
+	// A chunk of another write is detected.
+	saved := c.items[chunk(1)]
+	c.items[chunk(1)] = map[string]types.AttributeValue{
+		"pk":               saved["pk"],
+		chunkIDAttribute:   &types.AttributeValueMemberB{Value: []byte("other")},
+		chunkDataAttribute: saved[chunkDataAttribute],
+	}
+	_, err = get()
+	var integrityErr *ChunkIntegrityError
+	require.ErrorAs(t, err, &integrityErr)
+	c.items[chunk(1)] = saved
+
+	// Tampered data fails the checksum.
+	c.items[chunk(2)][chunkDataAttribute] = &types.AttributeValueMemberB{Value: []byte("xxxxxxxxxx")}
+	_, err = get()
+	require.ErrorAs(t, err, &integrityErr)
+
+	// Overwriting with a small value stores it inline and removes the chunks.
+	put([]byte("small"))
+	assert.Len(t, c.items, 1)
+	out, err = get()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), out.Item["blob"].(*types.AttributeValueMemberB).Value)
+
+	put(blob)
+	_, err = d.DeleteLargeItem(context.Background(), &dynamodb.DeleteItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+	require.NoError(t, err)
+	assert.Empty(t, c.items)
+}
+
+func TestLargeItem_failedRewrite(t *testing.T) {
+	c := &tableClient{items: map[string]map[string]types.AttributeValue{}}
+	d := &Dax{client: c}
+	opts := LargeItemOptions{KeyAttributes: []string{"pk"}, ChunkSize: 10}
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}
+	put := func(value []byte) error {
+		_, err := d.PutLargeItem(context.Background(), &dynamodb.PutItemInput{
+			TableName: aws.String("t"),
+			Item:      map[string]types.AttributeValue{"pk": key["pk"], "blob": &types.AttributeValueMemberB{Value: value}},
+		}, "blob", opts)
+		return err
+	}
+	blob := bytes.Repeat([]byte("a"), 30)
+	require.NoError(t, put(blob))
+	require.Len(t, c.items, 4)
+
+	for _, failAt := range []string{"chunk", "manifest"} {
+		failure := errors.New("write failed")
+		chunks := 0
+		c.failPut = func(input *dynamodb.PutItemInput) error {
+			if pk(input.Item) == "a" {
+				if failAt == "manifest" {
+					return failure
+				}
+				return nil
+			}
+			if chunks++; chunks == 2 && failAt == "chunk" {
+				return failure
+			}
+			return nil
+		}
+		assert.ErrorIs(t, put(bytes.Repeat([]byte("b"), 40)), failure)
+		assert.Len(t, c.items, 4, "the chunks of the failed write are deleted")
+		c.failPut = nil
+
+		out, err := d.GetLargeItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+		require.NoError(t, err, "the current value stays readable when failing at the %s", failAt)
+		assert.Equal(t, blob, out.Item["blob"].(*types.AttributeValueMemberB).Value)
+	}
+}
+
+func TestLargeItem_validation(t *testing.T) {
+	d := &Dax{client: &tableClient{}}
+	_, err := d.PutLargeItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String("t"),
+		Item:      map[string]types.AttributeValue{"blob": &types.AttributeValueMemberS{Value: "x"}},
+	}, "blob", LargeItemOptions{KeyAttributes: []string{"pk"}})
+	assert.Error(t, err)
+
+	opts := LargeItemOptions{}
+	_, err = opts.chunkKey(map[string]types.AttributeValue{"id": &types.AttributeValueMemberN{Value: "1"}}, []byte{1}, 0)
+	assert.Error(t, err)
+
+	_, err = d.PutLargeItem(context.Background(), nil, "blob", LargeItemOptions{KeyAttributes: []string{"pk"}})
+	assert.Error(t, err)
+	_, err = d.DeleteLargeItem(context.Background(), nil, "blob", LargeItemOptions{})
+	assert.Error(t, err)
+}
+
+type memoryStore map[string][]byte
+
+func (s memoryStore) Put(_ context.Context, key string, data []byte) error {
+	s[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (s memoryStore) Delete(_ context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestLargeItem_overflow(t *testing.T) {
+	c := &tableClient{items: map[string]map[string]types.AttributeValue{}}
+	d := &Dax{client: c}
+	store := memoryStore{}
+	opts := LargeItemOptions{KeyAttributes: []string{"pk"}, ChunkSize: 10, Overflow: store, OverflowThreshold: 20}
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}
+	put := func(value []byte) {
+		_, err := d.PutLargeItem(context.Background(), &dynamodb.PutItemInput{
+			TableName: aws.String("t"),
+			Item:      map[string]types.AttributeValue{"pk": key["pk"], "blob": &types.AttributeValueMemberB{Value: value}},
+		}, "blob", opts)
+		require.NoError(t, err)
+	}
+
+	blob := bytes.Repeat([]byte("x"), 50)
+	put(blob)
+	assert.Len(t, c.items, 1)
+	require.Len(t, store, 1)
+	out, err := d.GetLargeItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+	require.NoError(t, err)
+	assert.Equal(t, blob, out.Item["blob"].(*types.AttributeValueMemberB).Value)
+
+	for k := range store {
+		store[k] = []byte("tampered")
+	}
+	_, err = d.GetLargeItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+	var integrityErr *ChunkIntegrityError
+	require.ErrorAs(t, err, &integrityErr)
+
+	// Values between the chunk size and the threshold are chunked, replacing the object.
+	put(bytes.Repeat([]byte("y"), 15))
+	assert.Empty(t, store)
+	assert.Len(t, c.items, 3)
+
+	put(blob)
+	assert.Len(t, store, 1)
+	assert.Len(t, c.items, 1)
+	_, err = d.DeleteLargeItem(context.Background(), &dynamodb.DeleteItemInput{TableName: aws.String("t"), Key: key}, "blob", opts)
+	require.NoError(t, err)
+	assert.Empty(t, store)
+}
@@ -0,0 +1,209 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+// Package leakcheck finds goroutines and file descriptors left behind by a test.
+//
+// The DAX client runs background tasks for connection pools, cluster refreshes, health
+// checks and route rebuilds, all of which must stop when the client is closed. Tests of
+// code creating clients can verify that with:
+//
+//	func TestSomething(t *testing.T) {
+//		leakcheck.Check(t)
+//		client, err := dax.New(cfg)
+//		...
+//		defer client.Close()
+//	}
+package leakcheck
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+type options struct {
+	timeout time.Duration
+	ignored []string
+}
+
+// Option configures a leak check.
+type Option func(*options)
+
+// Timeout sets how long background tasks are given to exit before they are reported as
+// leaked. It defaults to 5 seconds.
+func Timeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// IgnoreFunction ignores goroutines which have fn, a fully qualified function name such
+// as "net/http.(*persistConn).readLoop", on their stack.
+func IgnoreFunction(fn string) Option {
+	return func(o *options) {
+		o.ignored = append(o.ignored, fn)
+	}
+}
+
+// Check snapshots the goroutines and file descriptors of the process and registers a
+// cleanup failing t if any were added and not released by the time t and its cleanups
+// registered after Check are done. It must not be used by parallel tests, whose
+// goroutines it can't tell apart.
+func Check(t testing.TB, opts ...Option) {
+	t.Helper()
+	s := Take()
+	t.Cleanup(func() {
+		if err := s.Leaks(opts...); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// Snapshot is the set of goroutines and the number of file descriptors of the process
+// at some point in time.
+type Snapshot struct {
+	goroutines map[uint64]bool
+	fds        int
+}
+
+// Take returns a snapshot of the current goroutines and file descriptors.
+func Take() Snapshot {
+	s := Snapshot{goroutines: make(map[uint64]bool), fds: countFds()}
+	for _, g := range goroutines() {
+		s.goroutines[g.id] = true
+	}
+	return s
+}
+
+// Leaks waits for goroutines and file descriptors created since s to go away, and
+// returns an error describing those still present after the timeout.
+func (s Snapshot) Leaks(opts ...Option) error {
+	o := options{timeout: defaultTimeout}
+	for _, fn := range opts {
+		fn(&o)
+	}
+
+	deadline := time.Now().Add(o.timeout)
+	backoff := time.Millisecond
+	for {
+		leaked := s.leakedGoroutines(o.ignored)
+		fds := countFds()
+		fdLeak := s.fds >= 0 && fds > s.fds
+		if len(leaked) == 0 && !fdLeak {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			var msgs []string
+			if len(leaked) > 0 {
+				stacks := make([]string, len(leaked))
+				for i, g := range leaked {
+					stacks[i] = g.stack
+				}
+				msgs = append(msgs, fmt.Sprintf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(stacks, "\n\n")))
+			}
+			if fdLeak {
+				msgs = append(msgs, fmt.Sprintf("%d file descriptors leaked (%d open, %d before)", fds-s.fds, fds, s.fds))
+			}
+			return fmt.Errorf("leakcheck: %s", strings.Join(msgs, "\n"))
+		}
+		time.Sleep(backoff)
+		if backoff < 100*time.Millisecond {
+			backoff *= 2
+		}
+	}
+}
+
+func (s Snapshot) leakedGoroutines(ignored []string) []goroutine {
+	var leaked []goroutine
+	// the first goroutine is the calling one, which is expected to be alive
+	for _, g := range goroutines()[1:] {
+		if s.goroutines[g.id] || g.ignored(ignored) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+	sort.Slice(leaked, func(i, j int) bool { return leaked[i].id < leaked[j].id })
+	return leaked
+}
+
+type goroutine struct {
+	id    uint64
+	stack string
+}
+
+// runtimeFunctions are on the stack of goroutines the runtime and the testing package
+// start on their own.
+var runtimeFunctions = []string{
+	"testing.(*T).Run",
+	"testing.(*F).Fuzz",
+	"testing.RunTests",
+	"testing.runFuzzing",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+}
+
+func (g goroutine) ignored(ignored []string) bool {
+	for _, fns := range [][]string{runtimeFunctions, ignored} {
+		for _, fn := range fns {
+			if strings.Contains(g.stack, "\n"+fn+"(") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goroutines returns all goroutines, starting with the calling one.
+func goroutines() []goroutine {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var gs []goroutine
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		header, _, _ := strings.Cut(string(stack), " [")
+		id, err := strconv.ParseUint(strings.TrimPrefix(header, "goroutine "), 10, 64)
+		if err != nil {
+			continue
+		}
+		gs = append(gs, goroutine{id: id, stack: string(stack)})
+	}
+	return gs
+}
+
+// countFds returns the number of open file descriptors of the process, or -1 where it
+// can't be determined.
+func countFds() int {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		if entries, err := os.ReadDir(dir); err == nil {
+			return len(entries)
+		}
+	}
+	return -1
+}
@@ -0,0 +1,76 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package leakcheck
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func blockUntilClosed(done chan struct{}) {
+	<-done
+}
+
+func TestLeaks_goroutine(t *testing.T) {
+	s := Take()
+	done := make(chan struct{})
+	go blockUntilClosed(done)
+
+	err := s.Leaks(Timeout(50 * time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "1 goroutines leaked") || !strings.Contains(err.Error(), "leakcheck.blockUntilClosed") {
+		t.Errorf("expect leaked goroutine to be reported, got %v", err)
+	}
+	if err := s.Leaks(Timeout(50*time.Millisecond), IgnoreFunction("github.com/aws/aws-dax-go-v2/dax/leakcheck.blockUntilClosed")); err != nil {
+		t.Errorf("expect ignored goroutine not to be reported, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+	if err := s.Leaks(Timeout(time.Second)); err != nil {
+		t.Errorf("expect goroutine exiting within the timeout not to be reported, got %v", err)
+	}
+}
+
+func TestLeaks_fd(t *testing.T) {
+	s := Take()
+	if s.fds < 0 {
+		t.Skip("file descriptors can't be counted on this platform")
+	}
+	f, err := os.Open(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Leaks(Timeout(50 * time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "1 file descriptors leaked") {
+		t.Errorf("expect leaked file descriptor to be reported, got %v", err)
+	}
+	f.Close()
+	if err := s.Leaks(Timeout(50 * time.Millisecond)); err != nil {
+		t.Errorf("expect no leak once the file is closed, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	Check(t)
+	done := make(chan struct{})
+	go blockUntilClosed(done)
+	t.Cleanup(func() { close(done) })
+}
@@ -0,0 +1,28 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// HistogramBucketsMeter is implemented by meters which accept explicit histogram
+// bucket boundaries, which are then used for the operation latency histograms.
+type HistogramBucketsMeter = client.HistogramBucketsMeter
+
+// DefaultLatencyHistogramBuckets are the latency bucket boundaries in microseconds,
+// suitable for an OpenTelemetry view when the meter provider cannot take hints.
+var DefaultLatencyHistogramBuckets = client.DefaultLatencyHistogramBuckets
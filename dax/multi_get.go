@@ -0,0 +1,185 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// MultiGetStrategy is the way MultiGet fetched the items.
+type MultiGetStrategy string
+
+const (
+	// MultiGetParallel fetches every key with its own GetItem call, routed and cached per key.
+	MultiGetParallel MultiGetStrategy = "parallel"
+	// MultiGetBatch fetches the keys with BatchGetItem calls.
+	MultiGetBatch MultiGetStrategy = "batch"
+)
+
+// DefaultMultiGetParallelThreshold is the number of keys up to which MultiGet issues
+// parallel GetItem calls by default.
+const DefaultMultiGetParallelThreshold = 8
+
+// MultiGetOptions configures MultiGet.
+type MultiGetOptions struct {
+	// ParallelThreshold is the largest number of keys fetched with parallel GetItem calls,
+	// DefaultMultiGetParallelThreshold when zero. Set it to a negative value to always batch.
+	ParallelThreshold int
+	// Concurrency limits the number of concurrent GetItem calls, ParallelThreshold when zero.
+	Concurrency int
+
+	ConsistentRead           bool
+	ProjectionExpression     *string
+	ExpressionAttributeNames map[string]string
+}
+
+func (o *MultiGetOptions) parallelThreshold() int {
+	if o.ParallelThreshold == 0 {
+		return DefaultMultiGetParallelThreshold
+	}
+	return o.ParallelThreshold
+}
+
+func (o *MultiGetOptions) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	if t := o.parallelThreshold(); t > 0 {
+		return t
+	}
+	return DefaultMultiGetParallelThreshold
+}
+
+// MultiGetOutput is the output of MultiGet.
+type MultiGetOutput struct {
+	// Items holds one entry per key in the order of the keys, nil for keys without an item.
+	Items    []map[string]types.AttributeValue
+	Strategy MultiGetStrategy
+	// BatchStats describes the BatchGetItem call of the MultiGetBatch strategy, nil with
+	// MultiGetParallel. The GetItem calls fetching the unprocessed keys count as a round.
+	BatchStats *BatchGetStats
+}
+
+// MultiGet fetches the items with the given keys from table. Up to opts.ParallelThreshold
+// keys are fetched with parallel GetItem calls, which DAX routes and caches per key; more
+// keys are fetched with BatchGetItem, falling back to GetItem for unprocessed keys.
+func (d *Dax) MultiGet(ctx context.Context, table string, keys []map[string]types.AttributeValue, opts MultiGetOptions, optFns ...func(*dynamodb.Options)) (*MultiGetOutput, error) {
+	if table == "" {
+		invalidParams := smithy.InvalidParamsError{Context: "MultiGet"}
+		invalidParams.Add(smithy.NewErrParamRequired("table"))
+		return nil, invalidParams
+	}
+	out := &MultiGetOutput{Items: make([]map[string]types.AttributeValue, len(keys))}
+	if len(keys) == 0 {
+		out.Strategy = MultiGetParallel
+		return out, nil
+	}
+	if len(keys) <= opts.parallelThreshold() {
+		out.Strategy = MultiGetParallel
+		indexes := make([]int, len(keys))
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return out, d.getItems(ctx, table, keys, indexes, &opts, out.Items, optFns)
+	}
+
+	out.Strategy = MultiGetBatch
+	input := &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+		table: {
+			Keys:                     keys,
+			ConsistentRead:           aws.Bool(opts.ConsistentRead),
+			ProjectionExpression:     opts.ProjectionExpression,
+			ExpressionAttributeNames: opts.ExpressionAttributeNames,
+		},
+	}}
+	batch, err := d.BatchGetItemOrdered(ctx, input, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	copy(out.Items, batch.Items[table])
+	unprocessed := batch.UnprocessedKeys[table].Keys
+	out.BatchStats = &BatchGetStats{Rounds: 1, Keys: len(keys), Unprocessed: []int{len(unprocessed)}}
+	if len(unprocessed) == 0 {
+		recordBatchGetRounds(ctx, d, 1)
+		return out, nil
+	}
+	out.BatchStats.Rounds++
+	out.BatchStats.Unprocessed = append(out.BatchStats.Unprocessed, 0)
+	recordBatchGetRounds(ctx, d, 2)
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		index[itemKey(k, k)] = i
+	}
+	indexes := make([]int, 0, len(unprocessed))
+	for _, k := range unprocessed {
+		indexes = append(indexes, index[itemKey(k, k)])
+	}
+	return out, d.getItems(ctx, table, unprocessed, indexes, &opts, out.Items, optFns)
+}
+
+// getItems fetches keys with parallel GetItem calls, storing the item of keys[i] in
+// items[indexes[i]]. The remaining calls are cancelled after the first error.
+func (d *Dax) getItems(ctx context.Context, table string, keys []map[string]types.AttributeValue, indexes []int, opts *MultiGetOptions, items []map[string]types.AttributeValue, optFns []func(*dynamodb.Options)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, opts.concurrency())
+	for i, key := range keys {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, key map[string]types.AttributeValue) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			out, err := d.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName:                aws.String(table),
+				Key:                      key,
+				ConsistentRead:           aws.Bool(opts.ConsistentRead),
+				ProjectionExpression:     opts.ProjectionExpression,
+				ExpressionAttributeNames: opts.ExpressionAttributeNames,
+			}, optFns...)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			items[indexes[i]] = out.Item
+		}(i, key)
+	}
+	wg.Wait()
+	if firstErr == nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,105 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multiGetClient returns every key as its item, except "1" which does not exist. Batches
+// leave their last key unprocessed.
+type multiGetClient struct {
+	client.DaxAPI
+	gets    int32
+	batches int32
+	err     error
+}
+
+func (c *multiGetClient) GetItemWithOptions(_ context.Context, input *dynamodb.GetItemInput, output *dynamodb.GetItemOutput, _ client.RequestOptions) (*dynamodb.GetItemOutput, error) {
+	atomic.AddInt32(&c.gets, 1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	if input.Key["pk"].(*types.AttributeValueMemberS).Value != "1" {
+		output.Item = input.Key
+	}
+	return output, nil
+}
+
+func (c *multiGetClient) BatchGetItemWithOptions(_ context.Context, input *dynamodb.BatchGetItemInput, output *dynamodb.BatchGetItemOutput, _ client.RequestOptions) (*dynamodb.BatchGetItemOutput, error) {
+	atomic.AddInt32(&c.batches, 1)
+	output.Responses = map[string][]map[string]types.AttributeValue{}
+	for table, kaa := range input.RequestItems {
+		last := len(kaa.Keys) - 1
+		for _, k := range kaa.Keys[:last] {
+			if k["pk"].(*types.AttributeValueMemberS).Value != "1" {
+				output.Responses[table] = append(output.Responses[table], k)
+			}
+		}
+		output.UnprocessedKeys = map[string]types.KeysAndAttributes{table: {Keys: kaa.Keys[last:]}}
+	}
+	return output, nil
+}
+
+func TestMultiGet(t *testing.T) {
+	for _, tc := range []struct {
+		keys     int
+		strategy MultiGetStrategy
+		gets     int32
+		batches  int32
+		stats    *BatchGetStats
+	}{
+		{keys: 5, strategy: MultiGetParallel, gets: 5},
+		{keys: 20, strategy: MultiGetBatch, gets: 1, batches: 1, stats: &BatchGetStats{Rounds: 2, Keys: 20, Unprocessed: []int{1, 0}}},
+	} {
+		c := &multiGetClient{}
+		d := &Dax{client: c}
+		keys := testKeys(tc.keys)
+		out, err := d.MultiGet(context.Background(), "t", keys, MultiGetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, tc.strategy, out.Strategy)
+		assert.Equal(t, tc.gets, c.gets)
+		assert.Equal(t, tc.batches, c.batches)
+		assert.Equal(t, tc.stats, out.BatchStats)
+		require.Len(t, out.Items, tc.keys)
+		for i, item := range out.Items {
+			if i == 1 {
+				assert.Nil(t, item)
+			} else {
+				assert.Equal(t, keys[i], item)
+			}
+		}
+	}
+
+	d := &Dax{client: &multiGetClient{}}
+	out, err := d.MultiGet(context.Background(), "t", testKeys(3), MultiGetOptions{ParallelThreshold: -1})
+	require.NoError(t, err)
+	assert.Equal(t, MultiGetBatch, out.Strategy)
+
+	boom := errors.New("boom")
+	d = &Dax{client: &multiGetClient{err: boom}}
+	_, err = d.MultiGet(context.Background(), "t", testKeys(4), MultiGetOptions{Concurrency: 1})
+	assert.ErrorIs(t, err, boom)
+}
@@ -0,0 +1,49 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// Operation names as used by the client in metrics, logs, errors and retry decisions.
+const (
+	OpGetItem            = client.OpGetItem
+	OpPutItem            = client.OpPutItem
+	OpUpdateItem         = client.OpUpdateItem
+	OpDeleteItem         = client.OpDeleteItem
+	OpBatchGetItem       = client.OpBatchGetItem
+	OpBatchWriteItem     = client.OpBatchWriteItem
+	OpTransactGetItems   = client.OpTransactGetItems
+	OpTransactWriteItems = client.OpTransactWriteItems
+	OpQuery              = client.OpQuery
+	OpScan               = client.OpScan
+)
+
+// Operations returns the names of all operations supported by the client.
+func Operations() []string {
+	return client.Operations()
+}
+
+// IsReadOperation reports whether op names an operation which only reads items.
+func IsReadOperation(op string) bool {
+	return client.IsReadOperation(op)
+}
+
+// IsWriteOperation reports whether op names an operation which modifies items.
+func IsWriteOperation(op string) bool {
+	return client.IsWriteOperation(op)
+}
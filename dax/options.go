@@ -0,0 +1,350 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/tracing"
+)
+
+// Option changes a setting of the Config a client is created with. Options leave the
+// settings they don't cover at the values of the Config passed to NewWithOptions, so
+// that new settings and defaults don't require changes to existing callers. Settings
+// without a dedicated option can be changed by a func(*Config) literal.
+type Option func(*Config)
+
+// NewWithOptions creates a new instance of the DAX client from cfg, usually
+// DefaultConfig(), after applying optFns in order. The initial discovery of the cluster
+// nodes gives up when ctx is done, the client then keeps discovering them in the
+// background.
+//
+// Example:
+//
+//	svc, err := dax.NewWithOptions(ctx, dax.DefaultConfig(),
+//		dax.WithEndpoints("dax://mycluster.frfx8h.clustercfg.dax.usw2.amazonaws.com"),
+//		dax.WithRegion("us-west-2"),
+//		dax.WithCredentials(awsCfg.Credentials),
+//	)
+func NewWithOptions(ctx context.Context, cfg Config, optFns ...Option) (*Dax, error) {
+	for _, fn := range optFns {
+		fn(&cfg)
+	}
+	return newWithContext(ctx, cfg)
+}
+
+// WithAWSConfig applies the region, credentials and retry attempts of an aws.Config.
+func WithAWSConfig(ac aws.Config) Option {
+	return func(c *Config) {
+		c.mergeFrom(ac, "")
+	}
+}
+
+// WithDynamoDBConfig forwards the operations DAX does not support to a DynamoDB client
+// created from ac, see Config.DynamoDBConfig.
+func WithDynamoDBConfig(ac aws.Config) Option {
+	return func(c *Config) {
+		c.DynamoDBConfig = &ac
+	}
+}
+
+// WithRegion sets the region of the cluster.
+func WithRegion(region string) Option {
+	return func(c *Config) {
+		c.Region = region
+	}
+}
+
+// WithEndpoints sets the cluster discovery endpoints, as host:port or dax:// and
+// daxs:// URLs.
+func WithEndpoints(hostPorts ...string) Option {
+	return func(c *Config) {
+		c.HostPorts = hostPorts
+	}
+}
+
+// WithCredentials sets the provider of the credentials requests are signed with.
+func WithCredentials(credentials aws.CredentialsProvider) Option {
+	return func(c *Config) {
+		c.Credentials = credentials
+	}
+}
+
+// WithLogger sets the logger of the client and the level of the messages it logs.
+func WithLogger(logger logging.Logger, level utils.LogLevelType) Option {
+	return func(c *Config) {
+		c.Logger = logger
+		c.LogLevel = level
+	}
+}
+
+// WithSlogLogger sets Config.SlogLogger.
+func WithSlogLogger(logger *slog.Logger) Option {
+	return func(c *Config) {
+		c.SlogLogger = logger
+	}
+}
+
+// WithMeterProvider sets the provider of the meters the client metrics are recorded with.
+func WithMeterProvider(mp metrics.MeterProvider) Option {
+	return func(c *Config) {
+		c.MeterProvider = mp
+	}
+}
+
+// WithTracerProvider sets the provider of the tracers the request spans are created with.
+func WithTracerProvider(tp tracing.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = tp
+	}
+}
+
+// WithDialContext sets the function connections to the nodes are established with.
+func WithDialContext(dialContext func(ctx context.Context, network string, address string) (net.Conn, error)) Option {
+	return func(c *Config) {
+		c.DialContext = dialContext
+	}
+}
+
+// WithTLSConfig sets the base TLS configuration of the connections to encrypted endpoints,
+// e.g. to trust a private CA.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(c *Config) {
+		c.TLSConfig = tlsConfig
+	}
+}
+
+// WithClientCertificates sets the certificates presented to the nodes of encrypted
+// endpoints requiring mutual TLS.
+func WithClientCertificates(certs ...tls.Certificate) Option {
+	return func(c *Config) {
+		c.ClientCertificates = certs
+	}
+}
+
+// WithExpectedHostnamePattern accepts node certificates valid for a hostname matching
+// pattern instead of the hostname of the endpoint, see Config.ExpectedHostnamePattern.
+func WithExpectedHostnamePattern(pattern string) Option {
+	return func(c *Config) {
+		c.ExpectedHostnamePattern = pattern
+	}
+}
+
+// WithHostOverrides connects to the given IP addresses for the nodes with the given
+// hostnames instead of the addresses they advertise, see Config.HostOverrides.
+func WithHostOverrides(overrides map[string]string) Option {
+	return func(c *Config) {
+		c.HostOverrides = overrides
+	}
+}
+
+// WithProxy sets the function returning the HTTP or SOCKS5 proxy to connect to a node
+// through, e.g. ProxyFromEnvironment.
+func WithProxy(proxy func(address string) (*url.URL, error)) Option {
+	return func(c *Config) {
+		c.Proxy = proxy
+	}
+}
+
+// WithClusterName discovers the cluster named name through the DAX control plane instead of
+// a configured endpoint, see Config.ClusterName.
+func WithClusterName(name string) Option {
+	return func(c *Config) {
+		c.ClusterName = name
+	}
+}
+
+// WithResolver resolves the hostnames of the seed endpoints with resolver instead of
+// net.DefaultResolver.
+func WithResolver(resolver Resolver) Option {
+	return func(c *Config) {
+		c.Resolver = resolver
+	}
+}
+
+// WithRouteSelector sets the policy picking the node each request attempt is sent to.
+func WithRouteSelector(selector RouteSelector) Option {
+	return func(c *Config) {
+		c.RouteSelector = selector
+	}
+}
+
+// WithAvailabilityZoneAffinity routes requests to the nodes in the availability zone az
+// while they are healthy, falling back to the other zones. An empty az is detected with
+// the EC2 instance metadata service.
+func WithAvailabilityZoneAffinity(az string) Option {
+	return func(c *Config) {
+		c.AvailabilityZoneAffinity = true
+		c.AvailabilityZone = az
+	}
+}
+
+// WithRoleAwareRouting sends writes to the leader node and reads to the replicas, see
+// Config.RoleAwareRouting.
+func WithRoleAwareRouting() Option {
+	return func(c *Config) {
+		c.RoleAwareRouting = true
+	}
+}
+
+// WithKeyAffinityRouting sends the requests for an item to the node its partition key is
+// assigned to, see Config.KeyAffinityRouting.
+func WithKeyAffinityRouting() Option {
+	return func(c *Config) {
+		c.KeyAffinityRouting = true
+	}
+}
+
+// WithNodeWeights scales the share of the requests sent to the nodes with the given
+// hostnames, see Config.NodeWeights.
+func WithNodeWeights(weights map[string]float64) Option {
+	return func(c *Config) {
+		c.NodeWeights = weights
+	}
+}
+
+// WithLatencyWeighting reduces the share of the requests sent to the nodes slower than the
+// fastest one, see Config.LatencyWeighting.
+func WithLatencyWeighting() Option {
+	return func(c *Config) {
+		c.LatencyWeighting = true
+	}
+}
+
+// WithConnectTimeout bounds the establishment of each connection to a node, handshakes
+// included.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ConnectTimeout = timeout
+	}
+}
+
+// WithAuthTimeout bounds retrieving the credentials for authenticating a connection.
+func WithAuthTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.AuthTimeout = timeout
+	}
+}
+
+// WithRequestTimeout sets the default timeout of a request, including its retries.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.RequestTimeout = timeout
+	}
+}
+
+// WithOperationTimeout sets the timeout of the requests of op, overriding the default
+// timeout of WithRequestTimeout.
+func WithOperationTimeout(op string, timeout time.Duration) Option {
+	return func(c *Config) {
+		if c.OperationTimeouts == nil {
+			c.OperationTimeouts = make(map[string]time.Duration)
+		}
+		c.OperationTimeouts[op] = timeout
+	}
+}
+
+// WithHealthCheckProbes sets the timeout of the health check probes of the nodes, retries
+// included, and the number of retries of a failed probe. The timeout must be shorter than
+// the health check interval.
+func WithHealthCheckProbes(timeout time.Duration, retries int) Option {
+	return func(c *Config) {
+		c.HealthCheckTimeout = timeout
+		c.HealthCheckRetries = retries
+	}
+}
+
+// WithMaxConcurrentRebuilds sets the number of clients of nodes failing their health
+// checks rebuilt at once, see Config.MaxConcurrentRebuilds.
+func WithMaxConcurrentRebuilds(n int) Option {
+	return func(c *Config) {
+		c.MaxConcurrentRebuilds = n
+	}
+}
+
+// WithWarmConnections opens and authenticates n connections to the nodes joining the
+// cluster before routing requests to them, see Config.WarmConnections.
+func WithWarmConnections(n int) Option {
+	return func(c *Config) {
+		c.WarmConnections = n
+	}
+}
+
+// WithRetries sets the default number of retries of read and write requests.
+func WithRetries(readRetries, writeRetries int) Option {
+	return func(c *Config) {
+		c.ReadRetries = readRetries
+		c.WriteRetries = writeRetries
+	}
+}
+
+// WithMaxRetryElapsedTime bounds the time a request may spend retrying, backoffs
+// included, see Config.MaxRetryElapsedTime.
+func WithMaxRetryElapsedTime(d time.Duration) Option {
+	return func(c *Config) {
+		c.MaxRetryElapsedTime = d
+	}
+}
+
+// WithHedgeDelay sends a second GetItem or BatchGetItem request to another node when the
+// first one didn't complete within delay, see Config.HedgeDelay.
+func WithHedgeDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.HedgeDelay = delay
+	}
+}
+
+// WithBatchWriteConflicts sets how BatchWriteItem requests holding several writes for the
+// same key are handled, e.g. BatchWriteConflictsSerialize to send them in successive
+// requests instead of rejecting them.
+func WithBatchWriteConflicts(mode BatchWriteConflicts) Option {
+	return func(c *Config) {
+		c.BatchWriteConflicts = mode
+	}
+}
+
+// WithDrainTimeout lets the requests in flight on a node leaving the cluster finish for
+// up to d before its client is closed, see Config.DrainTimeout.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.DrainTimeout = d
+	}
+}
+
+// WithMaxConnectionsPerHost bounds the connections open to each node to n, see
+// Config.MaxConnectionsPerHost.
+func WithMaxConnectionsPerHost(n int) Option {
+	return func(c *Config) {
+		c.MaxConnectionsPerHost = n
+	}
+}
+
+// WithMinIdleConnections keeps n idle connections open to each node, see
+// Config.MinIdleConnections.
+func WithMinIdleConnections(n int) Option {
+	return func(c *Config) {
+		c.MinIdleConnections = n
+	}
+}
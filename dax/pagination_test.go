@@ -18,6 +18,7 @@ package dax
 import (
 	"context"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -354,6 +355,11 @@ func TestBatchGetItemPagination(t *testing.T) {
 	if pageNum != 2 {
 		t.Errorf("Expected 2 pages, got %d", pageNum)
 	}
+
+	expectedStats := BatchGetStats{Rounds: 2, Keys: 4, Unprocessed: []int{2, 0}}
+	if stats := paginator.Stats(); !reflect.DeepEqual(expectedStats, stats) {
+		t.Errorf("Expected stats %v, got %v", expectedStats, stats)
+	}
 }
 
 func TestPaginationWithError(t *testing.T) {
@@ -1067,3 +1073,66 @@ func TestScanPaginator_MultiplePages(t *testing.T) {
 		t.Errorf("Expected 2 pages, got %d", pageNum)
 	}
 }
+
+// limitRecordingScanClient returns pages of the given number of items, recording the
+// Limit of every request.
+type limitRecordingScanClient struct {
+	pages  []int
+	limits []int32
+}
+
+func (c *limitRecordingScanClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.limits = append(c.limits, aws.ToInt32(params.Limit))
+	n := c.pages[len(c.limits)-1]
+	out := &dynamodb.ScanOutput{
+		Items:            make([]map[string]types.AttributeValue, n),
+		ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(float64(n) / 2)},
+	}
+	if len(c.limits) < len(c.pages) {
+		out.LastEvaluatedKey = map[string]types.AttributeValue{"id": &types.AttributeValueMemberN{Value: strconv.Itoa(len(c.limits))}}
+	}
+	return out, nil
+}
+
+func TestScanPaginator_budget(t *testing.T) {
+	scanAll := func(p *ScanPaginator) int {
+		pages := 0
+		for p.HasMorePages() {
+			if _, err := p.NextPage(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			pages++
+		}
+		return pages
+	}
+
+	client := &limitRecordingScanClient{pages: []int{4, 4, 4, 4}}
+	p := NewScanPaginator(client, &dynamodb.ScanInput{Limit: aws.Int32(4)}).WithBudget(ScanBudget{MaxPages: 2})
+	if pages := scanAll(p); pages != 2 || !p.BudgetExhausted() {
+		t.Errorf("expected to stop after 2 pages, got %d", pages)
+	}
+	if !reflect.DeepEqual(p.ResumeKey(), map[string]types.AttributeValue{"id": &types.AttributeValueMemberN{Value: "2"}}) {
+		t.Errorf("unexpected resume key %v", p.ResumeKey())
+	}
+
+	client = &limitRecordingScanClient{pages: []int{4, 4, 2, 4}}
+	p = NewScanPaginator(client, &dynamodb.ScanInput{Limit: aws.Int32(4)}).WithBudget(ScanBudget{MaxItems: 10})
+	if pages := scanAll(p); pages != 3 || !p.BudgetExhausted() {
+		t.Errorf("expected to stop after 3 pages, got %d", pages)
+	}
+	if !reflect.DeepEqual(client.limits, []int32{4, 4, 2}) {
+		t.Errorf("expected the limit of the last page to be lowered, got %v", client.limits)
+	}
+
+	client = &limitRecordingScanClient{pages: []int{4, 4, 4, 4}}
+	p = NewScanPaginator(client, &dynamodb.ScanInput{}).WithBudget(ScanBudget{MaxCapacityUnits: 3})
+	if pages := scanAll(p); pages != 2 {
+		t.Errorf("expected to stop after 4 capacity units, got %d pages", pages)
+	}
+
+	client = &limitRecordingScanClient{pages: []int{4, 4}}
+	p = NewScanPaginator(client, &dynamodb.ScanInput{}).WithBudget(ScanBudget{MaxPages: 5})
+	if pages := scanAll(p); pages != 2 || p.BudgetExhausted() || p.ResumeKey() != nil {
+		t.Errorf("expected the scan to complete within its budget, got %d pages", pages)
+	}
+}
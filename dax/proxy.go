@@ -0,0 +1,33 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// ProxyFromEnvironment is a Config.Proxy honoring the HTTPS_PROXY and NO_PROXY environment
+// variables.
+func ProxyFromEnvironment(address string) (*url.URL, error) {
+	return client.ProxyFromEnvironment(address)
+}
+
+// ProxyURL returns a Config.Proxy connecting to every node through proxyURL.
+func ProxyURL(proxyURL *url.URL) func(address string) (*url.URL, error) {
+	return client.ProxyURL(proxyURL)
+}
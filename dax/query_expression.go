@@ -0,0 +1,83 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// BuiltExpression is the result of expression.Builder.Build from the
+// github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression package, which
+// expression.Expression satisfies.
+type BuiltExpression interface {
+	Condition() *string
+	Filter() *string
+	KeyCondition() *string
+	Projection() *string
+	Update() *string
+	Names() map[string]string
+	Values() map[string]types.AttributeValue
+}
+
+// NewQueryInput returns a QueryInput for table using the key condition, filter and
+// projection of expr, after validating it the way DAX will parse it. Condition and
+// update expressions have no meaning for Query and are rejected rather than dropped.
+//
+// Example:
+//
+//	expr, err := expression.NewBuilder().
+//		WithKeyCondition(expression.Key("id").Equal(expression.Value("a"))).
+//		Build()
+//	input, err := dax.NewQueryInput("mytable", expr)
+func NewQueryInput(table string, expr BuiltExpression) (*dynamodb.QueryInput, error) {
+	invalidParams := smithy.InvalidParamsError{Context: "NewQueryInput"}
+	if table == "" {
+		invalidParams.Add(smithy.NewErrParamRequired("table"))
+	}
+	if expr == nil {
+		invalidParams.Add(smithy.NewErrParamRequired("expr"))
+		return nil, invalidParams
+	}
+	if expr.KeyCondition() == nil {
+		invalidParams.Add(client.NewCustomInvalidParamError("expr", "Query requires a key condition, build it with WithKeyCondition"))
+	}
+	if expr.Condition() != nil {
+		invalidParams.Add(client.NewCustomInvalidParamError("expr", "Query does not support condition expressions, use WithFilter to filter results"))
+	}
+	if expr.Update() != nil {
+		invalidParams.Add(client.NewCustomInvalidParamError("expr", "Query does not support update expressions"))
+	}
+	if invalidParams.Len() > 0 {
+		return nil, invalidParams
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+	if err := client.ValidateQueryExpressions(input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
@@ -0,0 +1,73 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// builtExpression mirrors the output of expression.Builder.Build.
+type builtExpression struct {
+	condition, filter, keyCondition, projection, update *string
+	names                                               map[string]string
+	values                                              map[string]types.AttributeValue
+}
+
+func (e builtExpression) Condition() *string                      { return e.condition }
+func (e builtExpression) Filter() *string                         { return e.filter }
+func (e builtExpression) KeyCondition() *string                   { return e.keyCondition }
+func (e builtExpression) Projection() *string                     { return e.projection }
+func (e builtExpression) Update() *string                         { return e.update }
+func (e builtExpression) Names() map[string]string                { return e.names }
+func (e builtExpression) Values() map[string]types.AttributeValue { return e.values }
+
+func TestNewQueryInput(t *testing.T) {
+	// As built by Key("id").Equal(Value("a")) with Filter(Name("n").GreaterThan(Value(1)))
+	// and projection NamesList(Name("n")).
+	expr := builtExpression{
+		keyCondition: aws.String("#0 = :0"),
+		filter:       aws.String("#1 > :1"),
+		projection:   aws.String("#1"),
+		names:        map[string]string{"#0": "id", "#1": "n"},
+		values: map[string]types.AttributeValue{
+			":0": &types.AttributeValueMemberS{Value: "a"},
+			":1": &types.AttributeValueMemberN{Value: "1"},
+		},
+	}
+	input, err := NewQueryInput("t", expr)
+	require.NoError(t, err)
+	assert.Equal(t, "t", aws.ToString(input.TableName))
+	assert.Equal(t, "#0 = :0", aws.ToString(input.KeyConditionExpression))
+	assert.Equal(t, "#1 > :1", aws.ToString(input.FilterExpression))
+	assert.Equal(t, "#1", aws.ToString(input.ProjectionExpression))
+
+	for name, bad := range map[string]builtExpression{
+		"no key condition": {filter: expr.filter, names: expr.names, values: expr.values},
+		"condition":        {keyCondition: expr.keyCondition, condition: aws.String("attribute_exists(#0)"), names: map[string]string{"#0": "id"}, values: map[string]types.AttributeValue{":0": expr.values[":0"]}},
+		"update":           {keyCondition: expr.keyCondition, update: aws.String("SET #0 = :0"), names: map[string]string{"#0": "id"}, values: map[string]types.AttributeValue{":0": expr.values[":0"]}},
+		"unused name":      {keyCondition: expr.keyCondition, names: expr.names, values: map[string]types.AttributeValue{":0": expr.values[":0"]}},
+	} {
+		_, err := NewQueryInput("t", bad)
+		assert.Error(t, err, name)
+	}
+	_, err = NewQueryInput("", expr)
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"io"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// RequestRecord is a sanitized description of a completed request, free of attribute values.
+type RequestRecord = client.RequestRecord
+
+// RequestRecorder receives a RequestRecord for every request when set as Config.Recorder.
+type RequestRecorder = client.RequestRecorder
+
+// RingRecorder is a RequestRecorder retaining the most recent records in memory.
+type RingRecorder = client.RingRecorder
+
+// FileRecorder is a RequestRecorder appending records to a size bounded file.
+type FileRecorder = client.FileRecorder
+
+// NewRingRecorder returns a recorder retaining at most size records.
+func NewRingRecorder(size int) *RingRecorder {
+	return client.NewRingRecorder(size)
+}
+
+// NewFileRecorder returns a recorder writing JSON lines to path, rotating the file
+// once it exceeds maxBytes.
+func NewFileRecorder(path string, maxBytes int64) (*FileRecorder, error) {
+	return client.NewFileRecorder(path, maxBytes)
+}
+
+// ReadRequestRecords decodes the records written by a FileRecorder for replay.
+func ReadRequestRecords(r io.Reader) ([]RequestRecord, error) {
+	return client.ReadRequestRecords(r)
+}
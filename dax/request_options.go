@@ -0,0 +1,48 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// RequestOptions are the options an operation is executed with, such as the number of
+// retries and the per attempt timeouts. They default to the values of Config.
+type RequestOptions = client.RequestOptions
+
+type requestOptionsKey struct{}
+
+// ContextWithOptions returns a context carrying optFns, which adjust the RequestOptions
+// of every operation called with the context or a context derived from it. They apply
+// after the defaults of Config and the options already carried by ctx, and before the
+// functional options passed to the operation. This lets frameworks set retries or
+// timeouts for a request in a middleware, without changing the calls to the client.
+//
+// The overall deadline of a request remains that of its context.
+func ContextWithOptions(ctx context.Context, optFns ...func(*RequestOptions)) context.Context {
+	fns := append(append([]func(*RequestOptions){}, contextOptions(ctx)...), optFns...)
+	return context.WithValue(ctx, requestOptionsKey{}, fns)
+}
+
+func contextOptions(ctx context.Context) []func(*RequestOptions) {
+	if ctx == nil {
+		return nil
+	}
+	fns, _ := ctx.Value(requestOptionsKey{}).([]func(*RequestOptions))
+	return fns
+}
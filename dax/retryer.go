@@ -0,0 +1,27 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// DaxRetryer is the backoff strategy of throttled requests, see Config.Retryer.
+type DaxRetryer = client.DaxRetryer
+
+// OperationRetryPolicy tunes the retries of throttled requests of an operation, see
+// DaxRetryer.Operations.
+type OperationRetryPolicy = client.OperationRetryPolicy
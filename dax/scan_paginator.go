@@ -31,6 +31,24 @@ type ScanPaginator struct {
 	params    *dynamodb.ScanInput
 	nextToken map[string]types.AttributeValue
 	firstPage bool
+
+	budget   ScanBudget
+	pages    int
+	items    int
+	capacity float64
+}
+
+// ScanBudget bounds the work of a ScanPaginator, so that a Scan serving an interactive
+// request cannot turn into a full table scan. The paginator stops once any of the limits
+// is reached; limits left at zero do not apply.
+type ScanBudget struct {
+	MaxPages int
+	MaxItems int // lowers the Limit of the pages so that no more items are returned
+
+	// MaxCapacityUnits bounds the read capacity consumed by the pages. It only applies when
+	// the pages report their consumed capacity, which they do when ReturnConsumedCapacity is
+	// set on the input.
+	MaxCapacityUnits float64
 }
 
 // NewScanPaginator returns a new ScanPaginator
@@ -57,9 +75,30 @@ func NewScanPaginator(client dynamodb.ScanAPIClient, params *dynamodb.ScanInput,
 	}
 }
 
+// WithBudget bounds the pages retrieved by p with budget. Once it is exhausted,
+// HasMorePages returns false and ResumeKey the key to resume the Scan from.
+func (p *ScanPaginator) WithBudget(budget ScanBudget) *ScanPaginator {
+	p.budget = budget
+	return p
+}
+
 // HasMorePages returns a boolean indicating whether more pages are available
 func (p *ScanPaginator) HasMorePages() bool {
-	return p.firstPage || p.nextToken != nil
+	return (p.firstPage || p.nextToken != nil) && !p.BudgetExhausted()
+}
+
+// BudgetExhausted reports whether the paginator stopped because its budget was used up.
+func (p *ScanPaginator) BudgetExhausted() bool {
+	b := p.budget
+	return (b.MaxPages > 0 && p.pages >= b.MaxPages) ||
+		(b.MaxItems > 0 && p.items >= b.MaxItems) ||
+		(b.MaxCapacityUnits > 0 && p.capacity >= b.MaxCapacityUnits)
+}
+
+// ResumeKey returns the ExclusiveStartKey of the next page, to resume the Scan from where
+// the paginator stopped, or nil when the Scan is complete.
+func (p *ScanPaginator) ResumeKey() map[string]types.AttributeValue {
+	return p.nextToken
 }
 
 // NextPage retrieves the next Scan page.
@@ -75,6 +114,9 @@ func (p *ScanPaginator) NextPage(ctx context.Context, optFns ...func(*dynamodb.O
 	if p.options.Limit > 0 {
 		limit = &p.options.Limit
 	}
+	if remaining := int32(p.budget.MaxItems - p.items); p.budget.MaxItems > 0 && (limit == nil || remaining < *limit) {
+		limit = &remaining
+	}
 	params.Limit = limit
 
 	result, err := p.client.Scan(ctx, &params, optFns...)
@@ -82,6 +124,11 @@ func (p *ScanPaginator) NextPage(ctx context.Context, optFns ...func(*dynamodb.O
 		return nil, err
 	}
 	p.firstPage = false
+	p.pages++
+	p.items += len(result.Items)
+	if cc := result.ConsumedCapacity; cc != nil && cc.CapacityUnits != nil {
+		p.capacity += *cc.CapacityUnits
+	}
 
 	prevToken := p.nextToken
 	p.nextToken = result.LastEvaluatedKey
@@ -18,6 +18,7 @@ package dax
 import (
 	"context"
 	"crypto/tls"
+	"log/slog"
 	"net"
 	"net/url"
 	"time"
@@ -49,8 +50,61 @@ type Config struct {
 	ReadRetries    int
 	RetryDelay     time.Duration
 
+	// OperationTimeouts overrides RequestTimeout for the operations it contains, keyed by
+	// operation name such as OpScan, so that a Scan may run for seconds while a GetItem
+	// is given up on after a few hundred milliseconds. Non-positive timeouts are ignored.
+	OperationTimeouts map[string]time.Duration
+
+	// ReadTimeout and WriteTimeout, when positive, bound each attempt of a read or write
+	// operation on the connection, while RequestTimeout covers the request including retries.
+	// Reads such as Query and Scan usually need a longer timeout than small writes.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// FirstByteTimeout, when positive, aborts an attempt whose response does not start
+	// arriving within the timeout, so that a node which accepted the request but stalls
+	// is given up on early. Reads are retried on another node, see ErrFirstByteTimeout.
+	FirstByteTimeout time.Duration
+
+	// MaxRetryElapsedTime, when positive, bounds the time a request may spend retrying,
+	// backoffs included, whatever the number of retries left. See RetryBudgetExceededError.
+	MaxRetryElapsedTime time.Duration
+
+	// Retryer sets the backoff of throttled requests, and lets operations such as Scan
+	// retry throttled attempts fewer times and with a longer backoff than point reads.
+	Retryer DaxRetryer
+
+	// VersionAttributes enables optimistic locking for the tables it contains, mapping the
+	// table name to the name of its number version attribute. Writes to these tables,
+	// including the items of transactions, increment the version and are conditioned on
+	// the version the item was read with, see WithExpectedVersion and VersionConflictError.
+	// BatchWriteItem requests on these tables are rejected.
+	VersionAttributes map[string]string
+
+	// Encryption, when set, encrypts the configured attributes on the client before they
+	// are written and decrypts them after they are read, see AttributeEncryption.
+	Encryption *AttributeEncryption
+
+	// Timestamps, when set, stamps the items written by PutItem and UpdateItem with their
+	// creation and last update times, see AttributeTimestamps.
+	Timestamps *AttributeTimestamps
+
+	// DynamoDB, when set, receives the operations DAX does not support, such as CreateTable
+	// or DescribeTable, which otherwise fail with ErrCodeNotImplemented. Item operations
+	// always go to DAX.
+	DynamoDB *dynamodb.Client
+
+	// DynamoDBConfig, when set and DynamoDB is not, creates the DynamoDB client the
+	// unsupported operations are forwarded to from an aws.Config.
+	DynamoDBConfig *aws.Config
+
 	Logger   logging.Logger
 	LogLevel utils.LogLevelType
+
+	// SlogLogger, when set, replaces Logger with an adapter logging to it with structured
+	// attributes: endpoint, op, attempt and requestID where they apply. A LogLevel left at
+	// LogOff is derived from the levels the handler of SlogLogger enables.
+	SlogLogger *slog.Logger
 }
 
 // DefaultConfig returns the default DAX configuration.
@@ -78,8 +132,21 @@ func NewConfig(config aws.Config, endpoint string) Config {
 
 // New creates a new instance of the DAX client with a DAX configuration.
 func New(cfg Config) (*Dax, error) {
+	return newWithContext(context.Background(), cfg)
+}
+
+func newWithContext(ctx context.Context, cfg Config) (*Dax, error) {
+	if cfg.SlogLogger != nil {
+		cfg.Logger = utils.NewSlogLogger(cfg.SlogLogger)
+		if cfg.LogLevel == utils.LogOff {
+			cfg.LogLevel = utils.LogLevelForSlog(cfg.SlogLogger.Handler())
+		}
+	}
 	cfg.Config.SetLogger(cfg.Logger, cfg.LogLevel)
-	c, err := client.New(cfg.Config)
+	if cfg.DynamoDB == nil && cfg.DynamoDBConfig != nil {
+		cfg.DynamoDB = dynamodb.NewFromConfig(*cfg.DynamoDBConfig)
+	}
+	c, err := client.NewWithContext(ctx, cfg.Config)
 	if err != nil {
 		if cfg.Logger != nil {
 			cfg.Logger.Logf("ERROR", "Exception in initialisation of DAX Client : %s", err)
@@ -138,7 +205,15 @@ func (c *Config) mergeFrom(ac aws.Config, endpoint string) {
 	}
 }
 
-func (c *Config) requestOptions(read bool, ctx context.Context, optFns ...func(*dynamodb.Options)) (client.RequestOptions, context.CancelFunc, error) {
+// requestTimeout returns the timeout of a request of op, including its retries.
+func (c *Config) requestTimeout(op string) time.Duration {
+	if timeout := c.OperationTimeouts[op]; timeout > 0 {
+		return timeout
+	}
+	return c.RequestTimeout
+}
+
+func (c *Config) requestOptions(op string, read bool, ctx context.Context, optFns ...func(*dynamodb.Options)) (client.RequestOptions, context.CancelFunc, error) {
 	r := c.WriteRetries
 	if read {
 		r = c.ReadRetries
@@ -148,16 +223,27 @@ func (c *Config) requestOptions(read bool, ctx context.Context, optFns ...func(*
 		ctx = context.Background()
 	}
 
-	if _, hasDeadline := ctx.Deadline(); !hasDeadline && c.RequestTimeout > 0 {
-		ctx, cfn = context.WithTimeout(ctx, c.RequestTimeout)
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		if timeout := c.requestTimeout(op); timeout > 0 {
+			ctx, cfn = context.WithTimeout(ctx, timeout)
+		}
 	}
 	opt := client.RequestOptions{}
 	opt.Logger = c.Logger
 	opt.LogLevel = c.LogLevel
 	opt.RetryMaxAttempts = r
 	opt.RetryDelay = c.RetryDelay
+	opt.ReadTimeout = c.ReadTimeout
+	opt.WriteTimeout = c.WriteTimeout
+	opt.FirstByteTimeout = c.FirstByteTimeout
+	opt.MaxRetryElapsedTime = c.MaxRetryElapsedTime
+	opt.Retryer = c.Retryer
 	opt.Context = ctx
 
+	for _, fn := range contextOptions(ctx) {
+		fn(&opt)
+	}
+
 	// merge from request options
 	for _, o := range optFns {
 		o(&opt.Options)
@@ -166,6 +252,9 @@ func (c *Config) requestOptions(read bool, ctx context.Context, optFns ...func(*
 	if err := client.RejectCustomMiddleware(opt.APIOptions); err != nil {
 		return client.RequestOptions{}, cfn, err
 	}
+	if err := client.RejectDivergentOptions(opt.Options, c.Region, c.Credentials); err != nil {
+		return client.RequestOptions{}, cfn, err
+	}
 
 	return opt, cfn, nil
 }
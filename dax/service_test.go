@@ -79,7 +79,7 @@ func TestRequestOptions(t *testing.T) {
 			WriteRetries: 5,
 		}
 
-		opts, cfn, err := cfg.requestOptions(true, nil)
+		opts, cfn, err := cfg.requestOptions(OpGetItem, true, nil)
 		defer func() {
 			if cfn != nil {
 				cfn()
@@ -97,7 +97,7 @@ func TestRequestOptions(t *testing.T) {
 			WriteRetries: 5,
 		}
 
-		opts, cfn, err := cfg.requestOptions(false, nil)
+		opts, cfn, err := cfg.requestOptions(OpPutItem, false, nil)
 		defer func() {
 			if cfn != nil {
 				cfn()
@@ -116,7 +116,7 @@ func TestRequestOptions(t *testing.T) {
 			RequestTimeout: time.Second * 5,
 		}
 
-		opts, cfn, err := cfg.requestOptions(true, nil)
+		opts, cfn, err := cfg.requestOptions(OpGetItem, true, nil)
 		defer func() {
 			if cfn != nil {
 				cfn()
@@ -128,6 +128,19 @@ func TestRequestOptions(t *testing.T) {
 		assert.NotNil(t, cfn)
 	})
 
+	t.Run("with read and write timeouts", func(t *testing.T) {
+		cfg := &Config{
+			ReadTimeout:  time.Second * 2,
+			WriteTimeout: time.Millisecond * 200,
+		}
+
+		opts, _, err := cfg.requestOptions(OpPutItem, false, context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, time.Second*2, opts.ReadTimeout)
+		assert.Equal(t, time.Millisecond*200, opts.WriteTimeout)
+	})
+
 	t.Run("with custom context", func(t *testing.T) {
 		t.Run("with RequestTimeout", func(t *testing.T) {
 			cfg := &Config{
@@ -137,7 +150,7 @@ func TestRequestOptions(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			opts, cfn, err := cfg.requestOptions(true, ctx)
+			opts, cfn, err := cfg.requestOptions(OpGetItem, true, ctx)
 			defer func() {
 				if cfn != nil {
 					cfn()
@@ -157,7 +170,7 @@ func TestRequestOptions(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			opts, cfn, err := cfg.requestOptions(true, ctx)
+			opts, cfn, err := cfg.requestOptions(OpGetItem, true, ctx)
 			defer func() {
 				if cfn != nil {
 					cfn()
@@ -168,6 +181,51 @@ func TestRequestOptions(t *testing.T) {
 			assert.Equal(t, 3, opts.RetryMaxAttempts)
 			assert.Nil(t, cfn) // Should be nil as no timeout is set
 		})
+
+		t.Run("with OperationTimeouts", func(t *testing.T) {
+			cfg := &Config{
+				RequestTimeout:    time.Second * 5,
+				OperationTimeouts: map[string]time.Duration{OpScan: time.Second * 10, OpGetItem: time.Millisecond * 200},
+			}
+
+			for op, want := range map[string]time.Duration{OpScan: time.Second * 10, OpGetItem: time.Millisecond * 200, OpQuery: time.Second * 5} {
+				start := time.Now()
+				opts, cfn, err := cfg.requestOptions(op, true, context.Background())
+				assert.NoError(t, err)
+				deadline, ok := opts.Context.Deadline()
+				if assert.True(t, ok, op) {
+					assert.WithinDuration(t, start.Add(want), deadline, 100*time.Millisecond, op)
+				}
+				cfn()
+			}
+		})
+	})
+
+	t.Run("with options in context", func(t *testing.T) {
+		cfg := &Config{
+			ReadRetries: 3,
+			ReadTimeout: time.Second,
+		}
+
+		ctx := ContextWithOptions(context.Background(), func(o *RequestOptions) {
+			o.RetryMaxAttempts = 0
+			o.ReadTimeout = time.Millisecond * 100
+		})
+		ctx = ContextWithOptions(ctx, func(o *RequestOptions) {
+			o.FirstByteTimeout = time.Millisecond * 50
+		})
+		opts, _, err := cfg.requestOptions(OpGetItem, true, ctx, func(o *dynamodb.Options) {
+			o.RetryMaxAttempts = 1
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, opts.RetryMaxAttempts)
+		assert.Equal(t, time.Millisecond*100, opts.ReadTimeout)
+		assert.Equal(t, time.Millisecond*50, opts.FirstByteTimeout)
+
+		opts, _, err = cfg.requestOptions(OpGetItem, true, ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, opts.RetryMaxAttempts)
 	})
 
 	t.Run("with custom middleware should return error", func(t *testing.T) {
@@ -182,7 +240,7 @@ func TestRequestOptions(t *testing.T) {
 			})
 		}
 
-		opts, cfn, err := cfg.requestOptions(true, nil, customOpt)
+		opts, cfn, err := cfg.requestOptions(OpGetItem, true, nil, customOpt)
 		defer func() {
 			if cfn != nil {
 				cfn()
@@ -193,4 +251,60 @@ func TestRequestOptions(t *testing.T) {
 		assert.Contains(t, err.Error(), "custom middleware through APIOptions is not supported")
 		assert.Equal(t, client.RequestOptions{}, opts)
 	})
+
+	t.Run("with divergent region or credentials should return error", func(t *testing.T) {
+		creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, nil
+		})
+		cfg := &Config{}
+		cfg.Region = "us-west-2"
+		cfg.Credentials = creds
+
+		_, _, err := cfg.requestOptions(OpGetItem, true, context.Background(), func(o *dynamodb.Options) {
+			o.Region = "us-west-2"
+			o.Credentials = creds
+		})
+		assert.NoError(t, err, "the options of the client are accepted")
+
+		_, _, err = cfg.requestOptions(OpGetItem, true, context.Background(), func(o *dynamodb.Options) {
+			o.Region = "eu-west-1"
+		})
+		var ipe *client.CustomInvalidParamError
+		if assert.ErrorAs(t, err, &ipe) {
+			assert.Equal(t, "Region", ipe.Field())
+		}
+
+		_, _, err = cfg.requestOptions(OpGetItem, true, context.Background(), func(o *dynamodb.Options) {
+			o.Credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "other"}, nil
+			})
+		})
+		if assert.ErrorAs(t, err, &ipe) {
+			assert.Equal(t, "Credentials", ipe.Field())
+		}
+	})
+}
+
+func TestNewWithOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d, err := NewWithOptions(ctx, DefaultConfig(),
+		WithEndpoints("127.0.0.1:8111"),
+		WithRegion("us-west-2"),
+		WithCredentials(aws.AnonymousCredentials{}),
+		WithRetries(3, 4),
+		func(c *Config) { c.ReadTimeout = time.Second },
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer d.Close()
+
+	assert.Equal(t, []string{"127.0.0.1:8111"}, d.config.HostPorts)
+	assert.Equal(t, "us-west-2", d.config.Region)
+	assert.Equal(t, 3, d.config.ReadRetries)
+	assert.Equal(t, 4, d.config.WriteRetries)
+	assert.Equal(t, time.Second, d.config.ReadTimeout)
+	assert.Equal(t, time.Minute, d.config.RequestTimeout)
+	assert.ErrorIs(t, d.Stats().LastRefreshError, context.Canceled)
 }
@@ -0,0 +1,101 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package soak
+
+import (
+	"math/bits"
+	"time"
+)
+
+// subBuckets per power of two bounds the relative error of a recorded latency to 1/64.
+const subBuckets = 64
+
+// histogram records latencies in microseconds with log-linear buckets, so long runs
+// use constant memory.
+type histogram struct {
+	counts []int64
+	count  int64
+	max    time.Duration
+}
+
+func bucketOf(us uint64) int {
+	if us < subBuckets {
+		return int(us)
+	}
+	shift := bits.Len64(us) - 7
+	return (shift+1)*subBuckets + int(us>>shift) - subBuckets
+}
+
+// lowerBound returns the smallest value, in microseconds, falling into bucket i.
+func lowerBound(i int) uint64 {
+	if i < subBuckets {
+		return uint64(i)
+	}
+	shift := i/subBuckets - 1
+	return uint64(i%subBuckets+subBuckets) << shift
+}
+
+func (h *histogram) record(d time.Duration) {
+	us := d.Microseconds()
+	if us < 0 {
+		us = 0
+	}
+	i := bucketOf(uint64(us))
+	if i >= len(h.counts) {
+		grown := make([]int64, i+1)
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	h.counts[i]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *histogram) merge(o *histogram) {
+	if len(o.counts) > len(h.counts) {
+		grown := make([]int64, len(o.counts))
+		copy(grown, h.counts)
+		h.counts = grown
+	}
+	for i, n := range o.counts {
+		h.counts[i] += n
+	}
+	h.count += o.count
+	if o.max > h.max {
+		h.max = o.max
+	}
+}
+
+// percentile returns the lower bound of the bucket holding the p-th percentile.
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(p / 100 * float64(h.count))
+	if rank >= h.count {
+		rank = h.count - 1
+	}
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen > rank {
+			return time.Duration(lowerBound(i)) * time.Microsecond
+		}
+	}
+	return h.max
+}
@@ -0,0 +1,361 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+// Package soak drives a configurable mix of GetItem, PutItem and Query requests
+// against a table and reports latency percentiles and error breakdowns. It is used
+// by cmd/daxsoak and can be embedded in other tools to validate cluster sizing and
+// client tuning.
+package soak
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// Client is the subset of the DAX (or DynamoDB) client used by the soak test.
+type Client interface {
+	GetItem(ctx context.Context, input *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, input *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, input *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+// Distribution selects how keys are drawn from the key space.
+type Distribution string
+
+const (
+	// Uniform draws every key with the same probability.
+	Uniform Distribution = "uniform"
+	// Zipf draws keys with a Zipfian distribution, concentrating traffic on few hot keys.
+	Zipf Distribution = "zipf"
+)
+
+// Operation names used in Report.
+const (
+	OpGetItem = dax.OpGetItem
+	OpPutItem = dax.OpPutItem
+	OpQuery   = dax.OpQuery
+)
+
+// Mix holds the relative weights of the operations. A mix of 80/15/5 sends 80% gets,
+// 15% puts and 5% queries.
+type Mix struct {
+	Get   int
+	Put   int
+	Query int
+}
+
+// ParseMix parses a mix in the form "get=80,put=15,query=5".
+func ParseMix(s string) (Mix, error) {
+	var m Mix
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return Mix{}, fmt.Errorf("invalid mix entry %q", part)
+		}
+		var weight int
+		if _, err := fmt.Sscanf(kv[1], "%d", &weight); err != nil || weight < 0 {
+			return Mix{}, fmt.Errorf("invalid weight in mix entry %q", part)
+		}
+		switch strings.ToLower(kv[0]) {
+		case "get":
+			m.Get = weight
+		case "put":
+			m.Put = weight
+		case "query":
+			m.Query = weight
+		default:
+			return Mix{}, fmt.Errorf("unknown operation %q in mix", kv[0])
+		}
+	}
+	return m, nil
+}
+
+// Config describes the workload.
+type Config struct {
+	Table string
+	// PartitionKey is the name of the string partition key of Table.
+	PartitionKey string
+	// Keys is the size of the key space.
+	Keys         int
+	Distribution Distribution
+	Mix          Mix
+	// ItemSize is the size in bytes of the payload attribute written by PutItem.
+	ItemSize    int
+	Concurrency int
+	// The run ends after Duration or after Requests requests, whichever comes first.
+	// At least one of them must be set.
+	Duration time.Duration
+	Requests int64
+}
+
+func (c *Config) validate() error {
+	switch {
+	case c.Table == "" || c.PartitionKey == "":
+		return errors.New("soak: Table and PartitionKey are required")
+	case c.Keys <= 0:
+		return errors.New("soak: Keys must be positive")
+	case c.Concurrency <= 0:
+		return errors.New("soak: Concurrency must be positive")
+	case c.Duration <= 0 && c.Requests <= 0:
+		return errors.New("soak: either Duration or Requests must be set")
+	case c.Mix.Get < 0 || c.Mix.Put < 0 || c.Mix.Query < 0 || c.Mix.Get+c.Mix.Put+c.Mix.Query == 0:
+		return errors.New("soak: Mix needs at least one positive weight")
+	case c.Distribution != "" && c.Distribution != Uniform && c.Distribution != Zipf:
+		return fmt.Errorf("soak: unknown distribution %q", c.Distribution)
+	}
+	return nil
+}
+
+// OperationReport summarizes the requests of a single operation.
+type OperationReport struct {
+	Operation string
+	Requests  int64
+	Errors    map[string]int64
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	P999      time.Duration
+	Max       time.Duration
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Elapsed    time.Duration
+	Operations []OperationReport
+}
+
+// Throughput returns the overall requests per second.
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	var n int64
+	for _, o := range r.Operations {
+		n += o.Requests
+	}
+	return float64(n) / r.Elapsed.Seconds()
+}
+
+// WriteTo writes the report as a text table.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "OPERATION\tREQUESTS\tERRORS\tP50\tP90\tP99\tP99.9\tMAX\n")
+	for _, o := range r.Operations {
+		var errs int64
+		for _, n := range o.Errors {
+			errs += n
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n", o.Operation, o.Requests, errs, o.P50, o.P90, o.P99, o.P999, o.Max)
+	}
+	tw.Flush()
+	fmt.Fprintf(&sb, "\n%.1f requests/s over %s\n", r.Throughput(), r.Elapsed.Round(time.Millisecond))
+	for _, o := range r.Operations {
+		for _, code := range sortedCodes(o.Errors) {
+			fmt.Fprintf(&sb, "%s %s: %d\n", o.Operation, code, o.Errors[code])
+		}
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func sortedCodes(m map[string]int64) []string {
+	codes := make([]string, 0, len(m))
+	for c := range m {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Run drives the workload described by cfg until it completes or ctx is done.
+func Run(ctx context.Context, client Client, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var sent int64
+	workers := make([]*worker, cfg.Concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range workers {
+		workers[i] = newWorker(client, &cfg, rand.New(rand.NewSource(start.UnixNano()+int64(i))))
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if cfg.Requests > 0 && atomic.AddInt64(&sent, 1) > cfg.Requests {
+					return
+				}
+				w.next(ctx)
+			}
+		}(workers[i])
+	}
+	wg.Wait()
+
+	report := &Report{Elapsed: time.Since(start)}
+	for _, op := range []string{OpGetItem, OpPutItem, OpQuery} {
+		total := &opStats{errors: map[string]int64{}}
+		for _, w := range workers {
+			total.merge(w.stats[op])
+		}
+		if total.latency.count == 0 {
+			continue
+		}
+		report.Operations = append(report.Operations, total.report(op))
+	}
+	return report, nil
+}
+
+type opStats struct {
+	latency histogram
+	errors  map[string]int64
+}
+
+func (s *opStats) merge(o *opStats) {
+	s.latency.merge(&o.latency)
+	for code, n := range o.errors {
+		s.errors[code] += n
+	}
+}
+
+func (s *opStats) report(op string) OperationReport {
+	return OperationReport{
+		Operation: op,
+		Requests:  s.latency.count,
+		Errors:    s.errors,
+		P50:       s.latency.percentile(50),
+		P90:       s.latency.percentile(90),
+		P99:       s.latency.percentile(99),
+		P999:      s.latency.percentile(99.9),
+		Max:       s.latency.max,
+	}
+}
+
+type worker struct {
+	client  Client
+	cfg     *Config
+	rnd     *rand.Rand
+	zipf    *rand.Zipf
+	payload string
+	stats   map[string]*opStats
+}
+
+func newWorker(client Client, cfg *Config, rnd *rand.Rand) *worker {
+	w := &worker{
+		client:  client,
+		cfg:     cfg,
+		rnd:     rnd,
+		payload: strings.Repeat("x", cfg.ItemSize),
+		stats:   map[string]*opStats{},
+	}
+	for _, op := range []string{OpGetItem, OpPutItem, OpQuery} {
+		w.stats[op] = &opStats{errors: map[string]int64{}}
+	}
+	if cfg.Distribution == Zipf && cfg.Keys > 1 {
+		w.zipf = rand.NewZipf(rnd, 1.1, 1, uint64(cfg.Keys-1))
+	}
+	return w
+}
+
+func (w *worker) key() types.AttributeValue {
+	var k uint64
+	if w.zipf != nil {
+		k = w.zipf.Uint64()
+	} else {
+		k = uint64(w.rnd.Intn(w.cfg.Keys))
+	}
+	return &types.AttributeValueMemberS{Value: fmt.Sprintf("soak-%d", k)}
+}
+
+func (w *worker) pick() string {
+	m := w.cfg.Mix
+	n := w.rnd.Intn(m.Get + m.Put + m.Query)
+	switch {
+	case n < m.Get:
+		return OpGetItem
+	case n < m.Get+m.Put:
+		return OpPutItem
+	default:
+		return OpQuery
+	}
+}
+
+func (w *worker) next(ctx context.Context) {
+	op := w.pick()
+	key := w.key()
+	table := aws.String(w.cfg.Table)
+	start := time.Now()
+	var err error
+	switch op {
+	case OpGetItem:
+		_, err = w.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: table,
+			Key:       map[string]types.AttributeValue{w.cfg.PartitionKey: key},
+		})
+	case OpPutItem:
+		_, err = w.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: table,
+			Item: map[string]types.AttributeValue{
+				w.cfg.PartitionKey: key,
+				"payload":          &types.AttributeValueMemberS{Value: w.payload},
+			},
+		})
+	case OpQuery:
+		_, err = w.client.Query(ctx, &dynamodb.QueryInput{
+			TableName:                 table,
+			KeyConditionExpression:    aws.String("#pk = :pk"),
+			ExpressionAttributeNames:  map[string]string{"#pk": w.cfg.PartitionKey},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":pk": key},
+		})
+	}
+	// Requests cut short by the end of the run are not counted.
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	s := w.stats[op]
+	s.latency.record(time.Since(start))
+	if err != nil {
+		s.errors[errorCode(err)]++
+	}
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return fmt.Sprintf("%T", err)
+}
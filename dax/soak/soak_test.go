@@ -0,0 +1,115 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package soak
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	puts int64
+}
+
+func (c *fakeClient) GetItem(context.Context, *dynamodb.GetItemInput, ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func (c *fakeClient) PutItem(context.Context, *dynamodb.PutItemInput, ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if atomic.AddInt64(&c.puts, 1)%2 == 0 {
+		return nil, &types.ProvisionedThroughputExceededException{}
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *fakeClient) Query(context.Context, *dynamodb.QueryInput, ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return &dynamodb.QueryOutput{}, nil
+}
+
+func TestRun(t *testing.T) {
+	cfg := Config{
+		Table:        "table",
+		PartitionKey: "pk",
+		Keys:         100,
+		Distribution: Zipf,
+		Mix:          Mix{Get: 1, Put: 1},
+		Concurrency:  4,
+		Requests:     1000,
+	}
+	c := &fakeClient{}
+	report, err := Run(context.Background(), c, cfg)
+	require.NoError(t, err)
+
+	var total int64
+	for _, o := range report.Operations {
+		total += o.Requests
+		assert.NotEqual(t, OpQuery, o.Operation)
+		if o.Operation == OpPutItem {
+			assert.Equal(t, c.puts/2, o.Errors["ProvisionedThroughputExceededException"])
+		}
+	}
+	assert.Equal(t, int64(1000), total)
+
+	var buf bytes.Buffer
+	_, err = report.WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "PutItem ProvisionedThroughputExceededException")
+
+	cfg.Requests = 0
+	_, err = Run(context.Background(), c, cfg)
+	assert.Error(t, err)
+}
+
+func TestParseMix(t *testing.T) {
+	m, err := ParseMix("get=80, put=15,Query=5")
+	require.NoError(t, err)
+	assert.Equal(t, Mix{Get: 80, Put: 15, Query: 5}, m)
+
+	_, err = ParseMix("get=80,scan=20")
+	assert.Error(t, err)
+	_, err = ParseMix("get")
+	assert.Error(t, err)
+}
+
+func TestHistogram(t *testing.T) {
+	for _, us := range []uint64{0, 1, 63, 64, 127, 128, 1000, 123456, 1 << 40} {
+		i := bucketOf(us)
+		assert.LessOrEqual(t, lowerBound(i), us, us)
+		assert.Greater(t, lowerBound(i+1), us, us)
+	}
+
+	var h histogram
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+	p50 := h.percentile(50)
+	assert.InDelta(t, 500, p50.Microseconds(), 10)
+	assert.Equal(t, time.Millisecond, h.max)
+
+	var merged histogram
+	merged.merge(&h)
+	merged.merge(&h)
+	assert.Equal(t, int64(2000), merged.count)
+	assert.Equal(t, p50, merged.percentile(50))
+}
@@ -0,0 +1,40 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// Stats is a snapshot of the internal state of a client.
+type Stats = client.Stats
+
+// NodeStats describes the connections and caches of a client to a single node.
+type NodeStats = client.NodeStats
+
+type statsReporter interface {
+	Stats() client.Stats
+}
+
+// Stats returns the nodes known to this client with their routing state, connection
+// counts and metadata cache sizes, along with the outcome of the last cluster
+// discovery. It is cheap enough to be called from a health or debug endpoint.
+func (d *Dax) Stats() Stats {
+	if r, ok := d.client.(statsReporter); ok {
+		return r.Stats()
+	}
+	return Stats{}
+}
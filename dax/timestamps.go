@@ -0,0 +1,154 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	createdAtName = "#daxCreatedAt"
+	updatedAtName = "#daxUpdatedAt"
+	timestampNow  = ":daxNow"
+)
+
+// AttributeTimestamps configures the attributes PutItem and UpdateItem stamp with the time
+// of the write, taken from Config.Clock. Either attribute name can be left empty to only
+// maintain the other one.
+//
+// PutItem sets UpdatedAt, and CreatedAt unless the item already carries it, e.g. because it
+// was read and is written back. UpdateItem sets UpdatedAt, and CreatedAt only when the
+// item doesn't have it yet; the update expression must not set these attributes itself.
+type AttributeTimestamps struct {
+	// CreatedAt and UpdatedAt are the names of the timestamp attributes.
+	CreatedAt string
+	UpdatedAt string
+
+	// Tables restricts the timestamps to the listed tables. All tables are stamped when
+	// it is empty.
+	Tables []string
+
+	// Format returns the attribute value of a timestamp. The default is a string holding
+	// the UTC time in RFC 3339 format with nanoseconds, which sorts chronologically.
+	Format func(time.Time) types.AttributeValue
+}
+
+func (ts *AttributeTimestamps) applies(table *string) bool {
+	if ts == nil || (ts.CreatedAt == "" && ts.UpdatedAt == "") {
+		return false
+	}
+	if len(ts.Tables) == 0 {
+		return true
+	}
+	for _, t := range ts.Tables {
+		if t == aws.ToString(table) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts *AttributeTimestamps) value(clock client.Clock) types.AttributeValue {
+	if clock == nil {
+		clock = SystemClock
+	}
+	now := clock.Now()
+	if ts.Format != nil {
+		return ts.Format(now)
+	}
+	return &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339Nano)}
+}
+
+// stampPutItem returns a copy of input whose item carries the timestamps.
+func (ts *AttributeTimestamps) stampPutItem(clock client.Clock, input *dynamodb.PutItemInput) *dynamodb.PutItemInput {
+	if input == nil || !ts.applies(input.TableName) {
+		return input
+	}
+	now := ts.value(clock)
+	in := *input
+	in.Item = make(map[string]types.AttributeValue, len(input.Item)+2)
+	for k, v := range input.Item {
+		in.Item[k] = v
+	}
+	if ts.CreatedAt != "" {
+		if _, ok := in.Item[ts.CreatedAt]; !ok {
+			in.Item[ts.CreatedAt] = now
+		}
+	}
+	if ts.UpdatedAt != "" {
+		in.Item[ts.UpdatedAt] = now
+	}
+	return &in
+}
+
+var setClause = regexp.MustCompile(`(?i)(^|\s)SET\s+`)
+
+// withTimestamps adds the assignments of the timestamps to an update expression, joining
+// an existing SET clause since clauses cannot be repeated.
+func (ts *AttributeTimestamps) withTimestamps(expr *string) *string {
+	var actions string
+	if ts.UpdatedAt != "" {
+		actions = updatedAtName + " = " + timestampNow
+	}
+	if ts.CreatedAt != "" {
+		if actions != "" {
+			actions += ", "
+		}
+		actions += createdAtName + " = if_not_exists(" + createdAtName + ", " + timestampNow + ")"
+	}
+	e := aws.ToString(expr)
+	if e == "" {
+		return aws.String("SET " + actions)
+	}
+	if loc := setClause.FindStringIndex(e); loc != nil {
+		return aws.String(e[:loc[1]] + actions + ", " + e[loc[1]:])
+	}
+	return aws.String(e + " SET " + actions)
+}
+
+// stampUpdateItem returns a copy of input which also sets the timestamps.
+func (ts *AttributeTimestamps) stampUpdateItem(clock client.Clock, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemInput, error) {
+	if input == nil || !ts.applies(input.TableName) {
+		return input, nil
+	}
+	if len(input.AttributeUpdates) > 0 || len(input.Expected) > 0 {
+		return nil, client.NewCustomInvalidParamError("UpdateItem", "attribute timestamps require expressions, legacy parameters are not supported")
+	}
+	in := *input
+	in.UpdateExpression = ts.withTimestamps(input.UpdateExpression)
+	in.ExpressionAttributeNames = make(map[string]string, len(input.ExpressionAttributeNames)+2)
+	for k, v := range input.ExpressionAttributeNames {
+		in.ExpressionAttributeNames[k] = v
+	}
+	if ts.CreatedAt != "" {
+		in.ExpressionAttributeNames[createdAtName] = ts.CreatedAt
+	}
+	if ts.UpdatedAt != "" {
+		in.ExpressionAttributeNames[updatedAtName] = ts.UpdatedAt
+	}
+	in.ExpressionAttributeValues = make(map[string]types.AttributeValue, len(input.ExpressionAttributeValues)+1)
+	for k, v := range input.ExpressionAttributeValues {
+		in.ExpressionAttributeValues[k] = v
+	}
+	in.ExpressionAttributeValues[timestampNow] = ts.value(clock)
+	return &in, nil
+}
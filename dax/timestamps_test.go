@@ -0,0 +1,110 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedClock struct {
+	Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func newTimestampedDax(c *versioningClient, ts *AttributeTimestamps) *Dax {
+	cfg := Config{Timestamps: ts}
+	cfg.Clock = fixedClock{Clock: SystemClock, now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("", 3600))}
+	return &Dax{client: c, config: cfg}
+}
+
+func TestTimestamps_putItem(t *testing.T) {
+	c := &versioningClient{}
+	d := newTimestampedDax(c, &AttributeTimestamps{CreatedAt: "createdAt", UpdatedAt: "updatedAt"})
+	now := &types.AttributeValueMemberS{Value: "2024-05-01T11:00:00Z"}
+
+	item := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}
+	_, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item})
+	require.NoError(t, err)
+	assert.Equal(t, now, c.put.Item["createdAt"])
+	assert.Equal(t, now, c.put.Item["updatedAt"])
+	assert.Len(t, item, 1, "input must not be modified")
+
+	created := &types.AttributeValueMemberS{Value: "2023-01-01T00:00:00Z"}
+	item["createdAt"] = created
+	_, err = d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item})
+	require.NoError(t, err)
+	assert.Equal(t, created, c.put.Item["createdAt"], "the creation time of an item written back is kept")
+	assert.Equal(t, now, c.put.Item["updatedAt"])
+}
+
+func TestTimestamps_nilInput(t *testing.T) {
+	ts := &AttributeTimestamps{CreatedAt: "createdAt", UpdatedAt: "updatedAt"}
+	assert.Nil(t, ts.stampPutItem(nil, nil))
+	update, err := ts.stampUpdateItem(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, update)
+}
+
+func TestTimestamps_updateItem(t *testing.T) {
+	c := &versioningClient{}
+	d := newTimestampedDax(c, &AttributeTimestamps{CreatedAt: "createdAt", UpdatedAt: "updatedAt", Tables: []string{"t"}})
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("t"),
+		UpdateExpression:          aws.String("REMOVE old SET #n = :v"),
+		ExpressionAttributeNames:  map[string]string{"#n": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}},
+	}
+	_, err := d.UpdateItem(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "REMOVE old SET #daxUpdatedAt = :daxNow, #daxCreatedAt = if_not_exists(#daxCreatedAt, :daxNow), #n = :v", aws.ToString(c.update.UpdateExpression))
+	assert.Equal(t, map[string]string{"#n": "name", "#daxCreatedAt": "createdAt", "#daxUpdatedAt": "updatedAt"}, c.update.ExpressionAttributeNames)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2024-05-01T11:00:00Z"}, c.update.ExpressionAttributeValues[":daxNow"])
+	assert.Len(t, input.ExpressionAttributeValues, 1, "input must not be modified")
+
+	_, err = d.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{TableName: aws.String("other"), UpdateExpression: aws.String("ADD n :one")})
+	require.NoError(t, err)
+	assert.Equal(t, "ADD n :one", aws.ToString(c.update.UpdateExpression), "other tables are not stamped")
+
+	_, err = d.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:        aws.String("t"),
+		AttributeUpdates: map[string]types.AttributeValueUpdate{"n": {Action: types.AttributeActionPut}},
+	})
+	assert.Error(t, err)
+}
+
+func TestTimestamps_withVersioning(t *testing.T) {
+	c := &versioningClient{}
+	d := newTimestampedDax(c, &AttributeTimestamps{UpdatedAt: "updatedAt", Format: func(t time.Time) types.AttributeValue {
+		return &types.AttributeValueMemberN{Value: "1714561200"}
+	}})
+	d.config.VersionAttributes = map[string]string{"t": "ver"}
+
+	_, err := d.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{TableName: aws.String("t")})
+	require.NoError(t, err)
+	assert.Equal(t, "SET #daxUpdatedAt = :daxNow ADD #daxVersion :daxVersionIncrement", aws.ToString(c.update.UpdateExpression))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1714561200"}, c.update.ExpressionAttributeValues[":daxNow"])
+}
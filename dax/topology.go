@@ -0,0 +1,132 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+)
+
+// Resolver resolves the hostnames of the seed endpoints, see Config.Resolver.
+// *net.Resolver implements it.
+type Resolver = client.Resolver
+
+// RouteSelector picks the node a request is sent to, see Config.RouteSelector.
+type RouteSelector = client.RouteSelector
+
+// RouteState describes a route offered to a RouteSelector.
+type RouteState = client.RouteState
+
+// Route is a node requests are sent to.
+type Route = client.DaxAPI
+
+// RoundRobinRouteSelector cycles through the nodes.
+type RoundRobinRouteSelector = client.RoundRobinRouteSelector
+
+// P2CRouteSelector picks the least loaded of two random nodes, steering requests away
+// from consistently slow nodes.
+type P2CRouteSelector = client.P2CRouteSelector
+
+// MaxForcedFailOpen bounds the duration of ForceFailOpen.
+const MaxForcedFailOpen = client.MaxForcedFailOpen
+
+// MaxPinnedLifetime bounds the lifetime of the clients returned by Pin.
+const MaxPinnedLifetime = client.MaxPinnedLifetime
+
+// ErrTopologyPinned is returned by RefreshTopology and UpdateEndpoints on a pinned client.
+var ErrTopologyPinned = client.ErrTopologyPinned
+
+// PinnedClientExpiredError is returned for the requests made with a pinned client once its
+// lifetime elapsed.
+type PinnedClientExpiredError = client.PinnedClientExpiredError
+
+type topologyPinner interface {
+	Pin(ctx context.Context, lifetime time.Duration) (*client.ClusterDaxClient, error)
+}
+
+type failOpenForcer interface {
+	ForceFailOpen(d time.Duration) error
+}
+
+type topologyRefresher interface {
+	RefreshTopology(ctx context.Context) ([]client.RosterNode, error)
+	WaitUntilReady(ctx context.Context) error
+	UpdateEndpoints(ctx context.Context, hostPorts []string) ([]client.RosterNode, error)
+}
+
+// RefreshTopology discovers the cluster nodes right away rather than on the next
+// Config.ClusterUpdateInterval tick, and returns the nodes requests are routed to after
+// the refresh. Orchestration code shifting traffic between nodes can call it to act on
+// the new topology as soon as the change is made. Discovery stops when ctx is done.
+func (d *Dax) RefreshTopology(ctx context.Context) ([]RosterNode, error) {
+	if r, ok := d.client.(topologyRefresher); ok {
+		return r.RefreshTopology(ctx)
+	}
+	return nil, d.unImpl()
+}
+
+// WaitUntilReady blocks until the client discovered the cluster nodes, or until ctx is
+// done. Requests made before then fail with a *NotReadyError; callers which would rather
+// block, or fall back to DynamoDB, during startup can call it first. The error returned
+// when ctx is done is a *NotReadyError wrapping ctx.Err().
+func (d *Dax) WaitUntilReady(ctx context.Context) error {
+	if r, ok := d.client.(topologyRefresher); ok {
+		return r.WaitUntilReady(ctx)
+	}
+	return nil
+}
+
+// UpdateEndpoints repoints the client to the cluster reachable through hostPorts, in the
+// format of Config.HostPorts, and discovers its nodes right away. Requests keep going to
+// the current nodes until the discovery succeeds, the nodes which are not part of the new
+// cluster are closed afterwards. When the discovery fails, the client keeps retrying with
+// the new endpoints in the background. It returns the nodes requests are routed to.
+func (d *Dax) UpdateEndpoints(ctx context.Context, hostPorts ...string) ([]RosterNode, error) {
+	if r, ok := d.client.(topologyRefresher); ok {
+		return r.UpdateEndpoints(ctx, hostPorts)
+	}
+	return nil, d.unImpl()
+}
+
+// Pin returns a new client routing requests to the nodes this client currently routes
+// requests to, which never discovers the cluster nodes again. It is meant for diagnostics
+// only: benchmarks against a fixed topology and bisection of topology related issues.
+// Nodes leaving or joining the cluster are ignored, and the pinned client fails every
+// request with a *PinnedClientExpiredError once lifetime, capped by MaxPinnedLifetime,
+// elapsed. The pinned client must be closed independently of d.
+func (d *Dax) Pin(ctx context.Context, lifetime time.Duration) (*Dax, error) {
+	if p, ok := d.client.(topologyPinner); ok {
+		c, err := p.Pin(ctx, lifetime)
+		if err != nil {
+			return nil, err
+		}
+		return &Dax{client: c, config: d.config}, nil
+	}
+	return nil, d.unImpl()
+}
+
+// ForceFailOpen keeps every node of the cluster routed to for duration, capped by
+// MaxForcedFailOpen, even when its health checks fail. During an incident where healthy
+// nodes seem to be taken out of rotation, this rules the route manager out. A zero
+// duration ends a forced fail-open early.
+func (d *Dax) ForceFailOpen(duration time.Duration) error {
+	if f, ok := d.client.(failOpenForcer); ok {
+		return f.ForceFailOpen(duration)
+	}
+	return d.unImpl()
+}
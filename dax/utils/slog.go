@@ -0,0 +1,94 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+// LevelTrace is the slog level of the messages logged with LogDebugWithRequestRetries,
+// below slog.LevelDebug.
+const LevelTrace = slog.LevelDebug - 4
+
+// AttrLogger is implemented by loggers accepting structured attributes along with the
+// message. The client attaches the endpoint, operation, attempt and request ID to the
+// messages it logs about a request as attributes.
+type AttrLogger interface {
+	LogAttrs(ctx context.Context, classification logging.Classification, msg string, attrs ...slog.Attr)
+}
+
+// SlogLogger adapts a *slog.Logger to the Logger interface of the client.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger writing to logger.
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+func (l *SlogLogger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	l.LogAttrs(context.Background(), classification, fmt.Sprintf(format, v...))
+}
+
+func (l *SlogLogger) LogAttrs(ctx context.Context, classification logging.Classification, msg string, attrs ...slog.Attr) {
+	l.logger.LogAttrs(ctx, SlogLevel(classification), msg, attrs...)
+}
+
+// SlogLevel returns the slog level of messages with the given classification.
+func SlogLevel(classification logging.Classification) slog.Level {
+	switch classification {
+	case logging.Debug:
+		return slog.LevelDebug
+	case logging.Warn:
+		return slog.LevelWarn
+	case "ERROR":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// SlogLevel returns the minimum slog level a handler needs to enable to receive all the
+// messages logged with l.
+func (l *LogLevelType) SlogLevel() slog.Level {
+	switch {
+	case l.Matches(LogDebugWithRequestRetries):
+		return LevelTrace
+	case l.AtLeast(LogDebug):
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LogLevelForSlog returns the LogLevelType matching the minimum level enabled by h, so
+// that the client doesn't format debug messages h would discard.
+func LogLevelForSlog(h slog.Handler) LogLevelType {
+	ctx := context.Background()
+	switch {
+	case h.Enabled(ctx, LevelTrace):
+		return LogDebugWithRequestRetries
+	case h.Enabled(ctx, slog.LevelDebug):
+		return LogDebug
+	default:
+		return LogOff
+	}
+}
@@ -0,0 +1,134 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/logging"
+)
+
+// Log levels of the aws-sdk-go aws.LogLevelType used by the Config of aws-dax-go.
+const (
+	v1LogDebug                   = 0x1000
+	v1LogDebugWithRequestRetries = 0x1004
+)
+
+// V1Logger is the aws.Logger interface of aws-sdk-go, used by the Config of aws-dax-go.
+type V1Logger interface {
+	Log(...interface{})
+}
+
+// V1Config has the shape of the Config of the aws-dax-go client, the DAX client of
+// aws-sdk-go, with the same field names, so that configurations written for it, including
+// templates decoded with encoding/json, keep working while migrating. Convert it with
+// ToConfig or create a client with NewFromV1Config.
+type V1Config struct {
+	MaxPendingConnectionsPerHost int
+	ClusterUpdateThreshold       time.Duration
+	ClusterUpdateInterval        time.Duration
+	IdleConnectionReapDelay      time.Duration
+	ClientHealthCheckInterval    time.Duration
+
+	HostPorts                []string
+	Region                   string
+	Credentials              aws.CredentialsProvider
+	DialContext              func(ctx context.Context, network string, address string) (net.Conn, error)
+	SkipHostnameVerification bool
+
+	RequestTimeout time.Duration
+	WriteRetries   int
+	ReadRetries    int
+	RetryDelay     time.Duration
+
+	Logger   V1Logger
+	LogLevel uint // an aws.LogLevelType of aws-sdk-go, e.g. 0x1000 for LogDebug
+}
+
+// ToConfig maps c onto DefaultConfig(). The connection settings, durations and timeouts
+// left at zero keep their defaults, while the retries are taken as they are, zero meaning
+// no retry as with aws-dax-go. The debug levels of LogLevel map to LogDebug, and to
+// LogDebugWithRequestRetries when it includes the request retries.
+func (c V1Config) ToConfig() Config {
+	cfg := DefaultConfig()
+	if c.MaxPendingConnectionsPerHost > 0 {
+		cfg.MaxPendingConnectionsPerHost = c.MaxPendingConnectionsPerHost
+	}
+	for _, d := range []struct {
+		v1  time.Duration
+		dst *time.Duration
+	}{
+		{c.ClusterUpdateThreshold, &cfg.ClusterUpdateThreshold},
+		{c.ClusterUpdateInterval, &cfg.ClusterUpdateInterval},
+		{c.IdleConnectionReapDelay, &cfg.IdleConnectionReapDelay},
+		{c.ClientHealthCheckInterval, &cfg.ClientHealthCheckInterval},
+		{c.RequestTimeout, &cfg.RequestTimeout},
+		{c.RetryDelay, &cfg.RetryDelay},
+	} {
+		if d.v1 > 0 {
+			*d.dst = d.v1
+		}
+	}
+
+	cfg.HostPorts = c.HostPorts
+	cfg.Region = c.Region
+	if c.Credentials != nil {
+		cfg.Credentials = c.Credentials
+	}
+	cfg.DialContext = c.DialContext
+	cfg.SkipHostnameVerification = c.SkipHostnameVerification
+	cfg.WriteRetries = c.WriteRetries
+	cfg.ReadRetries = c.ReadRetries
+
+	if c.Logger != nil {
+		cfg.Logger = v1LoggerAdapter(c.Logger)
+	}
+	switch {
+	case c.LogLevel&v1LogDebugWithRequestRetries == v1LogDebugWithRequestRetries:
+		cfg.LogLevel = utils.LogDebugWithRequestRetries
+	case c.LogLevel&v1LogDebug != 0:
+		cfg.LogLevel = utils.LogDebug
+	default:
+		cfg.LogLevel = utils.LogOff
+	}
+	return cfg
+}
+
+func v1LoggerAdapter(l V1Logger) logging.Logger {
+	return logging.LoggerFunc(func(classification logging.Classification, format string, v ...interface{}) {
+		l.Log(fmt.Sprintf("[%s] %s", classification, fmt.Sprintf(format, v...)))
+	})
+}
+
+// NewFromV1Config creates a new instance of the DAX client with a configuration written
+// for aws-dax-go, see V1Config.
+//
+// Example:
+//
+//	var v1 dax.V1Config
+//	if err := json.Unmarshal(template, &v1); err != nil {
+//		return err
+//	}
+//	v1.Credentials = awsCfg.Credentials
+//	svc, err := dax.NewFromV1Config(v1)
+func NewFromV1Config(cfg V1Config) (*Dax, error) {
+	return New(cfg.ToConfig())
+}
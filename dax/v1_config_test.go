@@ -0,0 +1,74 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-dax-go-v2/dax/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type v1Logger []string
+
+func (l *v1Logger) Log(args ...interface{}) {
+	*l = append(*l, fmt.Sprint(args...))
+}
+
+func TestV1Config_toConfig(t *testing.T) {
+	template := `{
+		"HostPorts": ["dax://mycluster.frfx8h.clustercfg.dax.usw2.amazonaws.com"],
+		"Region": "us-west-2",
+		"MaxPendingConnectionsPerHost": 20,
+		"ClusterUpdateInterval": 2000000000,
+		"RequestTimeout": 5000000000,
+		"WriteRetries": 0,
+		"ReadRetries": 4,
+		"LogLevel": 4100
+	}`
+	var v1 V1Config
+	require.NoError(t, json.Unmarshal([]byte(template), &v1))
+	v1.Credentials = aws.AnonymousCredentials{}
+	logger := &v1Logger{}
+	v1.Logger = logger
+
+	cfg := v1.ToConfig()
+	def := DefaultConfig()
+	assert.Equal(t, []string{"dax://mycluster.frfx8h.clustercfg.dax.usw2.amazonaws.com"}, cfg.HostPorts)
+	assert.Equal(t, "us-west-2", cfg.Region)
+	assert.Equal(t, aws.AnonymousCredentials{}, cfg.Credentials)
+	assert.Equal(t, 20, cfg.MaxPendingConnectionsPerHost)
+	assert.Equal(t, 2*time.Second, cfg.ClusterUpdateInterval)
+	assert.Equal(t, def.ClusterUpdateThreshold, cfg.ClusterUpdateThreshold, "durations left at zero keep their defaults")
+	assert.Equal(t, def.ClientHealthCheckInterval, cfg.ClientHealthCheckInterval)
+	assert.Equal(t, 5*time.Second, cfg.RequestTimeout)
+	assert.Equal(t, 0, cfg.WriteRetries, "retries are taken as they are")
+	assert.Equal(t, 4, cfg.ReadRetries)
+	assert.Equal(t, utils.LogDebugWithRequestRetries, cfg.LogLevel)
+
+	cfg.Logger.Logf("WARN", "node %d", 1)
+	assert.Equal(t, []string{"[WARN] node 1"}, []string(*logger))
+
+	for level, expected := range map[uint]utils.LogLevelType{0: utils.LogOff, 0x1000: utils.LogDebug, 0x1002: utils.LogDebug, 0x1004: utils.LogDebugWithRequestRetries} {
+		v1.LogLevel = level
+		assert.Equal(t, expected, v1.ToConfig().LogLevel, level)
+	}
+}
@@ -0,0 +1,349 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	versionName      = "#daxVersion"
+	versionExpected  = ":daxExpectedVersion"
+	versionIncrement = ":daxVersionIncrement"
+)
+
+// VersionConflictError is returned by PutItem, UpdateItem, DeleteItem and
+// TransactWriteItems on a versioned table when the stored version differs from the
+// expected one. Expected is zero when the item was expected not to exist. The version
+// condition is combined with the condition expression of the request, if any; as the
+// failure of either is reported the same way by DynamoDB, the failed condition of a
+// request with a condition expression of its own is returned as is.
+type VersionConflictError struct {
+	Table    string
+	Expected int64
+	Err      error
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Expected == 0 {
+		return fmt.Sprintf("version conflict on table %s: item already exists", e.Table)
+	}
+	return fmt.Sprintf("version conflict on table %s: expected version %d", e.Table, e.Expected)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return e.Err
+}
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion returns a context carrying the version an item had when it was read.
+// UpdateItem and DeleteItem on versioned tables only succeed if the stored version still
+// matches. PutItem takes the expected version from the version attribute of the item.
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+func expectedVersion(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	v, ok := ctx.Value(expectedVersionKey{}).(int64)
+	return v, ok
+}
+
+func (c *Config) versionAttribute(table *string) string {
+	if c.VersionAttributes == nil || table == nil {
+		return ""
+	}
+	return c.VersionAttributes[*table]
+}
+
+func versionNames(names map[string]string, attr string) map[string]string {
+	out := make(map[string]string, len(names)+1)
+	for k, v := range names {
+		out[k] = v
+	}
+	out[versionName] = attr
+	return out
+}
+
+func versionValues(values map[string]types.AttributeValue, extra map[string]int64) map[string]types.AttributeValue {
+	out := make(map[string]types.AttributeValue, len(values)+len(extra))
+	for k, v := range values {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = &types.AttributeValueMemberN{Value: strconv.FormatInt(v, 10)}
+	}
+	return out
+}
+
+func andCondition(existing *string, cond string) *string {
+	if aws.ToString(existing) == "" {
+		return aws.String(cond)
+	}
+	return aws.String("(" + *existing + ") AND " + cond)
+}
+
+// updateToken matches the keywords, names and placeholders of an update expression, so
+// that #add, :add or a.add aren't mistaken for the ADD keyword.
+var updateToken = regexp.MustCompile(`[#:]?[\w.\[\]]+`)
+
+// withVersionIncrement adds the increment of the version attribute to an update
+// expression, joining an existing ADD clause since clauses cannot be repeated.
+func withVersionIncrement(expr *string) *string {
+	inc := versionName + " " + versionIncrement
+	e := aws.ToString(expr)
+	if e == "" {
+		return aws.String("ADD " + inc)
+	}
+	for _, loc := range updateToken.FindAllStringIndex(e, -1) {
+		if strings.EqualFold(e[loc[0]:loc[1]], "ADD") {
+			return aws.String(e[:loc[1]] + " " + inc + "," + e[loc[1]:])
+		}
+	}
+	return aws.String(e + " ADD " + inc)
+}
+
+func legacyVersioningError(op string) error {
+	return client.NewCustomInvalidParamError(op, "optimistic locking requires expressions, legacy parameters are not supported")
+}
+
+// versionPutItem returns a copy of input which writes the next version of the item,
+// conditioned on the version it carries, and the expected version.
+func (c *Config) versionPutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemInput, int64, error) {
+	if input == nil {
+		return input, 0, nil
+	}
+	attr := c.versionAttribute(input.TableName)
+	if attr == "" {
+		return input, 0, nil
+	}
+	if len(input.Expected) > 0 {
+		return nil, 0, legacyVersioningError("PutItem")
+	}
+	var expected int64
+	if av, ok := input.Item[attr]; ok {
+		n, isNum := av.(*types.AttributeValueMemberN)
+		if !isNum {
+			return nil, 0, client.NewCustomInvalidParamError("PutItem", fmt.Sprintf("version attribute %s must be a number", attr))
+		}
+		v, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			return nil, 0, client.NewCustomInvalidParamError("PutItem", fmt.Sprintf("version attribute %s must be an integer", attr))
+		}
+		expected = v
+	}
+
+	in := *input
+	in.Item = make(map[string]types.AttributeValue, len(input.Item))
+	for k, v := range input.Item {
+		in.Item[k] = v
+	}
+	in.Item[attr] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expected+1, 10)}
+	in.ExpressionAttributeNames = versionNames(input.ExpressionAttributeNames, attr)
+	if expected == 0 {
+		in.ConditionExpression = andCondition(input.ConditionExpression, "attribute_not_exists("+versionName+")")
+		in.ExpressionAttributeValues = input.ExpressionAttributeValues
+	} else {
+		in.ConditionExpression = andCondition(input.ConditionExpression, versionName+" = "+versionExpected)
+		in.ExpressionAttributeValues = versionValues(input.ExpressionAttributeValues, map[string]int64{versionExpected: expected})
+	}
+	return &in, expected, nil
+}
+
+// versionUpdateItem returns a copy of input which increments the version attribute and,
+// when ctx carries an expected version, is conditioned on it.
+func (c *Config) versionUpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemInput, bool, int64, error) {
+	if input == nil {
+		return input, false, 0, nil
+	}
+	attr := c.versionAttribute(input.TableName)
+	if attr == "" {
+		return input, false, 0, nil
+	}
+	if len(input.AttributeUpdates) > 0 || len(input.Expected) > 0 {
+		return nil, false, 0, legacyVersioningError("UpdateItem")
+	}
+	in := *input
+	in.UpdateExpression = withVersionIncrement(input.UpdateExpression)
+	in.ExpressionAttributeNames = versionNames(input.ExpressionAttributeNames, attr)
+	values := map[string]int64{versionIncrement: 1}
+	expected, conditioned := expectedVersion(ctx)
+	if conditioned {
+		in.ConditionExpression = andCondition(input.ConditionExpression, versionName+" = "+versionExpected)
+		values[versionExpected] = expected
+	}
+	in.ExpressionAttributeValues = versionValues(input.ExpressionAttributeValues, values)
+	return &in, conditioned, expected, nil
+}
+
+// versionDeleteItem returns a copy of input conditioned on the expected version in ctx.
+func (c *Config) versionDeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemInput, bool, int64, error) {
+	if input == nil {
+		return input, false, 0, nil
+	}
+	attr := c.versionAttribute(input.TableName)
+	expected, conditioned := expectedVersion(ctx)
+	if attr == "" || !conditioned {
+		return input, false, 0, nil
+	}
+	if len(input.Expected) > 0 {
+		return nil, false, 0, legacyVersioningError("DeleteItem")
+	}
+	in := *input
+	in.ExpressionAttributeNames = versionNames(input.ExpressionAttributeNames, attr)
+	in.ConditionExpression = andCondition(input.ConditionExpression, versionName+" = "+versionExpected)
+	in.ExpressionAttributeValues = versionValues(input.ExpressionAttributeValues, map[string]int64{versionExpected: expected})
+	return &in, true, expected, nil
+}
+
+// versionCheck is the version a write of a transaction item is conditioned on.
+type versionCheck struct {
+	table    *string
+	expected int64
+}
+
+// versionTransactWriteItems returns a copy of input whose Put, Update and Delete items
+// on versioned tables are versioned like PutItem, UpdateItem and DeleteItem. The
+// expected version in ctx applies to all its Update and Delete items on versioned
+// tables. The version checks are returned by index of their item.
+func (c *Config) versionTransactWriteItems(ctx context.Context, input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsInput, map[int]versionCheck, error) {
+	if len(c.VersionAttributes) == 0 || input == nil {
+		return input, nil, nil
+	}
+	checks := make(map[int]versionCheck)
+	in := *input
+	in.TransactItems = make([]types.TransactWriteItem, len(input.TransactItems))
+	for i, ti := range input.TransactItems {
+		in.TransactItems[i] = ti
+		switch {
+		case ti.Put != nil && c.versionAttribute(ti.Put.TableName) != "":
+			p := ti.Put
+			v, expected, err := c.versionPutItem(&dynamodb.PutItemInput{
+				TableName:                 p.TableName,
+				Item:                      p.Item,
+				ConditionExpression:       p.ConditionExpression,
+				ExpressionAttributeNames:  p.ExpressionAttributeNames,
+				ExpressionAttributeValues: p.ExpressionAttributeValues,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			put := *p
+			put.Item, put.ConditionExpression = v.Item, v.ConditionExpression
+			put.ExpressionAttributeNames, put.ExpressionAttributeValues = v.ExpressionAttributeNames, v.ExpressionAttributeValues
+			in.TransactItems[i].Put = &put
+			if aws.ToString(p.ConditionExpression) == "" {
+				checks[i] = versionCheck{table: p.TableName, expected: expected}
+			}
+		case ti.Update != nil && c.versionAttribute(ti.Update.TableName) != "":
+			u := ti.Update
+			v, conditioned, expected, err := c.versionUpdateItem(ctx, &dynamodb.UpdateItemInput{
+				TableName:                 u.TableName,
+				Key:                       u.Key,
+				UpdateExpression:          u.UpdateExpression,
+				ConditionExpression:       u.ConditionExpression,
+				ExpressionAttributeNames:  u.ExpressionAttributeNames,
+				ExpressionAttributeValues: u.ExpressionAttributeValues,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			update := *u
+			update.UpdateExpression, update.ConditionExpression = v.UpdateExpression, v.ConditionExpression
+			update.ExpressionAttributeNames, update.ExpressionAttributeValues = v.ExpressionAttributeNames, v.ExpressionAttributeValues
+			in.TransactItems[i].Update = &update
+			if conditioned && aws.ToString(u.ConditionExpression) == "" {
+				checks[i] = versionCheck{table: u.TableName, expected: expected}
+			}
+		case ti.Delete != nil && c.versionAttribute(ti.Delete.TableName) != "":
+			d := ti.Delete
+			v, conditioned, expected, err := c.versionDeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName:                 d.TableName,
+				Key:                       d.Key,
+				ConditionExpression:       d.ConditionExpression,
+				ExpressionAttributeNames:  d.ExpressionAttributeNames,
+				ExpressionAttributeValues: d.ExpressionAttributeValues,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			if conditioned {
+				del := *d
+				del.ConditionExpression = v.ConditionExpression
+				del.ExpressionAttributeNames, del.ExpressionAttributeValues = v.ExpressionAttributeNames, v.ExpressionAttributeValues
+				in.TransactItems[i].Delete = &del
+				if aws.ToString(d.ConditionExpression) == "" {
+					checks[i] = versionCheck{table: d.TableName, expected: expected}
+				}
+			}
+		}
+	}
+	return &in, checks, nil
+}
+
+// transactionVersionConflict converts a transaction canceled by the failed condition of
+// a versioned item into a VersionConflictError for the first such item. Items with a
+// condition expression of their own have no version check, see VersionConflictError.
+func transactionVersionConflict(err error, checks map[int]versionCheck) error {
+	var tce *types.TransactionCanceledException
+	if len(checks) == 0 || !errors.As(err, &tce) {
+		return err
+	}
+	for i, r := range tce.CancellationReasons {
+		if check, ok := checks[i]; ok && aws.ToString(r.Code) == "ConditionalCheckFailed" {
+			return &VersionConflictError{Table: aws.ToString(check.table), Expected: check.expected, Err: err}
+		}
+	}
+	return err
+}
+
+// checkBatchWriteItem rejects batch writes to versioned tables, as BatchWriteItem cannot
+// carry the version conditions.
+func (c *Config) checkBatchWriteItem(input *dynamodb.BatchWriteItemInput) error {
+	if len(c.VersionAttributes) == 0 || input == nil {
+		return nil
+	}
+	for table := range input.RequestItems {
+		if c.versionAttribute(&table) != "" {
+			return client.NewCustomInvalidParamError("BatchWriteItem", fmt.Sprintf("table %s is versioned, use PutItem, DeleteItem or TransactWriteItems", table))
+		}
+	}
+	return nil
+}
+
+// versionConflict converts a failed condition of a versioned write into a
+// VersionConflictError, unless the write has a condition expression of its own which
+// may be the one that failed.
+func versionConflict(err error, table, condition *string, expected int64) error {
+	var ccf *types.ConditionalCheckFailedException
+	if aws.ToString(condition) == "" && errors.As(err, &ccf) {
+		return &VersionConflictError{Table: aws.ToString(table), Expected: expected, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package dax
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-dax-go-v2/dax/internal/client"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versioningClient struct {
+	client.DaxAPI
+	put    *dynamodb.PutItemInput
+	update *dynamodb.UpdateItemInput
+	write  *dynamodb.TransactWriteItemsInput
+	err    error
+}
+
+func (c *versioningClient) PutItemWithOptions(_ context.Context, input *dynamodb.PutItemInput, output *dynamodb.PutItemOutput, _ client.RequestOptions) (*dynamodb.PutItemOutput, error) {
+	c.put = input
+	return output, c.err
+}
+
+func (c *versioningClient) UpdateItemWithOptions(_ context.Context, input *dynamodb.UpdateItemInput, output *dynamodb.UpdateItemOutput, _ client.RequestOptions) (*dynamodb.UpdateItemOutput, error) {
+	c.update = input
+	return output, c.err
+}
+
+func (c *versioningClient) TransactWriteItemsWithOptions(_ context.Context, input *dynamodb.TransactWriteItemsInput, output *dynamodb.TransactWriteItemsOutput, _ client.RequestOptions) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.write = input
+	return output, c.err
+}
+
+func TestVersioning_putItem(t *testing.T) {
+	c := &versioningClient{}
+	d := &Dax{client: c, config: Config{VersionAttributes: map[string]string{"versioned": "ver"}}}
+
+	item := map[string]types.AttributeValue{
+		"pk":  &types.AttributeValueMemberS{Value: "a"},
+		"ver": &types.AttributeValueMemberN{Value: "3"},
+	}
+	_, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("versioned"), Item: item})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "4"}, c.put.Item["ver"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, item["ver"], "input must not be modified")
+	assert.Equal(t, "#daxVersion = :daxExpectedVersion", aws.ToString(c.put.ConditionExpression))
+
+	c.err = &types.ConditionalCheckFailedException{}
+	_, err = d.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String("versioned"),
+		Item:      map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "b"}},
+	})
+	var conflict *VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(0), conflict.Expected)
+	assert.Equal(t, "attribute_not_exists(#daxVersion)", aws.ToString(c.put.ConditionExpression))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, c.put.Item["ver"])
+
+	// the condition of the request may be the one that failed
+	_, err = d.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String("versioned"),
+		Item:                map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "b"}},
+		ConditionExpression: aws.String("size(pk) > :z"),
+	})
+	assert.IsType(t, &types.ConditionalCheckFailedException{}, err)
+	assert.Equal(t, "(size(pk) > :z) AND attribute_not_exists(#daxVersion)", aws.ToString(c.put.ConditionExpression))
+
+	in := &dynamodb.PutItemInput{TableName: aws.String("plain"), Item: item}
+	_, err = d.PutItem(context.Background(), in)
+	assert.IsType(t, &types.ConditionalCheckFailedException{}, err)
+	assert.Same(t, in, c.put)
+}
+
+func TestVersioning_updateItem(t *testing.T) {
+	c := &versioningClient{}
+	d := &Dax{client: c, config: Config{VersionAttributes: map[string]string{"versioned": "ver"}}}
+
+	ctx := WithExpectedVersion(context.Background(), 7)
+	_, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String("versioned"),
+		UpdateExpression: aws.String("SET a = :a ADD b :b"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SET a = :a ADD #daxVersion :daxVersionIncrement, b :b", aws.ToString(c.update.UpdateExpression))
+	assert.Equal(t, "#daxVersion = :daxExpectedVersion", aws.ToString(c.update.ConditionExpression))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "7"}, c.update.ExpressionAttributeValues[":daxExpectedVersion"])
+
+	_, err = d.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:        aws.String("versioned"),
+		UpdateExpression: aws.String("SET a = :a"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SET a = :a ADD #daxVersion :daxVersionIncrement", aws.ToString(c.update.UpdateExpression))
+	assert.Nil(t, c.update.ConditionExpression)
+
+	_, err = d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String("versioned"),
+		AttributeUpdates: map[string]types.AttributeValueUpdate{"a": {}},
+	})
+	assert.Error(t, err)
+
+	c.err = &types.ConditionalCheckFailedException{}
+	_, err = d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String("versioned"),
+		UpdateExpression: aws.String("SET a = :a"),
+	})
+	var conflict *VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(7), conflict.Expected)
+	_, err = d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String("versioned"),
+		UpdateExpression:    aws.String("SET a = :a"),
+		ConditionExpression: aws.String("a < :a"),
+	})
+	assert.IsType(t, &types.ConditionalCheckFailedException{}, err)
+}
+
+func TestWithVersionIncrement(t *testing.T) {
+	inc := "#daxVersion :daxVersionIncrement"
+	for in, out := range map[string]string{
+		"":                        "ADD " + inc,
+		"SET a = :a":              "SET a = :a ADD " + inc,
+		"add b :b":                "add " + inc + ", b :b",
+		"SET a = :a ADD b :b":     "SET a = :a ADD " + inc + ", b :b",
+		"SET #add = :add":         "SET #add = :add ADD " + inc,
+		"SET info.add = :v":       "SET info.add = :v ADD " + inc,
+		"SET a = :add\nADD\tb :b": "SET a = :add\nADD " + inc + ",\tb :b",
+	} {
+		assert.Equal(t, out, aws.ToString(withVersionIncrement(aws.String(in))), in)
+	}
+}
+
+func TestVersioning_transactWriteItems(t *testing.T) {
+	c := &versioningClient{}
+	d := &Dax{client: c, config: Config{VersionAttributes: map[string]string{"versioned": "ver"}}}
+
+	key := map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "a"}}
+	item := map[string]types.AttributeValue{
+		"pk":  &types.AttributeValueMemberS{Value: "b"},
+		"ver": &types.AttributeValueMemberN{Value: "2"},
+	}
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String("versioned"), Item: item}},
+		{Update: &types.Update{TableName: aws.String("versioned"), Key: key, UpdateExpression: aws.String("SET a = :a")}},
+		{Delete: &types.Delete{TableName: aws.String("versioned"), Key: key}},
+		{Put: &types.Put{TableName: aws.String("plain"), Item: item}},
+	}}
+	ctx := WithExpectedVersion(context.Background(), 5)
+	_, err := d.TransactWriteItems(ctx, input)
+	require.NoError(t, err)
+
+	items := c.write.TransactItems
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, items[0].Put.Item["ver"])
+	assert.Equal(t, "#daxVersion = :daxExpectedVersion", aws.ToString(items[0].Put.ConditionExpression))
+	assert.Equal(t, "SET a = :a ADD #daxVersion :daxVersionIncrement", aws.ToString(items[1].Update.UpdateExpression))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "5"}, items[1].Update.ExpressionAttributeValues[":daxExpectedVersion"])
+	assert.Equal(t, "#daxVersion = :daxExpectedVersion", aws.ToString(items[2].Delete.ConditionExpression))
+	assert.Same(t, input.TransactItems[3].Put, items[3].Put)
+	assert.Nil(t, input.TransactItems[0].Put.ConditionExpression, "input must not be modified")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, item["ver"], "input must not be modified")
+
+	c.err = &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+		{Code: aws.String("None")},
+		{Code: aws.String("ConditionalCheckFailed")},
+		{Code: aws.String("None")},
+		{Code: aws.String("None")},
+	}}
+	_, err = d.TransactWriteItems(ctx, input)
+	var conflict *VersionConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "versioned", conflict.Table)
+	assert.Equal(t, int64(5), conflict.Expected)
+
+	c.err = &types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+		{Code: aws.String("None")},
+		{Code: aws.String("None")},
+		{Code: aws.String("None")},
+		{Code: aws.String("ConditionalCheckFailed")},
+	}}
+	_, err = d.TransactWriteItems(ctx, input)
+	assert.IsType(t, &types.TransactionCanceledException{}, err)
+}
+
+func TestVersioning_batchWriteItem(t *testing.T) {
+	d := &Dax{client: &versioningClient{}, config: Config{VersionAttributes: map[string]string{"versioned": "ver"}}}
+
+	_, err := d.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+		"versioned": {{DeleteRequest: &types.DeleteRequest{}}},
+	}})
+	assert.Error(t, err)
+}
+
+func TestVersioning_nilInput(t *testing.T) {
+	d := &Dax{client: &versioningClient{}, config: Config{
+		VersionAttributes: map[string]string{"versioned": "ver"},
+		Timestamps:        &AttributeTimestamps{UpdatedAt: "updatedAt"},
+		Encryption:        testEncryption(t, 0),
+	}}
+	ctx := WithExpectedVersion(context.Background(), 1)
+
+	var required *smithy.ParamRequiredError
+	_, err := d.PutItem(ctx, nil)
+	assert.ErrorAs(t, err, &required)
+	_, err = d.UpdateItem(ctx, nil)
+	assert.ErrorAs(t, err, &required)
+	_, err = d.DeleteItem(ctx, nil)
+	assert.ErrorAs(t, err, &required)
+}
@@ -0,0 +1,239 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+// Package daxotel implements a smithy-go metrics.MeterProvider recording the metrics of
+// the DAX client with an OpenTelemetry metric.MeterProvider:
+//
+//	cfg := dax.DefaultConfig()
+//	cfg.MeterProvider = daxotel.New(otel.GetMeterProvider())
+//
+// Unlike the generic smithy-go adapter, the meters implement dax.HistogramBucketsMeter, so
+// that the histograms of the client are created with its bucket boundaries, such as
+// Config.LatencyHistogramBuckets, without configuring a view. Measurement properties
+// become attributes.
+//
+// It is a separate module so that the DAX client doesn't depend on OpenTelemetry.
+package daxotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MeterProvider creates smithy-go meters recording with the meters of an OpenTelemetry
+// metric.MeterProvider.
+type MeterProvider struct {
+	provider metric.MeterProvider
+}
+
+var _ metrics.MeterProvider = (*MeterProvider)(nil)
+
+// New returns a MeterProvider recording with provider.
+func New(provider metric.MeterProvider) *MeterProvider {
+	return &MeterProvider{provider: provider}
+}
+
+// Meter returns a meter creating OpenTelemetry instruments in the given scope.
+// Asynchronous instruments are not supported and do not record anything; the DAX client
+// does not use them.
+func (p *MeterProvider) Meter(scope string, opts ...metrics.MeterOption) metrics.Meter {
+	return &meter{Meter: metrics.NopMeterProvider{}.Meter(scope), otel: p.provider.Meter(scope)}
+}
+
+type meter struct {
+	metrics.Meter
+	otel metric.Meter
+}
+
+// instrumentOptions returns the description and unit set by opts. The units of the DAX
+// client are mapped to their UCUM symbols, as OpenTelemetry expects.
+func instrumentOptions(opts []metrics.InstrumentOption) (metric.InstrumentOption, metric.InstrumentOption) {
+	var o metrics.InstrumentOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	unit := o.UnitLabel
+	switch unit {
+	case "Microseconds":
+		unit = "us"
+	case "Items":
+		unit = "{item}"
+	}
+	return metric.WithDescription(o.Description), metric.WithUnit(unit)
+}
+
+func (m *meter) Int64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Int64Counter, error) {
+	d, u := instrumentOptions(opts)
+	c, err := m.otel.Int64Counter(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &int64Counter{c}, nil
+}
+
+func (m *meter) Float64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Float64Counter, error) {
+	d, u := instrumentOptions(opts)
+	c, err := m.otel.Float64Counter(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &float64Counter{c}, nil
+}
+
+func (m *meter) Int64UpDownCounter(name string, opts ...metrics.InstrumentOption) (metrics.Int64UpDownCounter, error) {
+	d, u := instrumentOptions(opts)
+	c, err := m.otel.Int64UpDownCounter(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &int64UpDownCounter{c}, nil
+}
+
+func (m *meter) Float64UpDownCounter(name string, opts ...metrics.InstrumentOption) (metrics.Float64UpDownCounter, error) {
+	d, u := instrumentOptions(opts)
+	c, err := m.otel.Float64UpDownCounter(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &float64UpDownCounter{c}, nil
+}
+
+func (m *meter) Int64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Int64Gauge, error) {
+	d, u := instrumentOptions(opts)
+	g, err := m.otel.Int64Gauge(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &int64Gauge{g}, nil
+}
+
+func (m *meter) Float64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Float64Gauge, error) {
+	d, u := instrumentOptions(opts)
+	g, err := m.otel.Float64Gauge(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &float64Gauge{g}, nil
+}
+
+func (m *meter) Int64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	d, u := instrumentOptions(opts)
+	h, err := m.otel.Int64Histogram(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &int64Histogram{h}, nil
+}
+
+// Int64HistogramWithBuckets creates a histogram with the given bucket boundaries. It
+// implements dax.HistogramBucketsMeter, through which the client passes the boundaries of
+// its histograms.
+func (m *meter) Int64HistogramWithBuckets(name string, buckets []float64, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	d, u := instrumentOptions(opts)
+	h, err := m.otel.Int64Histogram(name, d, u, metric.WithExplicitBucketBoundaries(buckets...))
+	if err != nil {
+		return nil, err
+	}
+	return &int64Histogram{h}, nil
+}
+
+func (m *meter) Float64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Float64Histogram, error) {
+	d, u := instrumentOptions(opts)
+	h, err := m.otel.Float64Histogram(name, d, u)
+	if err != nil {
+		return nil, err
+	}
+	return &float64Histogram{h}, nil
+}
+
+// attributes returns the properties of a measurement as OpenTelemetry attributes.
+func attributes(opts []metrics.RecordMetricOption) metric.MeasurementOption {
+	var o metrics.RecordMetricOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	values := o.Properties.Values()
+	kvs := make([]attribute.KeyValue, 0, len(values))
+	for k, v := range values {
+		key := attribute.Key(fmt.Sprint(k))
+		switch v := v.(type) {
+		case string:
+			kvs = append(kvs, key.String(v))
+		case bool:
+			kvs = append(kvs, key.Bool(v))
+		case int:
+			kvs = append(kvs, key.Int(v))
+		case int64:
+			kvs = append(kvs, key.Int64(v))
+		case float64:
+			kvs = append(kvs, key.Float64(v))
+		default:
+			kvs = append(kvs, key.String(fmt.Sprint(v)))
+		}
+	}
+	return metric.WithAttributes(kvs...)
+}
+
+type int64Counter struct{ c metric.Int64Counter }
+
+func (c *int64Counter) Add(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	c.c.Add(ctx, v, attributes(opts))
+}
+
+type float64Counter struct{ c metric.Float64Counter }
+
+func (c *float64Counter) Add(ctx context.Context, v float64, opts ...metrics.RecordMetricOption) {
+	c.c.Add(ctx, v, attributes(opts))
+}
+
+type int64UpDownCounter struct{ c metric.Int64UpDownCounter }
+
+func (c *int64UpDownCounter) Add(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	c.c.Add(ctx, v, attributes(opts))
+}
+
+type float64UpDownCounter struct{ c metric.Float64UpDownCounter }
+
+func (c *float64UpDownCounter) Add(ctx context.Context, v float64, opts ...metrics.RecordMetricOption) {
+	c.c.Add(ctx, v, attributes(opts))
+}
+
+type int64Gauge struct{ g metric.Int64Gauge }
+
+func (g *int64Gauge) Sample(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	g.g.Record(ctx, v, attributes(opts))
+}
+
+type float64Gauge struct{ g metric.Float64Gauge }
+
+func (g *float64Gauge) Sample(ctx context.Context, v float64, opts ...metrics.RecordMetricOption) {
+	g.g.Record(ctx, v, attributes(opts))
+}
+
+type int64Histogram struct{ h metric.Int64Histogram }
+
+func (h *int64Histogram) Record(ctx context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	h.h.Record(ctx, v, attributes(opts))
+}
+
+type float64Histogram struct{ h metric.Float64Histogram }
+
+func (h *float64Histogram) Record(ctx context.Context, v float64, opts ...metrics.RecordMetricOption) {
+	h.h.Record(ctx, v, attributes(opts))
+}
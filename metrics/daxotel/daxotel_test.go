@@ -0,0 +1,126 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package daxotel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/metrics"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func withProperty(k, v string) metrics.RecordMetricOption {
+	return func(o *metrics.RecordMetricOptions) {
+		o.Properties.Set(k, v)
+	}
+}
+
+func collect(t *testing.T, reader sdkmetric.Reader, name string) metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return metricdata.Metrics{}
+}
+
+func TestMeterProvider_counter(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	p := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	c, err := p.Meter("dax").Int64Counter("dax.op.GetItem.success", func(o *metrics.InstrumentOptions) {
+		o.Description = "Operations GetItem success"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add(ctx, 2, withProperty("endpoint", "127.0.0.1:8111"))
+	c.Add(ctx, 1, withProperty("endpoint", "127.0.0.1:8111"))
+
+	m := collect(t, reader, "dax.op.GetItem.success")
+	if m.Description != "Operations GetItem success" {
+		t.Errorf("unexpected description %q", m.Description)
+	}
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("expected one int64 sum data point, got %#v", m.Data)
+	}
+	dp := sum.DataPoints[0]
+	if dp.Value != 3 {
+		t.Errorf("expected 3, got %d", dp.Value)
+	}
+	if v, ok := dp.Attributes.Value("endpoint"); !ok || v.AsString() != "127.0.0.1:8111" {
+		t.Errorf("unexpected endpoint attribute %v", v)
+	}
+}
+
+func TestMeterProvider_gaugeAndHistogram(t *testing.T) {
+	ctx := context.Background()
+	reader := sdkmetric.NewManualReader()
+	m := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))).Meter("dax")
+
+	g, err := m.Int64Gauge("dax.connections.idle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Sample(ctx, 7)
+	g.Sample(ctx, 4)
+	gauge, ok := collect(t, reader, "dax.connections.idle").Data.(metricdata.Gauge[int64])
+	if !ok || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 4 {
+		t.Errorf("expected the last sample, got %#v", gauge)
+	}
+
+	bm, ok := m.(interface {
+		Int64HistogramWithBuckets(name string, buckets []float64, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error)
+	})
+	if !ok {
+		t.Fatal("meter doesn't accept histogram buckets")
+	}
+	h, err := bm.Int64HistogramWithBuckets("dax.op.GetItem.latency_us", []float64{100, 1000}, func(o *metrics.InstrumentOptions) {
+		o.UnitLabel = "Microseconds"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record(ctx, 250)
+
+	hm := collect(t, reader, "dax.op.GetItem.latency_us")
+	if hm.Unit != "us" {
+		t.Errorf("expected unit us, got %q", hm.Unit)
+	}
+	hist, ok := hm.Data.(metricdata.Histogram[int64])
+	if !ok || len(hist.DataPoints) != 1 {
+		t.Fatalf("expected one int64 histogram data point, got %#v", hm.Data)
+	}
+	if !reflect.DeepEqual(hist.DataPoints[0].Bounds, []float64{100, 1000}) {
+		t.Errorf("unexpected bounds %v", hist.DataPoints[0].Bounds)
+	}
+	if hist.DataPoints[0].Count != 1 {
+		t.Errorf("expected 1 measurement, got %d", hist.DataPoints[0].Count)
+	}
+}
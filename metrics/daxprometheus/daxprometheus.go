@@ -0,0 +1,325 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+// Package daxprometheus implements a smithy-go metrics.MeterProvider exporting the metrics
+// of the DAX client to Prometheus:
+//
+//	cfg := dax.DefaultConfig()
+//	cfg.MeterProvider = daxprometheus.New(prometheus.DefaultRegisterer)
+//
+// Counters, gauges and histograms become Prometheus counters, gauges and histograms. Their
+// names are derived from the instrument names by replacing the characters Prometheus does
+// not allow with underscores, and counters get the _total suffix: dax.op.GetItem.success is
+// exported as dax_op_GetItem_success_total and dax.connections.idle as
+// dax_connections_idle.
+//
+// It is a separate module so that the DAX client doesn't depend on the Prometheus client.
+package daxprometheus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultBuckets are the bucket boundaries of histograms created without explicit ones.
+// The DAX client passes explicit boundaries for all its histograms.
+var DefaultBuckets = prometheus.ExponentialBuckets(1, 4, 12)
+
+// Option configures a MeterProvider.
+type Option func(*MeterProvider)
+
+// WithNamespace prefixes the names of all metrics with namespace and an underscore.
+func WithNamespace(namespace string) Option {
+	return func(p *MeterProvider) {
+		p.namespace = namespace
+	}
+}
+
+// WithLabels exports the measurement properties with the given names, such as the labels
+// of a dax.ClientSet, as labels of every metric. Prometheus needs to know the labels of a
+// metric upfront: other properties are dropped, and labels missing from a measurement are
+// exported empty.
+func WithLabels(names ...string) Option {
+	return func(p *MeterProvider) {
+		p.labels = append(p.labels, names...)
+	}
+}
+
+// MeterProvider creates instruments registered with a prometheus.Registerer. Instruments
+// with the same name share the registered metric, so that several clients can use the
+// same MeterProvider.
+type MeterProvider struct {
+	registerer prometheus.Registerer
+	namespace  string
+	labels     []string
+
+	mu         sync.Mutex
+	collectors map[string]prometheus.Collector // protected by mu
+}
+
+var _ metrics.MeterProvider = (*MeterProvider)(nil)
+
+// New returns a MeterProvider registering its metrics with registerer.
+func New(registerer prometheus.Registerer, opts ...Option) *MeterProvider {
+	p := &MeterProvider{
+		registerer: registerer,
+		collectors: make(map[string]prometheus.Collector),
+	}
+	for _, fn := range opts {
+		fn(p)
+	}
+	return p
+}
+
+// Meter returns a meter creating Prometheus instruments. Asynchronous instruments are
+// not supported and do not record anything; the DAX client does not use them.
+func (p *MeterProvider) Meter(scope string, opts ...metrics.MeterOption) metrics.Meter {
+	return &meter{Meter: metrics.NopMeterProvider{}.Meter(scope), provider: p}
+}
+
+// register returns the collector registered under name, creating it with newFn if there
+// is none yet. A collector registered by someone else with the same descriptor is reused.
+func (p *MeterProvider) register(name string, newFn func(name string) prometheus.Collector) (prometheus.Collector, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.collectors[name]; ok {
+		return c, nil
+	}
+	c := newFn(name)
+	if err := p.registerer.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, err
+		}
+		c = are.ExistingCollector
+	}
+	p.collectors[name] = c
+	return c, nil
+}
+
+// metricName returns the Prometheus name of the instrument called name.
+func metricName(name, suffix string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':', r >= '0' && r <= '9' && i > 0:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String() + suffix
+}
+
+// labelValues returns the values of the labels of p among the properties of a measurement.
+func (p *MeterProvider) labelValues(opts []metrics.RecordMetricOption) []string {
+	if len(p.labels) == 0 {
+		return nil
+	}
+	var o metrics.RecordMetricOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	values := make([]string, len(p.labels))
+	for i, l := range p.labels {
+		if v := o.Properties.Get(l); v != nil {
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return values
+}
+
+type meter struct {
+	metrics.Meter
+	provider *MeterProvider
+}
+
+func description(name string, opts []metrics.InstrumentOption) string {
+	var o metrics.InstrumentOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	if o.Description == "" {
+		return name
+	}
+	return o.Description
+}
+
+func (m *meter) counterVec(name string, opts []metrics.InstrumentOption) (*prometheus.CounterVec, error) {
+	c, err := m.provider.register(metricName(name, "_total"), func(n string) prometheus.Collector {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: m.provider.namespace,
+			Name:      n,
+			Help:      description(name, opts),
+		}, m.provider.labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := c.(*prometheus.CounterVec)
+	if !ok {
+		return nil, fmt.Errorf("daxprometheus: %s is registered with another type", name)
+	}
+	return v, nil
+}
+
+func (m *meter) gaugeVec(name string, opts []metrics.InstrumentOption) (*prometheus.GaugeVec, error) {
+	c, err := m.provider.register(metricName(name, ""), func(n string) prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: m.provider.namespace,
+			Name:      n,
+			Help:      description(name, opts),
+		}, m.provider.labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := c.(*prometheus.GaugeVec)
+	if !ok {
+		return nil, fmt.Errorf("daxprometheus: %s is registered with another type", name)
+	}
+	return v, nil
+}
+
+func (m *meter) histogramVec(name string, buckets []float64, opts []metrics.InstrumentOption) (*prometheus.HistogramVec, error) {
+	c, err := m.provider.register(metricName(name, ""), func(n string) prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: m.provider.namespace,
+			Name:      n,
+			Help:      description(name, opts),
+			Buckets:   buckets,
+		}, m.provider.labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	v, ok := c.(*prometheus.HistogramVec)
+	if !ok {
+		return nil, fmt.Errorf("daxprometheus: %s is registered with another type", name)
+	}
+	return v, nil
+}
+
+func (m *meter) Int64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Int64Counter, error) {
+	v, err := m.counterVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &counter[int64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Float64Counter(name string, opts ...metrics.InstrumentOption) (metrics.Float64Counter, error) {
+	v, err := m.counterVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &counter[float64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Int64UpDownCounter(name string, opts ...metrics.InstrumentOption) (metrics.Int64UpDownCounter, error) {
+	v, err := m.gaugeVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &gauge[int64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Float64UpDownCounter(name string, opts ...metrics.InstrumentOption) (metrics.Float64UpDownCounter, error) {
+	v, err := m.gaugeVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &gauge[float64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Int64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Int64Gauge, error) {
+	v, err := m.gaugeVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &gauge[int64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Float64Gauge(name string, opts ...metrics.InstrumentOption) (metrics.Float64Gauge, error) {
+	v, err := m.gaugeVec(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &gauge[float64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Int64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	return m.Int64HistogramWithBuckets(name, DefaultBuckets, opts...)
+}
+
+// Int64HistogramWithBuckets creates a histogram with the given bucket boundaries. It
+// implements dax.HistogramBucketsMeter, through which the client passes the boundaries of
+// its histograms, such as Config.LatencyHistogramBuckets.
+func (m *meter) Int64HistogramWithBuckets(name string, buckets []float64, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error) {
+	v, err := m.histogramVec(name, buckets, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &histogram[int64]{vec: v, provider: m.provider}, nil
+}
+
+func (m *meter) Float64Histogram(name string, opts ...metrics.InstrumentOption) (metrics.Float64Histogram, error) {
+	v, err := m.histogramVec(name, DefaultBuckets, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &histogram[float64]{vec: v, provider: m.provider}, nil
+}
+
+type number interface {
+	int64 | float64
+}
+
+type counter[T number] struct {
+	vec      *prometheus.CounterVec
+	provider *MeterProvider
+}
+
+func (c *counter[T]) Add(_ context.Context, v T, opts ...metrics.RecordMetricOption) {
+	c.vec.WithLabelValues(c.provider.labelValues(opts)...).Add(float64(v))
+}
+
+type gauge[T number] struct {
+	vec      *prometheus.GaugeVec
+	provider *MeterProvider
+}
+
+func (g *gauge[T]) Add(_ context.Context, v T, opts ...metrics.RecordMetricOption) {
+	g.vec.WithLabelValues(g.provider.labelValues(opts)...).Add(float64(v))
+}
+
+func (g *gauge[T]) Sample(_ context.Context, v T, opts ...metrics.RecordMetricOption) {
+	g.vec.WithLabelValues(g.provider.labelValues(opts)...).Set(float64(v))
+}
+
+type histogram[T number] struct {
+	vec      *prometheus.HistogramVec
+	provider *MeterProvider
+}
+
+func (h *histogram[T]) Record(_ context.Context, v T, opts ...metrics.RecordMetricOption) {
+	h.vec.WithLabelValues(h.provider.labelValues(opts)...).Observe(float64(v))
+}
@@ -0,0 +1,126 @@
+/*
+  Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+
+  Licensed under the Apache License, Version 2.0 (the "License").
+  You may not use this file except in compliance with the License.
+  A copy of the License is located at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+  or in the "license" file accompanying this file. This file is distributed
+  on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+  express or implied. See the License for the specific language governing
+  permissions and limitations under the License.
+*/
+
+package daxprometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func withProperty(k, v string) metrics.RecordMetricOption {
+	return func(o *metrics.RecordMetricOptions) {
+		o.Properties.Set(k, v)
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	cases := map[string]string{
+		"dax.op.GetItem.success": "dax_op_GetItem_success",
+		"dax.connections.idle":   "dax_connections_idle",
+		"1st-metric":             "_st_metric",
+	}
+	for in, expected := range cases {
+		if actual := metricName(in, ""); actual != expected {
+			t.Errorf("metricName(%q) = %q, expected %q", in, actual, expected)
+		}
+	}
+}
+
+func TestMeterProvider_counter(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	p := New(reg, WithLabels("cluster"))
+
+	c, err := p.Meter("dax").Int64Counter("dax.op.GetItem.success")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Add(ctx, 2, withProperty("cluster", "a"), withProperty("other", "x"))
+
+	// a second client creating the same instrument shares the metric
+	c2, err := p.Meter("dax").Int64Counter("dax.op.GetItem.success")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2.Add(ctx, 1, withProperty("cluster", "a"))
+	c2.Add(ctx, 5)
+
+	vec := c.(*counter[int64]).vec
+	if v := testutil.ToFloat64(vec.WithLabelValues("a")); v != 3 {
+		t.Errorf("expected 3, got %v", v)
+	}
+	if v := testutil.ToFloat64(vec.WithLabelValues("")); v != 5 {
+		t.Errorf("expected 5, got %v", v)
+	}
+	if n, err := testutil.GatherAndCount(reg, "dax_op_GetItem_success_total"); err != nil || n != 2 {
+		t.Errorf("expected 2 series, got %d (%v)", n, err)
+	}
+}
+
+func TestMeterProvider_gaugeAndHistogram(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	p := New(reg, WithNamespace("app"))
+	m := p.Meter("dax")
+
+	g, err := m.Int64Gauge("dax.connections.idle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Sample(ctx, 7)
+	g.Sample(ctx, 4)
+	if v := testutil.ToFloat64(g.(*gauge[int64]).vec.WithLabelValues()); v != 4 {
+		t.Errorf("expected 4, got %v", v)
+	}
+
+	bm, ok := m.(interface {
+		Int64HistogramWithBuckets(name string, buckets []float64, opts ...metrics.InstrumentOption) (metrics.Int64Histogram, error)
+	})
+	if !ok {
+		t.Fatal("meter doesn't accept histogram buckets")
+	}
+	h, err := bm.Int64HistogramWithBuckets("dax.op.GetItem.latency_us", []float64{100, 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record(ctx, 250)
+	if n, err := testutil.GatherAndCount(reg, "app_dax_op_GetItem_latency_us"); err != nil || n != 1 {
+		t.Errorf("expected 1 series, got %d (%v)", n, err)
+	}
+}
+
+func TestMeterProvider_sharedRegistry(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c1, err := New(reg).Meter("dax").Int64Counter("dax.connections.created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := New(reg).Meter("dax").Int64Counter("dax.connections.created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1.(*counter[int64]).vec != c2.(*counter[int64]).vec {
+		t.Error("expected providers sharing a registry to share metrics")
+	}
+
+	if _, err := New(reg).Meter("dax").Int64Gauge("dax.connections.created_total"); err == nil {
+		t.Error("expected error registering a gauge under the name of a counter")
+	}
+}